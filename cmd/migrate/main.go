@@ -1,17 +1,27 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"github.com/teresa-solution/tenant-management-service/internal/provisioning"
+	"github.com/teresa-solution/tenant-management-service/internal/store"
+	"github.com/teresa-solution/tenant-management-service/internal/tenantschema"
 )
 
 func main() {
@@ -26,7 +36,26 @@ func main() {
 		dbUser  = flag.String("db-user", "admin", "Database user")
 		dbPass  = flag.String("db-pass", "securepassword", "Database password")
 		dbName  = flag.String("db-name", "tenant_registry", "Database name")
-		command = flag.String("command", "up", "Migration command (up, down, force)")
+		command = flag.String("command", "up", "Migration command (up, down, force, tenants)")
+
+		// tenants command
+		templateDir       = flag.String("tenant-template-dir", "scripts/tenant-migrations", "Directory holding the tenant schema template migrations")
+		sharedTemplateDir = flag.String("tenant-shared-template-dir", "scripts/tenant-shared-migrations", "Directory holding the template migrations of the tables shared tenants keep their rows in")
+		concurrency       = flag.Int("concurrency", 4, "Tenant schemas migrated at once")
+		canary            = flag.Int("canary", 0, "Only migrate the first N outdated tenant schemas (0 migrates all)")
+		tenantIDs         = flag.String("tenants", "", "Comma-separated tenant IDs to migrate (empty migrates all)")
+		maxFailures       = flag.Int("max-failures", 1, "Stop starting new tenant schemas after this many failed (0 never stops)")
+		dryRun            = flag.Bool("dry-run", false, "Report pending tenant migrations without applying them")
+
+		dedicatedDBHost    = flag.String("dedicated-db-host", "", "Server holding dedicated tenant databases to migrate as well")
+		dedicatedDBPort    = flag.Int("dedicated-db-port", 5432, "Port of the dedicated database server")
+		dedicatedDBUser    = flag.String("dedicated-db-user", "admin", "Administrator of the dedicated database server")
+		dedicatedDBPass    = flag.String("dedicated-db-pass", "", "Password of -dedicated-db-user")
+		dedicatedDBAdminDB = flag.String("dedicated-db-admin-name", "postgres", "Database connected to on the dedicated database server")
+
+		shardDBUser    = flag.String("shard-db-user", "admin", "User allowed to migrate tenant schemas on every registered database shard")
+		shardDBPass    = flag.String("shard-db-pass", "", "Password of -shard-db-user")
+		shardDBAdminDB = flag.String("shard-db-admin-name", "postgres", "Database connected to on a shard's server to manage tenant roles")
 	)
 	flag.Parse()
 
@@ -34,6 +63,38 @@ func main() {
 	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
 		*dbHost, *dbPort, *dbUser, *dbPass, *dbName)
 
+	if *command == "tenants" {
+		opts := tenantschema.RolloutOptions{
+			Concurrency: *concurrency,
+			Canary:      *canary,
+			MaxFailures: *maxFailures,
+			DryRun:      *dryRun,
+		}
+		for _, id := range strings.Split(*tenantIDs, ",") {
+			if id = strings.TrimSpace(id); id == "" {
+				continue
+			}
+			tenantID, err := uuid.Parse(id)
+			if err != nil {
+				log.Fatal().Err(err).Str("tenant_id", id).Msg("Invalid tenant ID")
+			}
+			opts.Tenants = append(opts.Tenants, tenantID)
+		}
+		var dedicated *store.DatabaseServer
+		if *dedicatedDBHost != "" {
+			var err error
+			dedicated, err = store.NewDatabaseServer(*dedicatedDBHost, *dedicatedDBPort, *dedicatedDBUser, *dedicatedDBPass, *dedicatedDBAdminDB)
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to connect to dedicated database server")
+			}
+		}
+		shards := shardLogin{user: *shardDBUser, password: *shardDBPass, adminDB: *shardDBAdminDB}
+		if !migrateTenants(dsn, *templateDir, *sharedTemplateDir, dedicated, shards, opts) {
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Connect to the database
 	config, err := pgx.ParseConfig(dsn)
 	if err != nil {
@@ -81,3 +142,97 @@ func main() {
 		log.Fatal().Msgf("Unknown command: %s", *command)
 	}
 }
+
+// shardLogin is how cmd/migrate logs in to every registered database shard
+type shardLogin struct {
+	user, password, adminDB string
+}
+
+// migrateTenants rolls the tenant schema template across tenant schemas in
+// the registry database and on every registered shard and, when dedicated is
+// set, the dedicated databases on it. Unless the rollout is restricted to
+// some tenants, the shared tables are brought up to the shared template as
+// well. It prints a report to stdout and reports whether every schema
+// succeeded.
+func migrateTenants(dsn, templateDir, sharedTemplateDir string, dedicated *store.DatabaseServer, login shardLogin, opts tenantschema.RolloutOptions) bool {
+	template, err := tenantschema.LoadDir(templateDir)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load tenant schema template")
+	}
+	sharedTemplate, err := tenantschema.LoadDir(sharedTemplateDir)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load shared tenant template")
+	}
+	repo, err := store.NewTenantRepository(dsn)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+	defer repo.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	log.Info().
+		Int("template_version", template.Version()).
+		Int("shared_template_version", sharedTemplate.Version()).
+		Bool("dry_run", opts.DryRun).
+		Msg("Migrating tenant schemas...")
+	type rollout struct {
+		registry tenantschema.Registry
+		template *tenantschema.Template
+	}
+	rollouts := []rollout{{repo, template}}
+	shards, err := repo.ListShards(ctx)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to list database shards")
+	}
+	for _, shard := range shards {
+		server, err := store.NewShardServer(shard.Host, shard.Port, login.user, login.password, login.adminDB, shard.DatabaseName)
+		if err != nil {
+			log.Fatal().Err(err).Str("shard", shard.Name).Msg("Failed to connect to database shard")
+		}
+		defer server.Close()
+		rollouts = append(rollouts, rollout{provisioning.ShardSchemas(repo, shard.ID, server), template})
+	}
+	if dedicated != nil {
+		defer dedicated.Close()
+		rollouts = append(rollouts, rollout{provisioning.DedicatedTemplateStore(repo, dedicated), template})
+	}
+	if len(opts.Tenants) == 0 {
+		rollouts = append(rollouts, rollout{provisioning.SharedTemplateStore(repo), sharedTemplate})
+	}
+	report := &tenantschema.Report{TemplateVersion: template.Version(), DryRun: opts.DryRun}
+	for _, ro := range rollouts {
+		r, err := tenantschema.Rollout(ctx, ro.registry, ro.template, opts)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to list tenant schemas")
+		}
+		report.Results = append(report.Results, r.Results...)
+		if r.Stopped {
+			report.Stopped = true
+			break
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TENANT\tSCHEMA\tOUTCOME\tFROM\tTO\tDURATION\tERROR")
+	for _, res := range report.Results {
+		errMsg := ""
+		if res.Err != nil {
+			errMsg = res.Err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\t%s\n", res.TenantID, res.SchemaName, res.Outcome,
+			res.From, res.To, res.Duration.Round(time.Millisecond), errMsg)
+	}
+	w.Flush()
+
+	fmt.Printf("\nTemplate version %d (shared %d): %d schemas, %d migrated, %d up to date, %d pending, %d failed, %d skipped\n",
+		report.TemplateVersion, sharedTemplate.Version(), len(report.Results),
+		report.Count(tenantschema.OutcomeMigrated), report.Count(tenantschema.OutcomeUpToDate),
+		report.Count(tenantschema.OutcomePending), report.Count(tenantschema.OutcomeFailed),
+		report.Count(tenantschema.OutcomeSkipped))
+	if report.Stopped {
+		fmt.Println("Rollout stopped early; skipped schemas were left untouched")
+	}
+	return report.Count(tenantschema.OutcomeFailed) == 0 && report.Count(tenantschema.OutcomeSkipped) == 0
+}
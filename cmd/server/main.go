@@ -1,22 +1,36 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"flag"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp" // Add this import
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"github.com/teresa-solution/tenant-management-service/internal/auth"
+	"github.com/teresa-solution/tenant-management-service/internal/dns"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
 	"github.com/teresa-solution/tenant-management-service/internal/monitoring" // Add this import
+	"github.com/teresa-solution/tenant-management-service/internal/provisioning"
+	"github.com/teresa-solution/tenant-management-service/internal/secrets"
 	"github.com/teresa-solution/tenant-management-service/internal/service"
 	"github.com/teresa-solution/tenant-management-service/internal/store"
+	"github.com/teresa-solution/tenant-management-service/internal/tenantschema"
+	grpcmw "github.com/teresa-solution/tenant-management-service/pkg/grpc"
+	"github.com/teresa-solution/tenant-management-service/pkg/resilience"
+	"github.com/teresa-solution/tenant-management-service/pkg/tlsconfig"
 	tenantpb "github.com/teresa-solution/tenant-management-service/proto/gen"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -31,19 +45,325 @@ func main() {
 		dbUser = flag.String("db-user", "admin", "Database user")
 		dbPass = flag.String("db-pass", "securepassword", "Database password")
 		dbName = flag.String("db-name", "tenant_registry", "Database name")
+
+		redisAddr = flag.String("redis-addr", "localhost:6379", "Redis server address")
+		redisPass = flag.String("redis-pass", "", "Redis password")
+
+		secretBackend       = flag.String("secret-store", secrets.BackendNone, "Where generated tenant credentials are kept and -*-pass-secret passwords are read from (none, file, vault)")
+		secretStoreFile     = flag.String("secret-store-file", "", "Encrypted secrets file of the file secret store")
+		secretStoreKeyFile  = flag.String("secret-store-key-file", "", "File holding the base64 encoded 32-byte key of -secret-store-file")
+		vaultAddr           = flag.String("vault-addr", "", "Address of the Vault server of the vault secret store")
+		vaultTokenFile      = flag.String("vault-token-file", "", "File holding the Vault token; VAULT_TOKEN is used when empty")
+		vaultNamespace      = flag.String("vault-namespace", "", "Vault Enterprise namespace")
+		vaultMount          = flag.String("vault-kv-mount", "secret", "Mount path of the Vault KV version 2 secrets engine")
+		vaultPrefix         = flag.String("vault-kv-prefix", "tenant-management", "Path under the KV mount secrets are kept at")
+		dbPassSecret        = flag.String("db-pass-secret", "", "ID of the secret holding the database password; overrides -db-pass")
+		redisPassSecret     = flag.String("redis-pass-secret", "", "ID of the secret holding the Redis password; overrides -redis-pass")
+		shardPassSecret     = flag.String("shard-db-pass-secret", "", "ID of the secret holding the password of -shard-db-user; overrides -shard-db-pass")
+		dedicatedPassSecret = flag.String("dedicated-db-pass-secret", "", "ID of the secret holding the password of -dedicated-db-user; overrides -dedicated-db-pass")
+
+		tlsCert           = flag.String("tls-cert", "", "TLS certificate file; enables TLS on the gRPC and HTTP listeners")
+		tlsKey            = flag.String("tls-key", "", "TLS private key file")
+		tlsClientCA       = flag.String("tls-client-ca", "", "CA bundle used to verify client certificates")
+		tlsClientAuth     = flag.String("tls-client-auth", tlsconfig.ClientAuthNone, "Client certificate policy (none, request, verify-if-given, require)")
+		tlsReloadInterval = flag.Duration("tls-reload-interval", 30*time.Second, "How often certificate files are checked for changes")
+
+		authDisabled       = flag.Bool("auth-disabled", false, "Disable RPC authentication (local development only)")
+		authJWKSFile       = flag.String("auth-jwks-file", "", "JWKS file with keys trusted to sign bearer tokens")
+		authHMACSecretFile = flag.String("auth-hmac-secret-file", "", "File holding a shared secret trusted for HS256/384/512 tokens")
+		authPublicKeys     = flag.String("auth-public-keys", "", "Comma-separated PEM public key or certificate files trusted to sign tokens")
+		authIssuer         = flag.String("auth-issuer", "", "Required token issuer (iss)")
+		authAudience       = flag.String("auth-audience", "", "Required token audience (aud)")
+		authRolesClaim     = flag.String("auth-roles-claim", "roles", "Token claim holding the caller's roles or groups")
+		authTenantClaim    = flag.String("auth-tenant-claim", "tenant_id", "Token claim holding the tenant of a tenant-admin")
+		authRoleAliases    = flag.String("auth-role-aliases", "", "Comma-separated group=role pairs mapping token groups onto roles")
+		authAPIKeys        = flag.Bool("auth-api-keys", true, "Accept tenant API keys in addition to bearer tokens")
+
+		rateLimitEnabled = flag.Bool("rate-limit", true, "Enable per-caller rate limiting backed by Redis")
+		rateLimits       = flag.String("rate-limits", auth.DefaultRoleRateLimits, "Comma-separated role=rate:burst limits in requests per second; the role \"default\" covers callers without a role")
+		rateLimitMethods = flag.String("rate-limit-methods", auth.DefaultMethodRateLimits, "Comma-separated Method=rate:burst caps applied to individual RPCs")
+		rateLimitConn    = flag.String("rate-limit-connection", "20:40", "rate:burst limit per client certificate or peer address and RPC, applied before authentication; empty disables it")
+
+		subdomainReclaim      = flag.String("subdomain-reclaim", service.ReclaimNever, "Whether deleted tenants' subdomains can be reused (never, after-grace, immediately)")
+		subdomainReclaimGrace = flag.Duration("subdomain-reclaim-grace", 30*24*time.Hour, "How long a deleted tenant keeps its subdomain when -subdomain-reclaim=after-grace")
+
+		customDomainTokenTTL   = flag.Duration("custom-domain-token-ttl", service.DefaultDomainVerification.TokenTTL, "How long a custom domain verification token is accepted")
+		customDomainNameserver = flag.String("custom-domain-nameserver", "", "host:port of the DNS server custom domain TXT records are looked up with; the system resolver is used when empty")
+
+		provisioningWorker      = flag.Bool("provisioning-worker", true, "Process provisioning jobs in this replica")
+		provisioningPoll        = flag.Duration("provisioning-poll-interval", 2*time.Second, "How often an idle worker looks for provisioning jobs")
+		provisioningLease       = flag.Duration("provisioning-lease", time.Minute, "How long a claimed provisioning job stays invisible to other workers without a heartbeat")
+		provisioningHeartbeat   = flag.Duration("provisioning-heartbeat", 20*time.Second, "How often a running provisioning job's lease is extended")
+		provisioningMaxAttempts = flag.Int("provisioning-max-attempts", 5, "Attempts before a provisioning job is dead-lettered")
+		provisioningRetryDelay  = flag.Duration("provisioning-retry-delay", 30*time.Second, "Delay before the first retry of a failed provisioning job; doubles with every attempt")
+		provisioningMaxRetry    = flag.Duration("provisioning-max-retry-delay", 10*time.Minute, "Cap on the delay between provisioning attempts")
+		provisioningWorkers     = flag.Int("provisioning-workers", 4, "Provisioning jobs this replica runs concurrently")
+		provisioningHostLimit   = flag.Int("provisioning-host-concurrency", 2, "Provisioning jobs this replica runs concurrently against one database host")
+		provisioningHostLimits  = flag.String("provisioning-host-limits", "", "Comma-separated host=limit overrides of -provisioning-host-concurrency")
+		provisioningMaxDepth    = flag.Int64("provisioning-max-queue-depth", 1000, "Pending provisioning jobs at which the queue counts as saturated (0 disables)")
+		provisioningSaturated   = flag.String("provisioning-when-saturated", "queue", "What CreateTenant does when the queue is saturated (queue, reject)")
+		provisioningRecovery    = flag.Duration("provisioning-recovery-interval", 5*time.Minute, "How often tenants stuck in provisioning are looked for")
+		provisioningStuckAfter  = flag.Duration("provisioning-stuck-after", 15*time.Minute, "How long a tenant may stay in provisioning without progress before it is recovered")
+		provisioningStepTimeout = flag.Duration("provisioning-step-timeout", 30*time.Second, "Time limit of each provisioning step")
+		moveStepTimeout         = flag.Duration("move-step-timeout", 30*time.Minute, "Time limit of each tenant move step copying or comparing data")
+		deprovisioningDropDelay = flag.Duration("deprovisioning-schema-drop-delay", 7*24*time.Hour, "How long a deleted tenant's schema is kept before it is dropped")
+		tenantTemplateDir       = flag.String("tenant-template-dir", "scripts/tenant-migrations", "Directory of versioned SQL migrations applied to every tenant schema")
+		tenantSharedTemplateDir = flag.String("tenant-shared-template-dir", "scripts/tenant-shared-migrations", "Directory of versioned SQL migrations building the tables tenants of the shared isolation mode keep their rows in")
+		tenantBaseDomain        = flag.String("tenant-base-domain", "tenants.local", "Domain under which tenant subdomains are registered")
+		tenantDNSTarget         = flag.String("tenant-dns-target", "", "Hostname or IP address tenant hostnames point at, such as the ingress hostname; no records are created when empty")
+		tenantIsolation         = flag.String("tenant-isolation", "", "Comma-separated tier=mode pairs choosing how tenants of a tier are isolated (schema, database, shared); other tiers get a schema")
+		tenantStatementTimeout  = flag.Duration("tenant-role-statement-timeout", provisioning.DefaultRoleLimits.StatementTimeout, "Statement timeout of every tenant database role (0 disables)")
+		tenantConnectionLimit   = flag.Int("tenant-role-connection-limit", provisioning.DefaultRoleLimits.ConnectionLimit, "Connections each tenant database role may hold at once")
+
+		dedicatedDBHost    = flag.String("dedicated-db-host", "", "Database server dedicated tenant databases are created on; required by the database isolation mode")
+		dedicatedDBPort    = flag.Int("dedicated-db-port", 5432, "Port of the dedicated database server")
+		dedicatedDBUser    = flag.String("dedicated-db-user", "admin", "User allowed to create roles and databases on the dedicated database server")
+		dedicatedDBPass    = flag.String("dedicated-db-pass", "", "Password of -dedicated-db-user")
+		dedicatedDBAdminDB = flag.String("dedicated-db-admin-name", "postgres", "Database connected to on the dedicated database server to manage tenant databases")
+
+		shardPlacement     = flag.String("shard-placement", provisioning.PlacementLeastLoaded, "Strategy placing new schema tenants on registered database shards (least-loaded, weighted, region-affine, pinned:<shard>)")
+		shardTierPlacement = flag.String("shard-tier-placement", "", "Comma-separated tier=strategy pairs overriding -shard-placement for tenants of a tier")
+		shardDBUser        = flag.String("shard-db-user", "admin", "User allowed to create roles and schemas on every database shard")
+		shardDBPass        = flag.String("shard-db-pass", "", "Password of -shard-db-user")
+		shardDBAdminDB     = flag.String("shard-db-admin-name", "postgres", "Database connected to on a shard's server to manage tenant roles")
+
+		dnsProvider       = flag.String("dns-provider", dns.ProviderLog, "Where tenant DNS records are created (log, zone-file, rfc2136)")
+		dnsZoneFile       = flag.String("dns-zone-file", "", "File the zone-file DNS provider keeps tenant records in")
+		dnsServer         = flag.String("dns-server", "", "host:port of the primary server the rfc2136 DNS provider sends updates to")
+		dnsZone           = flag.String("dns-zone", "", "Zone the rfc2136 DNS provider updates; -tenant-base-domain when empty")
+		dnsTSIGKeyName    = flag.String("dns-tsig-key-name", "", "Name of the TSIG key updates are signed with; updates are unsigned when empty")
+		dnsTSIGAlgorithm  = flag.String("dns-tsig-algorithm", dns.HMACSHA256, "Algorithm of the TSIG key (hmac-sha1, hmac-sha256, hmac-sha512)")
+		dnsTSIGSecretFile = flag.String("dns-tsig-secret-file", "", "File holding the base64 encoded secret of the TSIG key")
+		dnsTTL            = flag.Int("dns-ttl", 300, "TTL in seconds of tenant DNS records")
+
+		alertWebhookURL = flag.String("alert-webhook-url", "", "URL alerts are posted to as JSON; alerts are only logged when empty")
+		alertTimeout    = flag.Duration("alert-timeout", 5*time.Second, "Time limit for delivering an alert to the webhook")
 	)
 	flag.Parse()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tlsCfg := tlsconfig.Config{
+		CertFile:     *tlsCert,
+		KeyFile:      *tlsKey,
+		ClientCAFile: *tlsClientCA,
+		ClientAuth:   *tlsClientAuth,
+	}
+	if err := tlsCfg.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("Invalid TLS configuration")
+	}
+	var grpcTLS, httpTLS *tls.Config
+	if tlsCfg.Enabled() {
+		reloader, err := tlsconfig.NewCertReloader(tlsCfg.CertFile, tlsCfg.KeyFile, tlsCfg.ClientCAFile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to load TLS certificate")
+		}
+		go reloader.Watch(ctx, *tlsReloadInterval)
+
+		grpcTLS, err = tlsconfig.NewServerConfig(tlsCfg, reloader)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to build gRPC TLS configuration")
+		}
+		// Health probes and Prometheus scrapers rarely present client
+		// certificates, so the HTTP listener verifies them only when given
+		httpCfg := tlsCfg
+		if httpCfg.ClientAuth == tlsconfig.ClientAuthRequire {
+			httpCfg.ClientAuth = tlsconfig.ClientAuthVerifyIfGiven
+		}
+		httpTLS, err = tlsconfig.NewServerConfig(httpCfg, reloader)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to build HTTP TLS configuration")
+		}
+	}
+
+	secretStoreCfg := secrets.Config{
+		Backend:        *secretBackend,
+		File:           *secretStoreFile,
+		KeyFile:        *secretStoreKeyFile,
+		VaultTokenFile: *vaultTokenFile,
+		Vault: secrets.VaultConfig{
+			Address:   *vaultAddr,
+			Namespace: *vaultNamespace,
+			Mount:     *vaultMount,
+			Prefix:    *vaultPrefix,
+		},
+	}
+	secretStore, err := secrets.Open(secretStoreCfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to open secret store")
+	}
+	for _, password := range []struct {
+		value    *string
+		secretID string
+		flag     string
+	}{
+		{dbPass, *dbPassSecret, "db-pass-secret"},
+		{redisPass, *redisPassSecret, "redis-pass-secret"},
+		{shardDBPass, *shardPassSecret, "shard-db-pass-secret"},
+		{dedicatedDBPass, *dedicatedPassSecret, "dedicated-db-pass-secret"},
+	} {
+		if password.secretID == "" {
+			continue
+		}
+		if secretStore == nil {
+			log.Fatal().Str("flag", password.flag).Msg("Passwords can only be read from a secret store; set -secret-store")
+		}
+		*password.value, err = secrets.Load(secretStore, password.secretID, 10*time.Second)
+		if err != nil {
+			log.Fatal().Err(err).Str("flag", password.flag).Msg("Failed to load password from secret store")
+		}
+	}
+
 	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
 		*dbHost, *dbPort, *dbUser, *dbPass, *dbName)
 
-	repo, err := store.NewTenantRepository(dsn)
+	repo, err := store.NewTenantRepository(dsn, store.WithRedis(*redisAddr, *redisPass))
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to connect to database")
 	}
 	defer repo.Close()
 
-	tenantService := service.NewTenantService(repo)
+	subdomainPolicy := service.SubdomainPolicy{Reclaim: *subdomainReclaim, GracePeriod: *subdomainReclaimGrace}
+	if err := subdomainPolicy.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("Invalid subdomain reclaim policy")
+	}
+	domainVerification := service.DomainVerification{Resolver: net.DefaultResolver, TokenTTL: *customDomainTokenTTL}
+	if *customDomainNameserver != "" {
+		nameserver := *customDomainNameserver
+		domainVerification.Resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, nameserver)
+			},
+		}
+	}
+	if err := domainVerification.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("Invalid custom domain verification")
+	}
+	queueCfg := service.DefaultQueueConfig()
+	queueCfg.PollInterval = *provisioningPoll
+	queueCfg.LeaseDuration = *provisioningLease
+	queueCfg.HeartbeatInterval = *provisioningHeartbeat
+	queueCfg.MaxAttempts = *provisioningMaxAttempts
+	queueCfg.RetryDelay = *provisioningRetryDelay
+	queueCfg.MaxRetryDelay = *provisioningMaxRetry
+	queueCfg.Workers = *provisioningWorkers
+	queueCfg.DBHost = *dbHost
+	queueCfg.HostConcurrency = *provisioningHostLimit
+	queueCfg.MaxQueueDepth = *provisioningMaxDepth
+	queueCfg.RecoveryInterval = *provisioningRecovery
+	queueCfg.StuckAfter = *provisioningStuckAfter
+	queueCfg.HostLimits, err = service.ParseHostLimits(*provisioningHostLimits)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid provisioning host limits")
+	}
+	switch *provisioningSaturated {
+	case "queue":
+	case "reject":
+		queueCfg.RejectWhenSaturated = true
+	default:
+		log.Fatal().Str("value", *provisioningSaturated).Msg("Invalid -provisioning-when-saturated, want queue or reject")
+	}
+	if err := queueCfg.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("Invalid provisioning queue configuration")
+	}
+	stepCfg := provisioning.DefaultConfig()
+	stepCfg.DBPort = *dbPort
+	stepCfg.DBName = *dbName
+	stepCfg.BaseDomain = *tenantBaseDomain
+	stepCfg.DNSTarget = *tenantDNSTarget
+	stepCfg.DNSTTL = *dnsTTL
+	stepCfg.StepTimeout = *provisioningStepTimeout
+	stepCfg.MoveStepTimeout = *moveStepTimeout
+	stepCfg.SchemaDropDelay = *deprovisioningDropDelay
+	if secretStore != nil {
+		stepCfg.Secrets = secretStore
+	}
+	dnsCfg := dns.Config{
+		Provider: *dnsProvider,
+		ZoneFile: *dnsZoneFile,
+		RFC2136: dns.RFC2136Config{
+			Server:    *dnsServer,
+			Zone:      *dnsZone,
+			KeyName:   *dnsTSIGKeyName,
+			Algorithm: *dnsTSIGAlgorithm,
+		},
+		TSIGSecretFile: *dnsTSIGSecretFile,
+	}
+	if dnsCfg.RFC2136.Zone == "" {
+		dnsCfg.RFC2136.Zone = *tenantBaseDomain
+	}
+	dnsRecords, err := dns.Open(dnsCfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to open DNS provider")
+	}
+	if dnsRecords != nil {
+		if *tenantDNSTarget == "" {
+			log.Fatal().Str("provider", *dnsProvider).Msg("Tenant DNS records need a target; set -tenant-dns-target")
+		}
+		stepCfg.DNS = dnsRecords
+	}
+	stepCfg.RoleLimits = model.RoleLimits{StatementTimeout: *tenantStatementTimeout, ConnectionLimit: *tenantConnectionLimit}
+	stepCfg.Template, err = tenantschema.LoadDir(*tenantTemplateDir)
+	if err != nil {
+		log.Fatal().Err(err).Str("dir", *tenantTemplateDir).Msg("Failed to load tenant schema template")
+	}
+	log.Info().Int("version", stepCfg.Template.Version()).Msg("Loaded tenant schema template")
+	stepCfg.SharedTemplate, err = tenantschema.LoadDir(*tenantSharedTemplateDir)
+	if err != nil {
+		log.Fatal().Err(err).Str("dir", *tenantSharedTemplateDir).Msg("Failed to load shared tenant template")
+	}
+	if err := stepCfg.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("Invalid provisioning step configuration")
+	}
+	var alerter monitoring.Alerter = monitoring.LogAlerter{}
+	if *alertWebhookURL != "" {
+		alerter = monitoring.NewWebhookAlerter(*alertWebhookURL, *alertTimeout)
+	}
+	isolation := service.NewDefaultIsolation(repo, stepCfg)
+	isolation.Tiers, err = provisioning.ParseTierIsolation(*tenantIsolation)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid tenant isolation")
+	}
+	if *dedicatedDBHost != "" {
+		dedicatedServer, err := store.NewDatabaseServer(*dedicatedDBHost, *dedicatedDBPort, *dedicatedDBUser, *dedicatedDBPass, *dedicatedDBAdminDB)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to connect to dedicated database server")
+		}
+		defer dedicatedServer.Close()
+		isolation.Modes[model.IsolationDatabase] = service.NewDedicatedPipelines(repo, dedicatedServer, stepCfg)
+	}
+	defaultPlacement, err := provisioning.ParsePlacement(*shardPlacement)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid shard placement")
+	}
+	placement, err := provisioning.ParseTierPlacement(*shardTierPlacement, defaultPlacement)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid shard placement")
+	}
+	isolation.Shards = &service.Sharding{
+		Placement: placement,
+		Connect: func(shard *model.Shard) (provisioning.ShardServer, error) {
+			return store.NewShardServer(shard.Host, shard.Port, *shardDBUser, *shardDBPass, *shardDBAdminDB, shard.DatabaseName)
+		},
+		Steps: stepCfg,
+	}
+	if err := isolation.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("Invalid tenant isolation")
+	}
+	provisioningService := service.NewProvisioningService(repo, queueCfg, isolation, alerter)
+	if *provisioningWorker {
+		go provisioningService.Run(ctx)
+	}
+
+	tenantService := service.NewTenantService(repo,
+		service.WithSubdomainPolicy(subdomainPolicy),
+		service.WithBaseDomain(*tenantBaseDomain),
+		service.WithDomainVerification(domainVerification),
+		service.WithProvisioningService(provisioningService),
+	)
 
 	// Initialize metrics
 	monitoring.InitMetrics()
@@ -55,12 +375,55 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to listen")
 	}
 
-	server := grpc.NewServer()
+	unaryInterceptors := []grpc.UnaryServerInterceptor{grpcmw.UnaryClientCertIdentityInterceptor()}
+	streamInterceptors := []grpc.StreamServerInterceptor{grpcmw.StreamClientCertIdentityInterceptor()}
+	var limiter resilience.Limiter
+	if *rateLimitEnabled {
+		limiter = resilience.NewRedisLimiter(repo.Redis())
+	}
+	if *rateLimitEnabled && *rateLimitConn != "" {
+		connLimit, err := resilience.ParseLimit(*rateLimitConn)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid connection rate limit")
+		}
+		// Throttle by connection before authentication, so that callers with
+		// bad credentials cannot hammer the authenticator
+		unaryInterceptors = append(unaryInterceptors, grpcmw.UnaryRateLimitInterceptor(limiter, grpcmw.ConnectionRateLimitPolicy(connLimit)))
+		streamInterceptors = append(streamInterceptors, grpcmw.StreamRateLimitInterceptor(limiter, grpcmw.ConnectionRateLimitPolicy(connLimit)))
+	}
+	if *authDisabled {
+		log.Warn().Msg("RPC authentication is disabled")
+	} else {
+		var authOpts []auth.Option
+		if *authAPIKeys {
+			authOpts = append(authOpts, auth.WithAPIKeyVerifier(tenantService))
+		}
+		authenticator, err := newAuthenticator(*authJWKSFile, *authHMACSecretFile, *authPublicKeys, *authIssuer, *authAudience, *authRolesClaim, *authTenantClaim, *authRoleAliases, authOpts...)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to configure authentication")
+		}
+		unaryInterceptors = append(unaryInterceptors, authenticator.UnaryServerInterceptor())
+		streamInterceptors = append(streamInterceptors, authenticator.StreamServerInterceptor())
+	}
+	if *rateLimitEnabled {
+		limits, err := auth.ParseRateLimits(*rateLimits, *rateLimitMethods)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid rate limit configuration")
+		}
+		unaryInterceptors = append(unaryInterceptors, grpcmw.UnaryRateLimitInterceptor(limiter, limits.Policy()))
+		streamInterceptors = append(streamInterceptors, grpcmw.StreamRateLimitInterceptor(limiter, limits.Policy()))
+	}
+
+	serverOpts := grpcmw.ServerOptions(unaryInterceptors, streamInterceptors)
+	if grpcTLS != nil {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(grpcTLS)))
+	}
+	server := grpc.NewServer(serverOpts...)
 	tenantpb.RegisterTenantServiceServer(server, tenantService)
 	reflection.Register(server)
 
 	go func() {
-		log.Info().Msgf("gRPC server listening at %v", lis.Addr())
+		log.Info().Bool("tls", grpcTLS != nil).Msgf("gRPC server listening at %v", lis.Addr())
 		if err := server.Serve(lis); err != nil {
 			log.Fatal().Err(err).Msg("Failed to start gRPC server")
 		}
@@ -76,12 +439,19 @@ func main() {
 		mux.Handle("/metrics", promhttp.Handler()) // Add metrics endpoint
 
 		httpServer := &http.Server{
-			Addr:    ":8081",
-			Handler: mux,
+			Addr:      ":8081",
+			Handler:   mux,
+			TLSConfig: httpTLS,
 		}
 
-		log.Info().Msg("HTTP server for health checks and metrics started on port 8081")
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Info().Bool("tls", httpTLS != nil).Msg("HTTP server for health checks and metrics started on port 8081")
+		var err error
+		if httpTLS != nil {
+			err = httpServer.ListenAndServeTLS("", "")
+		} else {
+			err = httpServer.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Error().Err(err).Msg("HTTP server error")
 		}
 	}()
@@ -94,3 +464,57 @@ func main() {
 	server.GracefulStop()
 	log.Info().Msg("Server exiting")
 }
+
+// newAuthenticator builds the bearer token authenticator from command line settings
+func newAuthenticator(jwksFile, hmacSecretFile, publicKeys, issuer, audience, rolesClaim, tenantClaim, roleAliases string, opts ...auth.Option) (*auth.Authenticator, error) {
+	keys := auth.NewKeySet()
+	if jwksFile != "" {
+		if err := keys.LoadJWKSFile(jwksFile); err != nil {
+			return nil, err
+		}
+	}
+	if hmacSecretFile != "" {
+		if err := keys.LoadHMACSecretFile(hmacSecretFile); err != nil {
+			return nil, err
+		}
+	}
+	for _, file := range splitList(publicKeys) {
+		if err := keys.LoadPublicKeyFile(file); err != nil {
+			return nil, err
+		}
+	}
+	if keys.Len() == 0 {
+		return nil, fmt.Errorf("no token signing keys configured; set -auth-jwks-file, -auth-hmac-secret-file or -auth-public-keys, or pass -auth-disabled")
+	}
+
+	mapping := auth.ClaimMapping{
+		RolesClaim:  rolesClaim,
+		TenantClaim: tenantClaim,
+		RoleAliases: make(map[string]auth.Role),
+	}
+	for _, pair := range splitList(roleAliases) {
+		group, role, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid role alias %q, expected group=role", pair)
+		}
+		mapping.RoleAliases[group] = auth.Role(role)
+	}
+
+	verifier := auth.NewVerifier(keys, auth.VerifierConfig{
+		Issuer:   issuer,
+		Audience: audience,
+		Leeway:   30 * time.Second,
+	})
+	return auth.NewAuthenticator(verifier, mapping, auth.DefaultPermissions(), opts...), nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,233 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	tenantpb "github.com/teresa-solution/tenant-management-service/proto/gen"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func signHS256(t *testing.T, claims map[string]interface{}) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload, err := json.Marshal(claims)
+	assert.NoError(t, err)
+	input := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, testSecret)
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signES256(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	header, err := json.Marshal(map[string]string{"alg": "ES256", "kid": kid})
+	assert.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	assert.NoError(t, err)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest(crypto.SHA256, input))
+	assert.NoError(t, err)
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func newTestAuthenticator() *Authenticator {
+	keys := NewKeySet()
+	keys.AddHMACSecret("", testSecret)
+	verifier := NewVerifier(keys, VerifierConfig{Issuer: "https://idp.example.com", Audience: "tenant-management"})
+	return NewAuthenticator(verifier, DefaultClaimMapping(), DefaultPermissions())
+}
+
+func claimsFor(roles []string, tenantID string) map[string]interface{} {
+	claims := map[string]interface{}{
+		"sub":   "user-1",
+		"iss":   "https://idp.example.com",
+		"aud":   []string{"tenant-management"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": roles,
+	}
+	if tenantID != "" {
+		claims["tenant_id"] = tenantID
+	}
+	return claims
+}
+
+func callUnary(a *Authenticator, token, method string, req interface{}) error {
+	ctx := context.Background()
+	if token != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+token))
+	}
+	_, err := a.UnaryServerInterceptor()(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	return err
+}
+
+func TestVerifier_ES256WithJWK(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	keys := NewKeySet()
+	assert.NoError(t, keys.addJWK(jwk{
+		Kty: "EC",
+		Kid: "key-1",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(key.X.Bytes()),
+		Y:   base64.RawURLEncoding.EncodeToString(key.Y.Bytes()),
+	}))
+	verifier := NewVerifier(keys, VerifierConfig{})
+	exp := time.Now().Add(time.Hour).Unix()
+
+	claims, err := verifier.Verify(signES256(t, key, "key-1", map[string]interface{}{"sub": "svc", "exp": exp}))
+	assert.NoError(t, err)
+	assert.Equal(t, "svc", claims.Subject())
+
+	_, err = verifier.Verify(signES256(t, key, "other-key", map[string]interface{}{"sub": "svc", "exp": exp}))
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestVerifier_RejectsExpiredAndForeignTokens(t *testing.T) {
+	a := newTestAuthenticator()
+
+	expired := claimsFor([]string{"platform-admin"}, "")
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	_, err := a.verifier.Verify(signHS256(t, expired))
+	assert.ErrorIs(t, err, ErrTokenExpired)
+
+	eternal := claimsFor([]string{"platform-admin"}, "")
+	delete(eternal, "exp")
+	_, err = a.verifier.Verify(signHS256(t, eternal))
+	assert.ErrorIs(t, err, ErrMissingExpiry)
+
+	foreign := claimsFor([]string{"platform-admin"}, "")
+	foreign["aud"] = "someone-else"
+	_, err = a.verifier.Verify(signHS256(t, foreign))
+	assert.ErrorIs(t, err, ErrInvalidAudience)
+
+	_, err = a.verifier.Verify("not-a-token")
+	assert.ErrorIs(t, err, ErrMalformedToken)
+}
+
+func TestKeySet_RejectsShortJWKSecrets(t *testing.T) {
+	keys := NewKeySet()
+	assert.Error(t, keys.addJWK(jwk{Kty: "oct", Kid: "short", K: base64.RawURLEncoding.EncodeToString([]byte("x"))}))
+	assert.Error(t, keys.addJWK(jwk{Kty: "oct", Kid: "empty"}))
+	assert.NoError(t, keys.addJWK(jwk{Kty: "oct", Kid: "ok", K: base64.RawURLEncoding.EncodeToString(testSecret)}))
+}
+
+func TestAuthenticator_RoleChecks(t *testing.T) {
+	a := newTestAuthenticator()
+	tenantID := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+
+	err := callUnary(a, "", tenantpb.TenantService_GetTenant_FullMethodName, &tenantpb.GetTenantRequest{Id: tenantID})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	admin := signHS256(t, claimsFor([]string{"platform-admin"}, ""))
+	assert.NoError(t, callUnary(a, admin, tenantpb.TenantService_DeleteTenant_FullMethodName, &tenantpb.DeleteTenantRequest{Id: tenantID}))
+
+	support := signHS256(t, claimsFor([]string{"support"}, ""))
+	err = callUnary(a, support, tenantpb.TenantService_DeleteTenant_FullMethodName, &tenantpb.DeleteTenantRequest{Id: tenantID})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	err = callUnary(a, admin, "/tenant.v1.TenantService/Unknown", nil)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestAuthenticator_TenantAdminScope(t *testing.T) {
+	a := newTestAuthenticator()
+	own := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+	other := "9b2f4c1e-1c7d-4f0a-8d52-3a1f3f0f6b11"
+	token := signHS256(t, claimsFor([]string{"tenant-admin"}, own))
+
+	assert.NoError(t, callUnary(a, token, tenantpb.TenantService_GetTenant_FullMethodName, &tenantpb.GetTenantRequest{Id: own}))
+
+	err := callUnary(a, token, tenantpb.TenantService_GetTenant_FullMethodName, &tenantpb.GetTenantRequest{Id: other})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	err = callUnary(a, token, tenantpb.TenantService_UpdateTenant_FullMethodName, &tenantpb.UpdateTenantRequest{Id: own})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestClaimMapping_Aliases(t *testing.T) {
+	mapping := DefaultClaimMapping()
+	mapping.RoleAliases = map[string]Role{"ops-team": RoleSupport}
+
+	p := mapping.Principal(Claims{"sub": "u", "roles": []interface{}{"ops-team", "unknown", "tenant-admin"}})
+	assert.Equal(t, []Role{RoleSupport}, p.Roles)
+}
+
+type fakeAPIKeyVerifier map[string]*APIKeyIdentity
+
+func (f fakeAPIKeyVerifier) VerifyKey(ctx context.Context, key string) (*APIKeyIdentity, error) {
+	if identity, ok := f[key]; ok {
+		return identity, nil
+	}
+	return nil, ErrInvalidSignature
+}
+
+func TestAuthenticator_APIKeys(t *testing.T) {
+	tenantID := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+	keys := NewKeySet()
+	keys.AddHMACSecret("", testSecret)
+	a := NewAuthenticator(NewVerifier(keys, VerifierConfig{}), DefaultClaimMapping(), DefaultPermissions(),
+		WithAPIKeyVerifier(fakeAPIKeyVerifier{
+			"tms_readonly": {KeyID: "k1", TenantID: tenantID, Scopes: []string{ScopeTenantsRead}},
+		}))
+
+	call := func(key, method string, req interface{}) error {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(APIKeyHeader, key))
+		_, err := a.UnaryServerInterceptor()(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			p, ok := PrincipalFromContext(ctx)
+			assert.True(t, ok)
+			assert.Equal(t, "api-key:k1", p.Subject)
+			return nil, nil
+		})
+		return err
+	}
+
+	assert.NoError(t, call("tms_readonly", tenantpb.TenantService_GetTenant_FullMethodName, &tenantpb.GetTenantRequest{Id: tenantID}))
+
+	err := call("tms_readonly", tenantpb.TenantService_CreateAPIKey_FullMethodName, &tenantpb.CreateAPIKeyRequest{TenantId: tenantID})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	err = call("tms_readonly", tenantpb.TenantService_GetTenant_FullMethodName, &tenantpb.GetTenantRequest{Id: "9b2f4c1e-1c7d-4f0a-8d52-3a1f3f0f6b11"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	err = call("tms_unknown", tenantpb.TenantService_GetTenant_FullMethodName, &tenantpb.GetTenantRequest{Id: tenantID})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestRateLimits_Policy(t *testing.T) {
+	limits, err := ParseRateLimits(DefaultRoleRateLimits, DefaultMethodRateLimits)
+	assert.NoError(t, err)
+	policy := limits.Policy()
+
+	ctx := WithPrincipal(context.Background(), &Principal{Subject: "u", Roles: []Role{RoleReadOnly, RoleSupport}})
+	_, limit, ok := policy(ctx, tenantpb.TenantService_GetTenant_FullMethodName)
+	assert.True(t, ok)
+	assert.Equal(t, float64(20), limit.Rate)
+
+	_, limit, _ = policy(ctx, tenantpb.TenantService_CreateTenant_FullMethodName)
+	assert.Equal(t, 0.2, limit.Rate)
+
+	_, limit, _ = policy(context.Background(), tenantpb.TenantService_GetTenant_FullMethodName)
+	assert.Equal(t, float64(5), limit.Rate)
+
+	_, err = ParseRateLimits("wizard=1:1", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	grpcmw "github.com/teresa-solution/tenant-management-service/pkg/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Identity sources recorded for authenticated callers
+const (
+	IdentitySourceJWT    = "jwt"
+	IdentitySourceAPIKey = "api-key"
+)
+
+// APIKeyHeader is the metadata key carrying a tenant API key
+const APIKeyHeader = "x-api-key"
+
+// APIKeyIdentity describes the tenant and scopes granted by a verified API key
+type APIKeyIdentity struct {
+	KeyID    string
+	TenantID string
+	Scopes   []string
+}
+
+// APIKeyVerifier checks tenant API keys
+type APIKeyVerifier interface {
+	VerifyKey(ctx context.Context, key string) (*APIKeyIdentity, error)
+}
+
+// Option configures an Authenticator
+type Option func(*Authenticator)
+
+// WithAPIKeyVerifier makes the authenticator accept tenant API keys, sent in
+// the x-api-key header or as "Authorization: ApiKey <key>", in addition to
+// bearer tokens. API key callers act as tenant-admins of their tenant limited
+// to the key's scopes.
+func WithAPIKeyVerifier(verifier APIKeyVerifier) Option {
+	return func(a *Authenticator) {
+		a.apiKeys = verifier
+	}
+}
+
+// Authenticator authenticates RPCs with bearer tokens and authorizes them
+// against a permission table
+type Authenticator struct {
+	verifier    *Verifier
+	mapping     ClaimMapping
+	permissions PermissionTable
+	apiKeys     APIKeyVerifier
+	// public lists method prefixes that skip authentication entirely
+	public []string
+}
+
+// NewAuthenticator creates a new Authenticator
+func NewAuthenticator(verifier *Verifier, mapping ClaimMapping, permissions PermissionTable, opts ...Option) *Authenticator {
+	a := &Authenticator{
+		verifier:    verifier,
+		mapping:     mapping,
+		permissions: permissions,
+		public:      []string{"/grpc.reflection.", "/grpc.health."},
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// UnaryServerInterceptor authenticates and authorizes unary calls
+func (a *Authenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if a.isPublic(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		ctx, err := a.authorize(ctx, info.FullMethod, requestTenantID(req))
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor authenticates and authorizes streaming calls.
+// Streams cannot be tenant scoped because the request is not known up front.
+func (a *Authenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if a.isPublic(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		ctx, err := a.authorize(ss.Context(), info.FullMethod, "")
+		if err != nil {
+			return err
+		}
+		return handler(srv, grpcmw.WrapServerStream(ss, ctx))
+	}
+}
+
+func (a *Authenticator) authorize(ctx context.Context, method, tenantID string) (context.Context, error) {
+	principal, source, err := a.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	perm, ok := a.permissions[method]
+	if !ok || !perm.Allows(principal, tenantID) {
+		grpcmw.LoggerFromContext(ctx).Warn().
+			Str("subject", principal.Subject).
+			Str("grpc_method", method).
+			Msg("Permission denied")
+		return nil, status.Error(codes.PermissionDenied, "Permission denied")
+	}
+
+	logger := grpcmw.LoggerFromContext(ctx).With().Str("actor", principal.Subject).Logger()
+	ctx = logger.WithContext(ctx)
+	ctx = grpcmw.WithIdentity(ctx, grpcmw.Identity{Subject: principal.Subject, Source: source})
+	return WithPrincipal(ctx, principal), nil
+}
+
+func (a *Authenticator) authenticate(ctx context.Context) (*Principal, string, error) {
+	if key, ok := apiKey(ctx); ok && a.apiKeys != nil {
+		identity, err := a.apiKeys.VerifyKey(ctx, key)
+		if err != nil {
+			grpcmw.LoggerFromContext(ctx).Warn().Err(err).Msg("Rejected API key")
+			return nil, "", status.Error(codes.Unauthenticated, "invalid API key")
+		}
+		scopes := identity.Scopes
+		if scopes == nil {
+			scopes = []string{}
+		}
+		return &Principal{
+			Subject:  "api-key:" + identity.KeyID,
+			Roles:    []Role{RoleTenantAdmin},
+			TenantID: identity.TenantID,
+			Scopes:   scopes,
+		}, IdentitySourceAPIKey, nil
+	}
+
+	token, ok := bearerToken(ctx)
+	if !ok {
+		return nil, "", status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	claims, err := a.verifier.Verify(token)
+	if err != nil {
+		grpcmw.LoggerFromContext(ctx).Warn().Err(err).Msg("Rejected bearer token")
+		return nil, "", status.Error(codes.Unauthenticated, "invalid bearer token")
+	}
+	principal := a.mapping.Principal(claims)
+	if principal.Subject == "" {
+		return nil, "", status.Error(codes.Unauthenticated, "token has no subject")
+	}
+	return principal, IdentitySourceJWT, nil
+}
+
+func (a *Authenticator) isPublic(method string) bool {
+	for _, prefix := range a.public {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func bearerToken(ctx context.Context) (string, bool) {
+	return authorizationCredential(ctx, "bearer")
+}
+
+func apiKey(ctx context.Context) (string, bool) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(APIKeyHeader); len(values) > 0 && values[0] != "" {
+			return values[0], true
+		}
+	}
+	return authorizationCredential(ctx, "apikey")
+}
+
+// authorizationCredential returns the credential of the authorization header
+// using the given scheme
+func authorizationCredential(ctx context.Context, scheme string) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	for _, value := range md.Get("authorization") {
+		s, credential, found := strings.Cut(value, " ")
+		if found && strings.EqualFold(s, scheme) && credential != "" {
+			return strings.TrimSpace(credential), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,238 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Errors returned while validating a token
+var (
+	ErrMalformedToken   = errors.New("malformed token")
+	ErrUnsupportedAlg   = errors.New("unsupported signing algorithm")
+	ErrInvalidSignature = errors.New("invalid token signature")
+	ErrTokenExpired     = errors.New("token expired")
+	ErrMissingExpiry    = errors.New("token has no expiry")
+	ErrTokenNotYetValid = errors.New("token not yet valid")
+	ErrInvalidIssuer    = errors.New("invalid token issuer")
+	ErrInvalidAudience  = errors.New("invalid token audience")
+)
+
+// Claims holds the decoded payload of a JWT
+type Claims map[string]interface{}
+
+// Subject returns the sub claim
+func (c Claims) Subject() string {
+	sub, _ := c["sub"].(string)
+	return sub
+}
+
+// Strings returns a claim that may be encoded either as a single string or as
+// a list of strings
+func (c Claims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func (c Claims) time(name string) (time.Time, bool) {
+	v, ok := c[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(v), 0), true
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// VerifierConfig controls which registered claims a Verifier enforces
+type VerifierConfig struct {
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+}
+
+// Verifier validates signed JWTs against a KeySet
+type Verifier struct {
+	keys   *KeySet
+	config VerifierConfig
+	now    func() time.Time
+}
+
+// NewVerifier creates a new Verifier
+func NewVerifier(keys *KeySet, config VerifierConfig) *Verifier {
+	return &Verifier{keys: keys, config: config, now: time.Now}
+}
+
+// Verify checks the signature and registered claims of token and returns its claims
+func (v *Verifier) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	if err := v.verifySignature(header, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (v *Verifier) verifySignature(header jwtHeader, signingInput string, signature []byte) error {
+	alg, ok := algorithms[header.Alg]
+	if !ok {
+		return ErrUnsupportedAlg
+	}
+	for _, key := range v.keys.candidates(header.Kid, alg.keyType) {
+		if alg.verify(key, signingInput, signature) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func (v *Verifier) validateClaims(claims Claims) error {
+	now := v.now()
+	// Tokens without an expiry would be valid forever
+	exp, ok := claims.time("exp")
+	if !ok {
+		return ErrMissingExpiry
+	}
+	if now.After(exp.Add(v.config.Leeway)) {
+		return ErrTokenExpired
+	}
+	if nbf, ok := claims.time("nbf"); ok && now.Add(v.config.Leeway).Before(nbf) {
+		return ErrTokenNotYetValid
+	}
+	if v.config.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.config.Issuer {
+			return ErrInvalidIssuer
+		}
+	}
+	if v.config.Audience != "" && !contains(claims.Strings("aud"), v.config.Audience) {
+		return ErrInvalidAudience
+	}
+	return nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrMalformedToken
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformedToken, err)
+	}
+	return nil
+}
+
+type algorithm struct {
+	keyType string
+	verify  func(key interface{}, signingInput string, signature []byte) bool
+}
+
+var algorithms = map[string]algorithm{
+	"HS256": {keyTypeOct, verifyHMAC(crypto.SHA256)},
+	"HS384": {keyTypeOct, verifyHMAC(crypto.SHA384)},
+	"HS512": {keyTypeOct, verifyHMAC(crypto.SHA512)},
+	"RS256": {keyTypeRSA, verifyRSA(crypto.SHA256, false)},
+	"RS384": {keyTypeRSA, verifyRSA(crypto.SHA384, false)},
+	"RS512": {keyTypeRSA, verifyRSA(crypto.SHA512, false)},
+	"PS256": {keyTypeRSA, verifyRSA(crypto.SHA256, true)},
+	"PS384": {keyTypeRSA, verifyRSA(crypto.SHA384, true)},
+	"PS512": {keyTypeRSA, verifyRSA(crypto.SHA512, true)},
+	"ES256": {keyTypeEC, verifyECDSA(crypto.SHA256)},
+	"ES384": {keyTypeEC, verifyECDSA(crypto.SHA384)},
+	"ES512": {keyTypeEC, verifyECDSA(crypto.SHA512)},
+}
+
+func digest(hash crypto.Hash, signingInput string) []byte {
+	h := hash.New()
+	h.Write([]byte(signingInput))
+	return h.Sum(nil)
+}
+
+func verifyHMAC(hash crypto.Hash) func(interface{}, string, []byte) bool {
+	return func(key interface{}, signingInput string, signature []byte) bool {
+		secret, ok := key.([]byte)
+		if !ok {
+			return false
+		}
+		mac := hmac.New(hash.New, secret)
+		mac.Write([]byte(signingInput))
+		return hmac.Equal(mac.Sum(nil), signature)
+	}
+}
+
+func verifyRSA(hash crypto.Hash, pss bool) func(interface{}, string, []byte) bool {
+	return func(key interface{}, signingInput string, signature []byte) bool {
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return false
+		}
+		if pss {
+			return rsa.VerifyPSS(pub, hash, digest(hash, signingInput), signature, nil) == nil
+		}
+		return rsa.VerifyPKCS1v15(pub, hash, digest(hash, signingInput), signature) == nil
+	}
+}
+
+func verifyECDSA(hash crypto.Hash) func(interface{}, string, []byte) bool {
+	return func(key interface{}, signingInput string, signature []byte) bool {
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return false
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(pub, digest(hash, signingInput), r, s)
+	}
+}
+
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
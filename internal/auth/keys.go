@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+const (
+	keyTypeOct = "oct"
+	keyTypeRSA = "RSA"
+	keyTypeEC  = "EC"
+)
+
+type namedKey struct {
+	kid     string
+	keyType string
+	key     interface{}
+}
+
+// KeySet holds the keys trusted to sign tokens
+type KeySet struct {
+	keys []namedKey
+}
+
+// NewKeySet creates an empty KeySet
+func NewKeySet() *KeySet {
+	return &KeySet{}
+}
+
+// Len returns the number of keys in the set
+func (ks *KeySet) Len() int {
+	return len(ks.keys)
+}
+
+// AddHMACSecret trusts a shared secret for HS* tokens
+func (ks *KeySet) AddHMACSecret(kid string, secret []byte) {
+	ks.keys = append(ks.keys, namedKey{kid: kid, keyType: keyTypeOct, key: secret})
+}
+
+// AddPublicKey trusts an RSA or ECDSA public key for RS*, PS* or ES* tokens
+func (ks *KeySet) AddPublicKey(kid string, key interface{}) error {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		ks.keys = append(ks.keys, namedKey{kid: kid, keyType: keyTypeRSA, key: key})
+	case *ecdsa.PublicKey:
+		ks.keys = append(ks.keys, namedKey{kid: kid, keyType: keyTypeEC, key: key})
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+	return nil
+}
+
+// minHMACSecretLength is the shortest HMAC secret trusted to sign tokens
+const minHMACSecretLength = 32
+
+// LoadHMACSecretFile trusts the trimmed contents of path as an HMAC secret
+func (ks *KeySet) LoadHMACSecretFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	secret := []byte(strings.TrimSpace(string(data)))
+	if len(secret) < minHMACSecretLength {
+		return fmt.Errorf("HMAC secret in %s must be at least %d bytes", path, minHMACSecretLength)
+	}
+	ks.AddHMACSecret("", secret)
+	return nil
+}
+
+// LoadPublicKeyFile trusts a PEM encoded public key or certificate. The file
+// name is used as the key ID.
+func (ks *KeySet) LoadPublicKeyFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return fmt.Errorf("no PEM data found in %s", path)
+	}
+	var key interface{}
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return err
+		}
+		key = cert.PublicKey
+	default:
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return err
+		}
+	}
+	return ks.AddPublicKey("", key)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// LoadJWKSFile trusts every signing key of a JSON Web Key Set file
+func (ks *KeySet) LoadJWKSFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("parse JWKS %s: %w", path, err)
+	}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if err := ks.addJWK(k); err != nil {
+			return fmt.Errorf("key %q in %s: %w", k.Kid, path, err)
+		}
+	}
+	return nil
+}
+
+func (ks *KeySet) addJWK(k jwk) error {
+	switch k.Kty {
+	case keyTypeOct:
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil {
+			return err
+		}
+		if len(secret) < minHMACSecretLength {
+			return fmt.Errorf("HMAC secret must be at least %d bytes", minHMACSecretLength)
+		}
+		ks.AddHMACSecret(k.Kid, secret)
+		return nil
+	case keyTypeRSA:
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return err
+		}
+		return ks.AddPublicKey(k.Kid, &rsa.PublicKey{N: n, E: int(e.Int64())})
+	case keyTypeEC:
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return err
+		}
+		return ks.AddPublicKey(k.Kid, &ecdsa.PublicKey{Curve: curve, X: x, Y: y})
+	default:
+		return fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// candidates returns the keys that may have signed a token with the given
+// key ID and algorithm family. Tokens without a kid are tried against every
+// key of the right type.
+func (ks *KeySet) candidates(kid, keyType string) []interface{} {
+	var keys []interface{}
+	for _, k := range ks.keys {
+		if k.keyType != keyType {
+			continue
+		}
+		if kid != "" && k.kid != "" && k.kid != kid {
+			continue
+		}
+		keys = append(keys, k.key)
+	}
+	return keys
+}
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	tenantpb "github.com/teresa-solution/tenant-management-service/proto/gen"
+)
+
+// API key scopes
+const (
+	ScopeTenantsRead  = "tenants:read"
+	ScopeAPIKeysRead  = "api-keys:read"
+	ScopeAPIKeysWrite = "api-keys:write"
+)
+
+// Scopes lists every scope an API key may be granted
+var Scopes = []string{ScopeTenantsRead, ScopeAPIKeysRead, ScopeAPIKeysWrite}
+
+// Permission lists the roles allowed to call an RPC
+type Permission struct {
+	Roles []Role
+	// TenantScoped allows tenant-admins whose tenant matches the tenant
+	// addressed by the request
+	TenantScoped bool
+	// Scope is the scope an API key needs to call the RPC. RPCs without a
+	// scope cannot be called with API keys.
+	Scope string
+}
+
+// PermissionTable maps full gRPC method names to their permission. Methods
+// missing from the table are denied.
+type PermissionTable map[string]Permission
+
+// DefaultPermissions is the permission table for TenantService
+func DefaultPermissions() PermissionTable {
+	return PermissionTable{
+		tenantpb.TenantService_CreateTenant_FullMethodName: {
+			Roles: []Role{RolePlatformAdmin},
+		},
+		tenantpb.TenantService_GetTenant_FullMethodName: {
+			Roles:        []Role{RolePlatformAdmin, RoleSupport, RoleReadOnly},
+			TenantScoped: true,
+			Scope:        ScopeTenantsRead,
+		},
+		tenantpb.TenantService_UpdateTenant_FullMethodName: {
+			Roles: []Role{RolePlatformAdmin, RoleSupport},
+		},
+		tenantpb.TenantService_DeleteTenant_FullMethodName: {
+			Roles: []Role{RolePlatformAdmin},
+		},
+		tenantpb.TenantService_CreateAPIKey_FullMethodName: {
+			Roles:        []Role{RolePlatformAdmin},
+			TenantScoped: true,
+			Scope:        ScopeAPIKeysWrite,
+		},
+		tenantpb.TenantService_ListAPIKeys_FullMethodName: {
+			Roles:        []Role{RolePlatformAdmin, RoleSupport, RoleReadOnly},
+			TenantScoped: true,
+			Scope:        ScopeAPIKeysRead,
+		},
+		tenantpb.TenantService_RotateAPIKey_FullMethodName: {
+			Roles:        []Role{RolePlatformAdmin},
+			TenantScoped: true,
+			Scope:        ScopeAPIKeysWrite,
+		},
+		tenantpb.TenantService_RevokeAPIKey_FullMethodName: {
+			Roles:        []Role{RolePlatformAdmin, RoleSupport},
+			TenantScoped: true,
+			Scope:        ScopeAPIKeysWrite,
+		},
+		tenantpb.TenantService_VerifyAPIKey_FullMethodName: {
+			Roles: []Role{RolePlatformAdmin, RoleSupport, RoleReadOnly},
+		},
+		tenantpb.TenantService_GetQuotaUsage_FullMethodName: {
+			Roles:        []Role{RolePlatformAdmin, RoleSupport, RoleReadOnly},
+			TenantScoped: true,
+			Scope:        ScopeTenantsRead,
+		},
+		tenantpb.TenantService_SetQuotaOverride_FullMethodName: {
+			Roles: []Role{RolePlatformAdmin},
+		},
+		tenantpb.TenantService_ResolveTenant_FullMethodName: {
+			Roles: []Role{RolePlatformAdmin, RoleSupport, RoleReadOnly},
+		},
+		tenantpb.TenantService_GetDatabaseConfig_FullMethodName: {
+			Roles:        []Role{RolePlatformAdmin, RoleSupport, RoleReadOnly},
+			TenantScoped: true,
+			Scope:        ScopeTenantsRead,
+		},
+		tenantpb.TenantService_RotateDatabaseCredentials_FullMethodName: {
+			Roles:        []Role{RolePlatformAdmin},
+			TenantScoped: true,
+		},
+		tenantpb.TenantService_AttachCustomDomain_FullMethodName: {
+			Roles:        []Role{RolePlatformAdmin, RoleSupport},
+			TenantScoped: true,
+		},
+		tenantpb.TenantService_VerifyCustomDomain_FullMethodName: {
+			Roles:        []Role{RolePlatformAdmin, RoleSupport},
+			TenantScoped: true,
+		},
+		tenantpb.TenantService_ListCustomDomains_FullMethodName: {
+			Roles:        []Role{RolePlatformAdmin, RoleSupport, RoleReadOnly},
+			TenantScoped: true,
+			Scope:        ScopeTenantsRead,
+		},
+		tenantpb.TenantService_DetachCustomDomain_FullMethodName: {
+			Roles:        []Role{RolePlatformAdmin, RoleSupport},
+			TenantScoped: true,
+		},
+		tenantpb.TenantService_CreateShard_FullMethodName: {
+			Roles: []Role{RolePlatformAdmin},
+		},
+		tenantpb.TenantService_ListShards_FullMethodName: {
+			Roles: []Role{RolePlatformAdmin, RoleSupport, RoleReadOnly},
+		},
+		tenantpb.TenantService_UpdateShard_FullMethodName: {
+			Roles: []Role{RolePlatformAdmin},
+		},
+		tenantpb.TenantService_DeleteShard_FullMethodName: {
+			Roles: []Role{RolePlatformAdmin},
+		},
+		tenantpb.TenantService_MoveTenant_FullMethodName: {
+			Roles: []Role{RolePlatformAdmin},
+		},
+		tenantpb.TenantService_GetTenantMove_FullMethodName: {
+			Roles:        []Role{RolePlatformAdmin, RoleSupport, RoleReadOnly},
+			TenantScoped: true,
+			Scope:        ScopeTenantsRead,
+		},
+	}
+}
+
+// Allows reports whether p may call a method guarded by perm with a request
+// addressing tenantID
+func (perm Permission) Allows(p *Principal, tenantID string) bool {
+	if p.Scopes != nil && (perm.Scope == "" || !p.HasScope(perm.Scope)) {
+		return false
+	}
+	for _, role := range perm.Roles {
+		if p.HasRole(role) {
+			return true
+		}
+	}
+	return perm.TenantScoped && p.HasRole(RoleTenantAdmin) && tenantID != "" && tenantID == p.TenantID
+}
+
+// requestTenantID returns the tenant a request addresses. Requests carry it
+// either as tenant_id or, for tenant messages, as id.
+func requestTenantID(req interface{}) string {
+	switch r := req.(type) {
+	case interface{ GetTenantId() string }:
+		return r.GetTenantId()
+	case interface{ GetId() string }:
+		return r.GetId()
+	}
+	return ""
+}
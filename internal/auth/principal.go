@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+)
+
+// Role is a coarse-grained permission level granted to a caller
+type Role string
+
+// Roles understood by the permission table
+const (
+	RolePlatformAdmin Role = "platform-admin"
+	RoleSupport       Role = "support"
+	RoleReadOnly      Role = "read-only"
+	RoleTenantAdmin   Role = "tenant-admin"
+)
+
+// Principal is the authenticated caller of an RPC
+type Principal struct {
+	Subject string
+	Roles   []Role
+	// TenantID scopes tenant-admin callers to a single tenant
+	TenantID string
+	// Scopes restricts API key callers to the listed scopes. It is nil for
+	// callers authenticated with a token, which are limited by role alone.
+	Scopes []string
+}
+
+// HasRole reports whether the principal holds role
+func (p *Principal) HasRole(role Role) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope reports whether the principal may use scope
+func (p *Principal) HasScope(scope string) bool {
+	if p.Scopes == nil {
+		return true
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type principalContextKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, p)
+}
+
+// PrincipalFromContext returns the principal stored in ctx, if any
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return p, ok
+}
+
+// ClaimMapping describes how token claims translate into a Principal
+type ClaimMapping struct {
+	// RolesClaim names the claim holding the caller's roles or groups
+	RolesClaim string
+	// TenantClaim names the claim holding the tenant of a tenant-admin
+	TenantClaim string
+	// RoleAliases maps identity provider group names onto roles. Claim
+	// values that already name a role are accepted as-is.
+	RoleAliases map[string]Role
+}
+
+// DefaultClaimMapping reads roles from "roles" and the tenant from "tenant_id"
+func DefaultClaimMapping() ClaimMapping {
+	return ClaimMapping{RolesClaim: "roles", TenantClaim: "tenant_id"}
+}
+
+// Principal builds the principal described by claims. Tenant-admin is only
+// granted when the token also names the tenant it is scoped to.
+func (m ClaimMapping) Principal(claims Claims) *Principal {
+	p := &Principal{Subject: claims.Subject()}
+	if tenantID, ok := claims[m.TenantClaim].(string); ok {
+		p.TenantID = tenantID
+	}
+	seen := make(map[Role]bool)
+	for _, value := range claims.Strings(m.RolesClaim) {
+		role, ok := m.RoleAliases[value]
+		if !ok {
+			role = Role(value)
+		}
+		if !knownRole(role) || seen[role] {
+			continue
+		}
+		if role == RoleTenantAdmin && p.TenantID == "" {
+			continue
+		}
+		seen[role] = true
+		p.Roles = append(p.Roles, role)
+	}
+	return p
+}
+
+func knownRole(role Role) bool {
+	switch role {
+	case RolePlatformAdmin, RoleSupport, RoleReadOnly, RoleTenantAdmin:
+		return true
+	}
+	return false
+}
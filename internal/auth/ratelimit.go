@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	grpcmw "github.com/teresa-solution/tenant-management-service/pkg/grpc"
+	"github.com/teresa-solution/tenant-management-service/pkg/resilience"
+	tenantpb "github.com/teresa-solution/tenant-management-service/proto/gen"
+)
+
+// DefaultRoleRateLimits and DefaultMethodRateLimits are the default values of
+// the -rate-limits and -rate-limit-methods flags
+const (
+	DefaultRoleRateLimits   = "platform-admin=50:100,support=20:40,read-only=10:20,tenant-admin=5:10,default=5:10"
+	DefaultMethodRateLimits = "CreateTenant=0.2:5"
+)
+
+// RateLimits configures how many requests each caller may make
+type RateLimits struct {
+	// Roles holds the limit per role; callers holding several roles get the
+	// most generous one
+	Roles map[Role]resilience.Limit
+	// Default applies to callers without any role
+	Default resilience.Limit
+	// Methods caps individual RPCs, keyed by full method name, regardless of role
+	Methods map[string]resilience.Limit
+}
+
+// ParseRateLimits parses role limits written as role=rate:burst pairs, where
+// the role "default" covers callers without a role, and method caps written as
+// Method=rate:burst pairs naming TenantService RPCs
+func ParseRateLimits(roles, methods string) (RateLimits, error) {
+	rl := RateLimits{
+		Roles:   make(map[Role]resilience.Limit),
+		Methods: make(map[string]resilience.Limit),
+	}
+	err := parsePairs(roles, func(name string, limit resilience.Limit) error {
+		if name == "default" {
+			rl.Default = limit
+			return nil
+		}
+		if !knownRole(Role(name)) {
+			return fmt.Errorf("unknown role %q", name)
+		}
+		rl.Roles[Role(name)] = limit
+		return nil
+	})
+	if err != nil {
+		return RateLimits{}, err
+	}
+	err = parsePairs(methods, func(name string, limit resilience.Limit) error {
+		if !strings.HasPrefix(name, "/") {
+			name = "/" + tenantpb.TenantService_ServiceDesc.ServiceName + "/" + name
+		}
+		rl.Methods[name] = limit
+		return nil
+	})
+	if err != nil {
+		return RateLimits{}, err
+	}
+	return rl, nil
+}
+
+func parsePairs(value string, add func(name string, limit resilience.Limit) error) error {
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, limitStr, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid rate limit %q, expected name=rate:burst", pair)
+		}
+		limit, err := resilience.ParseLimit(limitStr)
+		if err != nil {
+			return err
+		}
+		if err := add(name, limit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Policy returns a rate limit policy keyed by caller identity that picks the
+// limit from the caller's roles and applies per-method caps on top
+func (rl RateLimits) Policy() grpcmw.RateLimitPolicy {
+	return func(ctx context.Context, method string) (string, resilience.Limit, bool) {
+		limit := rl.Default
+		if p, ok := PrincipalFromContext(ctx); ok {
+			matched := false
+			for _, role := range p.Roles {
+				roleLimit, ok := rl.Roles[role]
+				if !ok {
+					continue
+				}
+				if !matched || moreGenerous(roleLimit, limit) {
+					limit = roleLimit
+				}
+				matched = true
+			}
+		}
+		if methodCap, ok := rl.Methods[method]; ok && moreGenerous(limit, methodCap) {
+			limit = methodCap
+		}
+		return grpcmw.CallerKey(ctx), limit, !limit.Unlimited()
+	}
+}
+
+// moreGenerous reports whether a allows more traffic than b
+func moreGenerous(a, b resilience.Limit) bool {
+	if a.Unlimited() || b.Unlimited() {
+		return a.Unlimited() && !b.Unlimited()
+	}
+	return a.Rate > b.Rate || a.Rate == b.Rate && a.Burst > b.Burst
+}
@@ -0,0 +1,42 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKey_GenerateParseVerify(t *testing.T) {
+	key, prefix, secret, err := GenerateAPIKey()
+	assert.NoError(t, err)
+	assert.Len(t, prefix, 16)
+
+	parsedPrefix, parsedSecret, ok := ParseAPIKey(key)
+	assert.True(t, ok)
+	assert.Equal(t, prefix, parsedPrefix)
+	assert.Equal(t, secret, parsedSecret)
+
+	salt, err := NewSalt()
+	assert.NoError(t, err)
+	hash := HashAPIKeySecret(secret, salt)
+	assert.True(t, VerifyAPIKeySecret(secret, salt, hash))
+	assert.False(t, VerifyAPIKeySecret(secret+"x", salt, hash))
+
+	otherSalt, err := NewSalt()
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, HashAPIKeySecret(secret, otherSalt))
+}
+
+func TestParseAPIKey_AcceptsLegacyPrefix(t *testing.T) {
+	prefix, secret, ok := ParseAPIKey("tms_abcdef01_secret")
+	assert.True(t, ok)
+	assert.Equal(t, "abcdef01", prefix)
+	assert.Equal(t, "secret", secret)
+}
+
+func TestParseAPIKey_RejectsMalformedKeys(t *testing.T) {
+	for _, key := range []string{"", "tms_", "tms_abc_secret", "tms_abcdef0123_secret", "xyz_abcdef01_secret", "tms_abcdef01_"} {
+		_, _, ok := ParseAPIKey(key)
+		assert.False(t, ok, key)
+	}
+}
@@ -0,0 +1,91 @@
+// Package dns implements provisioning.DNSProvider for DNS servers accepting
+// RFC 2136 dynamic updates and, for local testing, for a zone file.
+package dns
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+// Provider publishes tenant records. It satisfies provisioning.DNSProvider.
+type Provider interface {
+	// CreateRecord replaces the records of the name and type of record
+	CreateRecord(ctx context.Context, record model.DNSRecord) error
+	// DeleteRecord removes the records of the name and type of record;
+	// removing records that do not exist is not an error
+	DeleteRecord(ctx context.Context, record model.DNSRecord) error
+}
+
+// Providers a Provider can be opened with
+const (
+	// ProviderLog only logs the records tenants would get
+	ProviderLog = "log"
+	// ProviderZoneFile keeps records in a zone file
+	ProviderZoneFile = "zone-file"
+	// ProviderRFC2136 sends records to a DNS server as dynamic updates
+	ProviderRFC2136 = "rfc2136"
+)
+
+// Config selects and configures a Provider
+type Config struct {
+	Provider string
+
+	// ZoneFile is the file of the zone-file provider
+	ZoneFile string
+
+	RFC2136 RFC2136Config
+	// TSIGSecretFile holds the base64 encoded secret of the TSIG key
+	TSIGSecretFile string
+}
+
+// Validate checks that the settings of the selected provider are complete
+func (c Config) Validate() error {
+	switch c.Provider {
+	case "", ProviderLog:
+	case ProviderZoneFile:
+		if c.ZoneFile == "" {
+			return errors.New("the zone-file DNS provider needs a zone file")
+		}
+	case ProviderRFC2136:
+		if c.RFC2136.Server == "" || c.RFC2136.Zone == "" {
+			return errors.New("the rfc2136 DNS provider needs a server and a zone")
+		}
+		if c.RFC2136.KeyName != "" && c.TSIGSecretFile == "" && len(c.RFC2136.Secret) == 0 {
+			return errors.New("the rfc2136 DNS provider needs the secret of its TSIG key")
+		}
+	default:
+		return fmt.Errorf("unknown DNS provider %q, want %s, %s or %s", c.Provider, ProviderLog, ProviderZoneFile, ProviderRFC2136)
+	}
+	return nil
+}
+
+// Open opens the configured Provider. It returns nil for ProviderLog.
+func Open(c Config) (Provider, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	switch c.Provider {
+	case ProviderZoneFile:
+		return NewZoneFile(c.ZoneFile)
+	case ProviderRFC2136:
+		cfg := c.RFC2136
+		if c.TSIGSecretFile != "" {
+			data, err := os.ReadFile(c.TSIGSecretFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read TSIG secret: %w", err)
+			}
+			cfg.Secret, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+			if err != nil {
+				return nil, fmt.Errorf("TSIG secret in %s is not base64 encoded", c.TSIGSecretFile)
+			}
+		}
+		return NewRFC2136(cfg)
+	}
+	return nil, nil
+}
@@ -0,0 +1,44 @@
+package dns
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{Provider: ProviderZoneFile, ZoneFile: "tenants.zone"}.Validate())
+	assert.Error(t, Config{Provider: ProviderZoneFile}.Validate())
+	assert.NoError(t, Config{Provider: ProviderRFC2136, RFC2136: RFC2136Config{Server: "ns1:53", Zone: "tenants.local"}}.Validate())
+	assert.Error(t, Config{Provider: ProviderRFC2136, RFC2136: RFC2136Config{Server: "ns1:53"}}.Validate())
+	assert.Error(t, Config{Provider: ProviderRFC2136, RFC2136: RFC2136Config{Server: "ns1:53", Zone: "tenants.local", KeyName: "tms"}}.Validate())
+	assert.Error(t, Config{Provider: "route53"}.Validate())
+}
+
+func TestOpen(t *testing.T) {
+	p, err := Open(Config{Provider: ProviderLog})
+	require.NoError(t, err)
+	assert.Nil(t, p)
+
+	secretFile := filepath.Join(t.TempDir(), "tsig.key")
+	require.NoError(t, os.WriteFile(secretFile, []byte("c2VjcmV0\n"), 0o600))
+	p, err = Open(Config{
+		Provider:       ProviderRFC2136,
+		RFC2136:        RFC2136Config{Server: "ns1:53", Zone: "tenants.local", KeyName: "tms"},
+		TSIGSecretFile: secretFile,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), p.(*RFC2136).cfg.Secret)
+
+	require.NoError(t, os.WriteFile(secretFile, []byte("not base64!"), 0o600))
+	_, err = Open(Config{
+		Provider:       ProviderRFC2136,
+		RFC2136:        RFC2136Config{Server: "ns1:53", Zone: "tenants.local", KeyName: "tms"},
+		TSIGSecretFile: secretFile,
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,383 @@
+package dns
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// TSIG algorithms updates can be signed with
+const (
+	HMACSHA1   = "hmac-sha1"
+	HMACSHA256 = "hmac-sha256"
+	HMACSHA512 = "hmac-sha512"
+)
+
+var tsigHashes = map[string]func() hash.Hash{
+	HMACSHA1:   sha1.New,
+	HMACSHA256: sha256.New,
+	HMACSHA512: sha512.New,
+}
+
+const (
+	opCodeUpdate = dnsmessage.OpCode(5)
+	typeTSIG     = dnsmessage.Type(250)
+	// tsigFudge is how far apart, in seconds, the clocks of the service and
+	// the DNS server may be
+	tsigFudge = 300
+)
+
+// RFC2136Config locates the primary server of the zone tenant records are
+// kept in and the TSIG key updates are signed with
+type RFC2136Config struct {
+	// Server is the host:port of the zone's primary server
+	Server string
+	// Zone is the zone tenant hostnames belong to
+	Zone string
+	// KeyName names the TSIG key; updates are not signed when it is empty
+	KeyName string
+	// Algorithm is the TSIG algorithm, HMACSHA256 by default
+	Algorithm string
+	Secret    []byte
+	// Timeout bounds each update
+	Timeout time.Duration
+}
+
+// RFC2136 publishes tenant records with RFC 2136 dynamic updates sent over
+// TCP, signed with TSIG (RFC 8945). Each update replaces or deletes the
+// records of one name and type.
+type RFC2136 struct {
+	cfg  RFC2136Config
+	hash func() hash.Hash
+}
+
+// NewRFC2136 creates an RFC2136 provider
+func NewRFC2136(cfg RFC2136Config) (*RFC2136, error) {
+	if _, _, err := net.SplitHostPort(cfg.Server); err != nil {
+		return nil, fmt.Errorf("invalid DNS server %q: %w", cfg.Server, err)
+	}
+	cfg.Zone = strings.ToLower(strings.TrimSuffix(cfg.Zone, "."))
+	if cfg.Zone == "" {
+		return nil, errors.New("DNS zone is required")
+	}
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = HMACSHA256
+	}
+	newHash, ok := tsigHashes[cfg.Algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unsupported TSIG algorithm %q", cfg.Algorithm)
+	}
+	if cfg.KeyName != "" && len(cfg.Secret) == 0 {
+		return nil, errors.New("TSIG key has no secret")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &RFC2136{cfg: cfg, hash: newHash}, nil
+}
+
+// CreateRecord replaces the records of the name and type of record with it
+// in one update
+func (p *RFC2136) CreateRecord(ctx context.Context, record model.DNSRecord) error {
+	return p.update(ctx, record, true)
+}
+
+func (p *RFC2136) DeleteRecord(ctx context.Context, record model.DNSRecord) error {
+	return p.update(ctx, record, false)
+}
+
+func (p *RFC2136) update(ctx context.Context, record model.DNSRecord, add bool) error {
+	id, err := randomID()
+	if err != nil {
+		return err
+	}
+	msg, err := p.message(id, record, add, time.Now())
+	if err != nil {
+		return err
+	}
+	resp, err := p.exchange(ctx, msg)
+	if err != nil {
+		return err
+	}
+	if err := checkResponse(resp, id); err != nil {
+		return fmt.Errorf("dynamic update of %s %s: %w", record.Name, record.Type, err)
+	}
+	return nil
+}
+
+// message builds an update deleting the RRset of the record's name and type
+// and, when add is set, adding the record (RFC 2136 sections 2.5.1, 2.5.2)
+func (p *RFC2136) message(id uint16, record model.DNSRecord, add bool, now time.Time) ([]byte, error) {
+	name := strings.ToLower(strings.TrimSuffix(record.Name, "."))
+	if name != p.cfg.Zone && !strings.HasSuffix(name, "."+p.cfg.Zone) {
+		return nil, fmt.Errorf("%s is not in DNS zone %s", record.Name, p.cfg.Zone)
+	}
+	owner, err := dnsmessage.NewName(name + ".")
+	if err != nil {
+		return nil, err
+	}
+	zone, err := dnsmessage.NewName(p.cfg.Zone + ".")
+	if err != nil {
+		return nil, err
+	}
+	var rrType dnsmessage.Type
+	switch record.Type {
+	case model.DNSRecordA:
+		rrType = dnsmessage.TypeA
+	case model.DNSRecordAAAA:
+		rrType = dnsmessage.TypeAAAA
+	case model.DNSRecordCNAME:
+		rrType = dnsmessage.TypeCNAME
+	default:
+		return nil, fmt.Errorf("unsupported DNS record type %q", record.Type)
+	}
+
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: id, OpCode: opCodeUpdate})
+	// The zone section has the layout of the question section
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := b.Question(dnsmessage.Question{Name: zone, Type: dnsmessage.TypeSOA, Class: dnsmessage.ClassINET}); err != nil {
+		return nil, err
+	}
+	// The update section has the layout of the authority section
+	if err := b.StartAuthorities(); err != nil {
+		return nil, err
+	}
+	if err := b.UnknownResource(dnsmessage.ResourceHeader{Name: owner, Class: dnsmessage.ClassANY}, dnsmessage.UnknownResource{Type: rrType}); err != nil {
+		return nil, err
+	}
+	if add {
+		if err := addResource(&b, owner, rrType, record); err != nil {
+			return nil, err
+		}
+	}
+	msg, err := b.Finish()
+	if err != nil {
+		return nil, err
+	}
+	if p.cfg.KeyName == "" {
+		return msg, nil
+	}
+	return p.sign(msg, id, uint64(now.Unix())), nil
+}
+
+// addResource adds record to the update section
+func addResource(b *dnsmessage.Builder, owner dnsmessage.Name, rrType dnsmessage.Type, record model.DNSRecord) error {
+	h := dnsmessage.ResourceHeader{Name: owner, Class: dnsmessage.ClassINET, TTL: uint32(record.TTL)}
+	switch rrType {
+	case dnsmessage.TypeA, dnsmessage.TypeAAAA:
+		ip := net.ParseIP(record.Value)
+		if ip == nil {
+			return fmt.Errorf("invalid IP address %q", record.Value)
+		}
+		if rrType == dnsmessage.TypeA {
+			if ip.To4() == nil {
+				return fmt.Errorf("%s is not an IPv4 address", record.Value)
+			}
+			var a dnsmessage.AResource
+			copy(a.A[:], ip.To4())
+			return b.AResource(h, a)
+		}
+		var aaaa dnsmessage.AAAAResource
+		copy(aaaa.AAAA[:], ip.To16())
+		return b.AAAAResource(h, aaaa)
+	default:
+		target, err := dnsmessage.NewName(strings.TrimSuffix(record.Value, ".") + ".")
+		if err != nil {
+			return err
+		}
+		return b.CNAMEResource(h, dnsmessage.CNAMEResource{CNAME: target})
+	}
+}
+
+// sign appends a TSIG record to msg (RFC 8945 section 4.2)
+func (p *RFC2136) sign(msg []byte, id uint16, signed uint64) []byte {
+	mac := p.tsigMAC(msg, signed, tsigFudge)
+
+	rdata := wireName(p.cfg.Algorithm)
+	rdata = append(rdata, tsigTime(signed)...)
+	rdata = binary.BigEndian.AppendUint16(rdata, tsigFudge)
+	rdata = binary.BigEndian.AppendUint16(rdata, uint16(len(mac)))
+	rdata = append(rdata, mac...)
+	rdata = binary.BigEndian.AppendUint16(rdata, id)
+	rdata = binary.BigEndian.AppendUint16(rdata, 0) // error
+	rdata = binary.BigEndian.AppendUint16(rdata, 0) // other len
+
+	signedMsg := append([]byte(nil), msg...)
+	signedMsg = append(signedMsg, wireName(p.cfg.KeyName)...)
+	signedMsg = binary.BigEndian.AppendUint16(signedMsg, uint16(typeTSIG))
+	signedMsg = binary.BigEndian.AppendUint16(signedMsg, uint16(dnsmessage.ClassANY))
+	signedMsg = binary.BigEndian.AppendUint32(signedMsg, 0) // TTL
+	signedMsg = binary.BigEndian.AppendUint16(signedMsg, uint16(len(rdata)))
+	signedMsg = append(signedMsg, rdata...)
+	// One more record in the additional section
+	binary.BigEndian.PutUint16(signedMsg[10:], binary.BigEndian.Uint16(msg[10:])+1)
+	return signedMsg
+}
+
+// tsigMAC computes the MAC of an unsigned message and the TSIG variables
+// (RFC 8945 section 4.3.3)
+func (p *RFC2136) tsigMAC(msg []byte, signed uint64, fudge uint16) []byte {
+	mac := hmac.New(p.hash, p.cfg.Secret)
+	mac.Write(msg)
+	mac.Write(wireName(p.cfg.KeyName))
+	variables := binary.BigEndian.AppendUint16(nil, uint16(dnsmessage.ClassANY))
+	variables = binary.BigEndian.AppendUint32(variables, 0) // TTL
+	variables = append(variables, wireName(p.cfg.Algorithm)...)
+	variables = append(variables, tsigTime(signed)...)
+	variables = binary.BigEndian.AppendUint16(variables, fudge)
+	variables = binary.BigEndian.AppendUint16(variables, 0) // error
+	variables = binary.BigEndian.AppendUint16(variables, 0) // other len
+	mac.Write(variables)
+	return mac.Sum(nil)
+}
+
+// tsigTime encodes a time as the 48-bit seconds TSIG uses
+func tsigTime(t uint64) []byte {
+	b := binary.BigEndian.AppendUint64(nil, t)
+	return b[2:]
+}
+
+// wireName encodes a name in canonical wire format: lower case and
+// uncompressed
+func wireName(name string) []byte {
+	var b []byte
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if name != "" {
+		for _, label := range strings.Split(name, ".") {
+			b = append(b, byte(len(label)))
+			b = append(b, label...)
+		}
+	}
+	return append(b, 0)
+}
+
+// exchange sends msg to the server over TCP and returns its response
+func (p *RFC2136) exchange(ctx context.Context, msg []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", p.cfg.Server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	framed := binary.BigEndian.AppendUint16(nil, uint16(len(msg)))
+	if _, err := conn.Write(append(framed, msg...)); err != nil {
+		return nil, err
+	}
+	var size [2]byte
+	if _, err := io.ReadFull(conn, size[:]); err != nil {
+		return nil, err
+	}
+	resp := make([]byte, binary.BigEndian.Uint16(size[:]))
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// rcodeNames names the response codes an update can fail with
+var rcodeNames = map[dnsmessage.RCode]string{
+	1: "FORMERR", 2: "SERVFAIL", 3: "NXDOMAIN", 4: "NOTIMP", 5: "REFUSED",
+	6: "YXDOMAIN", 7: "YXRRSET", 8: "NXRRSET", 9: "NOTAUTH", 10: "NOTZONE",
+}
+
+// tsigErrors names the TSIG errors a server reports a rejected key with
+var tsigErrors = map[uint16]string{16: "BADSIG", 17: "BADKEY", 18: "BADTIME"}
+
+// checkResponse returns an error unless resp reports that update id succeeded
+func checkResponse(resp []byte, id uint16) error {
+	var parser dnsmessage.Parser
+	h, err := parser.Start(resp)
+	if err != nil {
+		return err
+	}
+	if !h.Response || h.ID != id {
+		return errors.New("DNS server sent an unexpected message")
+	}
+	if h.RCode == dnsmessage.RCodeSuccess {
+		return nil
+	}
+	rcode, ok := rcodeNames[h.RCode]
+	if !ok {
+		rcode = fmt.Sprintf("RCODE %d", h.RCode)
+	}
+	if tsigErr := responseTSIGError(&parser); tsigErr != "" {
+		return fmt.Errorf("server answered %s (%s)", rcode, tsigErr)
+	}
+	return fmt.Errorf("server answered %s", rcode)
+}
+
+// responseTSIGError returns the TSIG error of a response, if it has one
+func responseTSIGError(parser *dnsmessage.Parser) string {
+	if parser.SkipAllQuestions() != nil || parser.SkipAllAnswers() != nil || parser.SkipAllAuthorities() != nil {
+		return ""
+	}
+	for {
+		h, err := parser.AdditionalHeader()
+		if err != nil {
+			return ""
+		}
+		if h.Type != typeTSIG {
+			if parser.SkipAdditional() != nil {
+				return ""
+			}
+			continue
+		}
+		r, err := parser.UnknownResource()
+		if err != nil {
+			return ""
+		}
+		// Skip the algorithm name, time signed, fudge and MAC
+		data := r.Data
+		for len(data) > 0 && data[0] != 0 {
+			if 1+int(data[0]) > len(data) {
+				return ""
+			}
+			data = data[1+int(data[0]):]
+		}
+		if len(data) < 11 {
+			return ""
+		}
+		data = data[1+6+2:]
+		macSize := int(binary.BigEndian.Uint16(data))
+		if len(data) < 2+macSize+4 {
+			return ""
+		}
+		code := binary.BigEndian.Uint16(data[2+macSize+2:])
+		if name, ok := tsigErrors[code]; ok {
+			return name
+		}
+		return fmt.Sprintf("TSIG error %d", code)
+	}
+}
+
+// randomID returns a random message ID
+func randomID() (uint16, error) {
+	var b [2]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(b[:]), nil
+}
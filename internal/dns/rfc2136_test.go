@@ -0,0 +1,249 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// update is a dynamic update as received by fakeUpdateServer
+type update struct {
+	zone    string
+	changes []string
+	signed  bool
+}
+
+// fakeUpdateServer stands in for the primary server of a zone, accepting
+// dynamic updates over TCP and checking their TSIG signature with the key of
+// verifier
+type fakeUpdateServer struct {
+	t        *testing.T
+	listener net.Listener
+	verifier *RFC2136
+	rcode    dnsmessage.RCode
+
+	mu      sync.Mutex
+	updates []update
+}
+
+func newFakeUpdateServer(t *testing.T, key RFC2136Config) *fakeUpdateServer {
+	key.Server, key.Zone = "127.0.0.1:53", "tenants.local"
+	verifier, err := NewRFC2136(key)
+	require.NoError(t, err)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	s := &fakeUpdateServer{t: t, listener: listener, verifier: verifier}
+	go s.serve()
+	return s
+}
+
+func (s *fakeUpdateServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.handle(conn)
+	}
+}
+
+func (s *fakeUpdateServer) handle(conn net.Conn) {
+	defer conn.Close()
+	var size [2]byte
+	if _, err := io.ReadFull(conn, size[:]); err != nil {
+		return
+	}
+	msg := make([]byte, binary.BigEndian.Uint16(size[:]))
+	if _, err := io.ReadFull(conn, msg); err != nil {
+		return
+	}
+	h, u, err := s.parse(msg)
+	if !assert.NoError(s.t, err) {
+		return
+	}
+	s.mu.Lock()
+	s.updates = append(s.updates, u)
+	s.mu.Unlock()
+
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: h.ID, Response: true, OpCode: h.OpCode, RCode: s.rcode})
+	resp, err := b.Finish()
+	if !assert.NoError(s.t, err) {
+		return
+	}
+	conn.Write(append(binary.BigEndian.AppendUint16(nil, uint16(len(resp))), resp...))
+}
+
+func (s *fakeUpdateServer) parse(msg []byte) (dnsmessage.Header, update, error) {
+	var u update
+	var parser dnsmessage.Parser
+	h, err := parser.Start(msg)
+	if err != nil {
+		return h, u, err
+	}
+	if h.OpCode != opCodeUpdate {
+		return h, u, fmt.Errorf("unexpected opcode %d", h.OpCode)
+	}
+	q, err := parser.Question()
+	if err != nil {
+		return h, u, err
+	}
+	u.zone = q.Name.String()
+	if err := parser.SkipAllQuestions(); err != nil {
+		return h, u, err
+	}
+	if err := parser.SkipAllAnswers(); err != nil {
+		return h, u, err
+	}
+	for {
+		rh, err := parser.AuthorityHeader()
+		if err == dnsmessage.ErrSectionDone {
+			break
+		}
+		if err != nil {
+			return h, u, err
+		}
+		r, err := parser.UnknownResource()
+		if err != nil {
+			return h, u, err
+		}
+		u.changes = append(u.changes, describe(rh, r.Data))
+	}
+	rh, err := parser.AdditionalHeader()
+	if err == dnsmessage.ErrSectionDone {
+		return h, u, nil
+	}
+	if err != nil {
+		return h, u, err
+	}
+	if rh.Type != typeTSIG {
+		return h, u, fmt.Errorf("unexpected additional record of type %d", rh.Type)
+	}
+	r, err := parser.UnknownResource()
+	if err != nil {
+		return h, u, err
+	}
+	u.signed = s.verify(msg, r.Data)
+	return h, u, nil
+}
+
+// verify checks the MAC of a TSIG record against the message it signs
+func (s *fakeUpdateServer) verify(msg, rdata []byte) bool {
+	alg := wireName(s.verifier.cfg.Algorithm)
+	if !bytes.HasPrefix(rdata, alg) {
+		return false
+	}
+	data := rdata[len(alg):]
+	signed := binary.BigEndian.Uint64(append([]byte{0, 0}, data[:6]...))
+	fudge := binary.BigEndian.Uint16(data[6:])
+	macSize := int(binary.BigEndian.Uint16(data[8:]))
+	mac := data[10 : 10+macSize]
+
+	// Strip the TSIG record and take it out of the additional section count
+	tsigSize := len(wireName(s.verifier.cfg.KeyName)) + 10 + len(rdata)
+	unsigned := append([]byte(nil), msg[:len(msg)-tsigSize]...)
+	binary.BigEndian.PutUint16(unsigned[10:], binary.BigEndian.Uint16(unsigned[10:])-1)
+	return bytes.Equal(s.verifier.tsigMAC(unsigned, signed, fudge), mac)
+}
+
+func (s *fakeUpdateServer) received() []update {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]update(nil), s.updates...)
+}
+
+// describe renders an update section record like a zone file line
+func describe(h dnsmessage.ResourceHeader, data []byte) string {
+	class := "ANY"
+	if h.Class == dnsmessage.ClassINET {
+		class = "IN"
+	}
+	line := fmt.Sprintf("%s %d %s %s", h.Name, h.TTL, class, strings.TrimPrefix(h.Type.String(), "Type"))
+	switch {
+	case len(data) == 0:
+		return line
+	case h.Type == dnsmessage.TypeCNAME:
+		var labels []string
+		for len(data) > 0 && data[0] != 0 {
+			labels = append(labels, string(data[1:1+data[0]]))
+			data = data[1+data[0]:]
+		}
+		return line + " " + strings.Join(labels, ".") + "."
+	default:
+		return line + " " + net.IP(data).String()
+	}
+}
+
+func TestRFC2136(t *testing.T) {
+	key := RFC2136Config{KeyName: "tms-key.", Algorithm: HMACSHA512, Secret: []byte("0123456789abcdef")}
+	server := newFakeUpdateServer(t, key)
+	cfg := key
+	cfg.Server, cfg.Zone = server.listener.Addr().String(), "Tenants.local."
+	p, err := NewRFC2136(cfg)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, p.CreateRecord(ctx, model.NewDNSRecord("acme.tenants.local", "ingress.example.com", 300)))
+	require.NoError(t, p.CreateRecord(ctx, model.NewDNSRecord("globex.tenants.local", "2001:db8::1", 60)))
+	require.NoError(t, p.DeleteRecord(ctx, model.NewDNSRecord("acme.tenants.local", "ingress.example.com", 300)))
+
+	assert.Equal(t, []update{
+		{zone: "tenants.local.", signed: true, changes: []string{
+			"acme.tenants.local. 0 ANY CNAME",
+			"acme.tenants.local. 300 IN CNAME ingress.example.com.",
+		}},
+		{zone: "tenants.local.", signed: true, changes: []string{
+			"globex.tenants.local. 0 ANY AAAA",
+			"globex.tenants.local. 60 IN AAAA 2001:db8::1",
+		}},
+		{zone: "tenants.local.", signed: true, changes: []string{
+			"acme.tenants.local. 0 ANY CNAME",
+		}},
+	}, server.received())
+}
+
+func TestRFC2136_Unsigned(t *testing.T) {
+	server := newFakeUpdateServer(t, RFC2136Config{})
+	p, err := NewRFC2136(RFC2136Config{Server: server.listener.Addr().String(), Zone: "tenants.local"})
+	require.NoError(t, err)
+
+	require.NoError(t, p.CreateRecord(context.Background(), model.NewDNSRecord("acme.tenants.local", "10.0.0.1", 300)))
+	assert.Equal(t, []update{{zone: "tenants.local.", changes: []string{
+		"acme.tenants.local. 0 ANY A",
+		"acme.tenants.local. 300 IN A 10.0.0.1",
+	}}}, server.received())
+}
+
+func TestRFC2136_Errors(t *testing.T) {
+	server := newFakeUpdateServer(t, RFC2136Config{})
+	server.rcode = dnsmessage.RCodeRefused
+	p, err := NewRFC2136(RFC2136Config{Server: server.listener.Addr().String(), Zone: "tenants.local", Timeout: time.Second})
+	require.NoError(t, err)
+
+	err = p.CreateRecord(context.Background(), model.NewDNSRecord("acme.tenants.local", "10.0.0.1", 300))
+	assert.EqualError(t, err, "dynamic update of acme.tenants.local A: server answered REFUSED")
+
+	err = p.CreateRecord(context.Background(), model.NewDNSRecord("acme.example.com", "10.0.0.1", 300))
+	assert.EqualError(t, err, "acme.example.com is not in DNS zone tenants.local")
+	assert.Len(t, server.received(), 1)
+
+	_, err = NewRFC2136(RFC2136Config{Server: "dns.local", Zone: "tenants.local"})
+	assert.Error(t, err)
+	_, err = NewRFC2136(RFC2136Config{Server: "dns.local:53", Zone: "tenants.local", KeyName: "tms-key"})
+	assert.Error(t, err)
+	_, err = NewRFC2136(RFC2136Config{Server: "dns.local:53", Zone: "tenants.local", Algorithm: "hmac-md5"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,152 @@
+package dns
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+// ZoneFile keeps tenant records in memory and, when it has a path, in a file
+// of zone file lines, which a local DNS server can $INCLUDE into a zone. The
+// file is rewritten through a rename on every change and read back when the
+// ZoneFile is opened, so it must not be edited by hand while in use.
+type ZoneFile struct {
+	mu      sync.Mutex
+	path    string
+	records map[recordKey]model.DNSRecord
+}
+
+// recordKey identifies the records a DNSProvider replaces or deletes at once
+type recordKey struct {
+	name, recordType string
+}
+
+func keyOf(r model.DNSRecord) recordKey {
+	return recordKey{strings.ToLower(r.Name), r.Type}
+}
+
+// NewZoneFile opens the zone file at path, or keeps records only in memory
+// when path is empty
+func NewZoneFile(path string) (*ZoneFile, error) {
+	z := &ZoneFile{path: path, records: make(map[recordKey]model.DNSRecord)}
+	if path == "" {
+		return z, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return z, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, ";") || strings.HasPrefix(text, "$") {
+			continue
+		}
+		record, err := model.ParseDNSRecord(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		z.records[keyOf(record)] = record
+	}
+	return z, scanner.Err()
+}
+
+func (z *ZoneFile) CreateRecord(ctx context.Context, record model.DNSRecord) error {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	key := keyOf(record)
+	if current, ok := z.records[key]; ok && current == record {
+		return nil
+	}
+	z.records[key] = record
+	return z.save()
+}
+
+func (z *ZoneFile) DeleteRecord(ctx context.Context, record model.DNSRecord) error {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	key := keyOf(record)
+	if _, ok := z.records[key]; !ok {
+		return nil
+	}
+	delete(z.records, key)
+	return z.save()
+}
+
+// Records returns every record, ordered by name and type
+func (z *ZoneFile) Records() []model.DNSRecord {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	return z.sorted()
+}
+
+// Lookup returns the records of a name
+func (z *ZoneFile) Lookup(name string) []model.DNSRecord {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	var records []model.DNSRecord
+	for _, record := range z.Records() {
+		if strings.ToLower(record.Name) == name {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+func (z *ZoneFile) sorted() []model.DNSRecord {
+	records := make([]model.DNSRecord, 0, len(z.records))
+	for _, record := range z.records {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Name != records[j].Name {
+			return records[i].Name < records[j].Name
+		}
+		return records[i].Type < records[j].Type
+	})
+	return records
+}
+
+// save writes the records to the zone file, if there is one
+func (z *ZoneFile) save() error {
+	if z.path == "" {
+		return nil
+	}
+	var buf bytes.Buffer
+	buf.WriteString("; Tenant records, managed by tenant-management-service\n")
+	for _, record := range z.sorted() {
+		buf.WriteString(record.String())
+		buf.WriteByte('\n')
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(z.path), filepath.Base(z.path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// DNS servers read the file as another user
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), z.path)
+}
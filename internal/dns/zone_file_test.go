@@ -0,0 +1,53 @@
+package dns
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+func TestZoneFile(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "tenants.zone")
+	z, err := NewZoneFile(path)
+	require.NoError(t, err)
+
+	acme := model.NewDNSRecord("acme.tenants.local", "ingress.example.com", 300)
+	globex := model.NewDNSRecord("globex.tenants.local", "10.0.0.1", 60)
+	require.NoError(t, z.CreateRecord(ctx, acme))
+	require.NoError(t, z.CreateRecord(ctx, globex))
+	require.NoError(t, z.CreateRecord(ctx, acme))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "; Tenant records, managed by tenant-management-service\n"+
+		"acme.tenants.local. 300 IN CNAME ingress.example.com.\n"+
+		"globex.tenants.local. 60 IN A 10.0.0.1\n", string(data))
+
+	// Creating a record replaces the one of the same name and type
+	moved := model.NewDNSRecord("ACME.tenants.local.", "lb.example.com", 300)
+	require.NoError(t, z.CreateRecord(ctx, moved))
+	assert.Equal(t, []model.DNSRecord{moved}, z.Lookup("acme.tenants.local"))
+
+	reopened, err := NewZoneFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, z.Records(), reopened.Records())
+
+	require.NoError(t, reopened.DeleteRecord(ctx, acme))
+	require.NoError(t, reopened.DeleteRecord(ctx, acme))
+	assert.Empty(t, reopened.Lookup("acme.tenants.local"))
+	assert.Equal(t, []model.DNSRecord{globex}, reopened.Records())
+}
+
+func TestZoneFile_InMemory(t *testing.T) {
+	z, err := NewZoneFile("")
+	require.NoError(t, err)
+	record := model.NewDNSRecord("acme.tenants.local", "2001:db8::1", 300)
+	require.NoError(t, z.CreateRecord(context.Background(), record))
+	assert.Equal(t, []model.DNSRecord{{Name: "acme.tenants.local", Type: model.DNSRecordAAAA, Value: "2001:db8::1", TTL: 300}}, z.Records())
+}
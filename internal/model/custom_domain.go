@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Custom domain statuses. Pending domains whose token has expired are
+// reported as DomainStatusExpired; the status is never stored.
+const (
+	DomainStatusPending  = "pending"
+	DomainStatusVerified = "verified"
+	DomainStatusExpired  = "expired"
+)
+
+// DomainVerificationLabel is prepended to a custom domain to name the TXT
+// record its owner publishes the verification token in
+const DomainVerificationLabel = "_tms-verification"
+
+// CustomDomain represents the tenant_custom_domains table: a domain a tenant
+// is reachable under once its ownership is verified
+type CustomDomain struct {
+	ID       uuid.UUID `json:"id"`
+	TenantID uuid.UUID `json:"tenant_id"`
+	// Domain is lower case, without a trailing dot
+	Domain            string     `json:"domain"`
+	Status            string     `json:"status"`
+	VerificationToken string     `json:"verification_token"`
+	TokenExpiresAt    time.Time  `json:"token_expires_at"`
+	VerifiedAt        *time.Time `json:"verified_at,omitempty"`
+	LastCheckedAt     *time.Time `json:"last_checked_at,omitempty"`
+	// LastCheckError describes why the last verification attempt failed
+	LastCheckError *string   `json:"last_check_error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// State returns the status of the domain at now
+func (d *CustomDomain) State(now time.Time) string {
+	if d.Status == DomainStatusPending && !now.Before(d.TokenExpiresAt) {
+		return DomainStatusExpired
+	}
+	return d.Status
+}
+
+// VerificationName returns the name of the TXT record proving ownership
+func (d *CustomDomain) VerificationName() string {
+	return DomainVerificationLabel + "." + d.Domain
+}
+
+// VerificationValue returns the text the TXT record must hold
+func (d *CustomDomain) VerificationValue() string {
+	return "tms-verification=" + d.VerificationToken
+}
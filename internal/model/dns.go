@@ -0,0 +1,70 @@
+package model
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// DNS record types tenant hostnames are published with
+const (
+	DNSRecordA     = "A"
+	DNSRecordAAAA  = "AAAA"
+	DNSRecordCNAME = "CNAME"
+)
+
+// DNSRecord is a DNS record publishing a tenant hostname. Names are fully
+// qualified and kept without their trailing dot.
+type DNSRecord struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+	TTL   int    `json:"ttl"`
+}
+
+// NewDNSRecord returns the record pointing name at target: an A or AAAA
+// record for an IP address, a CNAME for a hostname
+func NewDNSRecord(name, target string, ttl int) DNSRecord {
+	recordType := DNSRecordCNAME
+	if ip := net.ParseIP(target); ip != nil {
+		recordType = DNSRecordAAAA
+		if ip.To4() != nil {
+			recordType = DNSRecordA
+		}
+	}
+	return DNSRecord{Name: strings.TrimSuffix(name, "."), Type: recordType, Value: strings.TrimSuffix(target, "."), TTL: ttl}
+}
+
+// String returns the record as a zone file line, the form it is kept in
+// tenant_specific_configs.dns_record
+func (r DNSRecord) String() string {
+	value := r.Value
+	if r.Type == DNSRecordCNAME {
+		value += "."
+	}
+	return fmt.Sprintf("%s. %d IN %s %s", r.Name, r.TTL, r.Type, value)
+}
+
+// ParseDNSRecord parses a record written by DNSRecord.String
+func ParseDNSRecord(s string) (DNSRecord, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 5 || fields[2] != "IN" {
+		return DNSRecord{}, fmt.Errorf("invalid DNS record %q", s)
+	}
+	ttl, err := strconv.Atoi(fields[1])
+	if err != nil || ttl < 0 {
+		return DNSRecord{}, fmt.Errorf("invalid TTL in DNS record %q", s)
+	}
+	switch fields[3] {
+	case DNSRecordA, DNSRecordAAAA, DNSRecordCNAME:
+	default:
+		return DNSRecord{}, fmt.Errorf("unsupported type in DNS record %q", s)
+	}
+	return DNSRecord{
+		Name:  strings.TrimSuffix(fields[0], "."),
+		Type:  fields[3],
+		Value: strings.TrimSuffix(fields[4], "."),
+		TTL:   ttl,
+	}, nil
+}
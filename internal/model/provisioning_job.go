@@ -0,0 +1,67 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Provisioning job kinds
+const (
+	JobKindProvision   = "provision"
+	JobKindDeprovision = "deprovision"
+	JobKindMove        = "move"
+)
+
+// Provisioning job statuses
+const (
+	JobStatusPending    = "pending"
+	JobStatusRunning    = "running"
+	JobStatusSucceeded  = "succeeded"
+	JobStatusDeadLetter = "dead_letter"
+)
+
+// ProvisioningJob represents the provisioning_jobs table
+type ProvisioningJob struct {
+	ID          uuid.UUID  `json:"id"`
+	TenantID    uuid.UUID  `json:"tenant_id"`
+	Kind        string     `json:"kind"`
+	Status      string     `json:"status"`
+	DBHost      string     `json:"db_host"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	RunAt       time.Time  `json:"run_at"`
+	LockedBy    *string    `json:"locked_by,omitempty"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	HeartbeatAt *time.Time `json:"heartbeat_at,omitempty"`
+	LastError   *string    `json:"last_error,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Exhausted reports whether the job has used up its attempts
+func (j *ProvisioningJob) Exhausted() bool {
+	return j.Attempts >= j.MaxAttempts
+}
+
+// QueueStats summarises the provisioning job queue
+type QueueStats struct {
+	Pending    int64
+	Running    int64
+	DeadLetter int64
+	// OldestPendingAge is how long the oldest due pending job has been waiting
+	OldestPendingAge time.Duration
+}
+
+// StuckTenant is a tenant left in provisioning or deprovisioning that no
+// worker is making progress on
+type StuckTenant struct {
+	TenantID uuid.UUID
+	// Status is provisioning or deprovisioning
+	Status string
+	// Job is the tenant's latest job for its status, or nil if it never had one
+	Job *ProvisioningJob
+	// LastProgressAt is when the tenant's provisioning last logged progress
+	LastProgressAt time.Time
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Shard represents the database_shards table: a database on a server that
+// tenant schemas are placed on
+type Shard struct {
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	Host         string    `json:"host"`
+	Port         int       `json:"port"`
+	DatabaseName string    `json:"database_name"`
+	Region       string    `json:"region"`
+	// Capacity is how many tenant schemas the shard holds at most
+	Capacity int `json:"capacity"`
+	// Weight is the shard's share of new tenants under weighted placement
+	Weight int `json:"weight"`
+	// Draining shards keep their tenants but take no new ones
+	Draining bool `json:"draining"`
+	// Tenants is how many tenant schemas are placed on the shard
+	Tenants   int       `json:"tenants"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Available reports whether the shard can take another tenant
+func (s *Shard) Available() bool {
+	return !s.Draining && s.Tenants < s.Capacity
+}
+
+// ShardSchema represents the tenant_shard_schemas table
+type ShardSchema struct {
+	TenantID        uuid.UUID  `json:"tenant_id"`
+	ShardID         uuid.UUID  `json:"shard_id"`
+	SchemaName      string     `json:"schema_name"`
+	TemplateVersion int        `json:"template_version"`
+	DropAfter       *time.Time `json:"drop_after,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// Tenant move statuses
+const (
+	MoveStatusPending   = "pending"
+	MoveStatusCompleted = "completed"
+	MoveStatusFailed    = "failed"
+)
+
+// TenantMove represents the tenant_moves table: a tenant schema moving from
+// one shard to another
+type TenantMove struct {
+	ID            uuid.UUID `json:"id"`
+	TenantID      uuid.UUID `json:"tenant_id"`
+	SourceShardID uuid.UUID `json:"source_shard_id"`
+	TargetShardID uuid.UUID `json:"target_shard_id"`
+	SchemaName    string    `json:"schema_name"`
+	Status        string    `json:"status"`
+	LastError     *string   `json:"last_error,omitempty"`
+	// DropAfter is when the source schema is dropped once the move completed
+	DropAfter       *time.Time `json:"drop_after,omitempty"`
+	SourceDroppedAt *time.Time `json:"source_dropped_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+}
+
+// TableChecksum summarises the rows of a table, to compare two copies of it
+type TableChecksum struct {
+	Rows     int64  `json:"rows"`
+	Checksum string `json:"checksum"`
+}
@@ -6,15 +6,43 @@ import (
 	"github.com/google/uuid"
 )
 
+// Subscription tiers
+const (
+	TierFree       = "free"
+	TierBasic      = "basic"
+	TierPremium    = "premium"
+	TierEnterprise = "enterprise"
+)
+
+// Tiers lists every valid subscription tier
+var Tiers = []string{TierFree, TierBasic, TierPremium, TierEnterprise}
+
+// Isolation modes of tenant data
+const (
+	// IsolationSchema gives a tenant a schema in the shared database
+	IsolationSchema = "schema"
+	// IsolationDatabase gives a tenant a database of its own
+	IsolationDatabase = "database"
+	// IsolationShared keeps a tenant's rows in tables shared with other
+	// tenants, kept apart by row-level security
+	IsolationShared = "shared"
+)
+
+// IsolationModes lists every valid isolation mode
+var IsolationModes = []string{IsolationSchema, IsolationDatabase, IsolationShared}
+
 // Tenant represents the tenants table
 type Tenant struct {
 	ID             uuid.UUID  `json:"id"`
 	Name           string     `json:"name"`
 	Subdomain      string     `json:"subdomain"`
+	Tier           string     `json:"tier"`
+	Region         string     `json:"region,omitempty"` // Region the tenant's data should stay in
 	ContactEmail   string     // Plaintext (transient, not stored in DB)
 	EncryptedEmail []byte     // Stored in DB
 	EmailIV        []byte     // Stored in DB
 	Status         string     `json:"status"`
+	StatusReason   string     `json:"status_reason,omitempty"` // Why the tenant is in its status, e.g. a provisioning failure
 	CreatedAt      time.Time  `json:"created_at"`
 	UpdatedAt      time.Time  `json:"updated_at"`
 	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
@@ -46,6 +74,35 @@ type TenantDatabaseConfig struct {
 	MaxConnections            int       `json:"max_connections"`
 	IdleConnections           int       `json:"idle_connections"`
 	ConnectionLifetimeMinutes int       `json:"connection_lifetime_minutes"`
+	IsolationMode             string    `json:"isolation_mode"`
 	CreatedAt                 time.Time `json:"created_at"`
 	UpdatedAt                 time.Time `json:"updated_at"`
 }
+
+// RoleLimits bounds what a tenant's database login role may use
+type RoleLimits struct {
+	// StatementTimeout aborts statements running longer; 0 disables it
+	StatementTimeout time.Duration `json:"statement_timeout"`
+	// ConnectionLimit is how many connections the role may hold at once
+	ConnectionLimit int `json:"connection_limit"`
+}
+
+// TenantSchema is a tenant's schema and the template version it is at
+type TenantSchema struct {
+	TenantID        uuid.UUID `json:"tenant_id"`
+	SchemaName      string    `json:"schema_name"`
+	TemplateVersion int       `json:"template_version"`
+}
+
+// DedicatedDatabase represents the tenant_dedicated_databases table
+type DedicatedDatabase struct {
+	TenantID        uuid.UUID  `json:"tenant_id"`
+	Host            string     `json:"host"`
+	Port            int        `json:"port"`
+	DatabaseName    string     `json:"database_name"`
+	RoleName        string     `json:"role_name"`
+	TemplateVersion int        `json:"template_version"`
+	DropAfter       *time.Time `json:"drop_after,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
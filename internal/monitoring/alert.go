@@ -1,13 +1,77 @@
 package monitoring
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
 	"github.com/rs/zerolog/log"
 )
 
-// MockAlert sends a mock alert (logs for now)
-func MockAlert(message string, labels map[string]string) {
+// Alert severities
+const (
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Alert describes a condition that needs an operator's attention
+type Alert struct {
+	Name     string            `json:"name"`
+	Severity string            `json:"severity"`
+	Message  string            `json:"message"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	FiredAt  time.Time         `json:"fired_at"`
+}
+
+// Alerter delivers alerts to operators
+type Alerter interface {
+	Alert(ctx context.Context, alert Alert) error
+}
+
+// LogAlerter writes alerts to the log, for deployments that alert on log lines
+type LogAlerter struct{}
+
+func (LogAlerter) Alert(ctx context.Context, alert Alert) error {
 	log.Error().
-		Str("alert", message).
-		Fields(labels).
-		Msg("ALERT: Provisioning issue detected")
+		Str("alert", alert.Name).
+		Str("severity", alert.Severity).
+		Fields(alert.Labels).
+		Msg("ALERT: " + alert.Message)
+	return nil
+}
+
+// WebhookAlerter posts alerts as JSON to an HTTP endpoint, such as an
+// Alertmanager or chat webhook receiver
+type WebhookAlerter struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookAlerter creates an alerter posting to url
+func NewWebhookAlerter(url string, timeout time.Duration) *WebhookAlerter {
+	return &WebhookAlerter{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (w *WebhookAlerter) Alert(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("alert webhook returned %s", resp.Status)
+	}
+	return nil
 }
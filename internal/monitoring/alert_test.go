@@ -0,0 +1,37 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookAlerter(t *testing.T) {
+	var got Alert
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	alert := Alert{Name: "TenantProvisioningFailed", Severity: SeverityCritical, Message: "failed", Labels: map[string]string{"tenant_id": "t1"}}
+	assert.NoError(t, NewWebhookAlerter(srv.URL, time.Second).Alert(context.Background(), alert))
+	assert.Equal(t, alert.Name, got.Name)
+	assert.Equal(t, "t1", got.Labels["tenant_id"])
+}
+
+func TestWebhookAlerter_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	err := NewWebhookAlerter(srv.URL, time.Second).Alert(context.Background(), Alert{Name: "x"})
+	assert.ErrorContains(t, err, "503")
+}
@@ -13,6 +13,13 @@ var (
 		},
 		[]string{"status"},
 	)
+	TenantsDeprovisioned = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "tenants_deprovisioned_total",
+			Help: "Total number of tenants deprovisioned by status",
+		},
+		[]string{"status"},
+	)
 	ProvisioningDuration = prometheus.NewHistogram( // Changed to ProvisioningDuration
 		prometheus.HistogramOpts{
 			Name:    "tenant_provisioning_duration_seconds",
@@ -20,6 +27,67 @@ var (
 			Buckets: prometheus.LinearBuckets(0, 1, 10), // 0 to 10 seconds
 		},
 	)
+	ProvisioningQueueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "tenant_provisioning_queue_depth",
+			Help: "Number of provisioning jobs by status",
+		},
+		[]string{"status"},
+	)
+	ProvisioningQueueOldestAge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "tenant_provisioning_queue_oldest_age_seconds",
+			Help: "Age of the oldest provisioning job waiting to be claimed",
+		},
+	)
+	ProvisioningWorkersBusy = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "tenant_provisioning_workers_busy",
+			Help: "Provisioning jobs running in this replica by database host",
+		},
+		[]string{"db_host"},
+	)
+	ProvisioningEnqueueSaturated = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "tenant_provisioning_enqueue_saturated_total",
+			Help: "Provisioning requests made while the queue was saturated, by outcome",
+		},
+		[]string{"outcome"},
+	)
+	ProvisioningAttempts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "tenant_provisioning_attempts_total",
+			Help: "Provisioning job attempts by outcome (success, transient, permanent)",
+		},
+		[]string{"outcome"},
+	)
+	ProvisioningStuckTenants = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "tenant_provisioning_stuck_tenants",
+			Help: "Tenants found stuck in provisioning by the last recovery scan",
+		},
+	)
+	SchemasDropped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "tenant_schemas_dropped_total",
+			Help: "Schemas and dedicated databases of deprovisioned tenants dropped after their retention delay, by status",
+		},
+		[]string{"status"},
+	)
+	TenantsMoved = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "tenants_moved_total",
+			Help: "Tenant schemas moved to another database shard, by status",
+		},
+		[]string{"status"},
+	)
+	ProvisioningRecovered = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "tenant_provisioning_recovered_total",
+			Help: "Tenants recovered from a stuck provisioning state by action (requeued, released, failed)",
+		},
+		[]string{"action"},
+	)
 )
 
 func InitMetrics() {
@@ -32,4 +100,21 @@ func InitMetrics() {
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to register ProvisioningDuration metric")
 	}
+
+	for name, collector := range map[string]prometheus.Collector{
+		"TenantsDeprovisioned":         TenantsDeprovisioned,
+		"ProvisioningQueueDepth":       ProvisioningQueueDepth,
+		"ProvisioningQueueOldestAge":   ProvisioningQueueOldestAge,
+		"ProvisioningWorkersBusy":      ProvisioningWorkersBusy,
+		"ProvisioningEnqueueSaturated": ProvisioningEnqueueSaturated,
+		"ProvisioningAttempts":         ProvisioningAttempts,
+		"ProvisioningStuckTenants":     ProvisioningStuckTenants,
+		"ProvisioningRecovered":        ProvisioningRecovered,
+		"SchemasDropped":               SchemasDropped,
+		"TenantsMoved":                 TenantsMoved,
+	} {
+		if err := prometheus.Register(collector); err != nil {
+			log.Error().Err(err).Msgf("Failed to register %s metric", name)
+		}
+	}
 }
@@ -0,0 +1,68 @@
+package provisioning
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+
+	"github.com/google/uuid"
+)
+
+// PasswordSetter changes the password of tenant login roles
+type PasswordSetter interface {
+	SetTenantRolePassword(ctx context.Context, role, password string) error
+}
+
+// Credentials issues the passwords of the tenant roles on one server and
+// keeps them in a SecretWriter under PasswordSecretID
+type Credentials struct {
+	roles   PasswordSetter
+	secrets SecretWriter
+}
+
+// NewCredentials creates Credentials for the roles managed by roles
+func NewCredentials(roles PasswordSetter, secrets SecretWriter) *Credentials {
+	return &Credentials{roles: roles, secrets: secrets}
+}
+
+// Rotate gives a tenant's role a new password, stores it and returns the ID
+// of the secret holding it. The password is stored first, so it is never
+// lost: if the role cannot be changed, the stored secret is ahead of the role
+// until Rotate is run again, which is always safe. When the secret writer
+// does not keep secrets no password is issued and the ID is empty.
+func (c *Credentials) Rotate(ctx context.Context, tenantID uuid.UUID) (string, error) {
+	if !c.Stored() {
+		return "", nil
+	}
+	password, err := generatePassword()
+	if err != nil {
+		return "", err
+	}
+	secretID := PasswordSecretID(tenantID)
+	if err := c.secrets.PutSecret(ctx, secretID, password); err != nil {
+		return "", err
+	}
+	if err := c.roles.SetTenantRolePassword(ctx, RoleName(tenantID), password); err != nil {
+		return "", err
+	}
+	return secretID, nil
+}
+
+// Stored reports whether rotated passwords are kept anywhere
+func (c *Credentials) Stored() bool {
+	return c.secrets.KeepsSecrets()
+}
+
+// Forget deletes the stored password of a tenant's role
+func (c *Credentials) Forget(ctx context.Context, tenantID uuid.UUID) error {
+	return c.secrets.DeleteSecret(ctx, PasswordSecretID(tenantID))
+}
+
+// generatePassword returns a random password for a tenant role
+func generatePassword() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,70 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingSecrets struct{}
+
+func (failingSecrets) PutSecret(ctx context.Context, id, value string) error {
+	return errors.New("secret store unavailable")
+}
+
+func (failingSecrets) DeleteSecret(ctx context.Context, id string) error {
+	return nil
+}
+
+func (failingSecrets) KeepsSecrets() bool {
+	return true
+}
+
+func TestCredentials_Rotate(t *testing.T) {
+	server := newFakeServer()
+	secrets := fakeSecrets{}
+	creds := NewCredentials(server, secrets)
+	id := uuid.New()
+	role, secretID := RoleName(id), PasswordSecretID(id)
+
+	stored, err := creds.Rotate(context.Background(), id)
+	assert.NoError(t, err)
+	assert.Equal(t, secretID, stored)
+	first := secrets[secretID]
+	assert.NotEmpty(t, first)
+	assert.Equal(t, first, server.passwords[role])
+
+	_, err = creds.Rotate(context.Background(), id)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, secrets[secretID])
+	assert.Equal(t, secrets[secretID], server.passwords[role])
+
+	assert.NoError(t, creds.Forget(context.Background(), id))
+	assert.Empty(t, secrets)
+}
+
+func TestCredentials_RotateFailsWhenSecretNotStored(t *testing.T) {
+	server := newFakeServer()
+	creds := NewCredentials(server, failingSecrets{})
+	id := uuid.New()
+	_, err := creds.Rotate(context.Background(), id)
+	assert.Error(t, err)
+	assert.NotContains(t, server.passwords, RoleName(id))
+}
+
+func TestCredentials_Stored(t *testing.T) {
+	assert.True(t, NewCredentials(newFakeServer(), fakeSecrets{}).Stored())
+	assert.False(t, NewCredentials(newFakeServer(), LogSecretWriter{}).Stored())
+}
+
+func TestCredentials_RotateIssuesNoPasswordWhenSecretsDiscarded(t *testing.T) {
+	server := newFakeServer()
+	id := uuid.New()
+	secretID, err := NewCredentials(server, LogSecretWriter{}).Rotate(context.Background(), id)
+	assert.NoError(t, err)
+	assert.Empty(t, secretID)
+	assert.NotContains(t, server.passwords, RoleName(id))
+}
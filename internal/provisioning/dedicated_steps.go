@@ -0,0 +1,197 @@
+package provisioning
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"github.com/teresa-solution/tenant-management-service/internal/tenantschema"
+)
+
+// Names of the steps specific to dedicated databases
+const (
+	StepCreateDatabase       = "create_database"
+	StepScheduleDatabaseDrop = "schedule_database_drop"
+)
+
+// dedicatedSchema is the schema tenant data lives in inside a dedicated database
+const dedicatedSchema = "public"
+
+// DatabaseServer hosts the databases of tenants isolated by database
+type DatabaseServer interface {
+	Host() string
+	Port() int
+	// CreateTenantRole creates a login role held to limits or updates it
+	CreateTenantRole(ctx context.Context, role string, limits model.RoleLimits) error
+	PasswordSetter
+	DropTenantRole(ctx context.Context, role string) error
+	// CreateTenantDatabase creates a database only role can connect to
+	CreateTenantDatabase(ctx context.Context, dbName, role string) error
+	DropTenantDatabase(ctx context.Context, dbName string) error
+	BlockTenantConnections(ctx context.Context, role string) error
+	// UnblockTenantConnections lifts a block, restoring the connection limit
+	UnblockTenantConnections(ctx context.Context, role string, limit int) error
+	// TemplateVersion and ApplyMigration track the tenant schema template
+	// inside each database, like tenantschema.Store does for schemas
+	TemplateVersion(ctx context.Context, dbName string) (int, error)
+	ApplyMigration(ctx context.Context, dbName string, version int, sql string) (bool, error)
+}
+
+// DedicatedSteps returns the steps provisioning a tenant into a database of
+// its own on server, in the order they must run
+func DedicatedSteps(s Store, server DatabaseServer, cfg Config) []Step {
+	return []Step{
+		&createLoginRoleStep{stepInfo{StepCreateRole, cfg.StepTimeout}, server, cfg.RoleLimits, NewCredentials(server, cfg.Secrets)},
+		&createDatabaseStep{stepInfo{StepCreateDatabase, cfg.StepTimeout}, s, server},
+		&applyTemplateStep{stepInfo{StepApplyTemplate, cfg.StepTimeout}, DedicatedTemplateStore(s, server), cfg.Template},
+		&writeDBConfigStep{stepInfo{StepWriteDBConfig, cfg.StepTimeout}, s, model.IsolationDatabase, server.Port(), "", cfg.RoleLimits},
+		&seedFeaturesStep{stepInfo{StepSeedFeatures, cfg.StepTimeout}, s, cfg.TierFeatures},
+		&registerDNSStep{stepInfo{StepRegisterDNS, cfg.StepTimeout}, NewTenantDNS(s, cfg)},
+		&notifyStep{stepInfo{StepNotify, cfg.StepTimeout}, cfg.Notifier},
+	}
+}
+
+// DedicatedDeprovisionSteps returns the steps tearing down a deleted tenant
+// with a dedicated database. The database is kept as it is, with connections
+// blocked, until SchemaDropDelay has passed; it serves as the snapshot.
+func DedicatedDeprovisionSteps(s Store, server DatabaseServer, cfg Config) []Step {
+	return []Step{
+		&blockDedicatedConnectionsStep{stepInfo{StepBlockConnections, cfg.StepTimeout}, server, cfg.RoleLimits},
+		&disableRoutingStep{stepInfo{StepDisableRouting, cfg.StepTimeout}, NewTenantDNS(s, cfg)},
+		&scheduleDatabaseDropStep{stepInfo{StepScheduleDatabaseDrop, cfg.StepTimeout}, s, cfg.SchemaDropDelay},
+	}
+}
+
+// DatabaseName returns the dedicated database of a tenant
+func DatabaseName(tenantID uuid.UUID) string {
+	return RoleName(tenantID)
+}
+
+// DedicatedTemplateStore tracks the tenant schema template of the dedicated
+// databases on server, mirroring each database's version into
+// tenant_dedicated_databases so rollouts can list them
+func DedicatedTemplateStore(s Store, server DatabaseServer) tenantschema.Registry {
+	return &dedicatedTemplates{store: s, server: server}
+}
+
+type dedicatedTemplates struct {
+	store  Store
+	server DatabaseServer
+}
+
+func (d *dedicatedTemplates) ListTenantSchemas(ctx context.Context) ([]model.TenantSchema, error) {
+	dbs, err := d.store.ListDedicatedDatabases(ctx, d.server.Host())
+	if err != nil {
+		return nil, err
+	}
+	schemas := make([]model.TenantSchema, 0, len(dbs))
+	for _, db := range dbs {
+		schemas = append(schemas, model.TenantSchema{TenantID: db.TenantID, SchemaName: db.DatabaseName, TemplateVersion: db.TemplateVersion})
+	}
+	return schemas, nil
+}
+
+func (d *dedicatedTemplates) TenantTemplateVersion(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	return d.server.TemplateVersion(ctx, DatabaseName(tenantID))
+}
+
+func (d *dedicatedTemplates) ApplyTenantMigration(ctx context.Context, tenantID uuid.UUID, version int, sql string) (bool, error) {
+	applied, err := d.server.ApplyMigration(ctx, DatabaseName(tenantID), version, sql)
+	if err != nil || !applied {
+		return applied, err
+	}
+	return true, d.store.SetDedicatedTemplateVersion(ctx, tenantID, version)
+}
+
+type createLoginRoleStep struct {
+	stepInfo
+	server      DatabaseServer
+	limits      model.RoleLimits
+	credentials *Credentials
+}
+
+// Apply gives the role a new password on every attempt, so the stored secret
+// always matches the role even if an earlier attempt failed in between
+func (s *createLoginRoleStep) Apply(ctx context.Context, state *State) error {
+	role := RoleName(state.Tenant.ID)
+	if err := s.server.CreateTenantRole(ctx, role, s.limits); err != nil {
+		return err
+	}
+	secretID, err := s.credentials.Rotate(ctx, state.Tenant.ID)
+	if err != nil {
+		return err
+	}
+	state.RoleName = role
+	state.PasswordSecretID = secretID
+	return nil
+}
+
+func (s *createLoginRoleStep) Compensate(ctx context.Context, state *State) error {
+	if err := s.server.DropTenantRole(ctx, RoleName(state.Tenant.ID)); err != nil {
+		return err
+	}
+	return s.credentials.Forget(ctx, state.Tenant.ID)
+}
+
+type createDatabaseStep struct {
+	stepInfo
+	store  Store
+	server DatabaseServer
+}
+
+func (s *createDatabaseStep) Apply(ctx context.Context, state *State) error {
+	dbName, role := DatabaseName(state.Tenant.ID), RoleName(state.Tenant.ID)
+	if err := s.server.CreateTenantDatabase(ctx, dbName, role); err != nil {
+		return err
+	}
+	err := s.store.RecordDedicatedDatabase(ctx, &model.DedicatedDatabase{
+		TenantID:     state.Tenant.ID,
+		Host:         s.server.Host(),
+		Port:         s.server.Port(),
+		DatabaseName: dbName,
+		RoleName:     role,
+	})
+	if err != nil {
+		return err
+	}
+	state.DBHost = s.server.Host()
+	state.DatabaseName = dbName
+	state.SchemaName = dedicatedSchema
+	return nil
+}
+
+func (s *createDatabaseStep) Compensate(ctx context.Context, state *State) error {
+	if err := s.server.DropTenantDatabase(ctx, DatabaseName(state.Tenant.ID)); err != nil {
+		return err
+	}
+	return s.store.DeleteDedicatedDatabase(ctx, state.Tenant.ID)
+}
+
+type blockDedicatedConnectionsStep struct {
+	stepInfo
+	server DatabaseServer
+	limits model.RoleLimits
+}
+
+func (s *blockDedicatedConnectionsStep) Apply(ctx context.Context, state *State) error {
+	return s.server.BlockTenantConnections(ctx, RoleName(state.Tenant.ID))
+}
+
+func (s *blockDedicatedConnectionsStep) Compensate(ctx context.Context, state *State) error {
+	return s.server.UnblockTenantConnections(ctx, RoleName(state.Tenant.ID), s.limits.ConnectionLimit)
+}
+
+type scheduleDatabaseDropStep struct {
+	stepInfo
+	store Store
+	delay time.Duration
+}
+
+func (s *scheduleDatabaseDropStep) Apply(ctx context.Context, state *State) error {
+	return s.store.ScheduleDedicatedDatabaseDrop(ctx, state.Tenant.ID, time.Now().Add(s.delay))
+}
+
+func (s *scheduleDatabaseDropStep) Compensate(ctx context.Context, state *State) error {
+	return s.store.CancelDedicatedDatabaseDrop(ctx, state.Tenant.ID)
+}
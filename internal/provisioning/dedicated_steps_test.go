@@ -0,0 +1,111 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+type fakeServer struct {
+	DatabaseServer
+	limits    map[string]model.RoleLimits
+	passwords map[string]string
+}
+
+func newFakeServer() *fakeServer {
+	return &fakeServer{limits: make(map[string]model.RoleLimits), passwords: make(map[string]string)}
+}
+
+func (f *fakeServer) Host() string { return "dedicated.db" }
+func (f *fakeServer) Port() int    { return 6432 }
+
+func (f *fakeServer) CreateTenantRole(ctx context.Context, role string, limits model.RoleLimits) error {
+	f.limits[role] = limits
+	return nil
+}
+
+func (f *fakeServer) SetTenantRolePassword(ctx context.Context, role, password string) error {
+	f.passwords[role] = password
+	return nil
+}
+
+func (f *fakeServer) DropTenantRole(ctx context.Context, role string) error {
+	delete(f.limits, role)
+	delete(f.passwords, role)
+	return nil
+}
+
+type fakeSecrets map[string]string
+
+func (f fakeSecrets) PutSecret(ctx context.Context, id, value string) error {
+	f[id] = value
+	return nil
+}
+
+func (f fakeSecrets) KeepsSecrets() bool {
+	return true
+}
+
+func (f fakeSecrets) DeleteSecret(ctx context.Context, id string) error {
+	delete(f, id)
+	return nil
+}
+
+func TestParseTierIsolation(t *testing.T) {
+	isolation, err := ParseTierIsolation("enterprise=database, premium=schema,")
+	assert.NoError(t, err)
+	assert.Equal(t, model.IsolationDatabase, isolation.Mode(model.TierEnterprise))
+	assert.Equal(t, model.IsolationSchema, isolation.Mode(model.TierPremium))
+	assert.Equal(t, model.IsolationSchema, isolation.Mode(model.TierFree))
+
+	for _, bad := range []string{"enterprise", "gold=database", "enterprise=cluster"} {
+		_, err := ParseTierIsolation(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestDedicatedSteps(t *testing.T) {
+	cfg := DefaultConfig()
+	server := newFakeServer()
+
+	var names []string
+	for _, step := range DedicatedSteps(nil, server, cfg) {
+		names = append(names, step.Name())
+	}
+	assert.Equal(t, []string{StepCreateRole, StepCreateDatabase, StepApplyTemplate, StepWriteDBConfig,
+		StepSeedFeatures, StepRegisterDNS, StepNotify}, names)
+
+	names = nil
+	for _, step := range DedicatedDeprovisionSteps(nil, server, cfg) {
+		names = append(names, step.Name())
+	}
+	assert.Equal(t, []string{StepBlockConnections, StepDisableRouting, StepScheduleDatabaseDrop}, names)
+}
+
+func TestCreateLoginRoleStep_StoresPassword(t *testing.T) {
+	server := newFakeServer()
+	secrets := fakeSecrets{}
+	step := &createLoginRoleStep{stepInfo{StepCreateRole, defaultStepTimeout}, server, DefaultRoleLimits, NewCredentials(server, secrets)}
+	state := newState()
+	role, secretID := RoleName(state.Tenant.ID), PasswordSecretID(state.Tenant.ID)
+
+	assert.NoError(t, step.Apply(context.Background(), state))
+	assert.Equal(t, role, state.RoleName)
+	assert.Equal(t, secretID, state.PasswordSecretID)
+	assert.Equal(t, DefaultRoleLimits, server.limits[role])
+	first := secrets[secretID]
+	assert.Len(t, first, 32)
+	assert.Equal(t, first, server.passwords[role])
+
+	// A retry rotates the password and keeps the secret in step
+	assert.NoError(t, step.Apply(context.Background(), state))
+	assert.NotEqual(t, first, secrets[secretID])
+	assert.Equal(t, secrets[secretID], server.passwords[role])
+
+	assert.NoError(t, step.Compensate(context.Background(), state))
+	assert.Empty(t, secrets)
+	assert.Empty(t, server.limits)
+	assert.Empty(t, server.passwords)
+}
@@ -0,0 +1,138 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"github.com/teresa-solution/tenant-management-service/internal/store"
+)
+
+// Names of the deprovisioning steps, as written to tenant_provisioning_logs
+const (
+	StepRevokeRole         = "revoke_db_role"
+	StepBlockConnections   = "block_connections"
+	StepDisableRouting     = "disable_routing"
+	StepSnapshotData       = "snapshot_data"
+	StepScheduleSchemaDrop = "schedule_schema_drop"
+)
+
+// DeprovisionSteps returns the steps tearing a deleted tenant down, in the
+// order they must run. Run them in a forward pipeline: the schema itself is
+// only dropped once SchemaDropDelay has passed.
+func DeprovisionSteps(s Store, cfg Config) []Step {
+	return schemaDeprovisionSteps(s, s, cfg)
+}
+
+// schemaDeprovisionSteps returns the steps tearing down a tenant schema in schemas
+func schemaDeprovisionSteps(s Store, schemas Schemas, cfg Config) []Step {
+	return []Step{
+		&revokeRoleStep{stepInfo{StepRevokeRole, cfg.StepTimeout}, schemas, cfg.RoleLimits},
+		&blockConnectionsStep{stepInfo{StepBlockConnections, cfg.StepTimeout}, schemas, cfg.RoleLimits},
+		&disableRoutingStep{stepInfo{StepDisableRouting, cfg.StepTimeout}, NewTenantDNS(s, cfg)},
+		&snapshotStep{stepInfo{StepSnapshotData, cfg.StepTimeout}, schemas},
+		&scheduleDropStep{stepInfo{StepScheduleSchemaDrop, cfg.StepTimeout}, schemas, cfg.SchemaDropDelay},
+	}
+}
+
+// SnapshotSchemaName returns the schema a tenant's data is copied to before
+// it is deprovisioned
+func SnapshotSchemaName(tenantID uuid.UUID) string {
+	return "tenant_snapshot_" + strings.ReplaceAll(tenantID.String(), "-", "")
+}
+
+// loadSchema fills in the tenant's schema, which is empty if it never got one
+func loadSchema(ctx context.Context, s Schemas, state *State) error {
+	if state.SchemaName != "" {
+		return nil
+	}
+	schemaName, err := s.GetTenantSchema(ctx, state.Tenant.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	state.SchemaName = schemaName
+	return nil
+}
+
+type revokeRoleStep struct {
+	stepInfo
+	store  Schemas
+	limits model.RoleLimits
+}
+
+func (s *revokeRoleStep) Apply(ctx context.Context, state *State) error {
+	if err := loadSchema(ctx, s.store, state); err != nil {
+		return err
+	}
+	state.RoleName = RoleName(state.Tenant.ID)
+	return s.store.RevokeTenantRole(ctx, state.RoleName, state.SchemaName)
+}
+
+func (s *revokeRoleStep) Compensate(ctx context.Context, state *State) error {
+	if err := loadSchema(ctx, s.store, state); err != nil || state.SchemaName == "" {
+		return err
+	}
+	return s.store.CreateTenantRole(ctx, RoleName(state.Tenant.ID), state.SchemaName, s.limits)
+}
+
+type blockConnectionsStep struct {
+	stepInfo
+	store  Schemas
+	limits model.RoleLimits
+}
+
+func (s *blockConnectionsStep) Apply(ctx context.Context, state *State) error {
+	return s.store.BlockTenantConnections(ctx, RoleName(state.Tenant.ID))
+}
+
+func (s *blockConnectionsStep) Compensate(ctx context.Context, state *State) error {
+	return s.store.UnblockTenantConnections(ctx, RoleName(state.Tenant.ID), s.limits.ConnectionLimit)
+}
+
+type disableRoutingStep struct {
+	stepInfo
+	dns *TenantDNS
+}
+
+func (s *disableRoutingStep) Apply(ctx context.Context, state *State) error {
+	return s.dns.withdraw(ctx, state.Tenant)
+}
+
+func (s *disableRoutingStep) Compensate(ctx context.Context, state *State) error {
+	return s.dns.publish(ctx, state.Tenant)
+}
+
+type snapshotStep struct {
+	stepInfo
+	store Schemas
+}
+
+func (s *snapshotStep) Apply(ctx context.Context, state *State) error {
+	_, err := s.store.SnapshotTenantSchema(ctx, state.Tenant.ID, SnapshotSchemaName(state.Tenant.ID))
+	return err
+}
+
+// Compensate does nothing; a snapshot is kept even if deprovisioning is undone
+func (s *snapshotStep) Compensate(ctx context.Context, state *State) error {
+	return nil
+}
+
+type scheduleDropStep struct {
+	stepInfo
+	store Schemas
+	delay time.Duration
+}
+
+func (s *scheduleDropStep) Apply(ctx context.Context, state *State) error {
+	return s.store.ScheduleTenantSchemaDrop(ctx, state.Tenant.ID, time.Now().Add(s.delay))
+}
+
+func (s *scheduleDropStep) Compensate(ctx context.Context, state *State) error {
+	return s.store.CancelTenantSchemaDrop(ctx, state.Tenant.ID)
+}
@@ -0,0 +1,70 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+// permanentError marks an error that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks err as not worth retrying
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsTransient reports whether a provisioning error may go away on retry.
+// Errors marked Permanent and Postgres errors outside the connection,
+// resource and operator-intervention classes (bad SQL, constraint or
+// permission errors) are permanent; timeouts, network failures and
+// unrecognised errors are assumed transient.
+func IsTransient(err error) bool {
+	var perm *permanentError
+	if errors.As(err, &perm) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return transientClass(string(pqErr.Code.Class()))
+	}
+	// Data is copied between shards over pgx
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && len(pgErr.Code) == 5 {
+		return transientClass(pgErr.Code[:2])
+	}
+	return true
+}
+
+// transientClass reports whether Postgres errors of an SQLSTATE class may go
+// away on retry
+func transientClass(class string) bool {
+	switch class {
+	case "08", // connection exception
+		"40", // transaction rollback: serialization failure, deadlock
+		"53", // insufficient resources
+		"57", // operator intervention: query canceled, shutdown
+		"58": // system error
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,39 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"timeout", context.DeadlineExceeded, true},
+		{"connection failure", &pq.Error{Code: "08006"}, true},
+		{"deadlock", &pq.Error{Code: "40P01"}, true},
+		{"too many connections", &pq.Error{Code: "53300"}, true},
+		{"syntax error", &pq.Error{Code: "42601"}, false},
+		{"permission denied", &pq.Error{Code: "42501"}, false},
+		{"unique violation", &pq.Error{Code: "23505"}, false},
+		{"copy connection failure", &pgconn.PgError{Code: "08006"}, true},
+		{"copy permission denied", &pgconn.PgError{Code: "42501"}, false},
+		{"unknown", errors.New("dns server unreachable"), true},
+		{"marked permanent", Permanent(errors.New("bad tier")), false},
+		{"wrapped in step error", &StepError{Step: StepApplyTemplate, Err: fmt.Errorf("template statement 1: %w", &pq.Error{Code: "42601"})}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsTransient(tt.err))
+		})
+	}
+	assert.Nil(t, Permanent(nil))
+}
@@ -0,0 +1,101 @@
+package provisioning
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+// DNSProvider publishes the DNS records of tenant hostnames. The providers
+// of package dns implement it.
+type DNSProvider interface {
+	// CreateRecord publishes record, replacing the records of the same name
+	// and type; creating the same record twice is not an error
+	CreateRecord(ctx context.Context, record model.DNSRecord) error
+	// DeleteRecord removes the records of the name and type of record;
+	// removing an unknown record is not an error
+	DeleteRecord(ctx context.Context, record model.DNSRecord) error
+}
+
+// LogDNSProvider only logs records, for deployments whose DNS is managed
+// elsewhere, such as by a wildcard record
+type LogDNSProvider struct{}
+
+func (LogDNSProvider) CreateRecord(ctx context.Context, record model.DNSRecord) error {
+	log.Info().Str("record", record.String()).Msg("Created tenant DNS record")
+	return nil
+}
+
+func (LogDNSProvider) DeleteRecord(ctx context.Context, record model.DNSRecord) error {
+	log.Info().Str("hostname", record.Name).Str("type", record.Type).Msg("Deleted tenant DNS record")
+	return nil
+}
+
+// Event types sent to a Notifier
+const (
+	EventTenantProvisioned = "tenant.provisioned"
+	// EventTenantMaintenanceStarted and EventTenantMaintenanceEnded bracket
+	// the time a moving tenant is read-only
+	EventTenantMaintenanceStarted = "tenant.maintenance_started"
+	EventTenantMaintenanceEnded   = "tenant.maintenance_ended"
+	// EventTenantMoved is sent once a tenant's schema moved to another shard
+	EventTenantMoved = "tenant.moved"
+)
+
+// Event describes a tenant lifecycle change
+type Event struct {
+	Type      string
+	TenantID  uuid.UUID
+	Subdomain string
+	Hostname  string
+}
+
+// Notifier tells interested parties about tenant lifecycle changes
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// LogNotifier only logs events
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, event Event) error {
+	log.Info().
+		Str("event", event.Type).
+		Str("tenant_id", event.TenantID.String()).
+		Str("subdomain", event.Subdomain).
+		Str("hostname", event.Hostname).
+		Msg("Tenant event")
+	return nil
+}
+
+// SecretWriter keeps generated credentials, such as the password of a tenant
+// role under PasswordSecretID. The backends of secrets.SecretStore implement it.
+type SecretWriter interface {
+	// PutSecret stores value under id, replacing any previous value
+	PutSecret(ctx context.Context, id, value string) error
+	// DeleteSecret removes id; removing an unknown secret is not an error
+	DeleteSecret(ctx context.Context, id string) error
+	// KeepsSecrets reports whether written secrets can be read back. Tenant
+	// roles only get passwords from writers that keep them.
+	KeepsSecrets() bool
+}
+
+// LogSecretWriter only logs which secrets would be written and discards their
+// values, for deployments that do not hand tenant credentials out
+type LogSecretWriter struct{}
+
+func (LogSecretWriter) KeepsSecrets() bool {
+	return false
+}
+
+func (LogSecretWriter) PutSecret(ctx context.Context, id, value string) error {
+	log.Info().Str("secret_id", id).Msg("Discarded tenant secret")
+	return nil
+}
+
+func (LogSecretWriter) DeleteSecret(ctx context.Context, id string) error {
+	log.Info().Str("secret_id", id).Msg("Deleted tenant secret")
+	return nil
+}
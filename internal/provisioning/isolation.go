@@ -0,0 +1,46 @@
+package provisioning
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+// TierIsolation maps tiers onto the isolation mode their tenants get
+type TierIsolation map[string]string
+
+// Mode returns the isolation mode of a tier; tiers not listed share the
+// database through a schema each
+func (t TierIsolation) Mode(tier string) string {
+	if mode, ok := t[tier]; ok {
+		return mode
+	}
+	return model.IsolationSchema
+}
+
+// ParseTierIsolation parses comma-separated tier=mode pairs such as
+// "enterprise=database"
+func ParseTierIsolation(s string) (TierIsolation, error) {
+	isolation := make(TierIsolation)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		tier, mode, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid tier isolation %q, want tier=mode", pair)
+		}
+		tier, mode = strings.TrimSpace(tier), strings.TrimSpace(mode)
+		if !slices.Contains(model.Tiers, tier) {
+			return nil, fmt.Errorf("invalid tier isolation %q: unknown tier", pair)
+		}
+		if !slices.Contains(model.IsolationModes, mode) {
+			return nil, fmt.Errorf("invalid tier isolation %q: unknown isolation mode", pair)
+		}
+		isolation[tier] = mode
+	}
+	return isolation, nil
+}
@@ -0,0 +1,489 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"github.com/teresa-solution/tenant-management-service/internal/store"
+	"github.com/teresa-solution/tenant-management-service/internal/tenantschema"
+)
+
+// Names of the steps moving a tenant schema between shards
+const (
+	StepPrepareTarget    = "prepare_target"
+	StepCopyData         = "copy_data"
+	StepEnterMaintenance = "enter_maintenance"
+	StepSyncData         = "sync_data"
+	StepVerifyData       = "verify_data"
+	StepCutover          = "cutover"
+	StepExitMaintenance  = "exit_maintenance"
+	StepReleaseSource    = "release_source"
+	// StepDropMovedSchema is the name reclaims of schemas left behind by a
+	// move are logged as
+	StepDropMovedSchema = "drop_moved_schema"
+)
+
+// MoveSteps returns the steps moving the schema of state.Move from the shard
+// served by source onto target, served by targetServer. The tenant stays
+// writable while its data is first copied; it is then made read-only, the
+// rows that changed meanwhile are copied again, and both copies are compared
+// before the tenant's database config is pointed at target. Run them in a
+// compensating pipeline: a move failing before the cutover leaves the tenant
+// on the source, as it was. Once cut over, the tenant stays on the target and
+// every step before the cutover does nothing when retried.
+func MoveSteps(s Store, source ShardServer, target *model.Shard, targetServer ShardServer, cfg Config) []Step {
+	m := &mover{store: s, source: source, target: target, targetServer: targetServer, notifier: cfg.Notifier, baseDomain: cfg.BaseDomain}
+	return []Step{
+		&prepareTargetStep{stepInfo{StepPrepareTarget, cfg.StepTimeout}, m, cfg.Template},
+		&copyDataStep{stepInfo{StepCopyData, cfg.MoveStepTimeout}, m},
+		&enterMaintenanceStep{stepInfo{StepEnterMaintenance, cfg.StepTimeout}, m},
+		&syncDataStep{stepInfo{StepSyncData, cfg.MoveStepTimeout}, m},
+		&verifyDataStep{stepInfo{StepVerifyData, cfg.MoveStepTimeout}, m},
+		&cutoverStep{stepInfo{StepCutover, cfg.StepTimeout}, m, cfg.RoleLimits, NewCredentials(targetServer, cfg.Secrets)},
+		&exitMaintenanceStep{stepInfo{StepExitMaintenance, cfg.StepTimeout}, m},
+		&releaseSourceStep{stepInfo{StepReleaseSource, cfg.StepTimeout}, m, cfg.SchemaDropDelay},
+		&notifyMovedStep{stepInfo{StepNotify, cfg.StepTimeout}, m},
+	}
+}
+
+// mover holds what the steps of one move share
+type mover struct {
+	store        Store
+	source       ShardServer
+	target       *model.Shard
+	targetServer ShardServer
+	notifier     Notifier
+	baseDomain   string
+}
+
+// cutOver reports whether the tenant already points at the target shard
+func (m *mover) cutOver(ctx context.Context, state *State) (bool, error) {
+	if state.CutOver {
+		return true, nil
+	}
+	placement, err := m.store.GetShardSchema(ctx, state.Tenant.ID)
+	if err != nil {
+		return false, err
+	}
+	return placement.ShardID == m.target.ID, nil
+}
+
+// copyTables replaces tables on the target with their rows on the source
+func (m *mover) copyTables(ctx context.Context, schemaName string, tables []string) error {
+	if len(tables) == 0 {
+		return nil
+	}
+	return m.targetServer.ImportTables(ctx, schemaName, tables, func(ctx context.Context, table string, w io.Writer) error {
+		return m.source.ExportTable(ctx, schemaName, table, w)
+	})
+}
+
+// announce sends a maintenance event. Events are informational; failing to
+// send one does not hold the move up.
+func (m *mover) announce(ctx context.Context, state *State, eventType string) {
+	err := m.notifier.Notify(ctx, Event{
+		Type:      eventType,
+		TenantID:  state.Tenant.ID,
+		Subdomain: state.Tenant.Subdomain,
+		Hostname:  Hostname(state.Tenant.Subdomain, m.baseDomain),
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("tenant_id", state.Tenant.ID.String()).Str("event", eventType).Msg("Failed to send tenant event")
+	}
+}
+
+// leaveMaintenance makes the source schema writable again and the tenant active
+func (m *mover) leaveMaintenance(ctx context.Context, state *State) error {
+	if err := m.source.SetSchemaReadOnly(ctx, RoleName(state.Tenant.ID), state.Move.SchemaName, false); err != nil {
+		return err
+	}
+	if err := m.store.SetTenantMaintenance(ctx, state.Tenant.ID, false); err != nil {
+		return err
+	}
+	m.announce(ctx, state, EventTenantMaintenanceEnded)
+	return nil
+}
+
+type prepareTargetStep struct {
+	stepInfo
+	move     *mover
+	template *tenantschema.Template
+}
+
+// Apply creates an empty schema on the target at the template version of the
+// source schema, so both hold the same tables
+func (s *prepareTargetStep) Apply(ctx context.Context, state *State) error {
+	schemaName := state.Move.SchemaName
+	state.SchemaName = schemaName
+	cutOver, err := s.move.cutOver(ctx, state)
+	if err != nil || cutOver {
+		state.CutOver = cutOver
+		return err
+	}
+	version, err := s.move.source.SchemaTemplateVersion(ctx, schemaName)
+	if err != nil {
+		return err
+	}
+	if version > s.template.Version() {
+		return Permanent(fmt.Errorf("tenant schema is at template version %d, newer than the template (%d)", version, s.template.Version()))
+	}
+	// Start from scratch: an earlier attempt, or an earlier move away from
+	// the target, may have left a copy behind
+	if err := s.move.targetServer.DropSchema(ctx, schemaName); err != nil {
+		return err
+	}
+	if err := s.move.store.MarkMovedSchemaDropped(ctx, state.Tenant.ID, s.move.target.ID); err != nil {
+		return err
+	}
+	if err := s.move.targetServer.CreateSchema(ctx, schemaName); err != nil {
+		return err
+	}
+	for _, migration := range s.template.Migrations() {
+		if migration.Version > version {
+			break
+		}
+		if _, err := s.move.targetServer.ApplySchemaMigration(ctx, schemaName, migration.Version, migration.Up); err != nil {
+			return fmt.Errorf("template migration %d: %w", migration.Version, err)
+		}
+	}
+	state.TemplateVersion = version
+	return nil
+}
+
+func (s *prepareTargetStep) Compensate(ctx context.Context, state *State) error {
+	cutOver, err := s.move.cutOver(ctx, state)
+	if err != nil || cutOver {
+		return err
+	}
+	return s.move.targetServer.DropSchema(ctx, state.Move.SchemaName)
+}
+
+type copyDataStep struct {
+	stepInfo
+	move *mover
+}
+
+// Apply copies every table while the tenant keeps writing to the source; the
+// rows changed meanwhile are copied again by sync_data
+func (s *copyDataStep) Apply(ctx context.Context, state *State) error {
+	if state.CutOver {
+		return nil
+	}
+	checksums, err := s.move.source.TableChecksums(ctx, state.Move.SchemaName)
+	if err != nil {
+		return err
+	}
+	return s.move.copyTables(ctx, state.Move.SchemaName, tableNames(checksums))
+}
+
+// Compensate does nothing; the copy goes with the target schema
+func (s *copyDataStep) Compensate(ctx context.Context, state *State) error {
+	return nil
+}
+
+type enterMaintenanceStep struct {
+	stepInfo
+	move *mover
+}
+
+// Apply puts the tenant into maintenance and takes the write privileges on
+// the source schema away, ending the sessions that could still write
+func (s *enterMaintenanceStep) Apply(ctx context.Context, state *State) error {
+	if state.CutOver {
+		return nil
+	}
+	if err := s.move.store.SetTenantMaintenance(ctx, state.Tenant.ID, true); err != nil {
+		return err
+	}
+	if err := s.move.source.SetSchemaReadOnly(ctx, RoleName(state.Tenant.ID), state.Move.SchemaName, true); err != nil {
+		return err
+	}
+	s.move.announce(ctx, state, EventTenantMaintenanceStarted)
+	return nil
+}
+
+func (s *enterMaintenanceStep) Compensate(ctx context.Context, state *State) error {
+	cutOver, err := s.move.cutOver(ctx, state)
+	if err != nil || cutOver {
+		return err
+	}
+	return s.move.leaveMaintenance(ctx, state)
+}
+
+type syncDataStep struct {
+	stepInfo
+	move *mover
+}
+
+// Apply copies the tables whose rows changed since copy_data, and the
+// sequences. The source is read-only by now, so the copy is final.
+func (s *syncDataStep) Apply(ctx context.Context, state *State) error {
+	if state.CutOver {
+		return nil
+	}
+	schemaName := state.Move.SchemaName
+	source, err := s.move.source.TableChecksums(ctx, schemaName)
+	if err != nil {
+		return err
+	}
+	target, err := s.move.targetServer.TableChecksums(ctx, schemaName)
+	if err != nil {
+		return err
+	}
+	if err := s.move.copyTables(ctx, schemaName, changedTables(source, target)); err != nil {
+		return err
+	}
+	sequences, err := s.move.source.SequenceValues(ctx, schemaName)
+	if err != nil {
+		return err
+	}
+	return s.move.targetServer.SetSequenceValues(ctx, schemaName, sequences)
+}
+
+// Compensate does nothing; the copy goes with the target schema
+func (s *syncDataStep) Compensate(ctx context.Context, state *State) error {
+	return nil
+}
+
+type verifyDataStep struct {
+	stepInfo
+	move *mover
+}
+
+// Apply compares the row counts, row checksums and sequences of both copies
+func (s *verifyDataStep) Apply(ctx context.Context, state *State) error {
+	if state.CutOver {
+		return nil
+	}
+	schemaName := state.Move.SchemaName
+	sourceTables, err := s.move.source.TableChecksums(ctx, schemaName)
+	if err != nil {
+		return err
+	}
+	targetTables, err := s.move.targetServer.TableChecksums(ctx, schemaName)
+	if err != nil {
+		return err
+	}
+	sourceSequences, err := s.move.source.SequenceValues(ctx, schemaName)
+	if err != nil {
+		return err
+	}
+	targetSequences, err := s.move.targetServer.SequenceValues(ctx, schemaName)
+	if err != nil {
+		return err
+	}
+	return Permanent(compareCopies(sourceTables, targetTables, sourceSequences, targetSequences))
+}
+
+// Compensate does nothing; verifying changes nothing
+func (s *verifyDataStep) Compensate(ctx context.Context, state *State) error {
+	return nil
+}
+
+type cutoverStep struct {
+	stepInfo
+	move        *mover
+	limits      model.RoleLimits
+	credentials *Credentials
+}
+
+// Apply gives the tenant role access to the target schema with a new
+// password, then points the tenant's placement and database config at the
+// target. Switching is the last thing it does, so a tenant pointing at the
+// target has everything it needs there.
+func (s *cutoverStep) Apply(ctx context.Context, state *State) error {
+	if state.CutOver {
+		return nil
+	}
+	role := RoleName(state.Tenant.ID)
+	if err := s.move.targetServer.CreateTenantRole(ctx, role, state.Move.SchemaName, s.limits); err != nil {
+		return err
+	}
+	if _, err := s.credentials.Rotate(ctx, state.Tenant.ID); err != nil {
+		return err
+	}
+	if err := s.move.store.SwitchTenantShard(ctx, state.Tenant.ID, s.move.target); err != nil {
+		return err
+	}
+	state.CutOver = true
+	state.RoleName = role
+	return nil
+}
+
+// Compensate does nothing. Before the switch the role's grants on the target
+// go with the target schema; after it the tenant stays on the target.
+func (s *cutoverStep) Compensate(ctx context.Context, state *State) error {
+	return nil
+}
+
+type exitMaintenanceStep struct {
+	stepInfo
+	move *mover
+}
+
+// Apply makes the tenant active again. The source schema stays read-only.
+func (s *exitMaintenanceStep) Apply(ctx context.Context, state *State) error {
+	if err := s.move.store.SetTenantMaintenance(ctx, state.Tenant.ID, false); err != nil {
+		return err
+	}
+	s.move.announce(ctx, state, EventTenantMaintenanceEnded)
+	return nil
+}
+
+// Compensate does nothing; it only runs once the tenant is on the target
+func (s *exitMaintenanceStep) Compensate(ctx context.Context, state *State) error {
+	return nil
+}
+
+type releaseSourceStep struct {
+	stepInfo
+	move  *mover
+	delay time.Duration
+}
+
+// Apply takes every grant on the source database away from the tenant role
+// and schedules the source schema to be dropped after delay
+func (s *releaseSourceStep) Apply(ctx context.Context, state *State) error {
+	if err := s.move.source.ForgetTenantRole(ctx, RoleName(state.Tenant.ID)); err != nil {
+		return err
+	}
+	return s.move.store.ScheduleMovedSchemaDrop(ctx, state.Move.ID, time.Now().Add(s.delay))
+}
+
+// Compensate does nothing; it only runs once the tenant is on the target
+func (s *releaseSourceStep) Compensate(ctx context.Context, state *State) error {
+	return nil
+}
+
+type notifyMovedStep struct {
+	stepInfo
+	move *mover
+}
+
+func (s *notifyMovedStep) Apply(ctx context.Context, state *State) error {
+	return s.move.notifier.Notify(ctx, Event{
+		Type:      EventTenantMoved,
+		TenantID:  state.Tenant.ID,
+		Subdomain: state.Tenant.Subdomain,
+		Hostname:  Hostname(state.Tenant.Subdomain, s.move.baseDomain),
+	})
+}
+
+// Compensate does nothing; a notification cannot be taken back
+func (s *notifyMovedStep) Compensate(ctx context.Context, state *State) error {
+	return nil
+}
+
+// tableNames returns the tables of checksums in name order
+func tableNames(checksums map[string]model.TableChecksum) []string {
+	names := make([]string, 0, len(checksums))
+	for name := range checksums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// changedTables returns the tables of source whose rows differ in target
+func changedTables(source, target map[string]model.TableChecksum) []string {
+	var changed []string
+	for _, name := range tableNames(source) {
+		if sum, ok := target[name]; !ok || sum != source[name] {
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
+
+// compareCopies describes every difference between two copies of a schema,
+// or returns nil when they hold the same rows and sequence values
+func compareCopies(sourceTables, targetTables map[string]model.TableChecksum, sourceSequences, targetSequences map[string]int64) error {
+	var diffs []string
+	for _, name := range tableNames(sourceTables) {
+		sum, ok := targetTables[name]
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("table %s is missing", name))
+		case sum.Rows != sourceTables[name].Rows:
+			diffs = append(diffs, fmt.Sprintf("table %s has %d rows, want %d", name, sum.Rows, sourceTables[name].Rows))
+		case sum.Checksum != sourceTables[name].Checksum:
+			diffs = append(diffs, fmt.Sprintf("table %s has different rows", name))
+		}
+	}
+	for _, name := range tableNames(targetTables) {
+		if _, ok := sourceTables[name]; !ok {
+			diffs = append(diffs, fmt.Sprintf("table %s is not in the source", name))
+		}
+	}
+	for name, value := range sourceSequences {
+		if targetSequences[name] != value {
+			diffs = append(diffs, fmt.Sprintf("sequence %s is at %d, want %d", name, targetSequences[name], value))
+		}
+	}
+	if len(diffs) == 0 {
+		return nil
+	}
+	sort.Strings(diffs)
+	return errors.New("copies differ: " + strings.Join(diffs, "; "))
+}
+
+// MovedSchemaReclaimer drops the schemas tenants that moved off the shard
+// shardID, served by server, left behind once their retention delay has
+// passed. Roles belong to the server rather than to the shard's database, so
+// the tenant's role is only dropped when its schema is not on another
+// database of the same server.
+func MovedSchemaReclaimer(s Store, shardID uuid.UUID, server ShardServer) Reclaimer {
+	return &movedSchemaReclaimer{store: s, shardID: shardID, server: server}
+}
+
+type movedSchemaReclaimer struct {
+	store   Store
+	shardID uuid.UUID
+	server  ShardServer
+}
+
+func (r *movedSchemaReclaimer) Name() string {
+	return StepDropMovedSchema
+}
+
+func (r *movedSchemaReclaimer) Due(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	return r.store.DueMovedSchemaDrops(ctx, r.shardID, limit)
+}
+
+// Reclaim records the drop last, so a failed reclaim is retried
+func (r *movedSchemaReclaimer) Reclaim(ctx context.Context, tenantID uuid.UUID) error {
+	placement, err := r.store.GetShardSchema(ctx, tenantID)
+	placed := err == nil
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+	if placed && placement.ShardID == r.shardID {
+		// The tenant moved back; the schema here is its live one
+		return r.store.MarkMovedSchemaDropped(ctx, tenantID, r.shardID)
+	}
+	if err := r.server.DropSchema(ctx, ShardSchemaName(tenantID)); err != nil {
+		return err
+	}
+	keepRole := false
+	if placed {
+		current, err := r.store.GetShard(ctx, placement.ShardID)
+		if err != nil {
+			return err
+		}
+		keepRole = current.Host == r.server.Host() && current.Port == r.server.Port()
+	}
+	if !keepRole {
+		if err := r.server.DropTenantRole(ctx, RoleName(tenantID)); err != nil {
+			return err
+		}
+	}
+	return r.store.MarkMovedSchemaDropped(ctx, tenantID, r.shardID)
+}
@@ -0,0 +1,177 @@
+package provisioning
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"github.com/teresa-solution/tenant-management-service/internal/store"
+)
+
+type fakeMoveServer struct {
+	ShardServer
+	host      string
+	checksums map[string]model.TableChecksum
+	sequences map[string]int64
+	imported  []string
+	dropped   []string
+	roles     []string
+}
+
+func (f *fakeMoveServer) Host() string { return f.host }
+func (f *fakeMoveServer) Port() int    { return 5432 }
+
+func (f *fakeMoveServer) TableChecksums(ctx context.Context, schemaName string) (map[string]model.TableChecksum, error) {
+	return f.checksums, nil
+}
+
+func (f *fakeMoveServer) SequenceValues(ctx context.Context, schemaName string) (map[string]int64, error) {
+	return f.sequences, nil
+}
+
+func (f *fakeMoveServer) SetSequenceValues(ctx context.Context, schemaName string, values map[string]int64) error {
+	f.sequences = values
+	return nil
+}
+
+func (f *fakeMoveServer) ExportTable(ctx context.Context, schemaName, table string, w io.Writer) error {
+	_, err := io.WriteString(w, table)
+	return err
+}
+
+func (f *fakeMoveServer) ImportTables(ctx context.Context, schemaName string, tables []string, export func(ctx context.Context, table string, w io.Writer) error) error {
+	for _, table := range tables {
+		var b strings.Builder
+		if err := export(ctx, table, &b); err != nil {
+			return err
+		}
+		f.imported = append(f.imported, b.String())
+	}
+	return nil
+}
+
+func (f *fakeMoveServer) DropSchema(ctx context.Context, schemaName string) error {
+	f.dropped = append(f.dropped, schemaName)
+	return nil
+}
+
+func (f *fakeMoveServer) DropTenantRole(ctx context.Context, role string) error {
+	f.roles = append(f.roles, role)
+	return nil
+}
+
+type fakeMoveStore struct {
+	Store
+	placements map[uuid.UUID]*model.ShardSchema
+	shards     map[uuid.UUID]*model.Shard
+	reclaimed  []uuid.UUID
+}
+
+func (f *fakeMoveStore) GetShardSchema(ctx context.Context, tenantID uuid.UUID) (*model.ShardSchema, error) {
+	if p, ok := f.placements[tenantID]; ok {
+		return p, nil
+	}
+	return nil, &store.NotFoundError{Resource: store.ResourceShardSchema, Key: tenantID.String()}
+}
+
+func (f *fakeMoveStore) GetShard(ctx context.Context, id uuid.UUID) (*model.Shard, error) {
+	return f.shards[id], nil
+}
+
+func (f *fakeMoveStore) MarkMovedSchemaDropped(ctx context.Context, tenantID, shardID uuid.UUID) error {
+	f.reclaimed = append(f.reclaimed, tenantID)
+	return nil
+}
+
+func TestMoveSteps(t *testing.T) {
+	var names []string
+	for _, step := range MoveSteps(nil, &fakeMoveServer{}, &model.Shard{}, &fakeMoveServer{}, DefaultConfig()) {
+		names = append(names, step.Name())
+	}
+	assert.Equal(t, []string{StepPrepareTarget, StepCopyData, StepEnterMaintenance, StepSyncData, StepVerifyData,
+		StepCutover, StepExitMaintenance, StepReleaseSource, StepNotify}, names)
+}
+
+func TestChangedTables(t *testing.T) {
+	source := map[string]model.TableChecksum{
+		"orders":   {Rows: 2, Checksum: "a"},
+		"users":    {Rows: 1, Checksum: "b"},
+		"invoices": {Rows: 0, Checksum: ""},
+	}
+	target := map[string]model.TableChecksum{
+		"orders": {Rows: 2, Checksum: "c"},
+		"users":  {Rows: 1, Checksum: "b"},
+	}
+	assert.Equal(t, []string{"invoices", "orders"}, changedTables(source, target))
+	assert.Empty(t, changedTables(source, source))
+}
+
+func TestCompareCopies(t *testing.T) {
+	tables := map[string]model.TableChecksum{"orders": {Rows: 2, Checksum: "a"}, "users": {Rows: 1, Checksum: "b"}}
+	sequences := map[string]int64{"orders_id_seq": 2}
+	assert.NoError(t, compareCopies(tables, tables, sequences, sequences))
+
+	err := compareCopies(tables,
+		map[string]model.TableChecksum{"orders": {Rows: 3, Checksum: "a"}, "extra": {}},
+		sequences, map[string]int64{"orders_id_seq": 1})
+	assert.EqualError(t, err, "copies differ: sequence orders_id_seq is at 1, want 2; table extra is not in the source; "+
+		"table orders has 3 rows, want 2; table users is missing")
+
+	err = compareCopies(tables, map[string]model.TableChecksum{"orders": {Rows: 2, Checksum: "x"}, "users": tables["users"]}, nil, nil)
+	assert.EqualError(t, err, "copies differ: table orders has different rows")
+}
+
+func TestSyncDataStep_CopiesChangedTablesAndSequences(t *testing.T) {
+	source := &fakeMoveServer{
+		checksums: map[string]model.TableChecksum{"orders": {Rows: 3, Checksum: "new"}, "users": {Rows: 1, Checksum: "b"}},
+		sequences: map[string]int64{"orders_id_seq": 3},
+	}
+	target := &fakeMoveServer{
+		checksums: map[string]model.TableChecksum{"orders": {Rows: 2, Checksum: "old"}, "users": {Rows: 1, Checksum: "b"}},
+	}
+	step := &syncDataStep{stepInfo{StepSyncData, DefaultConfig().MoveStepTimeout}, &mover{source: source, targetServer: target}}
+	state := newState()
+	state.Move = &model.TenantMove{SchemaName: "tenant_x"}
+
+	assert.NoError(t, step.Apply(context.Background(), state))
+	assert.Equal(t, []string{"orders"}, target.imported)
+	assert.Equal(t, map[string]int64{"orders_id_seq": 3}, target.sequences)
+
+	// Nothing is copied once the tenant was cut over
+	target.imported = nil
+	state.CutOver = true
+	assert.NoError(t, step.Apply(context.Background(), state))
+	assert.Empty(t, target.imported)
+}
+
+func TestMovedSchemaReclaimer(t *testing.T) {
+	sourceID, sameServerID, otherServerID := uuid.New(), uuid.New(), uuid.New()
+	movedAway, movedNextDoor, movedBack, deleted := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	s := &fakeMoveStore{
+		placements: map[uuid.UUID]*model.ShardSchema{
+			movedAway:     {TenantID: movedAway, ShardID: otherServerID},
+			movedNextDoor: {TenantID: movedNextDoor, ShardID: sameServerID},
+			movedBack:     {TenantID: movedBack, ShardID: sourceID},
+		},
+		shards: map[uuid.UUID]*model.Shard{
+			sameServerID:  {ID: sameServerID, Host: "db-1", Port: 5432},
+			otherServerID: {ID: otherServerID, Host: "db-2", Port: 5432},
+		},
+	}
+	server := &fakeMoveServer{host: "db-1"}
+	reclaimer := MovedSchemaReclaimer(s, sourceID, server)
+	assert.Equal(t, StepDropMovedSchema, reclaimer.Name())
+
+	for _, id := range []uuid.UUID{movedAway, movedNextDoor, movedBack, deleted} {
+		assert.NoError(t, reclaimer.Reclaim(context.Background(), id))
+	}
+	// The live schema of a tenant that moved back is kept
+	assert.Equal(t, []string{ShardSchemaName(movedAway), ShardSchemaName(movedNextDoor), ShardSchemaName(deleted)}, server.dropped)
+	// A role still used by another database of the server is kept
+	assert.Equal(t, []string{RoleName(movedAway), RoleName(deleted)}, server.roles)
+	assert.Equal(t, []uuid.UUID{movedAway, movedNextDoor, movedBack, deleted}, s.reclaimed)
+}
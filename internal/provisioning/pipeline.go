@@ -0,0 +1,140 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// Provisioning log statuses written by the pipeline
+const (
+	LogInProgress         = "in_progress"
+	LogSuccess            = "success"
+	LogFailed             = "failed"
+	LogCompensating       = "compensating"
+	LogCompensated        = "compensated"
+	LogCompensationFailed = "compensation_failed"
+)
+
+// LogWriter records step progress in tenant_provisioning_logs
+type LogWriter interface {
+	CreateProvisioningLog(ctx context.Context, tenantID uuid.UUID, step, status string, details interface{}) error
+}
+
+// Pipeline runs provisioning steps in order
+type Pipeline struct {
+	steps []Step
+	logs  LogWriter
+	// forward pipelines never compensate
+	forward bool
+}
+
+// NewPipeline creates a pipeline running steps in the given order and
+// compensating them when one fails
+func NewPipeline(logs LogWriter, steps ...Step) *Pipeline {
+	return &Pipeline{steps: steps, logs: logs}
+}
+
+// NewForwardPipeline creates a pipeline that only moves forward: a failed run
+// leaves completed steps in place and is retried instead of rolled back. It
+// suits teardown, where undoing half of it would bring a deleted tenant back.
+func NewForwardPipeline(logs LogWriter, steps ...Step) *Pipeline {
+	return &Pipeline{steps: steps, logs: logs, forward: true}
+}
+
+// Steps returns the steps of the pipeline in order
+func (p *Pipeline) Steps() []Step {
+	return p.steps
+}
+
+// Run applies every step under its own timeout, stopping at the first
+// failure. Unless the pipeline is a forward one, the failed step and every
+// step before it are then compensated in reverse order, so that a later run
+// starts from a clean slate. The failure is returned as a *StepError. When
+// ctx is cancelled nothing is compensated: the job is abandoned, not failed,
+// and whoever resumes it re-applies the idempotent steps.
+func (p *Pipeline) Run(ctx context.Context, state *State) error {
+	for i, step := range p.steps {
+		p.log(ctx, state, step.Name(), LogInProgress, nil)
+
+		start := time.Now()
+		stepCtx, cancel := context.WithTimeout(ctx, step.Timeout())
+		err := step.Apply(stepCtx, state)
+		cancel()
+
+		details := map[string]interface{}{"duration_ms": time.Since(start).Milliseconds()}
+		if err != nil {
+			details["error"] = err.Error()
+			p.log(ctx, state, step.Name(), LogFailed, details)
+			stepErr := &StepError{Step: step.Name(), Err: err}
+			if ctx.Err() == nil && !p.forward {
+				stepErr.CompensationErr = p.compensate(ctx, state, p.steps[:i+1])
+			}
+			return stepErr
+		}
+		p.log(ctx, state, step.Name(), LogSuccess, details)
+	}
+	return nil
+}
+
+// Compensate undoes every step of the pipeline in reverse order, for runs
+// that died without compensating their own progress
+func (p *Pipeline) Compensate(ctx context.Context, state *State) error {
+	return p.compensate(ctx, state, p.steps)
+}
+
+// compensate undoes steps in reverse order. It keeps going past failures so
+// that as much as possible is cleaned up, and returns them joined.
+func (p *Pipeline) compensate(ctx context.Context, state *State, steps []Step) error {
+	var errs []error
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := steps[i]
+		p.log(ctx, state, step.Name(), LogCompensating, nil)
+
+		start := time.Now()
+		stepCtx, cancel := context.WithTimeout(ctx, step.Timeout())
+		err := step.Compensate(stepCtx, state)
+		cancel()
+
+		details := map[string]interface{}{"duration_ms": time.Since(start).Milliseconds()}
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("tenant_id", state.Tenant.ID.String()).
+				Str("step", step.Name()).
+				Msg("Failed to compensate provisioning step")
+			details["error"] = err.Error()
+			p.log(ctx, state, step.Name(), LogCompensationFailed, details)
+			errs = append(errs, fmt.Errorf("%s: %w", step.Name(), err))
+			continue
+		}
+		log.Info().
+			Str("tenant_id", state.Tenant.ID.String()).
+			Str("step", step.Name()).
+			Msg("Compensated provisioning step")
+		p.log(ctx, state, step.Name(), LogCompensated, details)
+	}
+	return errors.Join(errs...)
+}
+
+// log writes a provisioning log row; failures to log never fail the step
+func (p *Pipeline) log(ctx context.Context, state *State, step, status string, details map[string]interface{}) {
+	if details == nil {
+		details = make(map[string]interface{})
+	}
+	details["attempt"] = state.Attempt
+	if state.JobID != uuid.Nil {
+		details["job_id"] = state.JobID.String()
+	}
+	if err := p.logs.CreateProvisioningLog(ctx, state.Tenant.ID, step, status, details); err != nil {
+		log.Error().
+			Err(err).
+			Str("tenant_id", state.Tenant.ID.String()).
+			Str("step", step).
+			Msg("Failed to write provisioning log")
+	}
+}
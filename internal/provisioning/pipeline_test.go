@@ -0,0 +1,290 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"github.com/teresa-solution/tenant-management-service/internal/store"
+)
+
+type logEntry struct {
+	step, status string
+	details      map[string]interface{}
+}
+
+type fakeLogs struct {
+	entries []logEntry
+}
+
+func (f *fakeLogs) CreateProvisioningLog(ctx context.Context, tenantID uuid.UUID, step, status string, details interface{}) error {
+	f.entries = append(f.entries, logEntry{step, status, details.(map[string]interface{})})
+	return nil
+}
+
+type fakeStep struct {
+	stepInfo
+	err           error
+	applied       *[]string
+	compensateErr error
+	compensated   *[]string
+}
+
+func (s *fakeStep) Apply(ctx context.Context, state *State) error {
+	*s.applied = append(*s.applied, s.name)
+	if s.err != nil {
+		return s.err
+	}
+	if s.timeout < time.Millisecond {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (s *fakeStep) Compensate(ctx context.Context, state *State) error {
+	if s.compensated != nil {
+		*s.compensated = append(*s.compensated, s.name)
+	}
+	return s.compensateErr
+}
+
+func newState() *State {
+	return &State{Tenant: &model.Tenant{ID: uuid.New(), Subdomain: "acme"}, Attempt: 2}
+}
+
+func TestPipeline_RunsStepsInOrder(t *testing.T) {
+	var applied []string
+	logs := &fakeLogs{}
+	p := NewPipeline(logs,
+		&fakeStep{stepInfo: stepInfo{"one", time.Second}, applied: &applied},
+		&fakeStep{stepInfo: stepInfo{"two", time.Second}, applied: &applied},
+	)
+
+	assert.NoError(t, p.Run(context.Background(), newState()))
+	assert.Equal(t, []string{"one", "two"}, applied)
+	if assert.Len(t, logs.entries, 4) {
+		assert.Equal(t, logEntry{"one", LogInProgress, logs.entries[0].details}, logs.entries[0])
+		assert.Equal(t, LogSuccess, logs.entries[1].status)
+		assert.Equal(t, 2, logs.entries[1].details["attempt"])
+		assert.Contains(t, logs.entries[1].details, "duration_ms")
+		assert.Equal(t, "two", logs.entries[3].step)
+	}
+}
+
+func TestPipeline_CompensatesInReverseOnFailure(t *testing.T) {
+	var applied, compensated []string
+	logs := &fakeLogs{}
+	boom := errors.New("boom")
+	p := NewPipeline(logs,
+		&fakeStep{stepInfo: stepInfo{"one", time.Second}, applied: &applied, compensated: &compensated},
+		&fakeStep{stepInfo: stepInfo{"two", time.Second}, applied: &applied, compensated: &compensated},
+		&fakeStep{stepInfo: stepInfo{"three", time.Second}, err: boom, applied: &applied, compensated: &compensated},
+		&fakeStep{stepInfo: stepInfo{"four", time.Second}, applied: &applied, compensated: &compensated},
+	)
+
+	err := p.Run(context.Background(), newState())
+	var stepErr *StepError
+	if assert.ErrorAs(t, err, &stepErr) {
+		assert.Equal(t, "three", stepErr.Step)
+		assert.NoError(t, stepErr.CompensationErr)
+	}
+	assert.ErrorIs(t, err, boom)
+	assert.Equal(t, []string{"one", "two", "three"}, applied)
+	assert.Equal(t, []string{"three", "two", "one"}, compensated)
+
+	var statuses []string
+	for _, e := range logs.entries {
+		statuses = append(statuses, e.step+":"+e.status)
+	}
+	assert.Equal(t, []string{
+		"one:in_progress", "one:success", "two:in_progress", "two:success", "three:in_progress", "three:failed",
+		"three:compensating", "three:compensated", "two:compensating", "two:compensated", "one:compensating", "one:compensated",
+	}, statuses)
+	assert.Equal(t, "boom", logs.entries[5].details["error"])
+}
+
+func TestPipeline_CompensationFailureContinues(t *testing.T) {
+	var applied, compensated []string
+	stuck := errors.New("stuck")
+	p := NewPipeline(&fakeLogs{},
+		&fakeStep{stepInfo: stepInfo{"one", time.Second}, applied: &applied, compensated: &compensated},
+		&fakeStep{stepInfo: stepInfo{"two", time.Second}, applied: &applied, compensated: &compensated, compensateErr: stuck},
+		&fakeStep{stepInfo: stepInfo{"three", time.Second}, err: errors.New("boom"), applied: &applied},
+	)
+
+	err := p.Run(context.Background(), newState())
+	var stepErr *StepError
+	if assert.ErrorAs(t, err, &stepErr) {
+		assert.ErrorIs(t, stepErr.CompensationErr, stuck)
+	}
+	assert.Contains(t, err.Error(), "compensation incomplete")
+	assert.Equal(t, []string{"two", "one"}, compensated)
+}
+
+func TestForwardPipeline_DoesNotCompensate(t *testing.T) {
+	var applied, compensated []string
+	p := NewForwardPipeline(&fakeLogs{},
+		&fakeStep{stepInfo: stepInfo{"one", time.Second}, applied: &applied, compensated: &compensated},
+		&fakeStep{stepInfo: stepInfo{"two", time.Second}, err: errors.New("boom"), applied: &applied, compensated: &compensated},
+	)
+
+	assert.Error(t, p.Run(context.Background(), newState()))
+	assert.Equal(t, []string{"one", "two"}, applied)
+	assert.Empty(t, compensated)
+}
+
+func TestPipeline_NoCompensationWhenCancelled(t *testing.T) {
+	var applied, compensated []string
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p := NewPipeline(&fakeLogs{},
+		&fakeStep{stepInfo: stepInfo{"one", time.Second}, err: context.Canceled, applied: &applied, compensated: &compensated},
+	)
+
+	assert.Error(t, p.Run(ctx, newState()))
+	assert.Empty(t, compensated)
+}
+
+func TestPipeline_StepTimeout(t *testing.T) {
+	var applied []string
+	p := NewPipeline(&fakeLogs{}, &fakeStep{stepInfo: stepInfo{"slow", time.Microsecond}, applied: &applied})
+
+	err := p.Run(context.Background(), newState())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestDefaultSteps(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.NoError(t, cfg.Validate())
+
+	var names []string
+	for _, step := range DefaultSteps(nil, cfg) {
+		names = append(names, step.Name())
+		assert.Equal(t, cfg.StepTimeout, step.Timeout())
+	}
+	assert.Equal(t, []string{StepCreateSchema, StepApplyTemplate, StepCreateRole, StepWriteDBConfig,
+		StepSeedFeatures, StepRegisterDNS, StepNotify}, names)
+
+	names = nil
+	for _, step := range DeprovisionSteps(nil, cfg) {
+		names = append(names, step.Name())
+	}
+	assert.Equal(t, []string{StepRevokeRole, StepBlockConnections, StepDisableRouting, StepSnapshotData, StepScheduleSchemaDrop}, names)
+
+	bad := cfg
+	bad.StepTimeout = 0
+	assert.Error(t, bad.Validate())
+
+	bad = cfg
+	bad.RoleLimits.ConnectionLimit = 1
+	assert.Error(t, bad.Validate())
+}
+
+func TestNames(t *testing.T) {
+	id := uuid.MustParse("0b7f3c2e-8d4a-4c1b-9e6f-1a2b3c4d5e6f")
+	assert.Equal(t, "tenant_0b7f3c2e8d4a4c1b9e6f1a2b3c4d5e6f", RoleName(id))
+	assert.Equal(t, "tenants/0b7f3c2e-8d4a-4c1b-9e6f-1a2b3c4d5e6f/db-password", PasswordSecretID(id))
+	assert.Equal(t, "acme.example.com", Hostname("acme", ".example.com"))
+	assert.Equal(t, "tenant_snapshot_0b7f3c2e8d4a4c1b9e6f1a2b3c4d5e6f", SnapshotSchemaName(id))
+}
+
+type fakeDNSProvider struct {
+	created, deleted []model.DNSRecord
+}
+
+func (f *fakeDNSProvider) CreateRecord(ctx context.Context, record model.DNSRecord) error {
+	f.created = append(f.created, record)
+	return nil
+}
+
+func (f *fakeDNSProvider) DeleteRecord(ctx context.Context, record model.DNSRecord) error {
+	f.deleted = append(f.deleted, record)
+	return nil
+}
+
+type fakeDNSStore struct {
+	Store
+	records map[uuid.UUID]model.DNSRecord
+}
+
+func (f *fakeDNSStore) SetTenantDNSRecord(ctx context.Context, tenantID uuid.UUID, record *model.DNSRecord) error {
+	if record == nil {
+		delete(f.records, tenantID)
+	} else {
+		f.records[tenantID] = *record
+	}
+	return nil
+}
+
+func (f *fakeDNSStore) GetTenantDNSRecord(ctx context.Context, tenantID uuid.UUID) (*model.DNSRecord, error) {
+	record, ok := f.records[tenantID]
+	if !ok {
+		return nil, &store.NotFoundError{Resource: store.ResourceDNSRecord, Key: tenantID.String()}
+	}
+	return &record, nil
+}
+
+func TestDNSSteps(t *testing.T) {
+	ctx := context.Background()
+	provider := &fakeDNSProvider{}
+	s := &fakeDNSStore{records: make(map[uuid.UUID]model.DNSRecord)}
+	cfg := DefaultConfig()
+	cfg.BaseDomain, cfg.DNSTarget, cfg.DNS = "tenants.example.com", "ingress.example.com", provider
+
+	state := newState()
+	register := &registerDNSStep{stepInfo{StepRegisterDNS, cfg.StepTimeout}, NewTenantDNS(s, cfg)}
+	assert.NoError(t, register.Apply(ctx, state))
+	published := model.DNSRecord{Name: "acme.tenants.example.com", Type: model.DNSRecordCNAME, Value: "ingress.example.com", TTL: 300}
+	assert.Equal(t, []model.DNSRecord{published}, provider.created)
+	assert.Equal(t, published, s.records[state.Tenant.ID])
+	assert.Equal(t, "acme.tenants.example.com", state.Hostname)
+
+	// The published record is deleted even when the target has changed since
+	cfg.DNSTarget = "10.0.0.1"
+	disable := &disableRoutingStep{stepInfo{StepDisableRouting, cfg.StepTimeout}, NewTenantDNS(s, cfg)}
+	assert.NoError(t, disable.Apply(ctx, state))
+	assert.Equal(t, []model.DNSRecord{published}, provider.deleted)
+	assert.Empty(t, s.records)
+
+	// Without a recorded one, the record the tenant would get now is deleted
+	assert.NoError(t, disable.Apply(ctx, state))
+	assert.Equal(t, model.DNSRecordA, provider.deleted[1].Type)
+
+	// No records are published without a target
+	cfg.DNSTarget = ""
+	register = &registerDNSStep{stepInfo{StepRegisterDNS, cfg.StepTimeout}, NewTenantDNS(s, cfg)}
+	assert.NoError(t, register.Apply(ctx, state))
+	assert.NoError(t, register.Compensate(ctx, state))
+	assert.Len(t, provider.created, 1)
+	assert.Len(t, provider.deleted, 2)
+}
+
+func TestTenantDNS_Rename(t *testing.T) {
+	ctx := context.Background()
+	provider := &fakeDNSProvider{}
+	s := &fakeDNSStore{records: make(map[uuid.UUID]model.DNSRecord)}
+	cfg := DefaultConfig()
+	cfg.BaseDomain, cfg.DNSTarget, cfg.DNS = "tenants.example.com", "ingress.example.com", provider
+	dns := NewTenantDNS(s, cfg)
+	tenant := newState().Tenant
+	assert.NoError(t, dns.publish(ctx, tenant))
+	acme := s.records[tenant.ID]
+	globex := model.NewDNSRecord("globex.tenants.example.com", "ingress.example.com", 300)
+
+	// A failed save withdraws the new record and keeps the old one
+	assert.Error(t, dns.Rename(ctx, tenant, "globex", func(ctx context.Context) error { return errors.New("conflict") }))
+	assert.Equal(t, []model.DNSRecord{acme, globex}, provider.created)
+	assert.Equal(t, []model.DNSRecord{globex}, provider.deleted)
+	assert.Equal(t, acme, s.records[tenant.ID])
+
+	saved := false
+	assert.NoError(t, dns.Rename(ctx, tenant, "globex", func(ctx context.Context) error { saved = true; return nil }))
+	assert.True(t, saved)
+	assert.Equal(t, []model.DNSRecord{globex, acme}, provider.deleted)
+	assert.Equal(t, globex, s.records[tenant.ID])
+}
@@ -0,0 +1,179 @@
+package provisioning
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+// Placement strategies choosing the shard a tenant's schema lives on
+const (
+	// PlacementLeastLoaded picks the shard with the lowest share of its capacity used
+	PlacementLeastLoaded = "least-loaded"
+	// PlacementWeighted picks a shard at random in proportion to its weight
+	PlacementWeighted = "weighted"
+	// PlacementRegionAffine keeps a tenant in its region, picking the least
+	// loaded shard there; tenants without a region may go anywhere
+	PlacementRegionAffine = "region-affine"
+	// PlacementPinned puts every tenant on one named shard, as "pinned:<name>"
+	PlacementPinned = "pinned"
+)
+
+// ErrNoShardCapacity is returned by a Placement when no shard may take the tenant
+var ErrNoShardCapacity = errors.New("no database shard has capacity for the tenant")
+
+// Placement chooses the shard a new tenant's schema is created on
+type Placement interface {
+	// Place returns one of shards for tenant, or ErrNoShardCapacity. Shards
+	// that are draining or full are never chosen.
+	Place(tenant *model.Tenant, shards []model.Shard) (*model.Shard, error)
+}
+
+// ParsePlacement parses a placement strategy such as "weighted" or "pinned:eu-1"
+func ParsePlacement(spec string) (Placement, error) {
+	spec = strings.TrimSpace(spec)
+	name, arg, hasArg := strings.Cut(spec, ":")
+	switch {
+	case name == PlacementLeastLoaded && !hasArg:
+		return leastLoaded{}, nil
+	case name == PlacementWeighted && !hasArg:
+		return weighted{}, nil
+	case name == PlacementRegionAffine && !hasArg:
+		return regionAffine{}, nil
+	case name == PlacementPinned && strings.TrimSpace(arg) != "":
+		return pinned{name: strings.TrimSpace(arg)}, nil
+	case name == PlacementPinned:
+		return nil, fmt.Errorf("invalid placement %q, want pinned:<shard>", spec)
+	}
+	return nil, fmt.Errorf("invalid placement %q: unknown strategy", spec)
+}
+
+// available returns the shards that may take another tenant
+func available(shards []model.Shard) []model.Shard {
+	var candidates []model.Shard
+	for _, shard := range shards {
+		if shard.Available() {
+			candidates = append(candidates, shard)
+		}
+	}
+	return candidates
+}
+
+type leastLoaded struct{}
+
+func (leastLoaded) Place(tenant *model.Tenant, shards []model.Shard) (*model.Shard, error) {
+	candidates := available(shards)
+	if len(candidates) == 0 {
+		return nil, ErrNoShardCapacity
+	}
+	best := &candidates[0]
+	for i := range candidates[1:] {
+		shard := &candidates[i+1]
+		// Compare Tenants/Capacity without dividing
+		if shard.Tenants*best.Capacity < best.Tenants*shard.Capacity {
+			best = shard
+		}
+	}
+	return best, nil
+}
+
+type weighted struct{}
+
+func (weighted) Place(tenant *model.Tenant, shards []model.Shard) (*model.Shard, error) {
+	var candidates []model.Shard
+	total := 0
+	for _, shard := range available(shards) {
+		if shard.Weight > 0 {
+			candidates = append(candidates, shard)
+			total += shard.Weight
+		}
+	}
+	if total == 0 {
+		return nil, ErrNoShardCapacity
+	}
+	n := rand.IntN(total)
+	for i := range candidates {
+		if n < candidates[i].Weight {
+			return &candidates[i], nil
+		}
+		n -= candidates[i].Weight
+	}
+	return &candidates[len(candidates)-1], nil
+}
+
+type regionAffine struct{}
+
+func (regionAffine) Place(tenant *model.Tenant, shards []model.Shard) (*model.Shard, error) {
+	if tenant.Region == "" {
+		return leastLoaded{}.Place(tenant, shards)
+	}
+	var local []model.Shard
+	for _, shard := range shards {
+		if shard.Region == tenant.Region {
+			local = append(local, shard)
+		}
+	}
+	shard, err := leastLoaded{}.Place(tenant, local)
+	if err != nil {
+		return nil, fmt.Errorf("%w in region %s", err, tenant.Region)
+	}
+	return shard, nil
+}
+
+type pinned struct {
+	name string
+}
+
+func (p pinned) Place(tenant *model.Tenant, shards []model.Shard) (*model.Shard, error) {
+	for i := range shards {
+		if shards[i].Name == p.name && shards[i].Available() {
+			return &shards[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: shard %s is missing, draining or full", ErrNoShardCapacity, p.name)
+}
+
+// TierPlacement maps tiers onto the placement strategy of their tenants,
+// falling back to Default
+type TierPlacement struct {
+	Default Placement
+	Tiers   map[string]Placement
+}
+
+// Place places tenant with the strategy of its tier
+func (t TierPlacement) Place(tenant *model.Tenant, shards []model.Shard) (*model.Shard, error) {
+	if placement, ok := t.Tiers[tenant.Tier]; ok {
+		return placement.Place(tenant, shards)
+	}
+	return t.Default.Place(tenant, shards)
+}
+
+// ParseTierPlacement parses comma-separated tier=strategy pairs such as
+// "enterprise=pinned:dedicated-1", placing other tiers with def
+func ParseTierPlacement(s string, def Placement) (TierPlacement, error) {
+	placement := TierPlacement{Default: def, Tiers: make(map[string]Placement)}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		tier, spec, ok := strings.Cut(pair, "=")
+		if !ok {
+			return TierPlacement{}, fmt.Errorf("invalid tier placement %q, want tier=strategy", pair)
+		}
+		tier = strings.TrimSpace(tier)
+		if !slices.Contains(model.Tiers, tier) {
+			return TierPlacement{}, fmt.Errorf("invalid tier placement %q: unknown tier", pair)
+		}
+		strategy, err := ParsePlacement(spec)
+		if err != nil {
+			return TierPlacement{}, fmt.Errorf("invalid tier placement %q: %w", pair, err)
+		}
+		placement.Tiers[tier] = strategy
+	}
+	return placement, nil
+}
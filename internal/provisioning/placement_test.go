@@ -0,0 +1,83 @@
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+func testShards() []model.Shard {
+	return []model.Shard{
+		{Name: "eu-1", Region: "eu", Capacity: 10, Tenants: 5, Weight: 1},
+		{Name: "eu-2", Region: "eu", Capacity: 100, Tenants: 20, Weight: 0},
+		{Name: "us-1", Region: "us", Capacity: 10, Tenants: 1, Weight: 3, Draining: true},
+		{Name: "us-2", Region: "us", Capacity: 4, Tenants: 4, Weight: 3},
+	}
+}
+
+func TestParsePlacement(t *testing.T) {
+	for _, spec := range []string{"least-loaded", "weighted", " region-affine ", "pinned:eu-1"} {
+		_, err := ParsePlacement(spec)
+		assert.NoError(t, err, spec)
+	}
+	for _, bad := range []string{"", "random", "pinned", "pinned:", "weighted:eu-1"} {
+		_, err := ParsePlacement(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestPlacementSkipsUnavailableShards(t *testing.T) {
+	tenant := &model.Tenant{Tier: model.TierBasic}
+
+	shard, err := leastLoaded{}.Place(tenant, testShards())
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-2", shard.Name)
+
+	// eu-1 is the only available shard with a weight
+	for i := 0; i < 20; i++ {
+		shard, err = weighted{}.Place(tenant, testShards())
+		assert.NoError(t, err)
+		assert.Equal(t, "eu-1", shard.Name)
+	}
+
+	_, err = pinned{name: "us-1"}.Place(tenant, testShards())
+	assert.ErrorIs(t, err, ErrNoShardCapacity)
+	shard, err = pinned{name: "eu-1"}.Place(tenant, testShards())
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-1", shard.Name)
+
+	_, err = leastLoaded{}.Place(tenant, nil)
+	assert.ErrorIs(t, err, ErrNoShardCapacity)
+}
+
+func TestRegionAffinePlacement(t *testing.T) {
+	shard, err := regionAffine{}.Place(&model.Tenant{Region: "eu"}, testShards())
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-2", shard.Name)
+
+	// Tenants are never placed outside their region, even with room elsewhere
+	_, err = regionAffine{}.Place(&model.Tenant{Region: "us"}, testShards())
+	assert.ErrorIs(t, err, ErrNoShardCapacity)
+
+	shard, err = regionAffine{}.Place(&model.Tenant{}, testShards())
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-2", shard.Name)
+}
+
+func TestParseTierPlacement(t *testing.T) {
+	placement, err := ParseTierPlacement("enterprise=pinned:eu-1, free=weighted,", leastLoaded{})
+	assert.NoError(t, err)
+
+	shard, err := placement.Place(&model.Tenant{Tier: model.TierEnterprise}, testShards())
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-1", shard.Name)
+	shard, err = placement.Place(&model.Tenant{Tier: model.TierBasic}, testShards())
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-2", shard.Name)
+
+	for _, bad := range []string{"enterprise", "gold=weighted", "enterprise=random"} {
+		_, err := ParseTierPlacement(bad, leastLoaded{})
+		assert.Error(t, err, bad)
+	}
+}
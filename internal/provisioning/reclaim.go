@@ -0,0 +1,94 @@
+package provisioning
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Reclaimer drops what deprovisioned tenants keep during their retention
+// delay, once the delay has passed
+type Reclaimer interface {
+	// Name is the step reclaims are logged as in tenant_provisioning_logs
+	Name() string
+	// Due returns up to limit tenants whose retention delay has passed
+	Due(ctx context.Context, limit int) ([]uuid.UUID, error)
+	// Reclaim drops what a tenant kept; running it twice is not an error
+	Reclaim(ctx context.Context, tenantID uuid.UUID) error
+}
+
+// Names under which reclaims are logged
+const (
+	StepDropSchema   = "drop_schema"
+	StepDropDatabase = "drop_database"
+)
+
+// SchemaReclaimer drops the schemas, roles, passwords and database configs
+// of tenants sharing the database. Snapshots are kept.
+func SchemaReclaimer(s Store, secrets SecretWriter) Reclaimer {
+	return &schemaReclaimer{store: s, schemas: s, secrets: secrets}
+}
+
+type schemaReclaimer struct {
+	store   Store
+	schemas Schemas
+	secrets SecretWriter
+}
+
+func (r *schemaReclaimer) Name() string {
+	return StepDropSchema
+}
+
+func (r *schemaReclaimer) Due(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	return r.schemas.DueSchemaDrops(ctx, limit)
+}
+
+func (r *schemaReclaimer) Reclaim(ctx context.Context, tenantID uuid.UUID) error {
+	if err := r.schemas.DropTenantSchema(ctx, tenantID); err != nil {
+		return err
+	}
+	if err := r.schemas.DropTenantRole(ctx, RoleName(tenantID)); err != nil {
+		return err
+	}
+	if err := r.secrets.DeleteSecret(ctx, PasswordSecretID(tenantID)); err != nil {
+		return err
+	}
+	return r.store.DeleteTenantDatabaseConfig(ctx, tenantID)
+}
+
+// DedicatedReclaimer drops the dedicated databases of tenants on server,
+// together with their roles, passwords and database configs
+func DedicatedReclaimer(s Store, server DatabaseServer, secrets SecretWriter) Reclaimer {
+	return &dedicatedReclaimer{store: s, server: server, secrets: secrets}
+}
+
+type dedicatedReclaimer struct {
+	store   Store
+	server  DatabaseServer
+	secrets SecretWriter
+}
+
+func (r *dedicatedReclaimer) Name() string {
+	return StepDropDatabase
+}
+
+func (r *dedicatedReclaimer) Due(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	return r.store.DueDatabaseDrops(ctx, limit)
+}
+
+// Reclaim forgets the database last, so a failed reclaim is retried
+func (r *dedicatedReclaimer) Reclaim(ctx context.Context, tenantID uuid.UUID) error {
+	if err := r.server.DropTenantDatabase(ctx, DatabaseName(tenantID)); err != nil {
+		return err
+	}
+	if err := r.server.DropTenantRole(ctx, RoleName(tenantID)); err != nil {
+		return err
+	}
+	if err := r.secrets.DeleteSecret(ctx, PasswordSecretID(tenantID)); err != nil {
+		return err
+	}
+	if err := r.store.DeleteTenantDatabaseConfig(ctx, tenantID); err != nil {
+		return err
+	}
+	return r.store.DeleteDedicatedDatabase(ctx, tenantID)
+}
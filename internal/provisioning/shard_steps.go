@@ -0,0 +1,220 @@
+package provisioning
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"github.com/teresa-solution/tenant-management-service/internal/tenantschema"
+)
+
+// ShardServer hosts the schemas of tenants placed on a database shard
+type ShardServer interface {
+	Host() string
+	Port() int
+	// DatabaseName is the database tenant schemas are created in
+	DatabaseName() string
+	CreateSchema(ctx context.Context, schemaName string) error
+	DropSchema(ctx context.Context, schemaName string) error
+	// CreateTenantRole creates a login role with access to a schema or updates it
+	CreateTenantRole(ctx context.Context, role, schemaName string, limits model.RoleLimits) error
+	PasswordSetter
+	DropTenantRole(ctx context.Context, role string) error
+	RevokeTenantRole(ctx context.Context, role, schemaName string) error
+	BlockTenantConnections(ctx context.Context, role string) error
+	UnblockTenantConnections(ctx context.Context, role string, limit int) error
+	// SnapshotSchema copies a schema's tables, reporting false when there is
+	// neither a snapshot nor a schema
+	SnapshotSchema(ctx context.Context, schemaName, snapshotSchema string) (bool, error)
+	// SchemaTemplateVersion and ApplySchemaMigration track the tenant schema
+	// template on the shard, like tenantschema.Store does in the registry
+	SchemaTemplateVersion(ctx context.Context, schemaName string) (int, error)
+	ApplySchemaMigration(ctx context.Context, schemaName string, version int, sql string) (bool, error)
+	// ForgetTenantRole revokes a tenant role's grants in the shard's database
+	// but keeps the role, which other databases of the server may grant to
+	ForgetTenantRole(ctx context.Context, role string) error
+	// SetSchemaReadOnly takes the write privileges on a schema away from a
+	// tenant role, ending its sessions, or gives them back
+	SetSchemaReadOnly(ctx context.Context, role, schemaName string, readOnly bool) error
+	// TableChecksums summarises the rows of every table of a schema
+	TableChecksums(ctx context.Context, schemaName string) (map[string]model.TableChecksum, error)
+	SequenceValues(ctx context.Context, schemaName string) (map[string]int64, error)
+	SetSequenceValues(ctx context.Context, schemaName string, values map[string]int64) error
+	// ExportTable writes a table's rows to w in COPY text format
+	ExportTable(ctx context.Context, schemaName, table string, w io.Writer) error
+	// ImportTables replaces the rows of tables with what export writes for
+	// each of them, in one transaction
+	ImportTables(ctx context.Context, schemaName string, tables []string, export func(ctx context.Context, table string, w io.Writer) error) error
+}
+
+// ShardSchemaName returns the schema of a tenant placed on a shard. Unlike
+// schemas in the registry database it does not follow the subdomain, which
+// may be reused before the schema is dropped.
+func ShardSchemaName(tenantID uuid.UUID) string {
+	return RoleName(tenantID)
+}
+
+// ShardSteps returns the steps provisioning a tenant schema on the shard
+// shardID served by server. They are the steps of DefaultSteps; only where
+// the schema and role are created differs.
+func ShardSteps(s Store, shardID uuid.UUID, server ShardServer, cfg Config) []Step {
+	return schemaSteps(s, ShardSchemas(s, shardID, server), server.Port(), server.DatabaseName(), cfg)
+}
+
+// ShardDeprovisionSteps returns the steps tearing down a deleted tenant whose
+// schema is on a shard. The snapshot is kept on the shard.
+func ShardDeprovisionSteps(s Store, shardID uuid.UUID, server ShardServer, cfg Config) []Step {
+	return schemaDeprovisionSteps(s, ShardSchemas(s, shardID, server), cfg)
+}
+
+// ShardReclaimer drops the schemas, roles, passwords and database configs of
+// deprovisioned tenants on a shard, then frees their place on it
+func ShardReclaimer(s Store, shardID uuid.UUID, server ShardServer, secrets SecretWriter) Reclaimer {
+	return &shardReclaimer{
+		schemaReclaimer: schemaReclaimer{store: s, schemas: ShardSchemas(s, shardID, server), secrets: secrets},
+	}
+}
+
+type shardReclaimer struct {
+	schemaReclaimer
+}
+
+// Reclaim forgets the placement last, so a failed reclaim is retried
+func (r *shardReclaimer) Reclaim(ctx context.Context, tenantID uuid.UUID) error {
+	if err := r.schemaReclaimer.Reclaim(ctx, tenantID); err != nil {
+		return err
+	}
+	return r.store.DeleteShardSchema(ctx, tenantID)
+}
+
+// ShardSchemas adapts the tenant schemas on a shard to Schemas and
+// tenantschema.Registry. Schemas are found through the placements recorded in
+// the registry, and their template versions are mirrored there so rollouts
+// can list them.
+func ShardSchemas(s Store, shardID uuid.UUID, server ShardServer) interface {
+	Schemas
+	tenantschema.Registry
+} {
+	return &shardSchemas{store: s, shardID: shardID, server: server}
+}
+
+type shardSchemas struct {
+	store   Store
+	shardID uuid.UUID
+	server  ShardServer
+}
+
+func (s *shardSchemas) CreateTenantSchema(ctx context.Context, tenantID uuid.UUID, subdomain string) error {
+	schemaName, err := s.GetTenantSchema(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	return s.server.CreateSchema(ctx, schemaName)
+}
+
+// GetTenantSchema returns the schema a tenant was placed with, which exists
+// once CreateTenantSchema has run
+func (s *shardSchemas) GetTenantSchema(ctx context.Context, tenantID uuid.UUID) (string, error) {
+	placement, err := s.store.GetShardSchema(ctx, tenantID)
+	if err != nil {
+		return "", err
+	}
+	return placement.SchemaName, nil
+}
+
+// DropTenantSchema drops the schema but keeps the placement, so a tenant
+// provisioned again lands on the same shard
+func (s *shardSchemas) DropTenantSchema(ctx context.Context, tenantID uuid.UUID) error {
+	schemaName, err := s.GetTenantSchema(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	return s.server.DropSchema(ctx, schemaName)
+}
+
+func (s *shardSchemas) ListTenantSchemas(ctx context.Context) ([]model.TenantSchema, error) {
+	placements, err := s.store.ListShardSchemas(ctx, s.shardID)
+	if err != nil {
+		return nil, err
+	}
+	schemas := make([]model.TenantSchema, 0, len(placements))
+	for _, p := range placements {
+		schemas = append(schemas, model.TenantSchema{TenantID: p.TenantID, SchemaName: p.SchemaName, TemplateVersion: p.TemplateVersion})
+	}
+	return schemas, nil
+}
+
+func (s *shardSchemas) TenantTemplateVersion(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	schemaName, err := s.GetTenantSchema(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	return s.server.SchemaTemplateVersion(ctx, schemaName)
+}
+
+func (s *shardSchemas) ApplyTenantMigration(ctx context.Context, tenantID uuid.UUID, version int, sql string) (bool, error) {
+	schemaName, err := s.GetTenantSchema(ctx, tenantID)
+	if err != nil {
+		return false, err
+	}
+	applied, err := s.server.ApplySchemaMigration(ctx, schemaName, version, sql)
+	if err != nil || !applied {
+		return applied, err
+	}
+	return true, s.store.SetShardTemplateVersion(ctx, tenantID, version)
+}
+
+func (s *shardSchemas) CreateTenantRole(ctx context.Context, role, schemaName string, limits model.RoleLimits) error {
+	return s.server.CreateTenantRole(ctx, role, schemaName, limits)
+}
+
+func (s *shardSchemas) SetTenantRolePassword(ctx context.Context, role, password string) error {
+	return s.server.SetTenantRolePassword(ctx, role, password)
+}
+
+func (s *shardSchemas) DropTenantRole(ctx context.Context, role string) error {
+	return s.server.DropTenantRole(ctx, role)
+}
+
+func (s *shardSchemas) RevokeTenantRole(ctx context.Context, role, schemaName string) error {
+	return s.server.RevokeTenantRole(ctx, role, schemaName)
+}
+
+func (s *shardSchemas) BlockTenantConnections(ctx context.Context, role string) error {
+	return s.server.BlockTenantConnections(ctx, role)
+}
+
+func (s *shardSchemas) UnblockTenantConnections(ctx context.Context, role string, limit int) error {
+	return s.server.UnblockTenantConnections(ctx, role, limit)
+}
+
+// SnapshotTenantSchema copies the schema on the shard and records the
+// snapshot in the registry
+func (s *shardSchemas) SnapshotTenantSchema(ctx context.Context, tenantID uuid.UUID, snapshotSchema string) (string, error) {
+	schemaName, err := s.GetTenantSchema(ctx, tenantID)
+	if err != nil {
+		return "", err
+	}
+	copied, err := s.server.SnapshotSchema(ctx, schemaName, snapshotSchema)
+	if err != nil || !copied {
+		return "", err
+	}
+	if err := s.store.RecordShardSnapshot(ctx, tenantID, s.shardID, schemaName, snapshotSchema); err != nil {
+		return "", err
+	}
+	return snapshotSchema, nil
+}
+
+func (s *shardSchemas) ScheduleTenantSchemaDrop(ctx context.Context, tenantID uuid.UUID, at time.Time) error {
+	return s.store.ScheduleShardSchemaDrop(ctx, tenantID, at)
+}
+
+func (s *shardSchemas) CancelTenantSchemaDrop(ctx context.Context, tenantID uuid.UUID) error {
+	return s.store.CancelShardSchemaDrop(ctx, tenantID)
+}
+
+func (s *shardSchemas) DueSchemaDrops(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	return s.store.DueShardSchemaDrops(ctx, s.shardID, limit)
+}
@@ -0,0 +1,101 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+type fakeShardServer struct {
+	ShardServer
+	versions map[string]int
+}
+
+func (f *fakeShardServer) Port() int            { return 6432 }
+func (f *fakeShardServer) DatabaseName() string { return "shard_1" }
+
+func (f *fakeShardServer) SchemaTemplateVersion(ctx context.Context, schemaName string) (int, error) {
+	return f.versions[schemaName], nil
+}
+
+func (f *fakeShardServer) ApplySchemaMigration(ctx context.Context, schemaName string, version int, sql string) (bool, error) {
+	if version <= f.versions[schemaName] {
+		return false, nil
+	}
+	f.versions[schemaName] = version
+	return true, nil
+}
+
+type fakeShardStore struct {
+	Store
+	placements map[uuid.UUID]*model.ShardSchema
+}
+
+func (f *fakeShardStore) GetShardSchema(ctx context.Context, tenantID uuid.UUID) (*model.ShardSchema, error) {
+	return f.placements[tenantID], nil
+}
+
+func (f *fakeShardStore) ListShardSchemas(ctx context.Context, shardID uuid.UUID) ([]model.ShardSchema, error) {
+	var schemas []model.ShardSchema
+	for _, p := range f.placements {
+		if p.ShardID == shardID {
+			schemas = append(schemas, *p)
+		}
+	}
+	return schemas, nil
+}
+
+func (f *fakeShardStore) SetShardTemplateVersion(ctx context.Context, tenantID uuid.UUID, version int) error {
+	f.placements[tenantID].TemplateVersion = version
+	return nil
+}
+
+func TestShardSteps(t *testing.T) {
+	cfg := DefaultConfig()
+	server := &fakeShardServer{}
+
+	var names []string
+	for _, step := range ShardSteps(nil, uuid.New(), server, cfg) {
+		names = append(names, step.Name())
+	}
+	assert.Equal(t, []string{StepCreateSchema, StepApplyTemplate, StepCreateRole, StepWriteDBConfig,
+		StepSeedFeatures, StepRegisterDNS, StepNotify}, names)
+
+	names = nil
+	for _, step := range ShardDeprovisionSteps(nil, uuid.New(), server, cfg) {
+		names = append(names, step.Name())
+	}
+	assert.Equal(t, []string{StepRevokeRole, StepBlockConnections, StepDisableRouting, StepSnapshotData,
+		StepScheduleSchemaDrop}, names)
+}
+
+func TestShardSchemas(t *testing.T) {
+	shardID, tenantID := uuid.New(), uuid.New()
+	schemaName := ShardSchemaName(tenantID)
+	s := &fakeShardStore{placements: map[uuid.UUID]*model.ShardSchema{
+		tenantID:   {TenantID: tenantID, ShardID: shardID, SchemaName: schemaName},
+		uuid.New(): {ShardID: uuid.New(), SchemaName: "elsewhere"},
+	}}
+	server := &fakeShardServer{versions: map[string]int{schemaName: 1}}
+	schemas := ShardSchemas(s, shardID, server)
+
+	listed, err := schemas.ListTenantSchemas(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []model.TenantSchema{{TenantID: tenantID, SchemaName: schemaName}}, listed)
+
+	applied, err := schemas.ApplyTenantMigration(context.Background(), tenantID, 2, "SELECT 1")
+	assert.NoError(t, err)
+	assert.True(t, applied)
+	version, err := schemas.TenantTemplateVersion(context.Background(), tenantID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, version)
+	// The version is mirrored in the registry so rollouts can list it
+	assert.Equal(t, 2, s.placements[tenantID].TemplateVersion)
+
+	applied, err = schemas.ApplyTenantMigration(context.Background(), tenantID, 2, "SELECT 1")
+	assert.NoError(t, err)
+	assert.False(t, applied)
+}
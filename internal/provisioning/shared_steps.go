@@ -0,0 +1,197 @@
+package provisioning
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"github.com/teresa-solution/tenant-management-service/internal/store"
+	"github.com/teresa-solution/tenant-management-service/internal/tenantschema"
+)
+
+// Names of the steps specific to tenants sharing tables
+const (
+	StepJoinSharedSchema   = "join_shared_schema"
+	StepScheduleSharedDrop = "schedule_shared_drop"
+	StepDropSharedRows     = "drop_shared_rows"
+)
+
+// SharedSteps returns the steps provisioning a tenant into the shared tables,
+// in the order they must run. The tables are brought up to cfg.SharedTemplate
+// by the first tenant that needs them.
+func SharedSteps(s Store, cfg Config) []Step {
+	return []Step{
+		&joinSharedSchemaStep{stepInfo{StepJoinSharedSchema, cfg.StepTimeout}, s},
+		&applyTemplateStep{stepInfo{StepApplyTemplate, cfg.StepTimeout}, SharedTemplateStore(s), cfg.SharedTemplate},
+		&createSharedRoleStep{stepInfo{StepCreateRole, cfg.StepTimeout}, s, cfg.RoleLimits, NewCredentials(s, cfg.Secrets)},
+		&writeDBConfigStep{stepInfo{StepWriteDBConfig, cfg.StepTimeout}, s, model.IsolationShared, cfg.DBPort, cfg.DBName, cfg.RoleLimits},
+		&seedFeaturesStep{stepInfo{StepSeedFeatures, cfg.StepTimeout}, s, cfg.TierFeatures},
+		&registerDNSStep{stepInfo{StepRegisterDNS, cfg.StepTimeout}, NewTenantDNS(s, cfg)},
+		&notifyStep{stepInfo{StepNotify, cfg.StepTimeout}, cfg.Notifier},
+	}
+}
+
+// SharedDeprovisionSteps returns the steps tearing down a deleted tenant
+// sharing tables. Its rows are copied to a snapshot schema and deleted from
+// the shared tables once SchemaDropDelay has passed.
+func SharedDeprovisionSteps(s Store, cfg Config) []Step {
+	return []Step{
+		&revokeSharedRoleStep{stepInfo{StepRevokeRole, cfg.StepTimeout}, s, cfg.RoleLimits},
+		&blockConnectionsStep{stepInfo{StepBlockConnections, cfg.StepTimeout}, s, cfg.RoleLimits},
+		&disableRoutingStep{stepInfo{StepDisableRouting, cfg.StepTimeout}, NewTenantDNS(s, cfg)},
+		&sharedSnapshotStep{stepInfo{StepSnapshotData, cfg.StepTimeout}, s},
+		&scheduleSharedDropStep{stepInfo{StepScheduleSharedDrop, cfg.StepTimeout}, s, cfg.SchemaDropDelay},
+	}
+}
+
+// SharedTemplateStore tracks the template of the shared tables as if every
+// tenant had them, so tenantschema.Migrate and Rollout can apply it. They are
+// listed as a single schema with no tenant.
+func SharedTemplateStore(s Store) tenantschema.Registry {
+	return &sharedTemplate{store: s}
+}
+
+type sharedTemplate struct {
+	store Store
+}
+
+func (t *sharedTemplate) ListTenantSchemas(ctx context.Context) ([]model.TenantSchema, error) {
+	version, err := t.store.SharedTemplateVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []model.TenantSchema{{SchemaName: store.SharedSchema, TemplateVersion: version}}, nil
+}
+
+func (t *sharedTemplate) TenantTemplateVersion(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	return t.store.SharedTemplateVersion(ctx)
+}
+
+func (t *sharedTemplate) ApplyTenantMigration(ctx context.Context, tenantID uuid.UUID, version int, sql string) (bool, error) {
+	return t.store.ApplySharedMigration(ctx, version, sql)
+}
+
+type joinSharedSchemaStep struct {
+	stepInfo
+	store Store
+}
+
+func (s *joinSharedSchemaStep) Apply(ctx context.Context, state *State) error {
+	if err := s.store.AddSharedMember(ctx, state.Tenant.ID); err != nil {
+		return err
+	}
+	state.SchemaName = store.SharedSchema
+	return nil
+}
+
+// Compensate deletes whatever rows the tenant got in the shared tables
+func (s *joinSharedSchemaStep) Compensate(ctx context.Context, state *State) error {
+	return s.store.RemoveSharedMember(ctx, state.Tenant.ID)
+}
+
+type createSharedRoleStep struct {
+	stepInfo
+	store       Store
+	limits      model.RoleLimits
+	credentials *Credentials
+}
+
+// Apply gives the role a new password on every attempt, like createRoleStep
+func (s *createSharedRoleStep) Apply(ctx context.Context, state *State) error {
+	role := RoleName(state.Tenant.ID)
+	if err := s.store.CreateSharedTenantRole(ctx, role, state.Tenant.ID, s.limits); err != nil {
+		return err
+	}
+	secretID, err := s.credentials.Rotate(ctx, state.Tenant.ID)
+	if err != nil {
+		return err
+	}
+	state.RoleName = role
+	state.PasswordSecretID = secretID
+	return nil
+}
+
+func (s *createSharedRoleStep) Compensate(ctx context.Context, state *State) error {
+	if err := s.store.DropTenantRole(ctx, RoleName(state.Tenant.ID)); err != nil {
+		return err
+	}
+	return s.credentials.Forget(ctx, state.Tenant.ID)
+}
+
+type revokeSharedRoleStep struct {
+	stepInfo
+	store  Store
+	limits model.RoleLimits
+}
+
+func (s *revokeSharedRoleStep) Apply(ctx context.Context, state *State) error {
+	state.RoleName = RoleName(state.Tenant.ID)
+	return s.store.RevokeSharedTenantRole(ctx, state.RoleName)
+}
+
+func (s *revokeSharedRoleStep) Compensate(ctx context.Context, state *State) error {
+	return s.store.CreateSharedTenantRole(ctx, RoleName(state.Tenant.ID), state.Tenant.ID, s.limits)
+}
+
+type sharedSnapshotStep struct {
+	stepInfo
+	store Store
+}
+
+func (s *sharedSnapshotStep) Apply(ctx context.Context, state *State) error {
+	_, err := s.store.SnapshotSharedTenant(ctx, state.Tenant.ID, SnapshotSchemaName(state.Tenant.ID))
+	return err
+}
+
+// Compensate does nothing; a snapshot is kept even if deprovisioning is undone
+func (s *sharedSnapshotStep) Compensate(ctx context.Context, state *State) error {
+	return nil
+}
+
+type scheduleSharedDropStep struct {
+	stepInfo
+	store Store
+	delay time.Duration
+}
+
+func (s *scheduleSharedDropStep) Apply(ctx context.Context, state *State) error {
+	return s.store.ScheduleSharedMemberDrop(ctx, state.Tenant.ID, time.Now().Add(s.delay))
+}
+
+func (s *scheduleSharedDropStep) Compensate(ctx context.Context, state *State) error {
+	return s.store.CancelSharedMemberDrop(ctx, state.Tenant.ID)
+}
+
+// SharedReclaimer deletes the rows, roles, passwords and database configs of
+// deprovisioned tenants sharing tables. Snapshots are kept.
+func SharedReclaimer(s Store, secrets SecretWriter) Reclaimer {
+	return &sharedReclaimer{store: s, secrets: secrets}
+}
+
+type sharedReclaimer struct {
+	store   Store
+	secrets SecretWriter
+}
+
+func (r *sharedReclaimer) Name() string {
+	return StepDropSharedRows
+}
+
+func (r *sharedReclaimer) Due(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	return r.store.DueSharedDrops(ctx, limit)
+}
+
+// Reclaim forgets the membership last, so a failed reclaim is retried
+func (r *sharedReclaimer) Reclaim(ctx context.Context, tenantID uuid.UUID) error {
+	if err := r.store.DropTenantRole(ctx, RoleName(tenantID)); err != nil {
+		return err
+	}
+	if err := r.secrets.DeleteSecret(ctx, PasswordSecretID(tenantID)); err != nil {
+		return err
+	}
+	if err := r.store.DeleteTenantDatabaseConfig(ctx, tenantID); err != nil {
+		return err
+	}
+	return r.store.RemoveSharedMember(ctx, tenantID)
+}
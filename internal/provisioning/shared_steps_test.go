@@ -0,0 +1,67 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/teresa-solution/tenant-management-service/internal/store"
+)
+
+type fakeSharedStore struct {
+	Store
+	version int
+	applied []int
+}
+
+func (f *fakeSharedStore) SharedTemplateVersion(ctx context.Context) (int, error) {
+	return f.version, nil
+}
+
+func (f *fakeSharedStore) ApplySharedMigration(ctx context.Context, version int, sql string) (bool, error) {
+	if version <= f.version {
+		return false, nil
+	}
+	f.version = version
+	f.applied = append(f.applied, version)
+	return true, nil
+}
+
+func TestSharedSteps(t *testing.T) {
+	cfg := DefaultConfig()
+
+	var names []string
+	for _, step := range SharedSteps(nil, cfg) {
+		names = append(names, step.Name())
+	}
+	assert.Equal(t, []string{StepJoinSharedSchema, StepApplyTemplate, StepCreateRole, StepWriteDBConfig,
+		StepSeedFeatures, StepRegisterDNS, StepNotify}, names)
+
+	names = nil
+	for _, step := range SharedDeprovisionSteps(nil, cfg) {
+		names = append(names, step.Name())
+	}
+	assert.Equal(t, []string{StepRevokeRole, StepBlockConnections, StepDisableRouting, StepSnapshotData,
+		StepScheduleSharedDrop}, names)
+}
+
+func TestSharedTemplateStore(t *testing.T) {
+	s := &fakeSharedStore{version: 1}
+	registry := SharedTemplateStore(s)
+
+	schemas, err := registry.ListTenantSchemas(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, schemas, 1) {
+		assert.Equal(t, store.SharedSchema, schemas[0].SchemaName)
+		assert.Equal(t, 1, schemas[0].TemplateVersion)
+	}
+
+	// Every tenant sees the one version of the shared tables
+	applied, err := registry.ApplyTenantMigration(context.Background(), newState().Tenant.ID, 2, "SELECT 1")
+	assert.NoError(t, err)
+	assert.True(t, applied)
+	version, err := registry.TenantTemplateVersion(context.Background(), newState().Tenant.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, version)
+	assert.Equal(t, []int{2}, s.applied)
+}
@@ -0,0 +1,80 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+// Step is one unit of tenant provisioning. Apply must be idempotent so that a
+// retried job can run it again after a partial failure, and Compensate must
+// undo Apply while tolerating that Apply never ran or only partly ran.
+type Step interface {
+	Name() string
+	Timeout() time.Duration
+	Apply(ctx context.Context, state *State) error
+	Compensate(ctx context.Context, state *State) error
+}
+
+// State carries the tenant being provisioned and what earlier steps produced
+type State struct {
+	Tenant  *model.Tenant
+	JobID   uuid.UUID
+	Attempt int
+	// DBHost is the database host the tenant is placed on
+	DBHost string
+	// DatabaseName is set by the create_database step of dedicated databases
+	DatabaseName string
+	// SchemaName is set by the create_schema step
+	SchemaName string
+	// TemplateVersion is set by the apply_schema_template step
+	TemplateVersion int
+	// RoleName is set by the create_db_role step
+	RoleName string
+	// PasswordSecretID is set by the create_db_role step when the role's
+	// password is kept in a secret store
+	PasswordSecretID string
+	// Hostname is set by the register_dns step
+	Hostname string
+	// Move is the move a move job carries out
+	Move *model.TenantMove
+	// CutOver is set once a moving tenant points at its target shard
+	CutOver bool
+}
+
+// StepError reports which step of a pipeline failed and whether rolling back
+// the completed steps left anything behind
+type StepError struct {
+	Step string
+	Err  error
+	// CompensationErr holds the compensations that failed, if any
+	CompensationErr error
+}
+
+func (e *StepError) Error() string {
+	if e.CompensationErr != nil {
+		return fmt.Sprintf("provisioning step %s failed: %v (compensation incomplete: %v)", e.Step, e.Err, e.CompensationErr)
+	}
+	return fmt.Sprintf("provisioning step %s failed: %v", e.Step, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// stepInfo implements Name and Timeout for the built-in steps
+type stepInfo struct {
+	name    string
+	timeout time.Duration
+}
+
+func (s stepInfo) Name() string {
+	return s.name
+}
+
+func (s stepInfo) Timeout() time.Duration {
+	return s.timeout
+}
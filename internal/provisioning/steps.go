@@ -0,0 +1,485 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"github.com/teresa-solution/tenant-management-service/internal/store"
+	"github.com/teresa-solution/tenant-management-service/internal/tenantschema"
+)
+
+// Names of the built-in steps, as written to tenant_provisioning_logs
+const (
+	StepCreateSchema  = "create_schema"
+	StepApplyTemplate = "apply_schema_template"
+	StepCreateRole    = "create_db_role"
+	StepWriteDBConfig = "write_db_config"
+	StepSeedFeatures  = "seed_features"
+	StepRegisterDNS   = "register_dns"
+	StepNotify        = "notify"
+)
+
+// Connection pool settings written for new tenants, matching the column
+// defaults; the pool size is the role's connection limit
+const (
+	defaultIdleConns    = 2
+	defaultConnLifetime = 60
+)
+
+// DefaultRoleLimits are the limits of tenant roles when none are configured
+var DefaultRoleLimits = model.RoleLimits{StatementTimeout: 30 * time.Second, ConnectionLimit: 10}
+
+const defaultStepTimeout = 30 * time.Second
+
+// Schemas creates and tears down tenant schemas and their roles. The
+// registry database implements it for the schemas it holds, ShardSchemas for
+// those on a shard.
+type Schemas interface {
+	CreateTenantSchema(ctx context.Context, tenantID uuid.UUID, subdomain string) error
+	GetTenantSchema(ctx context.Context, tenantID uuid.UUID) (string, error)
+	DropTenantSchema(ctx context.Context, tenantID uuid.UUID) error
+	tenantschema.Store
+	CreateTenantRole(ctx context.Context, role, schemaName string, limits model.RoleLimits) error
+	PasswordSetter
+	DropTenantRole(ctx context.Context, role string) error
+	RevokeTenantRole(ctx context.Context, role, schemaName string) error
+	BlockTenantConnections(ctx context.Context, role string) error
+	UnblockTenantConnections(ctx context.Context, role string, limit int) error
+	SnapshotTenantSchema(ctx context.Context, tenantID uuid.UUID, snapshotSchema string) (string, error)
+	ScheduleTenantSchemaDrop(ctx context.Context, tenantID uuid.UUID, at time.Time) error
+	CancelTenantSchemaDrop(ctx context.Context, tenantID uuid.UUID) error
+	DueSchemaDrops(ctx context.Context, limit int) ([]uuid.UUID, error)
+}
+
+// Store is the persistence the built-in steps need
+type Store interface {
+	LogWriter
+	Schemas
+	UpsertTenantDatabaseConfig(ctx context.Context, cfg *model.TenantDatabaseConfig) error
+	DeleteTenantDatabaseConfig(ctx context.Context, tenantID uuid.UUID) error
+	SeedTenantFeatures(ctx context.Context, tenantID uuid.UUID, features []string) error
+	DeleteTenantFeatures(ctx context.Context, tenantID uuid.UUID) error
+	RecordDedicatedDatabase(ctx context.Context, d *model.DedicatedDatabase) error
+	ListDedicatedDatabases(ctx context.Context, host string) ([]model.DedicatedDatabase, error)
+	SetDedicatedTemplateVersion(ctx context.Context, tenantID uuid.UUID, version int) error
+	DeleteDedicatedDatabase(ctx context.Context, tenantID uuid.UUID) error
+	ScheduleDedicatedDatabaseDrop(ctx context.Context, tenantID uuid.UUID, at time.Time) error
+	CancelDedicatedDatabaseDrop(ctx context.Context, tenantID uuid.UUID) error
+	DueDatabaseDrops(ctx context.Context, limit int) ([]uuid.UUID, error)
+	SharedTemplateVersion(ctx context.Context) (int, error)
+	ApplySharedMigration(ctx context.Context, version int, sql string) (bool, error)
+	AddSharedMember(ctx context.Context, tenantID uuid.UUID) error
+	RemoveSharedMember(ctx context.Context, tenantID uuid.UUID) error
+	CreateSharedTenantRole(ctx context.Context, role string, tenantID uuid.UUID, limits model.RoleLimits) error
+	RevokeSharedTenantRole(ctx context.Context, role string) error
+	SnapshotSharedTenant(ctx context.Context, tenantID uuid.UUID, snapshotSchema string) (string, error)
+	ScheduleSharedMemberDrop(ctx context.Context, tenantID uuid.UUID, at time.Time) error
+	CancelSharedMemberDrop(ctx context.Context, tenantID uuid.UUID) error
+	DueSharedDrops(ctx context.Context, limit int) ([]uuid.UUID, error)
+	GetShardSchema(ctx context.Context, tenantID uuid.UUID) (*model.ShardSchema, error)
+	ListShardSchemas(ctx context.Context, shardID uuid.UUID) ([]model.ShardSchema, error)
+	SetShardTemplateVersion(ctx context.Context, tenantID uuid.UUID, version int) error
+	ScheduleShardSchemaDrop(ctx context.Context, tenantID uuid.UUID, at time.Time) error
+	CancelShardSchemaDrop(ctx context.Context, tenantID uuid.UUID) error
+	DueShardSchemaDrops(ctx context.Context, shardID uuid.UUID, limit int) ([]uuid.UUID, error)
+	DeleteShardSchema(ctx context.Context, tenantID uuid.UUID) error
+	RecordShardSnapshot(ctx context.Context, tenantID, shardID uuid.UUID, sourceSchema, snapshotSchema string) error
+	GetShard(ctx context.Context, id uuid.UUID) (*model.Shard, error)
+	SwitchTenantShard(ctx context.Context, tenantID uuid.UUID, shard *model.Shard) error
+	SetTenantMaintenance(ctx context.Context, tenantID uuid.UUID, on bool) error
+	ScheduleMovedSchemaDrop(ctx context.Context, moveID uuid.UUID, at time.Time) error
+	DueMovedSchemaDrops(ctx context.Context, shardID uuid.UUID, limit int) ([]uuid.UUID, error)
+	MarkMovedSchemaDropped(ctx context.Context, tenantID, shardID uuid.UUID) error
+	SetTenantDNSRecord(ctx context.Context, tenantID uuid.UUID, record *model.DNSRecord) error
+	GetTenantDNSRecord(ctx context.Context, tenantID uuid.UUID) (*model.DNSRecord, error)
+}
+
+// DefaultTierFeatures lists the features enabled for new tenants of each tier
+var DefaultTierFeatures = map[string][]string{
+	model.TierFree:       {"basic_reporting"},
+	model.TierBasic:      {"basic_reporting", "api_access"},
+	model.TierPremium:    {"basic_reporting", "api_access", "advanced_reporting", "custom_branding"},
+	model.TierEnterprise: {"basic_reporting", "api_access", "advanced_reporting", "custom_branding", "sso", "audit_export"},
+}
+
+// Config holds the settings of the built-in steps
+type Config struct {
+	// DBPort and DBName locate the database tenant schemas live in
+	DBPort int
+	DBName string
+	// BaseDomain is appended to a tenant's subdomain to form its hostname
+	BaseDomain string
+	// DNSTarget is what the DNS record of a tenant hostname points at: an IP
+	// address, or a hostname for a CNAME. No record is created when it is
+	// empty.
+	DNSTarget string
+	// DNSTTL is the TTL of the DNS records of tenant hostnames, in seconds
+	DNSTTL int
+	// Template builds every new tenant schema
+	Template *tenantschema.Template
+	// SharedTemplate builds the tables tenants of the shared isolation mode
+	// keep their rows in
+	SharedTemplate *tenantschema.Template
+	// TierFeatures lists the features enabled for new tenants of each tier
+	TierFeatures map[string][]string
+	// StepTimeout bounds each step
+	StepTimeout time.Duration
+	// MoveStepTimeout bounds the steps copying and comparing a moving
+	// tenant's data, which take longer the more data the tenant has
+	MoveStepTimeout time.Duration
+	// SchemaDropDelay is how long a deprovisioned tenant's schema is kept
+	// before it is dropped
+	SchemaDropDelay time.Duration
+	DNS             DNSProvider
+	Notifier        Notifier
+	// Secrets keeps the passwords generated for tenant roles
+	Secrets SecretWriter
+	// RoleLimits bounds the database login role of every tenant
+	RoleLimits model.RoleLimits
+}
+
+// DefaultConfig returns the step settings used when none are configured
+func DefaultConfig() Config {
+	return Config{
+		DBPort:          5432,
+		DBName:          "tenant_registry",
+		BaseDomain:      "tenants.local",
+		DNSTTL:          300,
+		Template:        &tenantschema.Template{},
+		SharedTemplate:  &tenantschema.Template{},
+		TierFeatures:    DefaultTierFeatures,
+		StepTimeout:     defaultStepTimeout,
+		MoveStepTimeout: 30 * time.Minute,
+		SchemaDropDelay: 7 * 24 * time.Hour,
+		DNS:             LogDNSProvider{},
+		Notifier:        LogNotifier{},
+		Secrets:         LogSecretWriter{},
+		RoleLimits:      DefaultRoleLimits,
+	}
+}
+
+// Validate checks that the step settings are usable
+func (c Config) Validate() error {
+	if c.StepTimeout <= 0 || c.MoveStepTimeout <= 0 {
+		return errors.New("step timeouts must be positive")
+	}
+	if c.SchemaDropDelay < 0 {
+		return errors.New("schema drop delay must not be negative")
+	}
+	if c.Template == nil || c.SharedTemplate == nil {
+		return errors.New("tenant schema and shared templates are required")
+	}
+	if c.BaseDomain == "" {
+		return errors.New("base domain is required")
+	}
+	if c.DNSTTL <= 0 {
+		return errors.New("DNS TTL must be positive")
+	}
+	if c.RoleLimits.StatementTimeout < 0 {
+		return errors.New("tenant role statement timeout must not be negative")
+	}
+	if c.RoleLimits.ConnectionLimit < defaultIdleConns {
+		return fmt.Errorf("tenant role connection limit must be at least %d", defaultIdleConns)
+	}
+	if c.DNS == nil || c.Notifier == nil || c.Secrets == nil {
+		return errors.New("DNS provider, notifier and secret writer are required")
+	}
+	return nil
+}
+
+// DefaultSteps returns the built-in steps in the order they must run
+func DefaultSteps(s Store, cfg Config) []Step {
+	return schemaSteps(s, s, cfg.DBPort, cfg.DBName, cfg)
+}
+
+// schemaSteps returns the steps provisioning a tenant schema in schemas,
+// which live in database dbName on port
+func schemaSteps(s Store, schemas Schemas, port int, dbName string, cfg Config) []Step {
+	return []Step{
+		&createSchemaStep{stepInfo{StepCreateSchema, cfg.StepTimeout}, schemas},
+		&applyTemplateStep{stepInfo{StepApplyTemplate, cfg.StepTimeout}, schemas, cfg.Template},
+		&createRoleStep{stepInfo{StepCreateRole, cfg.StepTimeout}, schemas, cfg.RoleLimits, NewCredentials(schemas, cfg.Secrets)},
+		&writeDBConfigStep{stepInfo{StepWriteDBConfig, cfg.StepTimeout}, s, model.IsolationSchema, port, dbName, cfg.RoleLimits},
+		&seedFeaturesStep{stepInfo{StepSeedFeatures, cfg.StepTimeout}, s, cfg.TierFeatures},
+		&registerDNSStep{stepInfo{StepRegisterDNS, cfg.StepTimeout}, NewTenantDNS(s, cfg)},
+		&notifyStep{stepInfo{StepNotify, cfg.StepTimeout}, cfg.Notifier},
+	}
+}
+
+// RoleName returns the database role of a tenant
+func RoleName(tenantID uuid.UUID) string {
+	return "tenant_" + strings.ReplaceAll(tenantID.String(), "-", "")
+}
+
+// PasswordSecretID returns the secret holding a tenant role's password
+func PasswordSecretID(tenantID uuid.UUID) string {
+	return fmt.Sprintf("tenants/%s/db-password", tenantID)
+}
+
+// Hostname returns the hostname of a subdomain under baseDomain
+func Hostname(subdomain, baseDomain string) string {
+	return subdomain + "." + strings.TrimPrefix(baseDomain, ".")
+}
+
+type createSchemaStep struct {
+	stepInfo
+	store Schemas
+}
+
+func (s *createSchemaStep) Apply(ctx context.Context, state *State) error {
+	schemaName, err := s.store.GetTenantSchema(ctx, state.Tenant.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		if err := s.store.CreateTenantSchema(ctx, state.Tenant.ID, state.Tenant.Subdomain); err != nil {
+			return err
+		}
+		schemaName, err = s.store.GetTenantSchema(ctx, state.Tenant.ID)
+	}
+	if err != nil {
+		return err
+	}
+	state.SchemaName = schemaName
+	return nil
+}
+
+func (s *createSchemaStep) Compensate(ctx context.Context, state *State) error {
+	return s.store.DropTenantSchema(ctx, state.Tenant.ID)
+}
+
+type applyTemplateStep struct {
+	stepInfo
+	store    tenantschema.Store
+	template *tenantschema.Template
+}
+
+// Apply brings the schema up to the template version; on a retry only the
+// migrations not yet recorded run
+func (s *applyTemplateStep) Apply(ctx context.Context, state *State) error {
+	_, version, err := tenantschema.Migrate(ctx, s.store, state.Tenant.ID, s.template)
+	state.TemplateVersion = version
+	return err
+}
+
+// Compensate does nothing; the template's objects go with the schema
+func (s *applyTemplateStep) Compensate(ctx context.Context, state *State) error {
+	return nil
+}
+
+type createRoleStep struct {
+	stepInfo
+	store       Schemas
+	limits      model.RoleLimits
+	credentials *Credentials
+}
+
+// Apply gives the role a new password on every attempt, like createLoginRoleStep
+func (s *createRoleStep) Apply(ctx context.Context, state *State) error {
+	role := RoleName(state.Tenant.ID)
+	if err := s.store.CreateTenantRole(ctx, role, state.SchemaName, s.limits); err != nil {
+		return err
+	}
+	secretID, err := s.credentials.Rotate(ctx, state.Tenant.ID)
+	if err != nil {
+		return err
+	}
+	state.RoleName = role
+	state.PasswordSecretID = secretID
+	return nil
+}
+
+func (s *createRoleStep) Compensate(ctx context.Context, state *State) error {
+	if err := s.store.DropTenantRole(ctx, RoleName(state.Tenant.ID)); err != nil {
+		return err
+	}
+	return s.credentials.Forget(ctx, state.Tenant.ID)
+}
+
+type writeDBConfigStep struct {
+	stepInfo
+	store Store
+	mode  string
+	port  int
+	// dbName is used unless an earlier step created a database for the tenant
+	dbName string
+	limits model.RoleLimits
+}
+
+func (s *writeDBConfigStep) Apply(ctx context.Context, state *State) error {
+	dbName := s.dbName
+	if state.DatabaseName != "" {
+		dbName = state.DatabaseName
+	}
+	return s.store.UpsertTenantDatabaseConfig(ctx, &model.TenantDatabaseConfig{
+		TenantID:                  state.Tenant.ID,
+		Host:                      state.DBHost,
+		Port:                      s.port,
+		DatabaseName:              dbName,
+		SchemaName:                state.SchemaName,
+		Username:                  state.RoleName,
+		PasswordSecretID:          state.PasswordSecretID,
+		MaxConnections:            s.limits.ConnectionLimit,
+		IdleConnections:           defaultIdleConns,
+		ConnectionLifetimeMinutes: defaultConnLifetime,
+		IsolationMode:             s.mode,
+	})
+}
+
+func (s *writeDBConfigStep) Compensate(ctx context.Context, state *State) error {
+	return s.store.DeleteTenantDatabaseConfig(ctx, state.Tenant.ID)
+}
+
+type seedFeaturesStep struct {
+	stepInfo
+	store    Store
+	features map[string][]string
+}
+
+func (s *seedFeaturesStep) Apply(ctx context.Context, state *State) error {
+	features := s.features[state.Tenant.Tier]
+	if len(features) == 0 {
+		return nil
+	}
+	return s.store.SeedTenantFeatures(ctx, state.Tenant.ID, features)
+}
+
+func (s *seedFeaturesStep) Compensate(ctx context.Context, state *State) error {
+	return s.store.DeleteTenantFeatures(ctx, state.Tenant.ID)
+}
+
+// TenantDNS publishes the DNS records of tenant hostnames and keeps the
+// record it published in tenant_specific_configs.dns_record
+type TenantDNS struct {
+	store      Store
+	provider   DNSProvider
+	baseDomain string
+	target     string
+	ttl        int
+}
+
+// NewTenantDNS creates a TenantDNS publishing records with the provider,
+// base domain, target and TTL of cfg
+func NewTenantDNS(s Store, cfg Config) *TenantDNS {
+	return &TenantDNS{store: s, provider: cfg.DNS, baseDomain: cfg.BaseDomain, target: cfg.DNSTarget, ttl: cfg.DNSTTL}
+}
+
+// record returns the record a tenant's hostname is published with, if any
+func (d *TenantDNS) record(tenant *model.Tenant) (model.DNSRecord, bool) {
+	if d.target == "" {
+		return model.DNSRecord{}, false
+	}
+	return model.NewDNSRecord(Hostname(tenant.Subdomain, d.baseDomain), d.target, d.ttl), true
+}
+
+// publish creates the record of a tenant's hostname and records it
+func (d *TenantDNS) publish(ctx context.Context, tenant *model.Tenant) error {
+	record, ok := d.record(tenant)
+	if !ok {
+		return nil
+	}
+	if err := d.provider.CreateRecord(ctx, record); err != nil {
+		return err
+	}
+	return d.store.SetTenantDNSRecord(ctx, tenant.ID, &record)
+}
+
+// withdraw deletes the record published for a tenant's hostname. Tenants
+// without a recorded one, such as those published before records were kept,
+// have the record they would get now deleted.
+func (d *TenantDNS) withdraw(ctx context.Context, tenant *model.Tenant) error {
+	record, err := d.store.GetTenantDNSRecord(ctx, tenant.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		current, ok := d.record(tenant)
+		if !ok {
+			return nil
+		}
+		record = &current
+	} else if err != nil {
+		return err
+	}
+	if err := d.provider.DeleteRecord(ctx, *record); err != nil {
+		return err
+	}
+	return d.store.SetTenantDNSRecord(ctx, tenant.ID, nil)
+}
+
+// Rename moves a provisioned tenant's record to the hostname of subdomain.
+// The new record is published before save stores the rename and the old one
+// is withdrawn after, so the tenant stays reachable throughout. If save fails
+// the new record is withdrawn again and the old one stays recorded.
+func (d *TenantDNS) Rename(ctx context.Context, tenant *model.Tenant, subdomain string, save func(ctx context.Context) error) error {
+	old, err := d.store.GetTenantDNSRecord(ctx, tenant.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		if current, ok := d.record(tenant); ok {
+			old = &current
+		}
+	} else if err != nil {
+		return err
+	}
+
+	renamed := *tenant
+	renamed.Subdomain = subdomain
+	if err := d.publish(ctx, &renamed); err != nil {
+		return err
+	}
+	if err := save(ctx); err != nil {
+		if record, ok := d.record(&renamed); ok {
+			if undoErr := d.provider.DeleteRecord(ctx, record); undoErr != nil {
+				log.Error().Err(undoErr).Str("tenant_id", tenant.ID.String()).Str("record", record.String()).Msg("Failed to withdraw DNS record of failed rename")
+			}
+		}
+		if undoErr := d.store.SetTenantDNSRecord(ctx, tenant.ID, old); undoErr != nil {
+			log.Error().Err(undoErr).Str("tenant_id", tenant.ID.String()).Msg("Failed to restore DNS record of failed rename")
+		}
+		return err
+	}
+	if old == nil {
+		return nil
+	}
+	if err := d.provider.DeleteRecord(ctx, *old); err != nil {
+		// The rename is saved; the old hostname keeps resolving until the
+		// record is removed by hand
+		log.Error().Err(err).Str("tenant_id", tenant.ID.String()).Str("record", old.String()).Msg("Failed to withdraw DNS record of old subdomain")
+	}
+	return nil
+}
+
+type registerDNSStep struct {
+	stepInfo
+	dns *TenantDNS
+}
+
+func (s *registerDNSStep) Apply(ctx context.Context, state *State) error {
+	if err := s.dns.publish(ctx, state.Tenant); err != nil {
+		return err
+	}
+	state.Hostname = Hostname(state.Tenant.Subdomain, s.dns.baseDomain)
+	return nil
+}
+
+func (s *registerDNSStep) Compensate(ctx context.Context, state *State) error {
+	return s.dns.withdraw(ctx, state.Tenant)
+}
+
+type notifyStep struct {
+	stepInfo
+	notifier Notifier
+}
+
+func (s *notifyStep) Apply(ctx context.Context, state *State) error {
+	return s.notifier.Notify(ctx, Event{
+		Type:      EventTenantProvisioned,
+		TenantID:  state.Tenant.ID,
+		Subdomain: state.Tenant.Subdomain,
+		Hostname:  state.Hostname,
+	})
+}
+
+// Compensate does nothing; a notification cannot be taken back
+func (s *notifyStep) Compensate(ctx context.Context, state *State) error {
+	return nil
+}
@@ -0,0 +1,213 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Resource names a quota-limited resource
+type Resource string
+
+// Quota-limited resources. Tenants are limited per tier; everything else is
+// limited per tenant.
+const (
+	ResourceTenants            Resource = "tenants"
+	ResourceContacts           Resource = "contacts"
+	ResourceAPIKeys            Resource = "api_keys"
+	ResourceConfigKeys         Resource = "config_keys"
+	ResourceSchemaStorageBytes Resource = "schema_storage_bytes"
+)
+
+// Resources lists every limited tenant-scoped resource, in reporting order
+var Resources = []Resource{ResourceContacts, ResourceAPIKeys, ResourceConfigKeys}
+
+// ReportOnly lists resources whose use is reported but never limited. Schema
+// storage can only be measured for schemas in the registry database, not for
+// tenants on shards, in dedicated databases or in the shared tables.
+var ReportOnly = []Resource{ResourceSchemaStorageBytes}
+
+// Unlimited is the limit value meaning no limit applies
+const Unlimited int64 = -1
+
+// ValidResource reports whether name is a known resource
+func ValidResource(name string) bool {
+	if Resource(name) == ResourceTenants {
+		return true
+	}
+	for _, r := range Resources {
+		if string(r) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Store provides quota definitions and usage counts
+type Store interface {
+	TierQuotaLimits(ctx context.Context, tier string) (map[string]int64, error)
+	TenantQuotaOverrides(ctx context.Context, tenantID uuid.UUID) (map[string]int64, error)
+	CountTenantsInTier(ctx context.Context, tier string) (int64, error)
+	CountTenantResource(ctx context.Context, tenantID uuid.UUID, resource string) (int64, error)
+	// TenantSchemaSize reports ok=false when the size of the tenant's schema
+	// cannot be measured
+	TenantSchemaSize(ctx context.Context, tenantID uuid.UUID) (size int64, ok bool, err error)
+}
+
+// Usage reports the limit and current use of one resource
+type Usage struct {
+	Resource   Resource
+	Limit      int64
+	Used       int64
+	Overridden bool
+}
+
+// ExceededError is returned when an operation would take a resource past its
+// limit. It converts to a gRPC ResourceExhausted status carrying QuotaFailure
+// details.
+type ExceededError struct {
+	Subject  string
+	Resource Resource
+	Limit    int64
+	Used     int64
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("quota exceeded for %s: %s limit is %d, %d in use", e.Subject, e.Resource, e.Limit, e.Used)
+}
+
+// GRPCStatus implements the interface used by status.FromError
+func (e *ExceededError) GRPCStatus() *status.Status {
+	st := status.New(codes.ResourceExhausted, e.Error())
+	detailed, err := st.WithDetails(&errdetails.QuotaFailure{
+		Violations: []*errdetails.QuotaFailure_Violation{{
+			Subject:     e.Subject,
+			Description: fmt.Sprintf("%s limit of %d reached (%d in use)", e.Resource, e.Limit, e.Used),
+		}},
+	})
+	if err != nil {
+		return st
+	}
+	return detailed
+}
+
+// Enforcer checks resource usage against tier limits and per-tenant overrides
+type Enforcer struct {
+	store Store
+}
+
+// NewEnforcer creates a new Enforcer
+func NewEnforcer(store Store) *Enforcer {
+	return &Enforcer{store: store}
+}
+
+// CheckTenantCreation verifies that another tenant fits into tier. Like the
+// other checks it is advisory under concurrency: two simultaneous requests may
+// both pass when a single slot is left.
+func (e *Enforcer) CheckTenantCreation(ctx context.Context, tier string) error {
+	limits, err := e.store.TierQuotaLimits(ctx, tierOrDefault(tier))
+	if err != nil {
+		return err
+	}
+	limit, ok := limits[string(ResourceTenants)]
+	if !ok || limit == Unlimited {
+		return nil
+	}
+	used, err := e.store.CountTenantsInTier(ctx, tierOrDefault(tier))
+	if err != nil {
+		return err
+	}
+	if used+1 > limit {
+		return &ExceededError{Subject: "tier:" + tierOrDefault(tier), Resource: ResourceTenants, Limit: limit, Used: used}
+	}
+	return nil
+}
+
+// Check verifies that tenant can use delta more units of resource
+func (e *Enforcer) Check(ctx context.Context, tenant *model.Tenant, resource Resource, delta int64) error {
+	limit, _, err := e.limit(ctx, tenant, resource)
+	if err != nil || limit == Unlimited {
+		return err
+	}
+	used, err := e.used(ctx, tenant, resource)
+	if err != nil {
+		return err
+	}
+	if used+delta > limit {
+		return &ExceededError{Subject: "tenant:" + tenant.ID.String(), Resource: resource, Limit: limit, Used: used}
+	}
+	return nil
+}
+
+// Usage reports the limit and current use of every tenant-scoped resource.
+// Report-only resources follow with no limit, when their use can be measured.
+func (e *Enforcer) Usage(ctx context.Context, tenant *model.Tenant) ([]Usage, error) {
+	tierLimits, err := e.store.TierQuotaLimits(ctx, tierOrDefault(tenant.Tier))
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := e.store.TenantQuotaOverrides(ctx, tenant.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]Usage, 0, len(Resources))
+	for _, resource := range Resources {
+		limit, overridden := resolveLimit(tierLimits, overrides, resource)
+		used, err := e.used(ctx, tenant, resource)
+		if err != nil {
+			return nil, err
+		}
+		usage = append(usage, Usage{Resource: resource, Limit: limit, Used: used, Overridden: overridden})
+	}
+
+	size, ok, err := e.store.TenantSchemaSize(ctx, tenant.ID)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		usage = append(usage, Usage{Resource: ResourceSchemaStorageBytes, Limit: Unlimited, Used: size})
+	}
+	return usage, nil
+}
+
+func (e *Enforcer) limit(ctx context.Context, tenant *model.Tenant, resource Resource) (int64, bool, error) {
+	tierLimits, err := e.store.TierQuotaLimits(ctx, tierOrDefault(tenant.Tier))
+	if err != nil {
+		return 0, false, err
+	}
+	overrides, err := e.store.TenantQuotaOverrides(ctx, tenant.ID)
+	if err != nil {
+		return 0, false, err
+	}
+	limit, overridden := resolveLimit(tierLimits, overrides, resource)
+	return limit, overridden, nil
+}
+
+func (e *Enforcer) used(ctx context.Context, tenant *model.Tenant, resource Resource) (int64, error) {
+	return e.store.CountTenantResource(ctx, tenant.ID, string(resource))
+}
+
+// resolveLimit picks the tenant override over the tier limit. Resources
+// without any definition are unlimited.
+func resolveLimit(tierLimits, overrides map[string]int64, resource Resource) (int64, bool) {
+	if limit, ok := overrides[string(resource)]; ok {
+		return limit, true
+	}
+	if limit, ok := tierLimits[string(resource)]; ok {
+		return limit, false
+	}
+	return Unlimited, false
+}
+
+func tierOrDefault(tier string) string {
+	if tier == "" {
+		return model.TierBasic
+	}
+	return tier
+}
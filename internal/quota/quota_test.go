@@ -0,0 +1,117 @@
+package quota
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeStore struct {
+	tiers     map[string]map[string]int64
+	overrides map[string]int64
+	counts    map[string]int64
+	tenants   int64
+	size      int64
+	sized     bool
+}
+
+func (f *fakeStore) TierQuotaLimits(ctx context.Context, tier string) (map[string]int64, error) {
+	return f.tiers[tier], nil
+}
+
+func (f *fakeStore) TenantQuotaOverrides(ctx context.Context, tenantID uuid.UUID) (map[string]int64, error) {
+	return f.overrides, nil
+}
+
+func (f *fakeStore) CountTenantsInTier(ctx context.Context, tier string) (int64, error) {
+	return f.tenants, nil
+}
+
+func (f *fakeStore) CountTenantResource(ctx context.Context, tenantID uuid.UUID, resource string) (int64, error) {
+	return f.counts[resource], nil
+}
+
+func (f *fakeStore) TenantSchemaSize(ctx context.Context, tenantID uuid.UUID) (int64, bool, error) {
+	return f.size, f.sized, nil
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		tiers: map[string]map[string]int64{
+			model.TierFree:  {"tenants": 2, "api_keys": 2, "contacts": 1},
+			model.TierBasic: {"tenants": -1, "api_keys": 5},
+		},
+		overrides: map[string]int64{},
+		counts:    map[string]int64{"api_keys": 2},
+	}
+}
+
+func TestEnforcer_Check(t *testing.T) {
+	store := newFakeStore()
+	e := NewEnforcer(store)
+	tenant := &model.Tenant{ID: uuid.New(), Tier: model.TierFree}
+
+	err := e.Check(context.Background(), tenant, ResourceAPIKeys, 1)
+	assert.Error(t, err)
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+	assert.Len(t, st.Details(), 1)
+	failure, ok := st.Details()[0].(*errdetails.QuotaFailure)
+	assert.True(t, ok)
+	assert.Equal(t, "tenant:"+tenant.ID.String(), failure.Violations[0].Subject)
+
+	store.overrides["api_keys"] = 3
+	assert.NoError(t, e.Check(context.Background(), tenant, ResourceAPIKeys, 1))
+
+	store.overrides["api_keys"] = Unlimited
+	assert.NoError(t, e.Check(context.Background(), tenant, ResourceAPIKeys, 100))
+
+	// Resources without a definition are unlimited
+	assert.NoError(t, e.Check(context.Background(), tenant, ResourceConfigKeys, 100))
+}
+
+func TestEnforcer_CheckTenantCreation(t *testing.T) {
+	store := newFakeStore()
+	e := NewEnforcer(store)
+
+	store.tenants = 1
+	assert.NoError(t, e.CheckTenantCreation(context.Background(), model.TierFree))
+	store.tenants = 2
+	assert.Error(t, e.CheckTenantCreation(context.Background(), model.TierFree))
+	assert.NoError(t, e.CheckTenantCreation(context.Background(), model.TierBasic))
+}
+
+func TestEnforcer_Usage(t *testing.T) {
+	store := newFakeStore()
+	store.overrides["contacts"] = 4
+	store.tiers[model.TierFree]["schema_storage_bytes"] = 1024
+	store.size, store.sized = 2048, true
+	e := NewEnforcer(store)
+	tenant := &model.Tenant{ID: uuid.New(), Tier: model.TierFree}
+
+	usage, err := e.Usage(context.Background(), tenant)
+	assert.NoError(t, err)
+	assert.Len(t, usage, len(Resources)+1)
+	byResource := make(map[Resource]Usage)
+	for _, u := range usage {
+		byResource[u.Resource] = u
+	}
+	assert.Equal(t, Usage{Resource: ResourceContacts, Limit: 4, Used: 0, Overridden: true}, byResource[ResourceContacts])
+	assert.Equal(t, Usage{Resource: ResourceAPIKeys, Limit: 2, Used: 2}, byResource[ResourceAPIKeys])
+	assert.Equal(t, int64(2048), byResource[ResourceSchemaStorageBytes].Used)
+	assert.Equal(t, Unlimited, byResource[ResourceSchemaStorageBytes].Limit)
+	assert.False(t, ValidResource(string(ResourceSchemaStorageBytes)))
+
+	// Schemas outside the registry database are not reported
+	store.sized = false
+	usage, err = e.Usage(context.Background(), tenant)
+	assert.NoError(t, err)
+	assert.Len(t, usage, len(Resources))
+}
@@ -0,0 +1,176 @@
+package secrets
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// fileFormat is authenticated with every secrets file, so a file written by
+// another format is rejected rather than misread
+var fileFormat = []byte("tenant-management-service/secrets/v1")
+
+// FileStore keeps secrets in one local file, encrypted as a whole with
+// AES-256-GCM. Every change rewrites the file through a rename, so a crash
+// never leaves it half written. It serialises changes within one process
+// only; replicas must not share a file.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+	aead cipher.AEAD
+}
+
+// NewFileStore opens the secrets file at path, encrypted with a 32-byte key.
+// The file is created by the first PutSecret.
+func NewFileStore(path string, key []byte) (*FileStore, error) {
+	if len(key) != 32 {
+		return nil, errors.New("secrets file key must be 32 bytes")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	s := &FileStore{path: path, aead: aead}
+	// Fail at startup rather than on the first secret when the key is wrong
+	if _, err := s.read(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// LoadKeyFile reads a base64 encoded 32-byte key
+func LoadKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets key: %w", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("secrets key in %s must be 32 base64 encoded bytes", path)
+	}
+	return key, nil
+}
+
+func (s *FileStore) GetSecret(ctx context.Context, id string) (string, error) {
+	if err := checkID(id); err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secrets, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	value, ok := secrets[id]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func (s *FileStore) KeepsSecrets() bool {
+	return true
+}
+
+func (s *FileStore) PutSecret(ctx context.Context, id, value string) error {
+	if err := checkID(id); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secrets, err := s.read()
+	if err != nil {
+		return err
+	}
+	secrets[id] = value
+	return s.write(secrets)
+}
+
+func (s *FileStore) DeleteSecret(ctx context.Context, id string) error {
+	if err := checkID(id); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secrets, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[id]; !ok {
+		return nil
+	}
+	delete(secrets, id)
+	return s.write(secrets)
+}
+
+// read decrypts the secrets in the file; a missing file holds none
+func (s *FileStore) read() (map[string]string, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]string), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := s.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, fmt.Errorf("secrets file %s is truncated", s.path)
+	}
+	plaintext, err := s.aead.Open(nil, data[:nonceSize], data[nonceSize:], fileFormat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secrets file %s; is the key right?", s.path)
+	}
+	secrets := make(map[string]string)
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("secrets file %s: %w", s.path, err)
+	}
+	return secrets, nil
+}
+
+// write encrypts secrets with a fresh nonce and replaces the file with them
+func (s *FileStore) write(secrets map[string]string) error {
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	data := s.aead.Seal(nonce, nonce, plaintext, fileFormat)
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
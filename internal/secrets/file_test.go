@@ -0,0 +1,76 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	key := bytes.Repeat([]byte{7}, 32)
+
+	s, err := NewFileStore(path, key)
+	require.NoError(t, err)
+	_, err = s.GetSecret(ctx, "tenants/1/db-password")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, s.PutSecret(ctx, "tenants/1/db-password", "hunter2"))
+	require.NoError(t, s.PutSecret(ctx, "service/db-password", "s3cret"))
+	require.NoError(t, s.PutSecret(ctx, "tenants/1/db-password", "rotated"))
+
+	// The file is encrypted and only readable by the service
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "rotated")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// A second store with the same key sees the secrets
+	reopened, err := NewFileStore(path, key)
+	require.NoError(t, err)
+	value, err := reopened.GetSecret(ctx, "tenants/1/db-password")
+	require.NoError(t, err)
+	assert.Equal(t, "rotated", value)
+
+	require.NoError(t, reopened.DeleteSecret(ctx, "tenants/1/db-password"))
+	require.NoError(t, reopened.DeleteSecret(ctx, "tenants/1/db-password"))
+	_, err = reopened.GetSecret(ctx, "tenants/1/db-password")
+	assert.ErrorIs(t, err, ErrNotFound)
+	value, err = reopened.GetSecret(ctx, "service/db-password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", value)
+
+	_, err = NewFileStore(path, bytes.Repeat([]byte{8}, 32))
+	assert.ErrorContains(t, err, "failed to decrypt")
+}
+
+func TestFileStore_RejectsInvalidIDs(t *testing.T) {
+	s, err := NewFileStore(filepath.Join(t.TempDir(), "secrets.enc"), bytes.Repeat([]byte{7}, 32))
+	require.NoError(t, err)
+	for _, id := range []string{"", "/tenants/1", "tenants//1", "tenants/../service", "tenants/1/", "a b"} {
+		assert.Error(t, s.PutSecret(context.Background(), id, "x"), id)
+	}
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "key")
+	key := bytes.Repeat([]byte{1}, 32)
+	require.NoError(t, os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0o600))
+	loaded, err := LoadKeyFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, key, loaded)
+
+	require.NoError(t, os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(key[:16])), 0o600))
+	_, err = LoadKeyFile(path)
+	assert.Error(t, err)
+}
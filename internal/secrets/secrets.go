@@ -0,0 +1,127 @@
+// Package secrets keeps the credentials the service generates and the ones
+// it logs in with outside the registry database and the command line.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SecretStore keeps secrets by ID: the passwords of tenant roles, under the
+// password_secret_id of their database config, and the passwords of the
+// service's own logins. It satisfies provisioning.SecretWriter.
+type SecretStore interface {
+	// GetSecret returns the value stored under id, or ErrNotFound
+	GetSecret(ctx context.Context, id string) (string, error)
+	// PutSecret stores value under id, replacing any previous value
+	PutSecret(ctx context.Context, id, value string) error
+	// DeleteSecret removes id; removing an unknown secret is not an error
+	DeleteSecret(ctx context.Context, id string) error
+	// KeepsSecrets reports whether stored secrets can be read back, which
+	// every backend does
+	KeepsSecrets() bool
+}
+
+// ErrNotFound is returned for a secret that was never stored or was deleted
+var ErrNotFound = errors.New("secret not found")
+
+// Backends a SecretStore can be opened with
+const (
+	// BackendNone keeps no secrets; generated credentials are discarded
+	BackendNone = "none"
+	// BackendFile keeps secrets in a local file encrypted with AES-256-GCM
+	BackendFile = "file"
+	// BackendVault keeps secrets in a Vault KV version 2 secrets engine
+	BackendVault = "vault"
+)
+
+// Config selects and configures the backend of a SecretStore
+type Config struct {
+	Backend string
+
+	// File is the encrypted secrets file of the file backend
+	File string
+	// KeyFile holds the base64 encoded 32-byte key File is encrypted with
+	KeyFile string
+
+	Vault VaultConfig
+	// VaultTokenFile holds the Vault token; the VAULT_TOKEN environment
+	// variable is used when it is empty
+	VaultTokenFile string
+}
+
+// Validate checks that the settings of the selected backend are complete
+func (c Config) Validate() error {
+	switch c.Backend {
+	case "", BackendNone:
+	case BackendFile:
+		if c.File == "" || c.KeyFile == "" {
+			return errors.New("the file secret store needs a file and a key file")
+		}
+	case BackendVault:
+		if c.Vault.Address == "" || c.Vault.Mount == "" {
+			return errors.New("the vault secret store needs an address and a KV mount")
+		}
+	default:
+		return fmt.Errorf("unknown secret store %q, want %s, %s or %s", c.Backend, BackendNone, BackendFile, BackendVault)
+	}
+	return nil
+}
+
+// Open opens the configured SecretStore. It returns nil for BackendNone.
+func Open(c Config) (SecretStore, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	switch c.Backend {
+	case BackendFile:
+		key, err := LoadKeyFile(c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return NewFileStore(c.File, key)
+	case BackendVault:
+		vault := c.Vault
+		if c.VaultTokenFile != "" {
+			token, err := os.ReadFile(c.VaultTokenFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read Vault token: %w", err)
+			}
+			vault.Token = strings.TrimSpace(string(token))
+		} else if vault.Token == "" {
+			vault.Token = os.Getenv("VAULT_TOKEN")
+		}
+		return NewVaultStore(vault)
+	}
+	return nil, nil
+}
+
+// Load returns the secret stored under id, waiting at most timeout. It is
+// meant for reading the passwords the service logs in with at startup.
+func Load(s SecretStore, id string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	value, err := s.GetSecret(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("secret %s: %w", id, err)
+	}
+	return value, nil
+}
+
+// validID matches the secret IDs backends accept: slash-separated names
+// without empty, "." or ".." segments
+var validID = regexp.MustCompile(`^[A-Za-z0-9_\-]+(\.[A-Za-z0-9_\-]+)*(/[A-Za-z0-9_\-]+(\.[A-Za-z0-9_\-]+)*)*$`)
+
+// checkID rejects IDs that could escape the place a backend keeps its
+// secrets in
+func checkID(id string) error {
+	if !validID.MatchString(id) {
+		return fmt.Errorf("invalid secret ID %q", id)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// VaultConfig locates a Vault KV version 2 secrets engine
+type VaultConfig struct {
+	// Address is the Vault server, e.g. https://vault.internal:8200
+	Address string
+	Token   string
+	// Namespace is the Vault Enterprise namespace, if any
+	Namespace string
+	// Mount is the path the KV engine is mounted at
+	Mount string
+	// Prefix is prepended to every secret ID
+	Prefix string
+	// Timeout bounds each request
+	Timeout time.Duration
+}
+
+// VaultStore keeps secrets in a Vault KV version 2 secrets engine, each under
+// <mount>/data/<prefix>/<id> with the value in its "value" field. It only
+// speaks the KV HTTP API, so any server implementing that works.
+type VaultStore struct {
+	cfg    VaultConfig
+	client *http.Client
+}
+
+// NewVaultStore creates a VaultStore
+func NewVaultStore(cfg VaultConfig) (*VaultStore, error) {
+	if _, err := url.Parse(cfg.Address); err != nil || cfg.Address == "" {
+		return nil, fmt.Errorf("invalid Vault address %q", cfg.Address)
+	}
+	if cfg.Token == "" {
+		return nil, errors.New("no Vault token configured")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	cfg.Address = strings.TrimRight(cfg.Address, "/")
+	cfg.Mount = strings.Trim(cfg.Mount, "/")
+	cfg.Prefix = strings.Trim(cfg.Prefix, "/")
+	return &VaultStore{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}, nil
+}
+
+// kvData is the body KV version 2 reads and writes secrets with
+type kvData struct {
+	Data map[string]string `json:"data"`
+}
+
+func (s *VaultStore) GetSecret(ctx context.Context, id string) (string, error) {
+	var resp struct {
+		Data kvData `json:"data"`
+	}
+	status, err := s.do(ctx, http.MethodGet, "data", id, nil, &resp)
+	if err != nil {
+		return "", err
+	}
+	value, ok := resp.Data.Data["value"]
+	if status == http.StatusNotFound || !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func (s *VaultStore) KeepsSecrets() bool {
+	return true
+}
+
+func (s *VaultStore) PutSecret(ctx context.Context, id, value string) error {
+	_, err := s.do(ctx, http.MethodPost, "data", id, kvData{Data: map[string]string{"value": value}}, nil)
+	return err
+}
+
+// DeleteSecret deletes every version of a secret and its metadata
+func (s *VaultStore) DeleteSecret(ctx context.Context, id string) error {
+	_, err := s.do(ctx, http.MethodDelete, "metadata", id, nil, nil)
+	return err
+}
+
+// do sends a request to the KV API path kind (data or metadata) of a secret
+// and decodes the response into out. A 404 is reported through the status,
+// not as an error.
+func (s *VaultStore) do(ctx context.Context, method, kind, id string, in, out interface{}) (int, error) {
+	if err := checkID(id); err != nil {
+		return 0, err
+	}
+	path := id
+	if s.cfg.Prefix != "" {
+		path = s.cfg.Prefix + "/" + id
+	}
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.cfg.Address+"/v1/"+s.cfg.Mount+"/"+kind+"/"+path, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("X-Vault-Token", s.cfg.Token)
+	if s.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.cfg.Namespace)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return resp.StatusCode, nil
+	case resp.StatusCode >= 300:
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&vaultErr)
+		if len(vaultErr.Errors) > 0 {
+			return resp.StatusCode, fmt.Errorf("vault %s %s: %s: %s", method, kind, resp.Status, strings.Join(vaultErr.Errors, "; "))
+		}
+		return resp.StatusCode, fmt.Errorf("vault %s %s: %s", method, kind, resp.Status)
+	case out != nil && resp.StatusCode != http.StatusNoContent:
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, fmt.Errorf("vault %s %s: %w", method, kind, err)
+		}
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,110 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault stands in for the KV version 2 API of a Vault server
+type fakeVault struct {
+	mu      sync.Mutex
+	token   string
+	mount   string
+	secrets map[string]map[string]string
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Vault-Token") != v.token {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string][]string{"errors": {"permission denied"}})
+		return
+	}
+	rest, ok := strings.CutPrefix(r.URL.Path, "/v1/"+v.mount+"/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	kind, path, _ := strings.Cut(rest, "/")
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	switch {
+	case kind == "data" && r.Method == http.MethodGet:
+		data, ok := v.secrets[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string][]string{"errors": {}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"data": data}})
+	case kind == "data" && r.Method == http.MethodPost:
+		var body struct {
+			Data map[string]string `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		v.secrets[path] = body.Data
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]int{"version": 1}})
+	case kind == "metadata" && r.Method == http.MethodDelete:
+		delete(v.secrets, path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestVaultStore(t *testing.T) {
+	ctx := context.Background()
+	vault := &fakeVault{token: "root", mount: "kv", secrets: make(map[string]map[string]string)}
+	server := httptest.NewServer(vault)
+	defer server.Close()
+
+	s, err := NewVaultStore(VaultConfig{Address: server.URL + "/", Token: "root", Mount: "/kv/", Prefix: "tms"})
+	require.NoError(t, err)
+
+	_, err = s.GetSecret(ctx, "tenants/1/db-password")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, s.PutSecret(ctx, "tenants/1/db-password", "hunter2"))
+	assert.Equal(t, map[string]string{"value": "hunter2"}, vault.secrets["tms/tenants/1/db-password"])
+	value, err := s.GetSecret(ctx, "tenants/1/db-password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", value)
+
+	require.NoError(t, s.DeleteSecret(ctx, "tenants/1/db-password"))
+	require.NoError(t, s.DeleteSecret(ctx, "tenants/1/db-password"))
+	_, err = s.GetSecret(ctx, "tenants/1/db-password")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestVaultStore_ReportsVaultErrors(t *testing.T) {
+	vault := &fakeVault{token: "root", mount: "kv", secrets: make(map[string]map[string]string)}
+	server := httptest.NewServer(vault)
+	defer server.Close()
+
+	s, err := NewVaultStore(VaultConfig{Address: server.URL, Token: "wrong", Mount: "kv"})
+	require.NoError(t, err)
+	err = s.PutSecret(context.Background(), "service/db-password", "x")
+	assert.ErrorContains(t, err, "permission denied")
+	_, err = s.GetSecret(context.Background(), "../sys")
+	assert.ErrorContains(t, err, "invalid secret ID")
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{Backend: BackendFile, File: "secrets.enc", KeyFile: "key"}.Validate())
+	assert.Error(t, Config{Backend: BackendFile, File: "secrets.enc"}.Validate())
+	assert.NoError(t, Config{Backend: BackendVault, Vault: VaultConfig{Address: "http://vault:8200", Mount: "secret"}}.Validate())
+	assert.Error(t, Config{Backend: BackendVault}.Validate())
+	assert.Error(t, Config{Backend: "env"}.Validate())
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/teresa-solution/tenant-management-service/internal/auth"
+	"github.com/teresa-solution/tenant-management-service/internal/crypto"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"github.com/teresa-solution/tenant-management-service/internal/quota"
+	"github.com/teresa-solution/tenant-management-service/internal/store"
+	grpcmw "github.com/teresa-solution/tenant-management-service/pkg/grpc"
+	tenantpb "github.com/teresa-solution/tenant-management-service/proto/gen"
+)
+
+// errInvalidAPIKey is returned by VerifyKey for unknown, revoked or expired keys
+var errInvalidAPIKey = errors.New("invalid API key")
+
+// CreateAPIKey issues a new API key for a tenant
+func (s *TenantService) CreateAPIKey(ctx context.Context, req *tenantpb.CreateAPIKeyRequest) (*tenantpb.CreateAPIKeyResponse, error) {
+	if err := validateCreateAPIKeyRequest(req); err != nil {
+		return nil, err
+	}
+	if err := checkScopesHeld(ctx, req.Scopes); err != nil {
+		return nil, err
+	}
+	tenant, err := s.activeTenant(ctx, req.TenantId)
+	if err != nil {
+		return nil, err
+	}
+	if s.quotas != nil {
+		if err := s.quotas.Check(ctx, tenant, quota.ResourceAPIKeys, 1); err != nil {
+			return nil, toStatusError(ctx, err, "Failed to check quota")
+		}
+	}
+
+	var expiresAt *time.Time
+	if req.TtlSeconds > 0 {
+		t := time.Now().Add(time.Duration(req.TtlSeconds) * time.Second)
+		expiresAt = &t
+	}
+	key, secret, err := s.issueAPIKey(ctx, tenant.ID, req.Name, req.Scopes, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &tenantpb.CreateAPIKeyResponse{ApiKey: toAPIKeyProto(key), Secret: secret}, nil
+}
+
+// ListAPIKeys lists the API keys of a tenant without their secrets
+func (s *TenantService) ListAPIKeys(ctx context.Context, req *tenantpb.ListAPIKeysRequest) (*tenantpb.ListAPIKeysResponse, error) {
+	tenant, err := s.activeTenant(ctx, req.TenantId)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := s.repo.ListAPIKeys(ctx, tenant.ID, req.IncludeRevoked)
+	if err != nil {
+		return nil, toStatusError(ctx, err, "Failed to list API keys")
+	}
+
+	resp := &tenantpb.ListAPIKeysResponse{}
+	for _, key := range keys {
+		resp.ApiKeys = append(resp.ApiKeys, toAPIKeyProto(key))
+	}
+	return resp, nil
+}
+
+// RotateAPIKey issues a replacement for an API key with the same name, scopes
+// and lifetime, and retires the old key after an optional grace period
+func (s *TenantService) RotateAPIKey(ctx context.Context, req *tenantpb.RotateAPIKeyRequest) (*tenantpb.RotateAPIKeyResponse, error) {
+	if req.GracePeriodSeconds < 0 {
+		return nil, invalidField("grace_period_seconds", "grace period must not be negative")
+	}
+	tenant, err := s.activeTenant(ctx, req.TenantId)
+	if err != nil {
+		return nil, err
+	}
+	old, err := s.apiKey(ctx, tenant.ID, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkScopesHeld(ctx, old.Scopes); err != nil {
+		return nil, err
+	}
+
+	var expiresAt *time.Time
+	if old.ExpiresAt != nil {
+		t := time.Now().Add(old.ExpiresAt.Sub(old.CreatedAt))
+		expiresAt = &t
+	}
+	key, secret, err := s.issueAPIKey(ctx, tenant.ID, old.Name, old.Scopes, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GracePeriodSeconds > 0 {
+		err = s.repo.ExpireAPIKey(ctx, old.ID, time.Now().Add(time.Duration(req.GracePeriodSeconds)*time.Second))
+	} else {
+		err = s.repo.RevokeAPIKey(ctx, tenant.ID, old.ID)
+	}
+	if err != nil {
+		return nil, toStatusError(ctx, err, "Failed to retire rotated API key")
+	}
+	return &tenantpb.RotateAPIKeyResponse{ApiKey: toAPIKeyProto(key), Secret: secret}, nil
+}
+
+// RevokeAPIKey revokes an API key immediately
+func (s *TenantService) RevokeAPIKey(ctx context.Context, req *tenantpb.RevokeAPIKeyRequest) (*tenantpb.RevokeAPIKeyResponse, error) {
+	tenantID, err := uuid.Parse(req.TenantId)
+	if err != nil {
+		return nil, invalidField("tenant_id", "Invalid tenant ID")
+	}
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, invalidField("id", "Invalid API key ID")
+	}
+	if err := s.repo.RevokeAPIKey(ctx, tenantID, id); err != nil {
+		return nil, toStatusError(ctx, err, "Failed to revoke API key")
+	}
+	return &tenantpb.RevokeAPIKeyResponse{Success: true}, nil
+}
+
+// VerifyAPIKey reports whether an API key is valid and which tenant it belongs to
+func (s *TenantService) VerifyAPIKey(ctx context.Context, req *tenantpb.VerifyAPIKeyRequest) (*tenantpb.VerifyAPIKeyResponse, error) {
+	if req.Key == "" {
+		return nil, invalidField("key", "key is required")
+	}
+	identity, err := s.VerifyKey(ctx, req.Key)
+	if err == errInvalidAPIKey {
+		return &tenantpb.VerifyAPIKeyResponse{Valid: false}, nil
+	}
+	if err != nil {
+		return nil, toStatusError(ctx, err, "Failed to verify API key")
+	}
+	if req.RequiredScope != "" && !contains(identity.Scopes, req.RequiredScope) {
+		return &tenantpb.VerifyAPIKeyResponse{Valid: false}, nil
+	}
+	return &tenantpb.VerifyAPIKeyResponse{
+		Valid:    true,
+		TenantId: identity.TenantID,
+		KeyId:    identity.KeyID,
+		Scopes:   identity.Scopes,
+	}, nil
+}
+
+// VerifyKey implements auth.APIKeyVerifier so the service accepts its own API keys
+func (s *TenantService) VerifyKey(ctx context.Context, rawKey string) (*auth.APIKeyIdentity, error) {
+	prefix, secret, ok := crypto.ParseAPIKey(rawKey)
+	if !ok {
+		return nil, errInvalidAPIKey
+	}
+	key, err := s.repo.GetAPIKeyByPrefix(ctx, prefix)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, errInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !key.Active(time.Now()) || !crypto.VerifyAPIKeySecret(secret, key.Salt, key.KeyHash) {
+		return nil, errInvalidAPIKey
+	}
+
+	tenant, err := s.repo.GetByID(ctx, key.TenantID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, errInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tenant.DeletedAt != nil {
+		return nil, errInvalidAPIKey
+	}
+
+	if err := s.repo.TouchAPIKey(ctx, key.ID); err != nil {
+		grpcmw.LoggerFromContext(ctx).Warn().Err(err).Str("api_key_id", key.ID.String()).Msg("Failed to record API key usage")
+	}
+	return &auth.APIKeyIdentity{
+		KeyID:    key.ID.String(),
+		TenantID: key.TenantID.String(),
+		Scopes:   key.Scopes,
+	}, nil
+}
+
+// issueAPIKey generates, hashes and stores a new key, returning the plaintext once
+func (s *TenantService) issueAPIKey(ctx context.Context, tenantID uuid.UUID, name string, scopes []string, expiresAt *time.Time) (*model.APIKey, string, error) {
+	rawKey, prefix, secret, err := crypto.GenerateAPIKey()
+	if err != nil {
+		return nil, "", toStatusError(ctx, err, "Failed to generate API key")
+	}
+	salt, err := crypto.NewSalt()
+	if err != nil {
+		return nil, "", toStatusError(ctx, err, "Failed to generate API key salt")
+	}
+
+	key := &model.APIKey{
+		TenantID:  tenantID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   crypto.HashAPIKeySecret(secret, salt),
+		Salt:      salt,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.repo.CreateAPIKey(ctx, key); err != nil {
+		return nil, "", toStatusError(ctx, err, "Failed to store API key")
+	}
+	return key, rawKey, nil
+}
+
+// activeTenant loads a tenant that has not been deleted
+func (s *TenantService) activeTenant(ctx context.Context, id string) (*model.Tenant, error) {
+	tenantID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, invalidField("tenant_id", "Invalid tenant ID")
+	}
+	tenant, err := s.repo.GetByID(ctx, tenantID)
+	if err != nil {
+		return nil, toStatusError(ctx, err, "Failed to fetch tenant")
+	}
+	if tenant.DeletedAt != nil {
+		return nil, notFoundError(store.ResourceTenant, id)
+	}
+	return tenant, nil
+}
+
+func (s *TenantService) apiKey(ctx context.Context, tenantID uuid.UUID, id string) (*model.APIKey, error) {
+	keyID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, invalidField("id", "Invalid API key ID")
+	}
+	key, err := s.repo.GetAPIKey(ctx, tenantID, keyID)
+	if err != nil {
+		return nil, toStatusError(ctx, err, "Failed to fetch API key")
+	}
+	if !key.Active(time.Now()) {
+		return nil, notFoundError(store.ResourceAPIKey, id)
+	}
+	return key, nil
+}
+
+// checkScopesHeld keeps API key callers from issuing keys with scopes they
+// do not hold themselves. Token callers are limited by role alone.
+func checkScopesHeld(ctx context.Context, scopes []string) error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || principal.Scopes == nil {
+		return nil
+	}
+	for _, scope := range scopes {
+		if !principal.HasScope(scope) {
+			return &Error{
+				Kind:         KindPermissionDenied,
+				Reason:       ReasonScopeNotHeld,
+				Message:      "API keys can only grant scopes the calling key holds",
+				ResourceType: resourceTypeAPIKey,
+				ResourceName: scope,
+			}
+		}
+	}
+	return nil
+}
+
+// validateCreateAPIKeyRequest validates the create API key request
+func validateCreateAPIKeyRequest(req *tenantpb.CreateAPIKeyRequest) error {
+	var v violations
+	if req.Name == "" {
+		v.add("name", "name is required")
+	} else if len(req.Name) > 100 {
+		v.add("name", "name must be at most 100 characters")
+	}
+	if len(req.Scopes) == 0 {
+		v.add("scopes", "at least one scope is required")
+	}
+	for i, scope := range req.Scopes {
+		if !contains(auth.Scopes, scope) {
+			v.add(fmt.Sprintf("scopes[%d]", i), "unknown scope "+scope)
+		}
+	}
+	if req.TtlSeconds < 0 {
+		v.add("ttl_seconds", "ttl must not be negative")
+	}
+	return v.err()
+}
+
+func toAPIKeyProto(key *model.APIKey) *tenantpb.APIKey {
+	return &tenantpb.APIKey{
+		Id:         key.ID.String(),
+		TenantId:   key.TenantID.String(),
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  formatTime(key.ExpiresAt),
+		LastUsedAt: formatTime(key.LastUsedAt),
+		RevokedAt:  formatTime(key.RevokedAt),
+		CreatedAt:  key.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// formatTime renders an optional timestamp, leaving unset values empty
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
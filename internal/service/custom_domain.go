@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"github.com/teresa-solution/tenant-management-service/internal/store"
+	grpcmw "github.com/teresa-solution/tenant-management-service/pkg/grpc"
+	tenantpb "github.com/teresa-solution/tenant-management-service/proto/gen"
+)
+
+// TXTResolver looks up the TXT records of a name. *net.Resolver implements
+// it.
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// DomainVerification decides how tenants prove they control a custom domain
+type DomainVerification struct {
+	Resolver TXTResolver
+	// TokenTTL is how long a verification token is accepted after it was
+	// issued
+	TokenTTL time.Duration
+}
+
+// DefaultDomainVerification uses the system resolver and accepts tokens for
+// a week
+var DefaultDomainVerification = DomainVerification{Resolver: net.DefaultResolver, TokenTTL: 7 * 24 * time.Hour}
+
+// Validate checks the resolver and token lifetime
+func (v DomainVerification) Validate() error {
+	if v.Resolver == nil {
+		return errors.New("domain verification needs a TXT resolver")
+	}
+	if v.TokenTTL <= 0 {
+		return errors.New("domain verification token lifetime must be positive")
+	}
+	return nil
+}
+
+// check returns nil when the TXT records of the verification name of d hold
+// its verification value
+func (v DomainVerification) check(ctx context.Context, d *model.CustomDomain) error {
+	name := d.VerificationName()
+	records, err := v.Resolver.LookupTXT(ctx, name)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return preconditionFailed(ReasonDomainUnverified, fmt.Sprintf("No TXT record %s found", name), resourceTypeCustomDomain, d.Domain)
+	}
+	if err != nil {
+		return &Error{
+			Kind:         KindUnavailable,
+			Reason:       ReasonDNSLookupFailed,
+			Message:      fmt.Sprintf("Failed to look up TXT record %s", name),
+			ResourceType: resourceTypeCustomDomain,
+			ResourceName: d.Domain,
+			RetryAfter:   30 * time.Second,
+			Err:          err,
+		}
+	}
+	for _, record := range records {
+		if strings.TrimSpace(record) == d.VerificationValue() {
+			return nil
+		}
+	}
+	return preconditionFailed(ReasonDomainUnverified, fmt.Sprintf("TXT record %s does not hold the verification value", name), resourceTypeCustomDomain, d.Domain)
+}
+
+// AttachCustomDomain attaches a custom domain to a tenant and issues the token
+// proving ownership of it. Attaching a domain again returns it, renewing an
+// expired token.
+func (s *TenantService) AttachCustomDomain(ctx context.Context, req *tenantpb.AttachCustomDomainRequest) (*tenantpb.AttachCustomDomainResponse, error) {
+	domain, err := s.customDomainName(req.Domain)
+	if err != nil {
+		return nil, err
+	}
+	tenant, err := s.activeTenant(ctx, req.TenantId)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkDomainAvailable(ctx, tenant.ID.String(), domain); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	d, err := s.repo.GetCustomDomain(ctx, tenant.ID, domain)
+	switch {
+	case err == nil && d.State(now) == model.DomainStatusExpired:
+		token, err := newVerificationToken()
+		if err != nil {
+			return nil, toStatusError(ctx, err, "Failed to generate verification token")
+		}
+		if err := s.repo.RenewCustomDomainToken(ctx, d, token, now.Add(s.domains.TokenTTL)); err != nil {
+			return nil, toStatusError(ctx, err, "Failed to renew verification token")
+		}
+	case err == nil:
+	case errors.Is(err, store.ErrNotFound):
+		token, err := newVerificationToken()
+		if err != nil {
+			return nil, toStatusError(ctx, err, "Failed to generate verification token")
+		}
+		d = &model.CustomDomain{TenantID: tenant.ID, Domain: domain, VerificationToken: token, TokenExpiresAt: now.Add(s.domains.TokenTTL)}
+		if err := s.repo.CreateCustomDomain(ctx, d); err != nil {
+			return nil, toStatusError(ctx, err, "Failed to attach custom domain")
+		}
+	default:
+		return nil, toStatusError(ctx, err, "Failed to fetch custom domain")
+	}
+	return &tenantpb.AttachCustomDomainResponse{CustomDomain: toCustomDomainProto(d, now)}, nil
+}
+
+// VerifyCustomDomain checks that the tenant published the verification token
+// of a custom domain and, if it did, makes the domain resolve to the tenant
+func (s *TenantService) VerifyCustomDomain(ctx context.Context, req *tenantpb.VerifyCustomDomainRequest) (*tenantpb.VerifyCustomDomainResponse, error) {
+	tenant, d, err := s.customDomain(ctx, req.TenantId, req.Domain)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	switch d.State(now) {
+	case model.DomainStatusVerified:
+		return &tenantpb.VerifyCustomDomainResponse{CustomDomain: toCustomDomainProto(d, now)}, nil
+	case model.DomainStatusExpired:
+		return nil, preconditionFailed(ReasonDomainExpired, "Verification token has expired; attach the domain again for a new one", resourceTypeCustomDomain, d.Domain)
+	}
+	if err := s.checkDomainAvailable(ctx, tenant.ID.String(), d.Domain); err != nil {
+		return nil, err
+	}
+
+	if checkErr := s.domains.check(ctx, d); checkErr != nil {
+		if err := s.repo.RecordCustomDomainCheck(ctx, d, checkErr.Error()); err != nil {
+			grpcmw.LoggerFromContext(ctx).Warn().Err(err).Str("domain", d.Domain).Msg("Failed to record custom domain check")
+		}
+		return nil, toStatusError(ctx, checkErr, "Failed to verify custom domain")
+	}
+	err = s.repo.VerifyCustomDomain(ctx, d)
+	if errors.Is(err, store.ErrConflict) {
+		return nil, conflictError(ReasonDomainTaken, "Custom domain is in use by another tenant", resourceTypeCustomDomain, d.Domain)
+	}
+	if err != nil {
+		return nil, toStatusError(ctx, err, "Failed to verify custom domain")
+	}
+	grpcmw.LoggerFromContext(ctx).Info().Str("tenant_id", tenant.ID.String()).Str("domain", d.Domain).Msg("Custom domain verified")
+	return &tenantpb.VerifyCustomDomainResponse{CustomDomain: toCustomDomainProto(d, now)}, nil
+}
+
+// ListCustomDomains lists the custom domains of a tenant
+func (s *TenantService) ListCustomDomains(ctx context.Context, req *tenantpb.ListCustomDomainsRequest) (*tenantpb.ListCustomDomainsResponse, error) {
+	tenant, err := s.activeTenant(ctx, req.TenantId)
+	if err != nil {
+		return nil, err
+	}
+	domains, err := s.repo.ListCustomDomains(ctx, tenant.ID)
+	if err != nil {
+		return nil, toStatusError(ctx, err, "Failed to list custom domains")
+	}
+
+	now := time.Now()
+	resp := &tenantpb.ListCustomDomainsResponse{}
+	for _, d := range domains {
+		resp.CustomDomains = append(resp.CustomDomains, toCustomDomainProto(d, now))
+	}
+	return resp, nil
+}
+
+// DetachCustomDomain removes a custom domain from a tenant; it stops
+// resolving to the tenant at once
+func (s *TenantService) DetachCustomDomain(ctx context.Context, req *tenantpb.DetachCustomDomainRequest) (*tenantpb.DetachCustomDomainResponse, error) {
+	tenant, d, err := s.customDomain(ctx, req.TenantId, req.Domain)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.DeleteCustomDomain(ctx, tenant.ID, d.Domain); err != nil {
+		return nil, toStatusError(ctx, err, "Failed to detach custom domain")
+	}
+	return &tenantpb.DetachCustomDomainResponse{Success: true}, nil
+}
+
+// customDomainTenant returns the tenant a verified custom domain belongs to
+func (s *TenantService) customDomainTenant(ctx context.Context, host string) (*model.Tenant, error) {
+	domain, ok := hostname(host)
+	if !ok {
+		return nil, notFoundError(store.ResourceTenant, host)
+	}
+	d, err := s.repo.GetVerifiedCustomDomain(ctx, domain)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, notFoundError(store.ResourceTenant, host)
+	}
+	if err != nil {
+		return nil, toStatusError(ctx, err, "Failed to resolve custom domain")
+	}
+	tenant, err := s.repo.GetByID(ctx, d.TenantID)
+	if err != nil {
+		return nil, toStatusError(ctx, err, "Failed to resolve tenant")
+	}
+	return tenant, nil
+}
+
+// customDomain loads a custom domain of a tenant that has not been deleted
+func (s *TenantService) customDomain(ctx context.Context, tenantID, name string) (*model.Tenant, *model.CustomDomain, error) {
+	domain, ok := hostname(name)
+	if !ok {
+		return nil, nil, invalidField("domain", "invalid domain")
+	}
+	tenant, err := s.activeTenant(ctx, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	d, err := s.repo.GetCustomDomain(ctx, tenant.ID, domain)
+	if err != nil {
+		return nil, nil, toStatusError(ctx, err, "Failed to fetch custom domain")
+	}
+	return tenant, d, nil
+}
+
+// checkDomainAvailable fails when a live tenant other than tenantID holds
+// domain verified
+func (s *TenantService) checkDomainAvailable(ctx context.Context, tenantID, domain string) error {
+	owner, err := s.customDomainTenant(ctx, domain)
+	var domainErr *Error
+	if errors.As(err, &domainErr) && domainErr.Kind == KindNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if owner.ID.String() != tenantID && owner.DeletedAt == nil {
+		return conflictError(ReasonDomainTaken, "Custom domain is in use by another tenant", resourceTypeCustomDomain, domain)
+	}
+	return nil
+}
+
+// customDomainName validates a domain a tenant asks for and returns it in
+// the form it is kept in
+func (s *TenantService) customDomainName(name string) (string, error) {
+	if name == "" {
+		return "", invalidField("domain", "domain is required")
+	}
+	domain, ok := hostname(name)
+	if !ok || strings.Contains(name, ":") || !isValidDomain(domain) {
+		return "", invalidField("domain", "invalid domain format")
+	}
+	base := strings.Trim(strings.ToLower(s.baseDomain), ".")
+	if domain == base || strings.HasSuffix(domain, "."+base) {
+		return "", invalidField("domain", "domain must not be under the tenant base domain")
+	}
+	return domain, nil
+}
+
+// hostname returns a hostname in lower case without a port or trailing dot
+func hostname(host string) (string, bool) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	return host, host != ""
+}
+
+// isValidDomain reports whether domain is a fully qualified ASCII domain
+// name; internationalized names must be given in their punycode form
+func isValidDomain(domain string) bool {
+	if len(domain) > 253 {
+		return false
+	}
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if !isValidSubdomain(label) {
+			return false
+		}
+	}
+	// Rules out IP addresses
+	return strings.Trim(labels[len(labels)-1], "0123456789") != ""
+}
+
+// newVerificationToken returns a random domain verification token
+func newVerificationToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func toCustomDomainProto(d *model.CustomDomain, now time.Time) *tenantpb.CustomDomain {
+	pb := &tenantpb.CustomDomain{
+		TenantId:          d.TenantID.String(),
+		Domain:            d.Domain,
+		Status:            d.State(now),
+		VerificationName:  d.VerificationName(),
+		VerificationValue: d.VerificationValue(),
+		TokenExpiresAt:    d.TokenExpiresAt.UTC().Format(time.RFC3339),
+		VerifiedAt:        formatTime(d.VerifiedAt),
+		LastCheckedAt:     formatTime(d.LastCheckedAt),
+		CreatedAt:         d.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if d.LastCheckError != nil {
+		pb.LastCheckError = *d.LastCheckError
+	}
+	return pb
+}
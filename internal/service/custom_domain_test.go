@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeResolver answers TXT lookups from a map, failing names in errs
+type fakeResolver struct {
+	records map[string][]string
+	errs    map[string]error
+	lookups []string
+}
+
+func (f *fakeResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	f.lookups = append(f.lookups, name)
+	if err, ok := f.errs[name]; ok {
+		return nil, err
+	}
+	records, ok := f.records[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func newCustomDomain() *model.CustomDomain {
+	return &model.CustomDomain{
+		TenantID:          uuid.New(),
+		Domain:            "portal.customer.com",
+		Status:            model.DomainStatusPending,
+		VerificationToken: "0f1e2d3c4b5a69788796a5b4c3d2e1f0",
+		TokenExpiresAt:    time.Now().Add(time.Hour),
+	}
+}
+
+func TestDomainVerification_Check(t *testing.T) {
+	ctx := context.Background()
+	d := newCustomDomain()
+	resolver := &fakeResolver{records: map[string][]string{
+		"_tms-verification.portal.customer.com": {"v=spf1 -all", " tms-verification=0f1e2d3c4b5a69788796a5b4c3d2e1f0 "},
+	}}
+	v := DomainVerification{Resolver: resolver, TokenTTL: time.Hour}
+	assert.NoError(t, v.check(ctx, d))
+	assert.Equal(t, []string{"_tms-verification.portal.customer.com"}, resolver.lookups)
+
+	resolver.records["_tms-verification.portal.customer.com"] = []string{"tms-verification=stale"}
+	assertReason(t, v.check(ctx, d), codes.FailedPrecondition, ReasonDomainUnverified)
+
+	delete(resolver.records, "_tms-verification.portal.customer.com")
+	err := v.check(ctx, d)
+	assertReason(t, err, codes.FailedPrecondition, ReasonDomainUnverified)
+	assert.EqualError(t, err, "No TXT record _tms-verification.portal.customer.com found")
+
+	resolver.errs = map[string]error{"_tms-verification.portal.customer.com": &net.DNSError{Err: "server misbehaving", IsTemporary: true}}
+	assertReason(t, v.check(ctx, d), codes.Unavailable, ReasonDNSLookupFailed)
+}
+
+func assertReason(t *testing.T, err error, code codes.Code, reason string) {
+	t.Helper()
+	st, ok := status.FromError(toStatusError(context.Background(), err, "check failed"))
+	require.True(t, ok)
+	assert.Equal(t, code, st.Code())
+	require.NotEmpty(t, st.Details())
+	assert.Equal(t, reason, st.Details()[0].(*errdetails.ErrorInfo).Reason)
+}
+
+func TestDomainVerification_Validate(t *testing.T) {
+	assert.NoError(t, DefaultDomainVerification.Validate())
+	assert.Error(t, DomainVerification{TokenTTL: time.Hour}.Validate())
+	assert.Error(t, DomainVerification{Resolver: &fakeResolver{}}.Validate())
+}
+
+func TestCustomDomainName(t *testing.T) {
+	s := &TenantService{baseDomain: "tenants.example.com"}
+	tests := []struct {
+		name   string
+		domain string
+		ok     bool
+	}{
+		{"Portal.Customer.com.", "portal.customer.com", true},
+		{"xn--bcher-kva.example", "xn--bcher-kva.example", true},
+		{"", "", false},
+		{"customer", "", false},
+		{"portal.customer.com:443", "", false},
+		{"-portal.customer.com", "", false},
+		{"portal..customer.com", "", false},
+		{"portal_1.customer.com", "", false},
+		{"10.0.0.1", "", false},
+		{"acme.tenants.example.com", "", false},
+		{"tenants.example.com", "", false},
+	}
+	for _, tt := range tests {
+		domain, err := s.customDomainName(tt.name)
+		assert.Equal(t, tt.domain, domain, tt.name)
+		var domainErr *Error
+		if tt.ok {
+			assert.NoError(t, err, tt.name)
+		} else if assert.True(t, errors.As(err, &domainErr), tt.name) {
+			assert.Equal(t, "domain", domainErr.Violations[0].Field)
+		}
+	}
+}
+
+func TestCustomDomain_State(t *testing.T) {
+	d := newCustomDomain()
+	now := time.Now()
+	assert.Equal(t, model.DomainStatusPending, d.State(now))
+	assert.Equal(t, model.DomainStatusExpired, d.State(now.Add(2*time.Hour)))
+
+	d.Status = model.DomainStatusVerified
+	assert.Equal(t, model.DomainStatusVerified, d.State(now.Add(2*time.Hour)))
+
+	pb := toCustomDomainProto(d, now)
+	assert.Equal(t, "_tms-verification.portal.customer.com", pb.VerificationName)
+	assert.Equal(t, "tms-verification=0f1e2d3c4b5a69788796a5b4c3d2e1f0", pb.VerificationValue)
+}
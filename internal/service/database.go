@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"github.com/teresa-solution/tenant-management-service/internal/provisioning"
+	"github.com/teresa-solution/tenant-management-service/internal/store"
+	tenantpb "github.com/teresa-solution/tenant-management-service/proto/gen"
+)
+
+// rotateCredentialsLogStep is the tenant_provisioning_logs step recording
+// password rotations
+const rotateCredentialsLogStep = "rotate_db_credentials"
+
+// RotateCredentials gives a tenant's database role a new password through
+// the pipelines of the isolation mode the tenant was provisioned with
+func (ps *ProvisioningService) RotateCredentials(ctx context.Context, tenant *model.Tenant) (*model.TenantDatabaseConfig, error) {
+	cfg, err := ps.repo.GetTenantDatabaseConfig(ctx, tenant.ID)
+	if errors.Is(err, store.ErrNotFound) || err == nil && tenant.Status == "provisioning" {
+		return nil, preconditionFailed(ReasonNotProvisioned, "Tenant database is not provisioned yet", resourceTypeTenant, tenant.ID.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+	pipelines, err := ps.modePipelines(ctx, tenant.ID, cfg.IsolationMode)
+	if err != nil {
+		return nil, err
+	}
+	if pipelines.Credentials == nil {
+		return nil, fmt.Errorf("isolation mode %s does not rotate credentials", cfg.IsolationMode)
+	}
+	if !pipelines.Credentials.Stored() {
+		return nil, preconditionFailed(ReasonNoSecretStore, "No secret store is configured to keep the new password", resourceTypeTenant, tenant.ID.String())
+	}
+	secretID, err := pipelines.Credentials.Rotate(ctx, tenant.ID)
+	if err != nil {
+		return nil, err
+	}
+	// Tenants provisioned without a secret store have no secret recorded yet
+	if cfg.PasswordSecretID != secretID {
+		cfg.PasswordSecretID = secretID
+		if err := ps.repo.UpsertTenantDatabaseConfig(ctx, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	details := map[string]interface{}{"password_secret_id": cfg.PasswordSecretID}
+	if err := ps.repo.CreateProvisioningLog(ctx, tenant.ID, rotateCredentialsLogStep, provisioning.LogSuccess, details); err != nil {
+		log.Error().Err(err).Str("tenant_id", tenant.ID.String()).Msg("Failed to record credential rotation")
+	}
+	log.Info().
+		Str("tenant_id", tenant.ID.String()).
+		Str("role", cfg.Username).
+		Msg("Rotated tenant database credentials")
+	return cfg, nil
+}
+
+// RotateDatabaseCredentials replaces the password of a tenant's database
+// role. The new password is only written to the secret store, never returned.
+func (s *TenantService) RotateDatabaseCredentials(ctx context.Context, req *tenantpb.RotateDatabaseCredentialsRequest) (*tenantpb.RotateDatabaseCredentialsResponse, error) {
+	tenant, err := s.activeTenant(ctx, req.TenantId)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkNotMoving(ctx, tenant.ID); err != nil {
+		return nil, err
+	}
+	cfg, err := s.provisioningService.RotateCredentials(ctx, tenant)
+	if err != nil {
+		return nil, toStatusError(ctx, err, "Failed to rotate database credentials")
+	}
+	return &tenantpb.RotateDatabaseCredentialsResponse{
+		Username:         cfg.Username,
+		PasswordSecretId: cfg.PasswordSecretID,
+		RotatedAt:        time.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// GetDatabaseConfig reports where and how a provisioned tenant's data is
+// reached, including its isolation mode
+func (s *TenantService) GetDatabaseConfig(ctx context.Context, req *tenantpb.GetDatabaseConfigRequest) (*tenantpb.GetDatabaseConfigResponse, error) {
+	tenant, err := s.activeTenant(ctx, req.TenantId)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := s.repo.GetTenantDatabaseConfig(ctx, tenant.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, preconditionFailed(ReasonNotProvisioned, "Tenant database is not provisioned yet", resourceTypeTenant, tenant.ID.String())
+	}
+	if err != nil {
+		return nil, toStatusError(ctx, err, "Failed to fetch database config")
+	}
+	return &tenantpb.GetDatabaseConfigResponse{Config: toDatabaseConfigProto(cfg)}, nil
+}
+
+func toDatabaseConfigProto(cfg *model.TenantDatabaseConfig) *tenantpb.DatabaseConfig {
+	return &tenantpb.DatabaseConfig{
+		IsolationMode:             cfg.IsolationMode,
+		Host:                      cfg.Host,
+		Port:                      int32(cfg.Port),
+		DatabaseName:              cfg.DatabaseName,
+		SchemaName:                cfg.SchemaName,
+		Username:                  cfg.Username,
+		PasswordSecretId:          cfg.PasswordSecretID,
+		MaxConnections:            int32(cfg.MaxConnections),
+		IdleConnections:           int32(cfg.IdleConnections),
+		ConnectionLifetimeMinutes: int32(cfg.ConnectionLifetimeMinutes),
+	}
+}
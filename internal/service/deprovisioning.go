@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"github.com/teresa-solution/tenant-management-service/internal/monitoring"
+	"github.com/teresa-solution/tenant-management-service/internal/provisioning"
+	"github.com/teresa-solution/tenant-management-service/internal/store"
+)
+
+// QueueForDeprovisioning records a durable job tearing down a deleted tenant,
+// run against the database host the tenant was placed on
+func (ps *ProvisioningService) QueueForDeprovisioning(ctx context.Context, tenantID uuid.UUID) (*model.ProvisioningJob, error) {
+	host := ps.cfg.DBHost
+	cfg, err := ps.repo.GetTenantDatabaseConfig(ctx, tenantID)
+	switch {
+	case err == nil:
+		host = cfg.Host
+	case !errors.Is(err, store.ErrNotFound):
+		return nil, err
+	}
+	job, err := ps.repo.EnqueueJob(ctx, tenantID, model.JobKindDeprovision, host, ps.cfg.MaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	log.Info().
+		Str("tenant_id", tenantID.String()).
+		Str("job_id", job.ID.String()).
+		Msg("Queued tenant for deprovisioning")
+	return job, nil
+}
+
+// deprovisionTenant runs the deprovisioning pipeline for a deleted tenant and
+// marks it deleted. What the tenant keeps is dropped later by reclaimDue.
+func (ps *ProvisioningService) deprovisionTenant(ctx context.Context, job *model.ProvisioningJob, tenant *model.Tenant) error {
+	state := &provisioning.State{
+		Tenant:  tenant,
+		JobID:   job.ID,
+		Attempt: job.Attempts,
+		DBHost:  job.DBHost,
+	}
+	pipelines, err := ps.pipelinesFor(ctx, tenant)
+	if err != nil {
+		return err
+	}
+	if err := pipelines.Deprovision.Run(ctx, state); err != nil {
+		return err
+	}
+	if err := ps.repo.FinishDeprovisioning(ctx, tenant.ID); err != nil {
+		log.Error().
+			Str("tenant_id", tenant.ID.String()).
+			Err(err).
+			Msg("Failed to update tenant status after deprovisioning")
+		return err
+	}
+
+	log.Info().
+		Str("tenant_id", tenant.ID.String()).
+		Msg("Deprovisioning completed successfully")
+	monitoring.TenantsDeprovisioned.WithLabelValues("success").Inc()
+	return nil
+}
+
+// reclaimDue drops what deprovisioned tenants of every isolation mode kept,
+// such as schemas, roles and database configs, and the schemas tenants that
+// moved to another shard left behind, once their retention delay has passed.
+// Snapshots are kept.
+func (ps *ProvisioningService) reclaimDue(ctx context.Context) {
+	for mode, pipelines := range ps.isolation.Modes {
+		if pipelines.Reclaim != nil {
+			ps.reclaim(ctx, mode, pipelines.Reclaim)
+		}
+	}
+	if ps.isolation.Shards == nil {
+		return
+	}
+	shards, err := ps.repo.ListShards(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to list database shards to reclaim")
+		}
+		return
+	}
+	for _, shard := range shards {
+		conn, err := ps.shardConn(ctx, shard.ID)
+		if err != nil {
+			log.Error().Err(err).Str("shard", shard.Name).Msg("Failed to reclaim deprovisioned tenants on database shard")
+			continue
+		}
+		ps.reclaim(ctx, model.IsolationSchema, conn.pipelines.Reclaim)
+		ps.reclaim(ctx, model.IsolationSchema, conn.moved)
+	}
+}
+
+func (ps *ProvisioningService) reclaim(ctx context.Context, mode string, reclaimer provisioning.Reclaimer) {
+	ids, err := reclaimer.Due(ctx, recoveryBatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error().Err(err).Str("isolation_mode", mode).Msg("Failed to list deprovisioned tenants due to be reclaimed")
+		}
+		return
+	}
+	for _, id := range ids {
+		start := time.Now()
+		err := reclaimer.Reclaim(ctx, id)
+
+		status := provisioning.LogSuccess
+		details := map[string]interface{}{"duration_ms": time.Since(start).Milliseconds()}
+		if err != nil {
+			status = provisioning.LogFailed
+			details["error"] = err.Error()
+			log.Error().Err(err).Str("tenant_id", id.String()).Str("isolation_mode", mode).Msg("Failed to reclaim deprovisioned tenant")
+		} else {
+			log.Info().Str("tenant_id", id.String()).Str("isolation_mode", mode).Msg("Reclaimed deprovisioned tenant")
+		}
+		monitoring.SchemasDropped.WithLabelValues(status).Inc()
+		if err := ps.repo.CreateProvisioningLog(ctx, id, reclaimer.Name(), status, details); err != nil {
+			log.Error().Err(err).Str("tenant_id", id.String()).Msg("Failed to write provisioning log")
+		}
+	}
+}
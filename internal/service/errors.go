@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/teresa-solution/tenant-management-service/internal/quota"
+	"github.com/teresa-solution/tenant-management-service/internal/store"
+	grpcmw "github.com/teresa-solution/tenant-management-service/pkg/grpc"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// ErrorDomain is the ErrorInfo domain of every error raised by this service
+const ErrorDomain = "tenant-management.teresa-solution"
+
+// ErrorKind classifies a domain error independently of the transport
+type ErrorKind int
+
+const (
+	KindInvalid ErrorKind = iota + 1
+	KindNotFound
+	KindConflict
+	KindPreconditionFailed
+	KindResourceExhausted
+	KindUnavailable
+	KindPermissionDenied
+)
+
+// Machine-readable reasons reported in ErrorInfo details
+const (
+	ReasonInvalidArgument  = "INVALID_ARGUMENT"
+	ReasonTenantNotFound   = "TENANT_NOT_FOUND"
+	ReasonAPIKeyNotFound   = "API_KEY_NOT_FOUND"
+	ReasonResourceNotFound = "RESOURCE_NOT_FOUND"
+	ReasonSubdomainTaken   = "SUBDOMAIN_ALREADY_EXISTS"
+	ReasonResourceConflict = "RESOURCE_CONFLICT"
+	ReasonTenantDeleted    = "TENANT_DELETED"
+	ReasonStatusTransition = "INVALID_STATUS_TRANSITION"
+	ReasonSubdomainFixed   = "SUBDOMAIN_IMMUTABLE"
+	ReasonQueueSaturated   = "PROVISIONING_QUEUE_SATURATED"
+	ReasonNotProvisioned   = "DATABASE_NOT_PROVISIONED"
+	ReasonNoSecretStore    = "SECRET_STORE_NOT_CONFIGURED"
+	ReasonNoShardCapacity  = "NO_SHARD_CAPACITY"
+	ReasonShardInUse       = "SHARD_IN_USE"
+	ReasonTenantMoving     = "TENANT_MOVING"
+	ReasonNotMovable       = "TENANT_NOT_MOVABLE"
+	ReasonDomainTaken      = "DOMAIN_ALREADY_EXISTS"
+	ReasonDomainUnverified = "DOMAIN_NOT_VERIFIED"
+	ReasonDomainExpired    = "DOMAIN_VERIFICATION_EXPIRED"
+	ReasonDNSLookupFailed  = "DNS_LOOKUP_FAILED"
+	ReasonScopeNotHeld     = "SCOPE_NOT_HELD"
+	ReasonInternal         = "INTERNAL"
+)
+
+// Resource types reported in ResourceInfo details
+const (
+	resourceTypeTenant       = "tenant.v1.Tenant"
+	resourceTypeAPIKey       = "tenant.v1.APIKey"
+	resourceTypeTenantSchema = "tenant.v1.TenantSchema"
+	resourceTypeShard        = "tenant.v1.Shard"
+	resourceTypeTenantMove   = "tenant.v1.TenantMove"
+	resourceTypeCustomDomain = "tenant.v1.CustomDomain"
+)
+
+// FieldViolation describes one invalid request field
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// Error is a domain error that converts to a gRPC status carrying ErrorInfo,
+// ResourceInfo, BadRequest or PreconditionFailure details depending on its kind
+type Error struct {
+	Kind         ErrorKind
+	Reason       string
+	Message      string
+	ResourceType string
+	ResourceName string
+	Violations   []FieldViolation
+	// RetryAfter, when set, is reported as RetryInfo
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Code returns the gRPC code for the error kind
+func (e *Error) Code() codes.Code {
+	switch e.Kind {
+	case KindInvalid:
+		return codes.InvalidArgument
+	case KindNotFound:
+		return codes.NotFound
+	case KindConflict:
+		return codes.AlreadyExists
+	case KindPreconditionFailed:
+		return codes.FailedPrecondition
+	case KindResourceExhausted:
+		return codes.ResourceExhausted
+	case KindUnavailable:
+		return codes.Unavailable
+	case KindPermissionDenied:
+		return codes.PermissionDenied
+	default:
+		return codes.Unknown
+	}
+}
+
+func (e *Error) GRPCStatus() *status.Status {
+	st := status.New(e.Code(), e.Message)
+
+	info := &errdetails.ErrorInfo{Reason: e.Reason, Domain: ErrorDomain}
+	if e.ResourceName != "" {
+		info.Metadata = map[string]string{"resource_type": e.ResourceType, "resource_name": e.ResourceName}
+	}
+	details := []protoadapt.MessageV1{info}
+
+	switch e.Kind {
+	case KindInvalid:
+		badRequest := &errdetails.BadRequest{}
+		for _, v := range e.Violations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Description,
+			})
+		}
+		details = append(details, badRequest)
+	case KindPreconditionFailed:
+		details = append(details, &errdetails.PreconditionFailure{
+			Violations: []*errdetails.PreconditionFailure_Violation{{
+				Type:        e.Reason,
+				Subject:     e.ResourceName,
+				Description: e.Message,
+			}},
+		})
+	}
+	if e.RetryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(e.RetryAfter)})
+	}
+	if e.ResourceType != "" {
+		details = append(details, &errdetails.ResourceInfo{
+			ResourceType: e.ResourceType,
+			ResourceName: e.ResourceName,
+			Description:  e.Message,
+		})
+	}
+
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		return st
+	}
+	return detailed
+}
+
+// invalidArgument reports one or more invalid request fields
+func invalidArgument(violations ...FieldViolation) *Error {
+	messages := make([]string, len(violations))
+	for i, v := range violations {
+		messages[i] = v.Description
+	}
+	return &Error{
+		Kind:       KindInvalid,
+		Reason:     ReasonInvalidArgument,
+		Message:    strings.Join(messages, "; "),
+		Violations: violations,
+	}
+}
+
+// invalidField reports a single invalid request field
+func invalidField(field, description string) *Error {
+	return invalidArgument(FieldViolation{Field: field, Description: description})
+}
+
+// notFoundError reports a missing resource, keyed by its store resource type
+func notFoundError(resource, name string) *Error {
+	switch resource {
+	case store.ResourceTenant:
+		return &Error{Kind: KindNotFound, Reason: ReasonTenantNotFound, Message: "Tenant not found", ResourceType: resourceTypeTenant, ResourceName: name}
+	case store.ResourceAPIKey:
+		return &Error{Kind: KindNotFound, Reason: ReasonAPIKeyNotFound, Message: "API key not found", ResourceType: resourceTypeAPIKey, ResourceName: name}
+	case store.ResourceTenantSchema:
+		return &Error{Kind: KindNotFound, Reason: ReasonResourceNotFound, Message: "Tenant schema not found", ResourceType: resourceTypeTenantSchema, ResourceName: name}
+	case store.ResourceShard:
+		return &Error{Kind: KindNotFound, Reason: ReasonResourceNotFound, Message: "Database shard not found", ResourceType: resourceTypeShard, ResourceName: name}
+	case store.ResourceTenantMove:
+		return &Error{Kind: KindNotFound, Reason: ReasonResourceNotFound, Message: "Tenant move not found", ResourceType: resourceTypeTenantMove, ResourceName: name}
+	case store.ResourceCustomDomain:
+		return &Error{Kind: KindNotFound, Reason: ReasonResourceNotFound, Message: "Custom domain not found", ResourceType: resourceTypeCustomDomain, ResourceName: name}
+	default:
+		return &Error{Kind: KindNotFound, Reason: ReasonResourceNotFound, Message: "Resource not found", ResourceType: resource, ResourceName: name}
+	}
+}
+
+// conflictError reports a write that clashes with existing data
+func conflictError(reason, message, resourceType, name string) *Error {
+	return &Error{Kind: KindConflict, Reason: reason, Message: message, ResourceType: resourceType, ResourceName: name}
+}
+
+// preconditionFailed reports an operation the resource's current state does not allow
+func preconditionFailed(reason, message, resourceType, name string) *Error {
+	return &Error{Kind: KindPreconditionFailed, Reason: reason, Message: message, ResourceType: resourceType, ResourceName: name}
+}
+
+// violations collects field violations while validating a request
+type violations []FieldViolation
+
+func (v *violations) add(field, description string) {
+	*v = append(*v, FieldViolation{Field: field, Description: description})
+}
+
+func (v violations) err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return invalidArgument(v...)
+}
+
+// toStatusError maps an error to a gRPC status. Domain, store and quota errors
+// keep their meaning and details; status errors pass through; anything else is
+// logged with msg and hidden behind Internal.
+func toStatusError(ctx context.Context, err error, msg string) error {
+	var domainErr *Error
+	var notFoundErr *store.NotFoundError
+	var conflictErr *store.ConflictError
+	var exceeded *quota.ExceededError
+	switch {
+	case errors.As(err, &domainErr):
+		return domainErr
+	case errors.As(err, &notFoundErr):
+		return notFoundError(notFoundErr.Resource, notFoundErr.Key)
+	case errors.As(err, &conflictErr):
+		return conflictFromStore(conflictErr)
+	case errors.As(err, &exceeded):
+		return exceeded
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	grpcmw.LoggerFromContext(ctx).Error().Err(err).Msg(msg)
+	return internalError()
+}
+
+func conflictFromStore(err *store.ConflictError) *Error {
+	if err.Resource == store.ResourceTenant && err.Field == "subdomain" {
+		return conflictError(ReasonSubdomainTaken, "Subdomain already exists", resourceTypeTenant, err.Value)
+	}
+	if err.Resource == store.ResourceTenantMove {
+		return conflictError(ReasonTenantMoving, "Tenant is already moving to another database shard", resourceTypeTenant, err.Value)
+	}
+	if err.Resource == store.ResourceCustomDomain {
+		return conflictError(ReasonDomainTaken, "Custom domain already exists", resourceTypeCustomDomain, err.Value)
+	}
+	if err.Resource == store.ResourceShard {
+		return conflictError(ReasonResourceConflict, err.Error(), resourceTypeShard, err.Value)
+	}
+	return conflictError(ReasonResourceConflict, err.Error(), err.Resource, err.Value)
+}
+
+// internalError hides failure details from clients
+func internalError() error {
+	st := status.New(codes.Internal, "Internal server error")
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{Reason: ReasonInternal, Domain: ErrorDomain})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
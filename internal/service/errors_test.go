@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/teresa-solution/tenant-management-service/internal/auth"
+	"github.com/teresa-solution/tenant-management-service/internal/store"
+	tenantpb "github.com/teresa-solution/tenant-management-service/proto/gen"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToStatusError_StoreNotFound(t *testing.T) {
+	err := toStatusError(context.Background(), fmt.Errorf("lookup: %w", &store.NotFoundError{Resource: store.ResourceTenant, Key: "abc"}), "lookup failed")
+
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, "Tenant not found", st.Message())
+
+	var info *errdetails.ErrorInfo
+	var resource *errdetails.ResourceInfo
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *errdetails.ResourceInfo:
+			resource = d
+		}
+	}
+	assert.NotNil(t, info)
+	assert.Equal(t, ReasonTenantNotFound, info.Reason)
+	assert.Equal(t, ErrorDomain, info.Domain)
+	assert.NotNil(t, resource)
+	assert.Equal(t, "tenant.v1.Tenant", resource.ResourceType)
+	assert.Equal(t, "abc", resource.ResourceName)
+}
+
+func TestToStatusError_Conflict(t *testing.T) {
+	err := toStatusError(context.Background(), &store.ConflictError{Resource: store.ResourceTenant, Field: "subdomain", Value: "acme"}, "create failed")
+
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.AlreadyExists, st.Code())
+	info := st.Details()[0].(*errdetails.ErrorInfo)
+	assert.Equal(t, ReasonSubdomainTaken, info.Reason)
+}
+
+func TestToStatusError_Internal(t *testing.T) {
+	err := toStatusError(context.Background(), errors.New("connection refused"), "query failed")
+
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.Internal, st.Code())
+	assert.Equal(t, "Internal server error", st.Message())
+
+	// Existing status errors pass through untouched
+	passed := status.Error(codes.Unavailable, "try later")
+	assert.Equal(t, passed, toStatusError(context.Background(), passed, "ignored"))
+}
+
+func TestValidateCreateTenantRequest_FieldViolations(t *testing.T) {
+	err := validateCreateTenantRequest(&tenantpb.CreateTenantRequest{
+		Subdomain:    "-bad",
+		ContactEmail: "nope",
+		Tier:         "gold",
+	})
+
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+
+	var badRequest *errdetails.BadRequest
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			badRequest = br
+		}
+	}
+	assert.NotNil(t, badRequest)
+	var fields []string
+	for _, v := range badRequest.FieldViolations {
+		fields = append(fields, v.Field)
+	}
+	assert.Equal(t, []string{"name", "subdomain", "contact_email", "tier"}, fields)
+
+	assert.NoError(t, validateCreateTenantRequest(&tenantpb.CreateTenantRequest{
+		Name:         "Acme",
+		Subdomain:    "acme",
+		ContactEmail: "ops@acme.io",
+	}))
+}
+
+func TestPreconditionFailed_Details(t *testing.T) {
+	st := preconditionFailed(ReasonTenantDeleted, "Tenant has been deleted", resourceTypeTenant, "abc").GRPCStatus()
+
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	var failure *errdetails.PreconditionFailure
+	for _, d := range st.Details() {
+		if pf, ok := d.(*errdetails.PreconditionFailure); ok {
+			failure = pf
+		}
+	}
+	assert.NotNil(t, failure)
+	assert.Equal(t, ReasonTenantDeleted, failure.Violations[0].Type)
+	assert.Equal(t, "abc", failure.Violations[0].Subject)
+}
+
+func TestCheckScopesHeld(t *testing.T) {
+	ctx := context.Background()
+	assert.NoError(t, checkScopesHeld(ctx, []string{auth.ScopeAPIKeysWrite}))
+
+	token := auth.WithPrincipal(ctx, &auth.Principal{Subject: "admin", Roles: []auth.Role{auth.RolePlatformAdmin}})
+	assert.NoError(t, checkScopesHeld(token, []string{auth.ScopeTenantsRead, auth.ScopeAPIKeysWrite}))
+
+	key := auth.WithPrincipal(ctx, &auth.Principal{Subject: "key", Scopes: []string{auth.ScopeAPIKeysWrite}})
+	assert.NoError(t, checkScopesHeld(key, []string{auth.ScopeAPIKeysWrite}))
+	err := checkScopesHeld(key, []string{auth.ScopeAPIKeysWrite, auth.ScopeTenantsRead})
+	st, _ := status.FromError(toStatusError(ctx, err, "create failed"))
+	assert.Equal(t, codes.PermissionDenied, st.Code())
+	assert.Equal(t, ReasonScopeNotHeld, st.Details()[0].(*errdetails.ErrorInfo).Reason)
+}
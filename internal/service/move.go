@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"github.com/teresa-solution/tenant-management-service/internal/monitoring"
+	"github.com/teresa-solution/tenant-management-service/internal/provisioning"
+	"github.com/teresa-solution/tenant-management-service/internal/store"
+	tenantpb "github.com/teresa-solution/tenant-management-service/proto/gen"
+)
+
+// QueueMove records a move of a tenant's schema onto the shard targetID and
+// a durable job carrying it out. Asking again for a pending move returns it
+// with its job.
+func (ps *ProvisioningService) QueueMove(ctx context.Context, tenant *model.Tenant, targetID uuid.UUID) (*model.TenantMove, *model.ProvisioningJob, error) {
+	name := tenant.ID.String()
+	if ps.isolation.Shards == nil {
+		return nil, nil, preconditionFailed(ReasonNotMovable, "Tenants are not placed on database shards", resourceTypeTenant, name)
+	}
+	move, err := ps.repo.GetPendingTenantMove(ctx, tenant.ID)
+	switch {
+	case err == nil && move.TargetShardID != targetID:
+		return nil, nil, conflictError(ReasonTenantMoving, "Tenant is already moving to another database shard", resourceTypeTenant, name)
+	case err == nil:
+		job, err := ps.enqueueMove(ctx, move)
+		return move, job, err
+	case !errors.Is(err, store.ErrNotFound):
+		return nil, nil, err
+	}
+
+	if tenant.Status != "active" {
+		return nil, nil, preconditionFailed(ReasonNotMovable, "Only active tenants can be moved", resourceTypeTenant, name)
+	}
+	placement, err := ps.repo.GetShardSchema(ctx, tenant.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil, preconditionFailed(ReasonNotMovable, "Tenant is not on a database shard", resourceTypeTenant, name)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if placement.ShardID == targetID {
+		return nil, nil, preconditionFailed(ReasonNotMovable, "Tenant is already on the database shard", resourceTypeTenant, name)
+	}
+	target, err := ps.repo.GetShard(ctx, targetID)
+	if err != nil {
+		return nil, nil, err
+	}
+	targetFull := &Error{
+		Kind:         KindResourceExhausted,
+		Reason:       ReasonNoShardCapacity,
+		Message:      "Database shard is draining or full",
+		ResourceType: resourceTypeShard,
+		ResourceName: target.ID.String(),
+	}
+	if !target.Available() {
+		return nil, nil, targetFull
+	}
+
+	move = &model.TenantMove{
+		TenantID:      tenant.ID,
+		SourceShardID: placement.ShardID,
+		TargetShardID: target.ID,
+		SchemaName:    placement.SchemaName,
+	}
+	// The target is checked again under a lock, as other placements and moves
+	// may have filled it since it was read
+	err = ps.repo.CreateTenantMove(ctx, move)
+	if errors.Is(err, store.ErrShardFull) {
+		return nil, nil, targetFull
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	job, err := ps.enqueueMove(ctx, move)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Info().
+		Str("tenant_id", name).
+		Str("move_id", move.ID.String()).
+		Str("job_id", job.ID.String()).
+		Str("target_shard", target.Name).
+		Msg("Queued tenant move")
+	return move, job, nil
+}
+
+// enqueueMove queues the job of a move, which runs against the target shard's
+// host, or returns the job already queued
+func (ps *ProvisioningService) enqueueMove(ctx context.Context, move *model.TenantMove) (*model.ProvisioningJob, error) {
+	conn, err := ps.shardConn(ctx, move.TargetShardID)
+	if err != nil {
+		return nil, err
+	}
+	return ps.repo.EnqueueJob(ctx, move.TenantID, model.JobKindMove, ps.dbHost(conn.pipelines), ps.cfg.MaxAttempts)
+}
+
+// movePipeline builds the pipeline moving a tenant between the shards of move
+func (ps *ProvisioningService) movePipeline(ctx context.Context, move *model.TenantMove) (*provisioning.Pipeline, error) {
+	if ps.isolation.Shards == nil {
+		return nil, provisioning.Permanent(errors.New("database shards are not configured"))
+	}
+	source, err := ps.shardConn(ctx, move.SourceShardID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := ps.shardConn(ctx, move.TargetShardID)
+	if err != nil {
+		return nil, err
+	}
+	shard, err := ps.repo.GetShard(ctx, move.TargetShardID)
+	if err != nil {
+		return nil, err
+	}
+	steps := provisioning.MoveSteps(ps.repo, source.server, shard, target.server, ps.isolation.Shards.Steps)
+	return provisioning.NewPipeline(ps.repo, steps...), nil
+}
+
+// moveTenant runs the pending move of a tenant and completes it. Every step
+// is idempotent, so a retried job resumes safely, also after the cutover.
+func (ps *ProvisioningService) moveTenant(ctx context.Context, job *model.ProvisioningJob, tenant *model.Tenant) error {
+	startTime := time.Now()
+
+	move, err := ps.repo.GetPendingTenantMove(ctx, tenant.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		return provisioning.Permanent(errors.New("tenant has no pending move"))
+	}
+	if err != nil {
+		return err
+	}
+	pipeline, err := ps.movePipeline(ctx, move)
+	if err != nil {
+		return err
+	}
+	state := &provisioning.State{
+		Tenant:  tenant,
+		JobID:   job.ID,
+		Attempt: job.Attempts,
+		DBHost:  job.DBHost,
+		Move:    move,
+	}
+	if err := pipeline.Run(ctx, state); err != nil {
+		return err
+	}
+	if err := ps.repo.CompleteTenantMove(ctx, move.ID); err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("tenant_id", tenant.ID.String()).
+		Str("move_id", move.ID.String()).
+		Dur("duration", time.Since(startTime)).
+		Msg("Tenant move completed successfully")
+	monitoring.TenantsMoved.WithLabelValues("success").Inc()
+	return nil
+}
+
+// failMove records why the pending move of a tenant failed. A move that
+// failed before its cutover was rolled back and left the tenant on the source
+// shard; one that failed after it leaves the tenant on the target, which is
+// taken out of maintenance.
+func (ps *ProvisioningService) failMove(ctx context.Context, tenantID uuid.UUID, reason string) {
+	move, err := ps.repo.GetPendingTenantMove(ctx, tenantID)
+	if err != nil {
+		log.Error().Err(err).Str("tenant_id", tenantID.String()).Msg("Failed to load tenant move after move failure")
+		return
+	}
+	if err := ps.repo.FailTenantMove(ctx, move.ID, reason); err != nil {
+		log.Error().Err(err).Str("move_id", move.ID.String()).Msg("Failed to record tenant move failure")
+	}
+	placement, err := ps.repo.GetShardSchema(ctx, tenantID)
+	if err != nil || placement.ShardID != move.TargetShardID {
+		return
+	}
+	if err := ps.repo.SetTenantMaintenance(ctx, tenantID, false); err != nil {
+		log.Error().Err(err).Str("tenant_id", tenantID.String()).Msg("Failed to end maintenance after move failure")
+	}
+}
+
+// MoveTenant queues a move of a tenant's schema onto another database shard.
+// Progress is written to the provisioning logs like provisioning steps and
+// reported by GetTenantMove.
+func (s *TenantService) MoveTenant(ctx context.Context, req *tenantpb.MoveTenantRequest) (*tenantpb.MoveTenantResponse, error) {
+	tenant, err := s.activeTenant(ctx, req.TenantId)
+	if err != nil {
+		return nil, err
+	}
+	targetID, err := uuid.Parse(req.TargetShardId)
+	if err != nil {
+		return nil, invalidField("target_shard_id", "Invalid shard ID")
+	}
+	if s.provisioningService == nil {
+		return nil, preconditionFailed(ReasonNotMovable, "Tenants are not placed on database shards", resourceTypeTenant, tenant.ID.String())
+	}
+	move, job, err := s.provisioningService.QueueMove(ctx, tenant, targetID)
+	if err != nil {
+		return nil, toStatusError(ctx, err, "Failed to queue tenant move")
+	}
+	return &tenantpb.MoveTenantResponse{Move: toTenantMoveProto(move, "", ""), JobId: job.ID.String()}, nil
+}
+
+// GetTenantMove reports the latest move of a tenant and the step it is at
+func (s *TenantService) GetTenantMove(ctx context.Context, req *tenantpb.GetTenantMoveRequest) (*tenantpb.GetTenantMoveResponse, error) {
+	tenant, err := s.activeTenant(ctx, req.TenantId)
+	if err != nil {
+		return nil, err
+	}
+	move, err := s.repo.LatestTenantMove(ctx, tenant.ID)
+	if err != nil {
+		return nil, toStatusError(ctx, err, "Failed to fetch tenant move")
+	}
+	step, stepStatus, err := s.repo.LatestProvisioningStep(ctx, tenant.ID, move.CreatedAt)
+	if err != nil {
+		return nil, toStatusError(ctx, err, "Failed to fetch tenant move progress")
+	}
+	return &tenantpb.GetTenantMoveResponse{Move: toTenantMoveProto(move, step, stepStatus)}, nil
+}
+
+// checkNotMoving rejects changes to a tenant while it moves between shards
+func (s *TenantService) checkNotMoving(ctx context.Context, tenantID uuid.UUID) error {
+	_, err := s.repo.GetPendingTenantMove(ctx, tenantID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return toStatusError(ctx, err, "Failed to check for a tenant move")
+	}
+	return preconditionFailed(ReasonTenantMoving, "Tenant is moving to another database shard, retry once the move finished", resourceTypeTenant, tenantID.String())
+}
+
+func toTenantMoveProto(move *model.TenantMove, step, stepStatus string) *tenantpb.TenantMove {
+	pb := &tenantpb.TenantMove{
+		MoveId:            move.ID.String(),
+		TenantId:          move.TenantID.String(),
+		SourceShardId:     move.SourceShardID.String(),
+		TargetShardId:     move.TargetShardID.String(),
+		Status:            move.Status,
+		CurrentStep:       step,
+		CurrentStepStatus: stepStatus,
+		CreatedAt:         move.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:         move.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	if move.LastError != nil {
+		pb.LastError = *move.LastError
+	}
+	if move.DropAfter != nil {
+		pb.SourceDropAfter = move.DropAfter.UTC().Format(time.RFC3339)
+	}
+	if move.CompletedAt != nil {
+		pb.CompletedAt = move.CompletedAt.UTC().Format(time.RFC3339)
+	}
+	return pb
+}
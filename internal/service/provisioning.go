@@ -2,130 +2,798 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 	"github.com/teresa-solution/tenant-management-service/internal/model"
 	"github.com/teresa-solution/tenant-management-service/internal/monitoring"
+	"github.com/teresa-solution/tenant-management-service/internal/provisioning"
 	"github.com/teresa-solution/tenant-management-service/internal/store"
 )
 
+// Provisioning states reported to callers when a tenant is queued
+const (
+	// ProvisioningAccepted means workers have capacity for the tenant
+	ProvisioningAccepted = "accepted"
+	// ProvisioningQueued means the backlog is saturated and the tenant waits longer than usual
+	ProvisioningQueued = "queued"
+)
+
+// attemptLogStep is the tenant_provisioning_logs step recording failed job attempts
+const attemptLogStep = "provisioning_attempt"
+
 // ProvisioningServiceInterface defines the methods required for provisioning
 type ProvisioningServiceInterface interface {
-	QueueForProvisioning(tenant *model.Tenant)
+	// Admit checks queue capacity before a tenant is created. It returns the
+	// state the tenant will be queued in, or a ResourceExhausted error when
+	// the backlog is saturated and new work is rejected.
+	Admit(ctx context.Context) (string, error)
+	// CheckPlacement checks before a tenant is created that a database shard
+	// can take it, returning a ResourceExhausted error when none can
+	CheckPlacement(ctx context.Context, tenant *model.Tenant) error
+	QueueForProvisioning(ctx context.Context, tenant *model.Tenant) (*model.ProvisioningJob, error)
+	// QueueForDeprovisioning records a job tearing down a deleted tenant
+	QueueForDeprovisioning(ctx context.Context, tenantID uuid.UUID) (*model.ProvisioningJob, error)
+	// RenameTenant changes a provisioned tenant's subdomain, moving its DNS
+	// record along; save stores the rename
+	RenameTenant(ctx context.Context, tenant *model.Tenant, subdomain string, save func(ctx context.Context) error) error
+	// RotateCredentials gives a provisioned tenant's database role a new
+	// password and returns the database config locating the stored secret
+	RotateCredentials(ctx context.Context, tenant *model.Tenant) (*model.TenantDatabaseConfig, error)
+	// QueueMove records a move of a tenant's schema onto another shard and
+	// the job carrying it out
+	QueueMove(ctx context.Context, tenant *model.Tenant, targetID uuid.UUID) (*model.TenantMove, *model.ProvisioningJob, error)
+}
+
+// QueueConfig tunes how provisioning jobs are claimed and retried
+type QueueConfig struct {
+	// WorkerID identifies this process in job leases
+	WorkerID string
+	// PollInterval is how long an idle worker waits before looking for jobs again
+	PollInterval time.Duration
+	// LeaseDuration is how long a claimed job stays invisible to other workers
+	// without a heartbeat (the visibility timeout)
+	LeaseDuration time.Duration
+	// HeartbeatInterval is how often a running job's lease is extended
+	HeartbeatInterval time.Duration
+	// MaxAttempts is the retry budget: how many times a job runs before it is
+	// dead-lettered. Permanent errors dead-letter a job at once.
+	MaxAttempts int
+	// RetryDelay is how long a job waits after its first failed attempt; the
+	// delay doubles with every further attempt
+	RetryDelay time.Duration
+	// MaxRetryDelay caps the delay between attempts
+	MaxRetryDelay time.Duration
+	// Workers is how many jobs this replica runs at once
+	Workers int
+	// DBHost is the database host new tenants are provisioned on
+	DBHost string
+	// HostConcurrency caps the jobs this replica runs against one database host
+	HostConcurrency int
+	// HostLimits overrides HostConcurrency for individual hosts
+	HostLimits map[string]int
+	// MaxQueueDepth is the number of pending jobs at which the queue counts as
+	// saturated; zero disables the check
+	MaxQueueDepth int64
+	// RejectWhenSaturated makes Admit fail with ResourceExhausted instead of
+	// accepting work into a saturated queue
+	RejectWhenSaturated bool
+	// StatsInterval is how often queue depth and age metrics are refreshed
+	StatsInterval time.Duration
+	// RecoveryInterval is how often tenants stuck in provisioning are looked for
+	RecoveryInterval time.Duration
+	// StuckAfter is how long a tenant may stay in provisioning without
+	// progress before it is recovered
+	StuckAfter time.Duration
+}
+
+// DefaultQueueConfig returns the queue settings used when none are configured
+func DefaultQueueConfig() QueueConfig {
+	return QueueConfig{
+		WorkerID:          defaultWorkerID(),
+		PollInterval:      2 * time.Second,
+		LeaseDuration:     time.Minute,
+		HeartbeatInterval: 20 * time.Second,
+		MaxAttempts:       5,
+		RetryDelay:        30 * time.Second,
+		MaxRetryDelay:     10 * time.Minute,
+		Workers:           4,
+		DBHost:            "localhost",
+		HostConcurrency:   2,
+		MaxQueueDepth:     1000,
+		StatsInterval:     15 * time.Second,
+		RecoveryInterval:  5 * time.Minute,
+		StuckAfter:        15 * time.Minute,
+	}
 }
 
+// Validate checks that the queue settings are usable
+func (c QueueConfig) Validate() error {
+	if c.WorkerID == "" {
+		return errors.New("worker ID is required")
+	}
+	if c.PollInterval <= 0 || c.LeaseDuration <= 0 || c.HeartbeatInterval <= 0 || c.StatsInterval <= 0 || c.RetryDelay < 0 {
+		return errors.New("queue intervals must be positive")
+	}
+	if c.HeartbeatInterval >= c.LeaseDuration {
+		return errors.New("heartbeat interval must be shorter than the lease duration")
+	}
+	if c.RecoveryInterval <= 0 || c.StuckAfter <= 0 {
+		return errors.New("recovery interval and stuck threshold must be positive")
+	}
+	if c.StuckAfter <= c.LeaseDuration {
+		return errors.New("stuck threshold must be longer than the lease duration")
+	}
+	if c.MaxRetryDelay < c.RetryDelay {
+		return errors.New("max retry delay must not be shorter than the retry delay")
+	}
+	if c.MaxAttempts < 1 {
+		return errors.New("max attempts must be at least 1")
+	}
+	if c.Workers < 1 || c.HostConcurrency < 1 {
+		return errors.New("workers and host concurrency must be at least 1")
+	}
+	for host, limit := range c.HostLimits {
+		if limit < 1 {
+			return fmt.Errorf("concurrency limit for host %s must be at least 1", host)
+		}
+	}
+	if c.MaxQueueDepth < 0 {
+		return errors.New("max queue depth must not be negative")
+	}
+	return nil
+}
 
+// retryBackoff returns how long a job waits after its attempt-th failed
+// attempt: RetryDelay doubled for every earlier attempt and capped at
+// MaxRetryDelay, with the upper half scaled by jitter, a number in [0, 1), so
+// that jobs failing together do not retry together
+func (c QueueConfig) retryBackoff(attempt int, jitter float64) time.Duration {
+	delay := c.RetryDelay
+	for i := 1; i < attempt && delay < c.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > c.MaxRetryDelay {
+		delay = c.MaxRetryDelay
+	}
+	half := delay / 2
+	return half + time.Duration(jitter*float64(delay-half))
+}
 
-// ProvisioningService handles tenant provisioning workflows
+// ParseHostLimits parses comma-separated host=limit pairs
+func ParseHostLimits(spec string) (map[string]int, error) {
+	limits := make(map[string]int)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, value, ok := strings.Cut(entry, "=")
+		host = strings.TrimSpace(host)
+		if !ok || host == "" {
+			return nil, fmt.Errorf("invalid host limit %q, want host=limit", entry)
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid host limit %q: %w", entry, err)
+		}
+		limits[host] = limit
+	}
+	return limits, nil
+}
+
+func defaultWorkerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), uuid.NewString()[:8])
+}
+
+// ProvisioningService handles tenant provisioning workflows. Jobs are stored
+// in the provisioning_jobs table so they survive restarts and can be shared
+// by several replicas.
 type ProvisioningService struct {
-	repo         *store.TenantRepository
-	provisioning chan *model.Tenant
+	repo      *store.TenantRepository
+	cfg       QueueConfig
+	isolation Isolation
+	alerter   monitoring.Alerter
+	hosts     *hostLimiter
+
+	// shardMu guards shards, every shard connected to so far
+	shardMu sync.Mutex
+	shards  map[uuid.UUID]*shardConn
+}
+
+// shardConn is a connected shard with the pipelines of the tenants on it
+type shardConn struct {
+	server    provisioning.ShardServer
+	pipelines Pipelines
+	// moved drops the schemas tenants that moved away left behind
+	moved provisioning.Reclaimer
 }
 
-// NewProvisioningService creates a new ProvisioningService
-func NewProvisioningService(repo *store.TenantRepository) *ProvisioningService {
-	ps := &ProvisioningService{
-		repo:         repo,
-		provisioning: make(chan *model.Tenant, 10),
+// Pipelines holds the pipeline run for each kind of job, for the tenants of
+// one isolation mode
+type Pipelines struct {
+	Provision   *provisioning.Pipeline
+	Deprovision *provisioning.Pipeline
+	// Reclaim drops what deprovisioned tenants kept once their retention
+	// delay has passed
+	Reclaim provisioning.Reclaimer
+	// Credentials rotates the passwords of the mode's tenant roles
+	Credentials *provisioning.Credentials
+	// DNS moves the records of the mode's tenants when they are renamed
+	DNS *provisioning.TenantDNS
+	// DBHost is the database host tenants of the mode are placed on; empty
+	// means QueueConfig.DBHost
+	DBHost string
+}
+
+// Isolation holds the pipelines of every isolation mode in use and the mode
+// each tier gets
+type Isolation struct {
+	Tiers provisioning.TierIsolation
+	Modes map[string]Pipelines
+	// Shards, when set, places the schemas of new schema-isolated tenants on
+	// the registered database shards. Without any shard registered they stay
+	// in the registry database.
+	Shards *Sharding
+}
+
+// Sharding spreads schema-isolated tenants over the database shards and
+// moves them between shards
+type Sharding struct {
+	Placement provisioning.Placement
+	// Connect connects to a shard. It is called once per shard; where a
+	// shard lives cannot change.
+	Connect func(shard *model.Shard) (provisioning.ShardServer, error)
+	// Steps configures the steps run against shards
+	Steps provisioning.Config
+}
+
+// Validate checks that the schema mode and the mode of every tier have pipelines
+func (i Isolation) Validate() error {
+	if _, ok := i.Modes[model.IsolationSchema]; !ok {
+		return errors.New("schema isolation pipelines are required")
 	}
-	go ps.startProvisioningWorker()
-	return ps
+	if i.Shards != nil && (i.Shards.Placement == nil || i.Shards.Connect == nil) {
+		return errors.New("sharding needs a placement strategy and a way to connect to shards")
+	}
+	for tier, mode := range i.Tiers {
+		if _, ok := i.Modes[mode]; !ok {
+			return fmt.Errorf("tier %s uses isolation mode %s, which is not configured", tier, mode)
+		}
+	}
+	return nil
 }
 
-// startProvisioningWorker runs the background job for provisioning
-func (ps *ProvisioningService) startProvisioningWorker() {
-	for tenant := range ps.provisioning {
-		log.Info().
-			Str("tenant_id", tenant.ID.String()).
-			Str("subdomain", tenant.Subdomain).
-			Msg("Starting provisioning process")
-		if err := ps.provisionTenant(tenant); err != nil {
-			log.Error().
-				Str("tenant_id", tenant.ID.String()).
-				Err(err).
-				Msg("Provisioning failed")
+// NewProvisioningService creates a new ProvisioningService whose workers run
+// the pipeline matching each job's kind and tenant's isolation mode, and
+// raise alerts for tenants that cannot be provisioned or torn down. It only
+// enqueues jobs until Run is called.
+func NewProvisioningService(repo *store.TenantRepository, cfg QueueConfig, isolation Isolation, alerter monitoring.Alerter) *ProvisioningService {
+	return &ProvisioningService{
+		repo:      repo,
+		cfg:       cfg,
+		isolation: isolation,
+		alerter:   alerter,
+		hosts:     newHostLimiter(cfg.HostConcurrency, cfg.HostLimits),
+		shards:    make(map[uuid.UUID]*shardConn),
+	}
+}
+
+// NewDefaultPipelines builds the pipelines of built-in steps. Deprovisioning
+// only moves forward, so a deleted tenant is never brought back halfway.
+func NewDefaultPipelines(repo *store.TenantRepository, cfg provisioning.Config) Pipelines {
+	return Pipelines{
+		Provision:   provisioning.NewPipeline(repo, provisioning.DefaultSteps(repo, cfg)...),
+		Deprovision: provisioning.NewForwardPipeline(repo, provisioning.DeprovisionSteps(repo, cfg)...),
+		Reclaim:     provisioning.SchemaReclaimer(repo, cfg.Secrets),
+		Credentials: provisioning.NewCredentials(repo, cfg.Secrets),
+		DNS:         provisioning.NewTenantDNS(repo, cfg),
+	}
+}
+
+// NewDedicatedPipelines builds the pipelines of tenants isolated in a
+// database of their own on server
+func NewDedicatedPipelines(repo *store.TenantRepository, server provisioning.DatabaseServer, cfg provisioning.Config) Pipelines {
+	return Pipelines{
+		Provision:   provisioning.NewPipeline(repo, provisioning.DedicatedSteps(repo, server, cfg)...),
+		Deprovision: provisioning.NewForwardPipeline(repo, provisioning.DedicatedDeprovisionSteps(repo, server, cfg)...),
+		Reclaim:     provisioning.DedicatedReclaimer(repo, server, cfg.Secrets),
+		Credentials: provisioning.NewCredentials(server, cfg.Secrets),
+		DNS:         provisioning.NewTenantDNS(repo, cfg),
+		DBHost:      server.Host(),
+	}
+}
+
+// NewShardPipelines builds the pipelines of tenants whose schema was placed
+// on the shard shardID, served by server
+func NewShardPipelines(repo *store.TenantRepository, shardID uuid.UUID, server provisioning.ShardServer, cfg provisioning.Config) Pipelines {
+	return Pipelines{
+		Provision:   provisioning.NewPipeline(repo, provisioning.ShardSteps(repo, shardID, server, cfg)...),
+		Deprovision: provisioning.NewForwardPipeline(repo, provisioning.ShardDeprovisionSteps(repo, shardID, server, cfg)...),
+		Reclaim:     provisioning.ShardReclaimer(repo, shardID, server, cfg.Secrets),
+		Credentials: provisioning.NewCredentials(server, cfg.Secrets),
+		DNS:         provisioning.NewTenantDNS(repo, cfg),
+		DBHost:      server.Host(),
+	}
+}
+
+// NewSharedPipelines builds the pipelines of tenants keeping their rows in
+// the shared tables of the registry database
+func NewSharedPipelines(repo *store.TenantRepository, cfg provisioning.Config) Pipelines {
+	return Pipelines{
+		Provision:   provisioning.NewPipeline(repo, provisioning.SharedSteps(repo, cfg)...),
+		Deprovision: provisioning.NewForwardPipeline(repo, provisioning.SharedDeprovisionSteps(repo, cfg)...),
+		Reclaim:     provisioning.SharedReclaimer(repo, cfg.Secrets),
+		Credentials: provisioning.NewCredentials(repo, cfg.Secrets),
+		DNS:         provisioning.NewTenantDNS(repo, cfg),
+	}
+}
+
+// NewDefaultIsolation gives tenants of every tier a schema in the shared
+// database, and configures the shared mode, which needs no other server
+func NewDefaultIsolation(repo *store.TenantRepository, cfg provisioning.Config) Isolation {
+	return Isolation{Modes: map[string]Pipelines{
+		model.IsolationSchema: NewDefaultPipelines(repo, cfg),
+		model.IsolationShared: NewSharedPipelines(repo, cfg),
+	}}
+}
+
+// pipelinesFor returns the pipelines of a tenant's isolation mode: the one
+// recorded in its database config, or else the one of its tier
+func (ps *ProvisioningService) pipelinesFor(ctx context.Context, tenant *model.Tenant) (Pipelines, error) {
+	mode := ps.isolation.Tiers.Mode(tenant.Tier)
+	cfg, err := ps.repo.GetTenantDatabaseConfig(ctx, tenant.ID)
+	switch {
+	case err == nil:
+		mode = cfg.IsolationMode
+	case !errors.Is(err, store.ErrNotFound):
+		return Pipelines{}, err
+	}
+	return ps.modePipelines(ctx, tenant.ID, mode)
+}
+
+// modePipelines returns the pipelines of a tenant isolated by mode. Schema
+// tenants placed on a shard get the pipelines of their shard.
+func (ps *ProvisioningService) modePipelines(ctx context.Context, tenantID uuid.UUID, mode string) (Pipelines, error) {
+	if mode == model.IsolationSchema && ps.isolation.Shards != nil {
+		placement, err := ps.repo.GetShardSchema(ctx, tenantID)
+		switch {
+		case err == nil:
+			conn, err := ps.shardConn(ctx, placement.ShardID)
+			if err != nil {
+				return Pipelines{}, err
+			}
+			return conn.pipelines, nil
+		case !errors.Is(err, store.ErrNotFound):
+			return Pipelines{}, err
 		}
 	}
+	pipelines, ok := ps.isolation.Modes[mode]
+	if !ok {
+		return Pipelines{}, provisioning.Permanent(fmt.Errorf("isolation mode %s is not configured", mode))
+	}
+	return pipelines, nil
 }
 
-// provisionTenant simulates the provisioning process
-func (ps *ProvisioningService) provisionTenant(tenant *model.Tenant) error {
-	startTime := time.Now()
-	ctx := context.Background()
+// shardConn returns a shard with its pipelines, connecting to it the first
+// time it is needed
+func (ps *ProvisioningService) shardConn(ctx context.Context, shardID uuid.UUID) (*shardConn, error) {
+	ps.shardMu.Lock()
+	defer ps.shardMu.Unlock()
+	if conn, ok := ps.shards[shardID]; ok {
+		return conn, nil
+	}
+	shard, err := ps.repo.GetShard(ctx, shardID)
+	if err != nil {
+		return nil, err
+	}
+	server, err := ps.isolation.Shards.Connect(shard)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database shard %s: %w", shard.Name, err)
+	}
+	conn := &shardConn{
+		server:    server,
+		pipelines: NewShardPipelines(ps.repo, shardID, server, ps.isolation.Shards.Steps),
+		moved:     provisioning.MovedSchemaReclaimer(ps.repo, shardID, server),
+	}
+	ps.shards[shardID] = conn
+	return conn, nil
+}
+
+// RenameTenant moves the DNS record of a provisioned tenant to its new
+// subdomain around save, with the DNS settings of the tenant's mode
+func (ps *ProvisioningService) RenameTenant(ctx context.Context, tenant *model.Tenant, subdomain string, save func(ctx context.Context) error) error {
+	pipelines, err := ps.pipelinesFor(ctx, tenant)
+	if err != nil {
+		return err
+	}
+	if pipelines.DNS == nil {
+		return save(ctx)
+	}
+	return pipelines.DNS.Rename(ctx, tenant, subdomain, save)
+}
+
+// CheckPlacement reports whether a shard can take a new tenant, so that
+// CreateTenant fails before the tenant's subdomain is taken
+func (ps *ProvisioningService) CheckPlacement(ctx context.Context, tenant *model.Tenant) error {
+	_, err := ps.chooseShard(ctx, tenant)
+	return err
+}
 
+// chooseShard picks the shard a schema-isolated tenant's schema is created
+// on, or returns nil when the tenant is not placed on a shard
+func (ps *ProvisioningService) chooseShard(ctx context.Context, tenant *model.Tenant) (*model.Shard, error) {
+	if ps.isolation.Shards == nil || ps.isolation.Tiers.Mode(tenant.Tier) != model.IsolationSchema {
+		return nil, nil
+	}
+	shards, err := ps.repo.ListShards(ctx)
+	if err != nil || len(shards) == 0 {
+		return nil, err
+	}
+	shard, err := ps.isolation.Shards.Placement.Place(tenant, shards)
+	if errors.Is(err, provisioning.ErrNoShardCapacity) {
+		return nil, ps.noShardCapacity()
+	}
+	return shard, err
+}
+
+func (ps *ProvisioningService) noShardCapacity() error {
+	return &Error{
+		Kind:       KindResourceExhausted,
+		Reason:     ReasonNoShardCapacity,
+		Message:    "No database shard can take the tenant, retry later",
+		RetryAfter: ps.cfg.RecoveryInterval,
+	}
+}
+
+// placeTenant records the shard a new schema-isolated tenant's schema is
+// created on, when shards are registered. Tenants already placed or
+// provisioned stay where they are. The strategy picks from a snapshot of the
+// shards; the slot is only taken if the shard still has room when the
+// placement is recorded.
+func (ps *ProvisioningService) placeTenant(ctx context.Context, tenant *model.Tenant) error {
+	if ps.isolation.Shards == nil || ps.isolation.Tiers.Mode(tenant.Tier) != model.IsolationSchema {
+		return nil
+	}
+	if _, err := ps.repo.GetTenantDatabaseConfig(ctx, tenant.ID); !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+	if _, err := ps.repo.GetShardSchema(ctx, tenant.ID); !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+	shard, err := ps.chooseShard(ctx, tenant)
+	if err != nil || shard == nil {
+		return err
+	}
+	placement := &model.ShardSchema{TenantID: tenant.ID, ShardID: shard.ID, SchemaName: provisioning.ShardSchemaName(tenant.ID)}
+	err = ps.repo.PlaceTenantSchema(ctx, placement)
+	if errors.Is(err, store.ErrShardFull) {
+		return ps.noShardCapacity()
+	}
+	if err != nil {
+		return err
+	}
 	log.Info().
 		Str("tenant_id", tenant.ID.String()).
-		Str("subdomain", tenant.Subdomain).
-		Msg("Starting provisioning process")
+		Str("shard", shard.Name).
+		Str("schema_name", placement.SchemaName).
+		Msg("Placed tenant on database shard")
+	return nil
+}
 
-	// Create tenant schema
-	if err := ps.repo.CreateTenantSchema(ctx, tenant.ID, tenant.Subdomain); err != nil {
-		log.Error().
-			Str("tenant_id", tenant.ID.String()).
-			Err(err).
-			Msg("Failed to create tenant schema")
-		return err
+// dbHost returns the database host jobs for tenants of a mode run against
+func (ps *ProvisioningService) dbHost(pipelines Pipelines) string {
+	if pipelines.DBHost != "" {
+		return pipelines.DBHost
 	}
+	return ps.cfg.DBHost
+}
 
-	if err := ps.repo.CreateProvisioningLog(ctx, tenant.ID, "init", "pending", nil); err != nil {
-		log.Error().
-			Str("tenant_id", tenant.ID.String()).
-			Err(err).
-			Msg("Failed to create provisioning log")
-		return err
+// Admit reports whether the queue can take another job without blocking the
+// caller. The check is a snapshot; concurrent callers may overshoot the
+// configured depth slightly.
+func (ps *ProvisioningService) Admit(ctx context.Context) (string, error) {
+	if ps.cfg.MaxQueueDepth <= 0 {
+		return ProvisioningAccepted, nil
+	}
+	pending, err := ps.repo.CountPendingJobs(ctx)
+	if err != nil {
+		return "", err
+	}
+	if pending < ps.cfg.MaxQueueDepth {
+		return ProvisioningAccepted, nil
 	}
+	if ps.cfg.RejectWhenSaturated {
+		monitoring.ProvisioningEnqueueSaturated.WithLabelValues("rejected").Inc()
+		return "", &Error{
+			Kind:       KindResourceExhausted,
+			Reason:     ReasonQueueSaturated,
+			Message:    "Provisioning queue is full, retry later",
+			RetryAfter: ps.cfg.RetryDelay,
+		}
+	}
+	monitoring.ProvisioningEnqueueSaturated.WithLabelValues("queued").Inc()
+	return ProvisioningQueued, nil
+}
 
-	time.Sleep(2 * time.Second)
-	if err := ps.repo.CreateProvisioningLog(ctx, tenant.ID, "db_setup", "in_progress", map[string]interface{}{"host": "db.example.com"}); err != nil {
-		log.Error().
-			Str("tenant_id", tenant.ID.String()).
-			Err(err).
-			Msg("Failed to log db_setup step")
-		return err
+// QueueForProvisioning records a durable provisioning job for a tenant
+func (ps *ProvisioningService) QueueForProvisioning(ctx context.Context, tenant *model.Tenant) (*model.ProvisioningJob, error) {
+	if err := ps.placeTenant(ctx, tenant); err != nil {
+		return nil, err
+	}
+	pipelines, err := ps.pipelinesFor(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	job, err := ps.repo.EnqueueJob(ctx, tenant.ID, model.JobKindProvision, ps.dbHost(pipelines), ps.cfg.MaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	log.Info().
+		Str("tenant_id", tenant.ID.String()).
+		Str("job_id", job.ID.String()).
+		Str("db_host", job.DBHost).
+		Msg("Queued tenant for provisioning")
+	return job, nil
+}
+
+// processJob runs one claimed job while heartbeating its lease. If the lease
+// is lost the job context is cancelled, since another worker may take over.
+func (ps *ProvisioningService) processJob(ctx context.Context, job *model.ProvisioningJob) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go ps.heartbeat(jobCtx, cancel, job)
+
+	logger := log.With().
+		Str("job_id", job.ID.String()).
+		Str("tenant_id", job.TenantID.String()).
+		Int("attempt", job.Attempts).
+		Logger()
+
+	tenant, err := ps.repo.GetByID(jobCtx, job.TenantID)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		err = provisioning.Permanent(err)
+	case err != nil:
+	case job.Kind == model.JobKindDeprovision:
+		logger.Info().Str("subdomain", tenant.Subdomain).Msg("Starting deprovisioning process")
+		err = ps.deprovisionTenant(jobCtx, job, tenant)
+	case tenant.DeletedAt != nil:
+		err = provisioning.Permanent(errors.New("tenant was deleted before it was provisioned"))
+	case job.Kind == model.JobKindMove:
+		logger.Info().Str("subdomain", tenant.Subdomain).Msg("Starting tenant move")
+		err = ps.moveTenant(jobCtx, job, tenant)
+	default:
+		logger.Info().Str("subdomain", tenant.Subdomain).Msg("Starting provisioning process")
+		err = ps.provisionTenant(jobCtx, job, tenant)
+	}
+	if ctx.Err() != nil {
+		// Shutting down: leave the lease to lapse so the job is picked up again
+		return
 	}
 
-	var provisioningStatus string
-	if time.Now().UnixNano()%2 == 0 {
-		if err := ps.repo.CreateProvisioningLog(ctx, tenant.ID, "db_setup", "success", nil); err != nil {
-			log.Error().
-				Str("tenant_id", tenant.ID.String()).
-				Err(err).
-				Msg("Failed to log db_setup success")
-			return err
+	if err == nil {
+		monitoring.ProvisioningAttempts.WithLabelValues("success").Inc()
+		if err := ps.repo.CompleteJob(ctx, job.ID, ps.cfg.WorkerID); err != nil {
+			logger.Error().Err(err).Msg("Failed to complete provisioning job")
 		}
-		tenant.Status = "active"
-		tenant.Provisioned = true
-		provisioningStatus = "success"
-		log.Info().
-			Str("tenant_id", tenant.ID.String()).
-			Msg("Provisioning completed successfully")
+		return
+	}
+
+	transient := provisioning.IsTransient(err)
+	logger.Error().Err(err).Bool("transient", transient).Msg("Provisioning attempt failed")
+	if transient && !job.Exhausted() {
+		monitoring.ProvisioningAttempts.WithLabelValues("transient").Inc()
+		runAt := time.Now().Add(ps.cfg.retryBackoff(job.Attempts, rand.Float64()))
+		ps.recordAttempt(ctx, job, err, transient, &runAt)
+		if err := ps.repo.RetryJob(ctx, job.ID, ps.cfg.WorkerID, err.Error(), runAt); err != nil {
+			logger.Error().Err(err).Msg("Failed to reschedule provisioning job")
+		}
+		return
+	}
+	if transient {
+		monitoring.ProvisioningAttempts.WithLabelValues("transient").Inc()
 	} else {
-		if err := ps.repo.CreateProvisioningLog(ctx, tenant.ID, "db_setup", "failed", map[string]interface{}{"error": "timeout"}); err != nil {
-			log.Error().
-				Str("tenant_id", tenant.ID.String()).
-				Err(err).
-				Msg("Failed to log db_setup failure")
-			return err
+		monitoring.ProvisioningAttempts.WithLabelValues("permanent").Inc()
+	}
+	ps.recordAttempt(ctx, job, err, transient, nil)
+	if err := ps.repo.DeadLetterJob(ctx, job.ID, ps.cfg.WorkerID, err.Error()); err != nil {
+		logger.Error().Err(err).Msg("Failed to dead-letter provisioning job")
+		return
+	}
+	ps.markFailed(ctx, job, err.Error())
+}
+
+// recordAttempt logs a failed attempt of a job and when it runs again, if it does
+func (ps *ProvisioningService) recordAttempt(ctx context.Context, job *model.ProvisioningJob, err error, transient bool, retryAt *time.Time) {
+	details := map[string]interface{}{
+		"job_id":       job.ID.String(),
+		"attempt":      job.Attempts,
+		"max_attempts": job.MaxAttempts,
+		"error":        err.Error(),
+		"transient":    transient,
+	}
+	if retryAt != nil {
+		details["retry_at"] = retryAt.UTC().Format(time.RFC3339)
+	}
+	if err := ps.repo.CreateProvisioningLog(ctx, job.TenantID, attemptLogStep, provisioning.LogFailed, details); err != nil {
+		log.Error().Err(err).Str("job_id", job.ID.String()).Msg("Failed to record provisioning attempt")
+	}
+}
+
+// heartbeat extends the lease of a running job until ctx is done
+func (ps *ProvisioningService) heartbeat(ctx context.Context, cancel context.CancelFunc, job *model.ProvisioningJob) {
+	ticker := time.NewTicker(ps.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := ps.repo.HeartbeatJob(ctx, job.ID, ps.cfg.WorkerID, ps.cfg.LeaseDuration)
+			if errors.Is(err, store.ErrLeaseLost) {
+				log.Warn().Str("job_id", job.ID.String()).Msg("Lost provisioning job lease, abandoning job")
+				cancel()
+				return
+			}
+			if err != nil && ctx.Err() == nil {
+				log.Error().Err(err).Str("job_id", job.ID.String()).Msg("Failed to heartbeat provisioning job")
+			}
 		}
-		tenant.Status = "error"
-		provisioningStatus = "failed"
-		log.Warn().
-			Str("tenant_id", tenant.ID.String()).
-			Msg("Provisioning failed due to timeout")
+	}
+}
 
-		monitoring.MockAlert("Tenant provisioning failed", map[string]string{
-			"tenant_id": tenant.ID.String(),
-			"subdomain": tenant.Subdomain,
-			"error":     "timeout",
-		})
+// reapExpiredJobs dead-letters jobs whose worker died during the final attempt
+func (ps *ProvisioningService) reapExpiredJobs(ctx context.Context) {
+	jobs, err := ps.repo.DeadLetterExpiredJobs(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to dead-letter expired provisioning jobs")
+		}
+		return
+	}
+	for _, job := range jobs {
+		reason := "lease expired on final attempt"
+		if err := ps.rollBack(ctx, job); err != nil {
+			reason += "; rollback incomplete: " + err.Error()
+		}
+		ps.markFailed(ctx, job, reason)
+	}
+}
+
+// rollBack compensates every step of a provisioning or move job whose
+// worker died. Deprovisioning jobs are never rolled back.
+func (ps *ProvisioningService) rollBack(ctx context.Context, job *model.ProvisioningJob) error {
+	if job.Kind != model.JobKindProvision && job.Kind != model.JobKindMove {
+		return nil
+	}
+	tenant, err := ps.repo.GetByID(ctx, job.TenantID)
+	if err != nil {
+		return err
+	}
+	state := &provisioning.State{
+		Tenant:  tenant,
+		JobID:   job.ID,
+		Attempt: job.Attempts,
+		DBHost:  job.DBHost,
+	}
+	if job.Kind == model.JobKindMove {
+		move, err := ps.repo.GetPendingTenantMove(ctx, tenant.ID)
+		if err != nil {
+			return err
+		}
+		pipeline, err := ps.movePipeline(ctx, move)
+		if err != nil {
+			return err
+		}
+		state.Move = move
+		return pipeline.Compensate(ctx, state)
+	}
+	pipelines, err := ps.pipelinesFor(ctx, tenant)
+	if err != nil {
+		return err
 	}
+	return pipelines.Provision.Compensate(ctx, state)
+}
+
+// markFailed moves the tenant of a dead-lettered job into the error state,
+// recording reason as the root cause, and raises an alert. A failed
+// provisioning run has already rolled back its steps, so the tenant holds no
+// provisioning artifacts; a failed deprovisioning run leaves its completed
+// steps in place for an operator to finish. A failed move only fails the
+// move: the tenant keeps serving from the shard it points at.
+func (ps *ProvisioningService) markFailed(ctx context.Context, job *model.ProvisioningJob, reason string) {
+	log.Warn().
+		Str("job_id", job.ID.String()).
+		Str("tenant_id", job.TenantID.String()).
+		Int("attempts", job.Attempts).
+		Msg("Provisioning job dead-lettered")
 
-	monitoring.TenantsProvisioned.WithLabelValues(provisioningStatus).Inc()
-	duration := time.Since(startTime).Seconds()
-	monitoring.ProvisioningDuration.Observe(duration)
+	name, message := "TenantProvisioningFailed", "Tenant provisioning failed"
+	switch job.Kind {
+	case model.JobKindDeprovision:
+		name, message = "TenantDeprovisioningFailed", "Tenant deprovisioning failed"
+		monitoring.TenantsDeprovisioned.WithLabelValues("failed").Inc()
+	case model.JobKindMove:
+		name, message = "TenantMoveFailed", "Tenant move failed"
+		monitoring.TenantsMoved.WithLabelValues("failed").Inc()
+	default:
+		monitoring.TenantsProvisioned.WithLabelValues("failed").Inc()
+	}
+	err := ps.alerter.Alert(ctx, monitoring.Alert{
+		Name:     name,
+		Severity: monitoring.SeverityCritical,
+		Message:  message,
+		Labels: map[string]string{
+			"tenant_id": job.TenantID.String(),
+			"job_id":    job.ID.String(),
+			"attempts":  strconv.Itoa(job.Attempts),
+			"error":     reason,
+		},
+		FiredAt: time.Now(),
+	})
+	if err != nil {
+		log.Error().Err(err).Str("job_id", job.ID.String()).Msg("Failed to send provisioning alert")
+	}
+	if job.Kind == model.JobKindMove {
+		ps.failMove(ctx, job.TenantID, reason)
+		return
+	}
 
+	tenant, err := ps.repo.GetByID(ctx, job.TenantID)
+	if err != nil {
+		log.Error().Err(err).Str("tenant_id", job.TenantID.String()).Msg("Failed to load tenant after provisioning failure")
+		return
+	}
+	if tenant.DeletedAt != nil && job.Kind == model.JobKindProvision {
+		// Deprovisioning owns deleted tenants
+		return
+	}
+	tenant.Status = "error"
+	tenant.StatusReason = reason
 	if err := ps.repo.Update(ctx, tenant); err != nil {
+		log.Error().Err(err).Str("tenant_id", tenant.ID.String()).Msg("Failed to update tenant status after provisioning")
+	}
+}
+
+// provisionTenant runs the provisioning pipeline for a tenant and activates
+// it. Every step is idempotent, so a retried job resumes safely.
+func (ps *ProvisioningService) provisionTenant(ctx context.Context, job *model.ProvisioningJob, tenant *model.Tenant) error {
+	startTime := time.Now()
+
+	state := &provisioning.State{
+		Tenant:  tenant,
+		JobID:   job.ID,
+		Attempt: job.Attempts,
+		DBHost:  job.DBHost,
+	}
+	pipelines, err := ps.pipelinesFor(ctx, tenant)
+	if err != nil {
+		return err
+	}
+	if err := pipelines.Provision.Run(ctx, state); err != nil {
+		return err
+	}
+
+	err = ps.repo.ActivateTenant(ctx, tenant.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		// Deleted while provisioning ran; its deprovisioning job cleans up
+		log.Info().Str("tenant_id", tenant.ID.String()).Msg("Tenant deleted during provisioning, not activating")
+		return nil
+	}
+	if err != nil {
 		log.Error().
 			Str("tenant_id", tenant.ID.String()).
 			Err(err).
@@ -133,10 +801,10 @@ func (ps *ProvisioningService) provisionTenant(tenant *model.Tenant) error {
 		return err
 	}
 
+	log.Info().
+		Str("tenant_id", tenant.ID.String()).
+		Msg("Provisioning completed successfully")
+	monitoring.TenantsProvisioned.WithLabelValues("success").Inc()
+	monitoring.ProvisioningDuration.Observe(time.Since(startTime).Seconds())
 	return nil
 }
-
-// QueueForProvisioning adds a tenant to the provisioning queue
-func (ps *ProvisioningService) QueueForProvisioning(tenant *model.Tenant) {
-	ps.provisioning <- tenant
-}
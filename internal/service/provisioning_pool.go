@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"github.com/teresa-solution/tenant-management-service/internal/monitoring"
+	"github.com/teresa-solution/tenant-management-service/internal/store"
+)
+
+// Run claims and processes provisioning jobs with a pool of cfg.Workers
+// workers until ctx is cancelled. Jobs for a database host that already runs
+// its share of jobs in this replica are left for later or for other replicas.
+func (ps *ProvisioningService) Run(ctx context.Context) {
+	log.Info().
+		Str("worker_id", ps.cfg.WorkerID).
+		Int("workers", ps.cfg.Workers).
+		Msg("Provisioning worker pool started")
+	go ps.collectStats(ctx)
+	go ps.maintenanceLoop(ctx)
+
+	slots := make(chan struct{}, ps.cfg.Workers)
+	var wg sync.WaitGroup
+	defer func() {
+		wg.Wait()
+		log.Info().Str("worker_id", ps.cfg.WorkerID).Msg("Provisioning worker pool stopped")
+	}()
+
+	for {
+		// Wait for a free worker before claiming, so claimed jobs never sit idle
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+
+		ps.reapExpiredJobs(ctx)
+		job, err := ps.repo.ClaimJob(ctx, ps.cfg.WorkerID, ps.cfg.LeaseDuration, ps.hosts.saturated())
+		if err != nil {
+			<-slots
+			if !errors.Is(err, store.ErrNoJobReady) && ctx.Err() == nil {
+				log.Error().Err(err).Msg("Failed to claim provisioning job")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(ps.cfg.PollInterval):
+			}
+			continue
+		}
+
+		ps.hosts.acquire(job.DBHost)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			defer ps.hosts.release(job.DBHost)
+			ps.processJob(ctx, job)
+		}()
+	}
+}
+
+// collectStats refreshes the queue depth and age metrics
+func (ps *ProvisioningService) collectStats(ctx context.Context) {
+	ticker := time.NewTicker(ps.cfg.StatsInterval)
+	defer ticker.Stop()
+	for {
+		stats, err := ps.repo.JobQueueStats(ctx)
+		if err == nil {
+			monitoring.ProvisioningQueueDepth.WithLabelValues(model.JobStatusPending).Set(float64(stats.Pending))
+			monitoring.ProvisioningQueueDepth.WithLabelValues(model.JobStatusRunning).Set(float64(stats.Running))
+			monitoring.ProvisioningQueueDepth.WithLabelValues(model.JobStatusDeadLetter).Set(float64(stats.DeadLetter))
+			monitoring.ProvisioningQueueOldestAge.Set(stats.OldestPendingAge.Seconds())
+		} else if ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to collect provisioning queue stats")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// hostLimiter counts the jobs running against each database host in this
+// replica. Only the dispatcher acquires, so a host reported as unsaturated
+// cannot fill up before the claimed job is counted.
+type hostLimiter struct {
+	mu           sync.Mutex
+	running      map[string]int
+	defaultLimit int
+	limits       map[string]int
+}
+
+func newHostLimiter(defaultLimit int, limits map[string]int) *hostLimiter {
+	return &hostLimiter{running: make(map[string]int), defaultLimit: defaultLimit, limits: limits}
+}
+
+func (h *hostLimiter) limit(host string) int {
+	if limit, ok := h.limits[host]; ok {
+		return limit
+	}
+	return h.defaultLimit
+}
+
+// saturated lists the hosts that cannot take another job
+func (h *hostLimiter) saturated() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var hosts []string
+	for host, running := range h.running {
+		if running >= h.limit(host) {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+func (h *hostLimiter) acquire(host string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.running[host]++
+	monitoring.ProvisioningWorkersBusy.WithLabelValues(host).Set(float64(h.running[host]))
+}
+
+func (h *hostLimiter) release(host string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.running[host]--
+	monitoring.ProvisioningWorkersBusy.WithLabelValues(host).Set(float64(h.running[host]))
+	if h.running[host] <= 0 {
+		delete(h.running, host)
+	}
+}
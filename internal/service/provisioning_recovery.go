@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"github.com/teresa-solution/tenant-management-service/internal/monitoring"
+)
+
+// Recovery actions taken for tenants stuck in provisioning
+const (
+	// RecoveryRequeued queues a new job for a tenant without an outstanding one
+	RecoveryRequeued = "requeued"
+	// RecoveryReleased takes a stalled job away from its worker so it runs again
+	RecoveryReleased = "released"
+	// RecoveryFailed moves a tenant whose job is dead-lettered into the error state
+	RecoveryFailed = "failed"
+)
+
+// recoveryBatchSize caps the stuck tenants handled per scan
+const recoveryBatchSize = 100
+
+// recoveryAction decides what to do with a stuck tenant. The decision only
+// depends on the tenant's latest job, so replicas scanning concurrently agree.
+func recoveryAction(stuck *model.StuckTenant) string {
+	switch {
+	case stuck.Job == nil, stuck.Job.Status == model.JobStatusSucceeded:
+		// The tenant was never queued, e.g. the process died between creating
+		// the tenant and enqueueing, or its job finished without activating it.
+		// The pipeline is idempotent, so running it again is safe.
+		return RecoveryRequeued
+	case stuck.Job.Status == model.JobStatusDeadLetter:
+		return RecoveryFailed
+	default:
+		return RecoveryReleased
+	}
+}
+
+// maintenanceLoop recovers stuck tenants and reclaims what deprovisioned
+// tenants kept at startup and then every RecoveryInterval
+func (ps *ProvisioningService) maintenanceLoop(ctx context.Context) {
+	ticker := time.NewTicker(ps.cfg.RecoveryInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := ps.RecoverStuckTenants(ctx); err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to recover stuck provisioning tenants")
+		}
+		ps.reclaimDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RecoverStuckTenants finds tenants left in provisioning or deprovisioning
+// with nobody working on them and resumes or fails each one. It returns how many were recovered.
+func (ps *ProvisioningService) RecoverStuckTenants(ctx context.Context) (int, error) {
+	stuck, err := ps.repo.FindStuckTenants(ctx, ps.cfg.StuckAfter, recoveryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	monitoring.ProvisioningStuckTenants.Set(float64(len(stuck)))
+
+	recovered := 0
+	for _, t := range stuck {
+		action := recoveryAction(t)
+		logger := log.With().
+			Str("tenant_id", t.TenantID.String()).
+			Str("status", t.Status).
+			Str("action", action).
+			Time("last_progress_at", t.LastProgressAt).
+			Logger()
+
+		if err := ps.recover(ctx, t, action); err != nil {
+			logger.Error().Err(err).Msg("Failed to recover tenant stuck in provisioning")
+			continue
+		}
+		logger.Warn().Msg("Recovered tenant stuck in provisioning")
+		monitoring.ProvisioningRecovered.WithLabelValues(action).Inc()
+		recovered++
+	}
+	return recovered, nil
+}
+
+func (ps *ProvisioningService) recover(ctx context.Context, stuck *model.StuckTenant, action string) error {
+	switch action {
+	case RecoveryRequeued:
+		if stuck.Status == "deprovisioning" {
+			_, err := ps.QueueForDeprovisioning(ctx, stuck.TenantID)
+			return err
+		}
+		tenant, err := ps.repo.GetByID(ctx, stuck.TenantID)
+		if err != nil {
+			return err
+		}
+		_, err = ps.QueueForProvisioning(ctx, tenant)
+		return err
+
+	case RecoveryFailed:
+		reason := "provisioning job dead-lettered"
+		if stuck.Job.LastError != nil {
+			reason = *stuck.Job.LastError
+		}
+		ps.markFailed(ctx, stuck.Job, reason)
+		return nil
+
+	default:
+		reason := fmt.Sprintf("no provisioning progress since %s", stuck.LastProgressAt.UTC().Format(time.RFC3339))
+		job, err := ps.repo.ReleaseStalledJob(ctx, stuck.Job.ID, reason)
+		if err != nil {
+			return err
+		}
+		if job.Status == model.JobStatusDeadLetter {
+			if err := ps.rollBack(ctx, job); err != nil {
+				reason += "; rollback incomplete: " + err.Error()
+			}
+			ps.markFailed(ctx, job, reason)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"github.com/teresa-solution/tenant-management-service/internal/provisioning"
+)
+
+func TestQueueConfig_Validate(t *testing.T) {
+	cfg := DefaultQueueConfig()
+	assert.NoError(t, cfg.Validate())
+	assert.NotEmpty(t, cfg.WorkerID)
+
+	bad := cfg
+	bad.HeartbeatInterval = bad.LeaseDuration
+	assert.Error(t, bad.Validate())
+
+	bad = cfg
+	bad.MaxAttempts = 0
+	assert.Error(t, bad.Validate())
+
+	bad = cfg
+	bad.PollInterval = -time.Second
+	assert.Error(t, bad.Validate())
+}
+
+func TestParseHostLimits(t *testing.T) {
+	limits, err := ParseHostLimits("db1=4, db2=1,")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"db1": 4, "db2": 1}, limits)
+
+	_, err = ParseHostLimits("db1")
+	assert.Error(t, err)
+	_, err = ParseHostLimits("db1=many")
+	assert.Error(t, err)
+}
+
+func TestHostLimiter(t *testing.T) {
+	h := newHostLimiter(2, map[string]int{"small": 1})
+
+	h.acquire("small")
+	h.acquire("big")
+	assert.Equal(t, []string{"small"}, h.saturated())
+
+	h.acquire("big")
+	assert.ElementsMatch(t, []string{"small", "big"}, h.saturated())
+
+	h.release("small")
+	h.release("big")
+	assert.Empty(t, h.saturated())
+}
+
+func TestQueueConfig_RetryBackoff(t *testing.T) {
+	cfg := DefaultQueueConfig()
+	cfg.RetryDelay = 10 * time.Second
+	cfg.MaxRetryDelay = time.Minute
+
+	// Without jitter the delay is half the exponential step
+	assert.Equal(t, 5*time.Second, cfg.retryBackoff(1, 0))
+	assert.Equal(t, 10*time.Second, cfg.retryBackoff(2, 0))
+	assert.Equal(t, 20*time.Second, cfg.retryBackoff(3, 0))
+	assert.Equal(t, 30*time.Second, cfg.retryBackoff(10, 0))
+
+	// Jitter spreads the delay up to the full step, never past the cap
+	assert.Equal(t, 15*time.Second, cfg.retryBackoff(2, 0.5))
+	assert.Less(t, cfg.retryBackoff(10, 0.999), time.Minute+time.Nanosecond)
+
+	bad := cfg
+	bad.MaxRetryDelay = time.Second
+	assert.Error(t, bad.Validate())
+}
+
+func TestRecoveryAction(t *testing.T) {
+	job := func(status string) *model.ProvisioningJob {
+		return &model.ProvisioningJob{Status: status}
+	}
+	assert.Equal(t, RecoveryRequeued, recoveryAction(&model.StuckTenant{}))
+	assert.Equal(t, RecoveryRequeued, recoveryAction(&model.StuckTenant{Job: job(model.JobStatusSucceeded)}))
+	assert.Equal(t, RecoveryFailed, recoveryAction(&model.StuckTenant{Job: job(model.JobStatusDeadLetter)}))
+	assert.Equal(t, RecoveryReleased, recoveryAction(&model.StuckTenant{Job: job(model.JobStatusRunning)}))
+
+	cfg := DefaultQueueConfig()
+	cfg.StuckAfter = cfg.LeaseDuration
+	assert.Error(t, cfg.Validate())
+}
+
+func TestIsolation_Validate(t *testing.T) {
+	isolation := Isolation{Modes: map[string]Pipelines{model.IsolationSchema: {}}}
+	assert.NoError(t, isolation.Validate())
+
+	isolation.Tiers = provisioning.TierIsolation{model.TierEnterprise: model.IsolationDatabase}
+	assert.Error(t, isolation.Validate())
+
+	isolation.Modes[model.IsolationDatabase] = Pipelines{}
+	assert.NoError(t, isolation.Validate())
+
+	assert.Error(t, Isolation{}.Validate())
+}
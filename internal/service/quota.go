@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+
+	"github.com/teresa-solution/tenant-management-service/internal/quota"
+	tenantpb "github.com/teresa-solution/tenant-management-service/proto/gen"
+)
+
+// GetQuotaUsage reports the limits and current usage of a tenant's resources
+func (s *TenantService) GetQuotaUsage(ctx context.Context, req *tenantpb.GetQuotaUsageRequest) (*tenantpb.GetQuotaUsageResponse, error) {
+	tenant, err := s.activeTenant(ctx, req.TenantId)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.quotas.Usage(ctx, tenant)
+	if err != nil {
+		return nil, toStatusError(ctx, err, "Failed to compute quota usage")
+	}
+
+	resp := &tenantpb.GetQuotaUsageResponse{Tier: tenant.Tier}
+	for _, u := range usage {
+		resp.Usage = append(resp.Usage, toQuotaUsageProto(u))
+	}
+	return resp, nil
+}
+
+// SetQuotaOverride replaces or restores the tier limit of one resource for a tenant
+func (s *TenantService) SetQuotaOverride(ctx context.Context, req *tenantpb.SetQuotaOverrideRequest) (*tenantpb.SetQuotaOverrideResponse, error) {
+	if !quota.ValidResource(req.Resource) || quota.Resource(req.Resource) == quota.ResourceTenants {
+		return nil, invalidField("resource", "invalid resource")
+	}
+	if !req.Clear && req.Limit < quota.Unlimited {
+		return nil, invalidField("limit", "limit must be -1 (unlimited) or greater")
+	}
+	tenant, err := s.activeTenant(ctx, req.TenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Clear {
+		err = s.repo.DeleteTenantQuotaOverride(ctx, tenant.ID, req.Resource)
+	} else {
+		err = s.repo.SetTenantQuotaOverride(ctx, tenant.ID, req.Resource, req.Limit)
+	}
+	if err != nil {
+		return nil, toStatusError(ctx, err, "Failed to update quota override")
+	}
+
+	usage, err := s.quotas.Usage(ctx, tenant)
+	if err != nil {
+		return nil, toStatusError(ctx, err, "Failed to compute quota usage")
+	}
+	for _, u := range usage {
+		if string(u.Resource) == req.Resource {
+			return &tenantpb.SetQuotaOverrideResponse{Usage: toQuotaUsageProto(u)}, nil
+		}
+	}
+	return &tenantpb.SetQuotaOverrideResponse{}, nil
+}
+
+func toQuotaUsageProto(u quota.Usage) *tenantpb.QuotaUsage {
+	return &tenantpb.QuotaUsage{
+		Resource:   string(u.Resource),
+		Limit:      u.Limit,
+		Used:       u.Used,
+		Overridden: u.Overridden,
+	}
+}
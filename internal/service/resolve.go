@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"github.com/teresa-solution/tenant-management-service/internal/store"
+	tenantpb "github.com/teresa-solution/tenant-management-service/proto/gen"
+)
+
+// ResolveTenant finds the tenant a hostname under the base domain or a
+// verified custom domain belongs to, together with where its data lives and
+// how it is isolated
+func (s *TenantService) ResolveTenant(ctx context.Context, req *tenantpb.ResolveTenantRequest) (*tenantpb.ResolveTenantResponse, error) {
+	if req.Host == "" {
+		return nil, invalidField("host", "host is required")
+	}
+	var tenant *model.Tenant
+	var err error
+	if subdomain, ok := subdomainOf(req.Host, s.baseDomain); ok {
+		tenant, err = s.repo.GetBySubdomain(ctx, subdomain)
+		if err != nil {
+			return nil, toStatusError(ctx, err, "Failed to resolve tenant")
+		}
+	} else {
+		tenant, err = s.customDomainTenant(ctx, req.Host)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if tenant.DeletedAt != nil {
+		return nil, notFoundError(store.ResourceTenant, req.Host)
+	}
+
+	resp := &tenantpb.ResolveTenantResponse{Tenant: toTenantProto(tenant)}
+	cfg, err := s.repo.GetTenantDatabaseConfig(ctx, tenant.ID)
+	switch {
+	case err == nil:
+		resp.Database = toDatabaseConfigProto(cfg)
+	case !errors.Is(err, store.ErrNotFound):
+		return nil, toStatusError(ctx, err, "Failed to fetch database config")
+	}
+	return resp, nil
+}
+
+// subdomainOf returns the tenant subdomain of a hostname directly under
+// baseDomain, ignoring case, a port and a trailing dot
+func subdomainOf(host, baseDomain string) (string, bool) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	suffix := "." + strings.Trim(strings.ToLower(baseDomain), ".")
+	subdomain, ok := strings.CutSuffix(host, suffix)
+	if !ok || subdomain == "" || strings.Contains(subdomain, ".") {
+		return "", false
+	}
+	return subdomain, true
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubdomainOf(t *testing.T) {
+	tests := []struct {
+		host      string
+		subdomain string
+		ok        bool
+	}{
+		{"acme.example.com", "acme", true},
+		{"ACME.Example.com.", "acme", true},
+		{"acme.example.com:8443", "acme", true},
+		{"example.com", "", false},
+		{".example.com", "", false},
+		{"api.acme.example.com", "", false},
+		{"acme.example.org", "", false},
+		{"acmeexample.com", "", false},
+	}
+	for _, tt := range tests {
+		subdomain, ok := subdomainOf(tt.host, "example.com")
+		assert.Equal(t, tt.ok, ok, tt.host)
+		assert.Equal(t, tt.subdomain, subdomain, tt.host)
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"github.com/teresa-solution/tenant-management-service/internal/store"
+	tenantpb "github.com/teresa-solution/tenant-management-service/proto/gen"
+)
+
+// Column sizes of database_shards and tenants
+const (
+	maxShardNameLength = 63
+	maxRegionLength    = 64
+)
+
+const defaultShardPort = 5432
+
+// CreateShard registers a database that tenant schemas may be placed on
+func (s *TenantService) CreateShard(ctx context.Context, req *tenantpb.CreateShardRequest) (*tenantpb.CreateShardResponse, error) {
+	shard := &model.Shard{
+		Name:         req.Name,
+		Host:         req.Host,
+		Port:         int(req.Port),
+		DatabaseName: req.DatabaseName,
+		Region:       req.Region,
+		Capacity:     int(req.Capacity),
+		Weight:       int(req.Weight),
+		Draining:     req.Draining,
+	}
+	if shard.Port == 0 {
+		shard.Port = defaultShardPort
+	}
+	if shard.Weight == 0 {
+		shard.Weight = 1
+	}
+	if err := validateShard(shard); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateShard(ctx, shard); err != nil {
+		return nil, toStatusError(ctx, err, "Failed to create database shard")
+	}
+	log.Info().
+		Str("shard_id", shard.ID.String()).
+		Str("shard", shard.Name).
+		Str("host", shard.Host).
+		Msg("Registered database shard")
+	return &tenantpb.CreateShardResponse{Shard: toShardProto(shard)}, nil
+}
+
+// ListShards returns every database shard with how many tenants it holds
+func (s *TenantService) ListShards(ctx context.Context, req *tenantpb.ListShardsRequest) (*tenantpb.ListShardsResponse, error) {
+	shards, err := s.repo.ListShards(ctx)
+	if err != nil {
+		return nil, toStatusError(ctx, err, "Failed to list database shards")
+	}
+	resp := &tenantpb.ListShardsResponse{}
+	for i := range shards {
+		resp.Shards = append(resp.Shards, toShardProto(&shards[i]))
+	}
+	return resp, nil
+}
+
+// UpdateShard changes the placement settings of a shard. Lowering its
+// capacity below its tenant count or draining it only stops new placements.
+func (s *TenantService) UpdateShard(ctx context.Context, req *tenantpb.UpdateShardRequest) (*tenantpb.UpdateShardResponse, error) {
+	id, err := uuid.Parse(req.ShardId)
+	if err != nil {
+		return nil, invalidField("shard_id", "Invalid shard ID")
+	}
+	shard, err := s.repo.GetShard(ctx, id)
+	if err != nil {
+		return nil, toStatusError(ctx, err, "Failed to get database shard")
+	}
+	if req.Region != nil {
+		shard.Region = *req.Region
+	}
+	if req.Capacity != nil {
+		shard.Capacity = int(*req.Capacity)
+	}
+	if req.Weight != nil {
+		shard.Weight = int(*req.Weight)
+	}
+	if req.Draining != nil {
+		shard.Draining = *req.Draining
+	}
+	if err := validateShard(shard); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateShard(ctx, shard); err != nil {
+		return nil, toStatusError(ctx, err, "Failed to update database shard")
+	}
+	return &tenantpb.UpdateShardResponse{Shard: toShardProto(shard)}, nil
+}
+
+// DeleteShard forgets a shard that no tenant schema or snapshot is kept on
+func (s *TenantService) DeleteShard(ctx context.Context, req *tenantpb.DeleteShardRequest) (*tenantpb.DeleteShardResponse, error) {
+	id, err := uuid.Parse(req.ShardId)
+	if err != nil {
+		return nil, invalidField("shard_id", "Invalid shard ID")
+	}
+	err = s.repo.DeleteShard(ctx, id)
+	if errors.Is(err, store.ErrShardInUse) {
+		return nil, preconditionFailed(ReasonShardInUse, "Database shard still holds tenant schemas or snapshots", resourceTypeShard, id.String())
+	}
+	if err != nil {
+		return nil, toStatusError(ctx, err, "Failed to delete database shard")
+	}
+	log.Info().Str("shard_id", id.String()).Msg("Deleted database shard")
+	return &tenantpb.DeleteShardResponse{}, nil
+}
+
+// validateShard checks the fields of a shard about to be saved
+func validateShard(shard *model.Shard) error {
+	var v violations
+	if shard.Name == "" {
+		v.add("name", "name is required")
+	} else if len(shard.Name) > maxShardNameLength {
+		v.add("name", fmt.Sprintf("name must be at most %d characters", maxShardNameLength))
+	}
+	if shard.Host == "" {
+		v.add("host", "host is required")
+	}
+	if shard.Port < 1 || shard.Port > 65535 {
+		v.add("port", "port must be between 1 and 65535")
+	}
+	if shard.DatabaseName == "" {
+		v.add("database_name", "database name is required")
+	}
+	if len(shard.Region) > maxRegionLength {
+		v.add("region", fmt.Sprintf("region must be at most %d characters", maxRegionLength))
+	}
+	if shard.Capacity < 1 {
+		v.add("capacity", "capacity must be at least 1")
+	}
+	if shard.Weight < 0 {
+		v.add("weight", "weight must not be negative")
+	}
+	return v.err()
+}
+
+func toShardProto(shard *model.Shard) *tenantpb.Shard {
+	return &tenantpb.Shard{
+		ShardId:      shard.ID.String(),
+		Name:         shard.Name,
+		Host:         shard.Host,
+		Port:         int32(shard.Port),
+		DatabaseName: shard.DatabaseName,
+		Region:       shard.Region,
+		Capacity:     int32(shard.Capacity),
+		Weight:       int32(shard.Weight),
+		Draining:     shard.Draining,
+		Tenants:      int32(shard.Tenants),
+		CreatedAt:    shard.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:    shard.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
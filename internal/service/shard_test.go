@@ -0,0 +1,39 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+func TestValidateShard(t *testing.T) {
+	valid := func() *model.Shard {
+		return &model.Shard{Name: "eu-1", Host: "db-eu-1", Port: 5432, DatabaseName: "tenants", Capacity: 100, Weight: 1}
+	}
+	assert.NoError(t, validateShard(valid()))
+
+	tests := []struct {
+		field  string
+		modify func(*model.Shard)
+	}{
+		{"name", func(s *model.Shard) { s.Name = "" }},
+		{"name", func(s *model.Shard) { s.Name = strings.Repeat("a", maxShardNameLength+1) }},
+		{"host", func(s *model.Shard) { s.Host = "" }},
+		{"port", func(s *model.Shard) { s.Port = 70000 }},
+		{"database_name", func(s *model.Shard) { s.DatabaseName = "" }},
+		{"region", func(s *model.Shard) { s.Region = strings.Repeat("a", maxRegionLength+1) }},
+		{"capacity", func(s *model.Shard) { s.Capacity = 0 }},
+		{"weight", func(s *model.Shard) { s.Weight = -1 }},
+	}
+	for _, tt := range tests {
+		shard := valid()
+		tt.modify(shard)
+		var domainErr *Error
+		if assert.True(t, errors.As(validateShard(shard), &domainErr), tt.field) && assert.Len(t, domainErr.Violations, 1) {
+			assert.Equal(t, tt.field, domainErr.Violations[0].Field)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"github.com/teresa-solution/tenant-management-service/internal/store"
+)
+
+// Subdomain reclaim modes
+const (
+	// ReclaimNever keeps a deleted tenant's subdomain reserved forever
+	ReclaimNever = "never"
+	// ReclaimAfterGrace frees a deleted tenant's subdomain once the grace period has passed
+	ReclaimAfterGrace = "after-grace"
+	// ReclaimImmediately frees a subdomain as soon as its tenant is deleted
+	ReclaimImmediately = "immediately"
+)
+
+// ReclaimModes lists the accepted subdomain reclaim modes
+var ReclaimModes = []string{ReclaimNever, ReclaimAfterGrace, ReclaimImmediately}
+
+// ReasonSubdomainRetained is reported when a deleted tenant still holds a subdomain
+const ReasonSubdomainRetained = "SUBDOMAIN_RETAINED"
+
+// SubdomainPolicy decides whether the subdomain of a soft-deleted tenant may
+// be given to a new tenant. Live tenants always keep their subdomain; that is
+// enforced by a partial unique index.
+type SubdomainPolicy struct {
+	Reclaim     string
+	GracePeriod time.Duration
+}
+
+// DefaultSubdomainPolicy never reuses subdomains
+var DefaultSubdomainPolicy = SubdomainPolicy{Reclaim: ReclaimNever}
+
+// Validate checks the reclaim mode and grace period
+func (p SubdomainPolicy) Validate() error {
+	if !contains(ReclaimModes, p.Reclaim) {
+		return fmt.Errorf("unknown subdomain reclaim mode %q", p.Reclaim)
+	}
+	if p.Reclaim == ReclaimAfterGrace && p.GracePeriod <= 0 {
+		return errors.New("subdomain reclaim grace period must be positive")
+	}
+	return nil
+}
+
+// Reclaimable reports whether a subdomain held by a tenant deleted at
+// deletedAt may be reused at now
+func (p SubdomainPolicy) Reclaimable(deletedAt, now time.Time) bool {
+	switch p.Reclaim {
+	case ReclaimImmediately:
+		return true
+	case ReclaimAfterGrace:
+		return !now.Before(deletedAt.Add(p.GracePeriod))
+	default:
+		return false
+	}
+}
+
+// checkSubdomainAvailable reports a conflict when a live tenant owns the
+// subdomain, or a deleted one still holds it under the reclaim policy. The
+// check is advisory for live tenants: concurrent creates are settled by the
+// unique index and surface as a store ConflictError.
+func (s *TenantService) checkSubdomainAvailable(ctx context.Context, subdomain string) error {
+	owner, err := s.repo.GetBySubdomain(ctx, subdomain)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return toStatusError(ctx, err, "Failed to check subdomain uniqueness")
+	}
+	return s.subdomainConflict(owner, time.Now())
+}
+
+func (s *TenantService) subdomainConflict(owner *model.Tenant, now time.Time) error {
+	if owner.DeletedAt == nil {
+		return conflictError(ReasonSubdomainTaken, "Subdomain already exists", resourceTypeTenant, owner.Subdomain)
+	}
+	if s.subdomainPolicy.Reclaimable(*owner.DeletedAt, now) {
+		return nil
+	}
+	return conflictError(ReasonSubdomainRetained, "Subdomain is retained by a deleted tenant", resourceTypeTenant, owner.Subdomain)
+}
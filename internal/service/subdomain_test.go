@@ -0,0 +1,52 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSubdomainPolicy_Validate(t *testing.T) {
+	assert.NoError(t, DefaultSubdomainPolicy.Validate())
+	assert.NoError(t, SubdomainPolicy{Reclaim: ReclaimImmediately}.Validate())
+	assert.NoError(t, SubdomainPolicy{Reclaim: ReclaimAfterGrace, GracePeriod: time.Hour}.Validate())
+	assert.Error(t, SubdomainPolicy{Reclaim: ReclaimAfterGrace}.Validate())
+	assert.Error(t, SubdomainPolicy{Reclaim: "sometimes"}.Validate())
+}
+
+func TestSubdomainConflict(t *testing.T) {
+	now := time.Now()
+	deletedAt := now.Add(-2 * time.Hour)
+	live := &model.Tenant{Subdomain: "acme"}
+	deleted := &model.Tenant{Subdomain: "acme", DeletedAt: &deletedAt}
+
+	tests := []struct {
+		name   string
+		policy SubdomainPolicy
+		owner  *model.Tenant
+		reason string
+	}{
+		{"live owner", SubdomainPolicy{Reclaim: ReclaimImmediately}, live, ReasonSubdomainTaken},
+		{"never reclaimed", DefaultSubdomainPolicy, deleted, ReasonSubdomainRetained},
+		{"within grace", SubdomainPolicy{Reclaim: ReclaimAfterGrace, GracePeriod: 3 * time.Hour}, deleted, ReasonSubdomainRetained},
+		{"after grace", SubdomainPolicy{Reclaim: ReclaimAfterGrace, GracePeriod: time.Hour}, deleted, ""},
+		{"immediately", SubdomainPolicy{Reclaim: ReclaimImmediately}, deleted, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &TenantService{subdomainPolicy: tt.policy}
+			err := svc.subdomainConflict(tt.owner, now)
+			if tt.reason == "" {
+				assert.NoError(t, err)
+				return
+			}
+			st, _ := status.FromError(err)
+			assert.Equal(t, codes.AlreadyExists, st.Code())
+			assert.Equal(t, tt.reason, err.(*Error).Reason)
+		})
+	}
+}
@@ -2,180 +2,281 @@ package service
 
 import (
 	"context"
-	"database/sql"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/rs/zerolog/log"
 	"github.com/teresa-solution/tenant-management-service/internal/crypto"
 	"github.com/teresa-solution/tenant-management-service/internal/model"
+	"github.com/teresa-solution/tenant-management-service/internal/monitoring"
+	"github.com/teresa-solution/tenant-management-service/internal/provisioning"
+	"github.com/teresa-solution/tenant-management-service/internal/quota"
 	"github.com/teresa-solution/tenant-management-service/internal/store"
+	grpcmw "github.com/teresa-solution/tenant-management-service/pkg/grpc"
 	tenantpb "github.com/teresa-solution/tenant-management-service/proto/gen"
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
-	"google.golang.org/grpc/status"
 )
 
 // Update TenantService constructor to include ProvisioningService
 type TenantService struct {
 	repo                *store.TenantRepository
 	provisioningService ProvisioningServiceInterface
+	quotas              *quota.Enforcer
+	subdomainPolicy     SubdomainPolicy
+	baseDomain          string
+	domains             DomainVerification
 	tenantpb.UnimplementedTenantServiceServer
 }
 
-func NewTenantService(repo *store.TenantRepository) *TenantService {
-	return &TenantService{
+// Option configures a TenantService
+type Option func(*TenantService)
+
+// WithProvisioningService replaces the default enqueue-only provisioning service
+func WithProvisioningService(ps ProvisioningServiceInterface) Option {
+	return func(s *TenantService) {
+		s.provisioningService = ps
+	}
+}
+
+// WithBaseDomain sets the domain tenant subdomains are registered under,
+// which ResolveTenant strips from hostnames
+func WithBaseDomain(domain string) Option {
+	return func(s *TenantService) {
+		s.baseDomain = domain
+	}
+}
+
+// WithSubdomainPolicy sets the policy for reusing deleted tenants' subdomains
+func WithSubdomainPolicy(policy SubdomainPolicy) Option {
+	return func(s *TenantService) {
+		s.subdomainPolicy = policy
+	}
+}
+
+// WithDomainVerification sets how ownership of custom domains is verified
+func WithDomainVerification(v DomainVerification) Option {
+	return func(s *TenantService) {
+		s.domains = v
+	}
+}
+
+func NewTenantService(repo *store.TenantRepository, opts ...Option) *TenantService {
+	s := &TenantService{
 		repo:                repo,
-		provisioningService: NewProvisioningService(repo),
+		provisioningService: NewProvisioningService(repo, DefaultQueueConfig(), NewDefaultIsolation(repo, provisioning.DefaultConfig()), monitoring.LogAlerter{}),
+		quotas:              quota.NewEnforcer(repo),
+		subdomainPolicy:     DefaultSubdomainPolicy,
+		baseDomain:          provisioning.DefaultConfig().BaseDomain,
+		domains:             DefaultDomainVerification,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 func (s *TenantService) CreateTenant(ctx context.Context, req *tenantpb.CreateTenantRequest) (*tenantpb.CreateTenantResponse, error) {
 	if err := validateCreateTenantRequest(req); err != nil {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+		return nil, err
 	}
 
 	// Extract tenant subdomain from metadata
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return nil, status.Error(codes.InvalidArgument, "missing metadata")
+		return nil, invalidField("metadata", "missing metadata")
 	}
 	subdomains := md.Get("x-tenant-subdomain")
 	if len(subdomains) == 0 || subdomains[0] == "" {
-		return nil, status.Error(codes.InvalidArgument, "missing X-Tenant-Subdomain header")
+		return nil, invalidField("x-tenant-subdomain", "missing X-Tenant-Subdomain header")
 	}
 	subdomain := subdomains[0]
 
-	existingTenant, err := s.repo.GetBySubdomain(ctx, subdomain)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to check subdomain uniqueness")
-		return nil, status.Error(codes.Internal, "Internal server error")
+	if err := s.checkSubdomainAvailable(ctx, subdomain); err != nil {
+		return nil, err
 	}
-	if existingTenant != nil {
-		return nil, status.Error(codes.AlreadyExists, "Subdomain already exists")
+
+	provisioningState := ProvisioningAccepted
+	if s.provisioningService != nil {
+		state, err := s.provisioningService.Admit(ctx)
+		if err != nil {
+			return nil, toStatusError(ctx, err, "Failed to check provisioning capacity")
+		}
+		provisioningState = state
+	}
+
+	tier := req.Tier
+	if tier == "" {
+		tier = model.TierBasic
+	}
+	if s.quotas != nil {
+		if err := s.quotas.CheckTenantCreation(ctx, tier); err != nil {
+			return nil, toStatusError(ctx, err, "Failed to check quota")
+		}
+	}
+	if s.provisioningService != nil {
+		candidate := &model.Tenant{Subdomain: subdomain, Tier: tier, Region: req.Region}
+		if err := s.provisioningService.CheckPlacement(ctx, candidate); err != nil {
+			return nil, toStatusError(ctx, err, "Failed to place tenant")
+		}
 	}
 
 	// Encrypt the contact email
 	encryptedEmail, emailIV, err := crypto.Encrypt(req.ContactEmail)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to encrypt contact email")
-		return nil, status.Error(codes.Internal, "Failed to encrypt contact email")
+		return nil, toStatusError(ctx, err, "Failed to encrypt contact email")
 	}
 
 	tenant := &model.Tenant{
 		Name:           req.Name,
 		Subdomain:      subdomain,
+		Tier:           tier,
+		Region:         req.Region,
 		ContactEmail:   req.ContactEmail, // Transient, not stored in DB
 		EncryptedEmail: encryptedEmail,
 		EmailIV:        emailIV,
 		Status:         "provisioning",
 	}
 	if err := s.repo.Create(ctx, tenant); err != nil {
-		log.Error().Err(err).Msg("Failed to create tenant")
-		return nil, status.Error(codes.Internal, "Failed to create tenant")
+		return nil, toStatusError(ctx, err, "Failed to create tenant")
 	}
 
+	var jobID string
 	if s.provisioningService != nil {
-		s.provisioningService.QueueForProvisioning(tenant)
+		job, err := s.provisioningService.QueueForProvisioning(ctx, tenant)
+		var domainErr *Error
+		if errors.As(err, &domainErr) && domainErr.Reason == ReasonNoShardCapacity {
+			// The shards filled up since CheckPlacement. Nothing was placed, so
+			// the tenant is removed to free its subdomain for the retry.
+			if purgeErr := s.repo.PurgeUnprovisionedTenant(ctx, tenant.ID); purgeErr != nil {
+				grpcmw.LoggerFromContext(ctx).Error().Err(purgeErr).Str("tenant_id", tenant.ID.String()).Msg("Failed to remove unplaced tenant")
+				return nil, toStatusError(ctx, purgeErr, "Failed to queue tenant for provisioning")
+			}
+			return nil, toStatusError(ctx, err, "Failed to queue tenant for provisioning")
+		}
+		if err != nil {
+			// The tenant stays in the provisioning state; the job can be requeued later
+			return nil, toStatusError(ctx, err, "Failed to queue tenant for provisioning")
+		}
+		jobID = job.ID.String()
 	}
 
 	respTenant := &tenantpb.Tenant{
-		Id:        tenant.ID.String(),
-		Name:      tenant.Name,
-		Subdomain: tenant.Subdomain,
-		Status:    tenant.Status,
-		CreatedAt: tenant.CreatedAt.UTC().Format(time.RFC3339),
-		UpdatedAt: tenant.UpdatedAt.UTC().Format(time.RFC3339),
-	}
-	return &tenantpb.CreateTenantResponse{Tenant: respTenant}, nil
+		Id:           tenant.ID.String(),
+		Name:         tenant.Name,
+		Subdomain:    tenant.Subdomain,
+		Tier:         tenant.Tier,
+		Region:       tenant.Region,
+		Status:       tenant.Status,
+		StatusReason: tenant.StatusReason,
+		CreatedAt:    tenant.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:    tenant.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	return &tenantpb.CreateTenantResponse{
+		Tenant:            respTenant,
+		ProvisioningJobId: jobID,
+		ProvisioningState: provisioningState,
+	}, nil
 }
 
 func (s *TenantService) GetTenant(ctx context.Context, req *tenantpb.GetTenantRequest) (*tenantpb.GetTenantResponse, error) {
-	tenantID, err := uuid.Parse(req.Id)
+	tenant, err := s.activeTenant(ctx, req.Id)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, "Invalid tenant ID")
-	}
-
-	tenant, err := s.repo.GetByID(ctx, tenantID)
-	if err != nil {
-		log.Error().Err(err).Str("tenant_id", req.Id).Msg("Failed to fetch tenant")
-		return nil, status.Error(codes.Internal, "Internal server error")
-	}
-	if tenant == nil || tenant.DeletedAt != nil {
-		return nil, status.Error(codes.NotFound, "Tenant not found")
+		return nil, err
 	}
 
 	// Decrypt the contact email
 	if len(tenant.EncryptedEmail) > 0 && len(tenant.EmailIV) > 0 {
 		contactEmail, err := crypto.Decrypt(tenant.EncryptedEmail, tenant.EmailIV)
 		if err != nil {
-			log.Error().Err(err).Str("tenant_id", req.Id).Msg("Failed to decrypt contact email")
-			return nil, status.Error(codes.Internal, "Failed to decrypt contact email")
+			return nil, toStatusError(ctx, err, "Failed to decrypt contact email")
 		}
 		tenant.ContactEmail = contactEmail
 	}
 
-	respTenant := &tenantpb.Tenant{
-		Id:        tenant.ID.String(),
-		Name:      tenant.Name,
-		Subdomain: tenant.Subdomain,
-		Status:    tenant.Status,
-		CreatedAt: tenant.CreatedAt.UTC().Format(time.RFC3339),
-		UpdatedAt: tenant.UpdatedAt.UTC().Format(time.RFC3339),
-	}
-	return &tenantpb.GetTenantResponse{Tenant: respTenant}, nil
+	return &tenantpb.GetTenantResponse{Tenant: toTenantProto(tenant)}, nil
+}
+
+func toTenantProto(tenant *model.Tenant) *tenantpb.Tenant {
+	return &tenantpb.Tenant{
+		Id:           tenant.ID.String(),
+		Name:         tenant.Name,
+		Subdomain:    tenant.Subdomain,
+		Tier:         tenant.Tier,
+		Region:       tenant.Region,
+		Status:       tenant.Status,
+		StatusReason: tenant.StatusReason,
+		CreatedAt:    tenant.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:    tenant.UpdatedAt.UTC().Format(time.RFC3339),
+	}
 }
 
 // UpdateTenant updates an existing tenant
 func (s *TenantService) UpdateTenant(ctx context.Context, req *tenantpb.UpdateTenantRequest) (*tenantpb.UpdateTenantResponse, error) {
 	id, err := uuid.Parse(req.Id)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, "Invalid tenant ID")
+		return nil, invalidField("id", "Invalid tenant ID")
 	}
 
 	tenant, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to get tenant")
-		return nil, status.Error(codes.Internal, "Internal server error")
+		return nil, toStatusError(ctx, err, "Failed to get tenant")
+	}
+	if tenant.DeletedAt != nil {
+		return nil, preconditionFailed(ReasonTenantDeleted, "Tenant has been deleted", resourceTypeTenant, tenant.ID.String())
 	}
-	if tenant == nil {
-		return nil, status.Error(codes.NotFound, "Tenant not found")
+	if err := s.checkNotMoving(ctx, tenant.ID); err != nil {
+		return nil, err
 	}
 
 	// Validate update
 	if err := validateUpdateTenantRequest(req); err != nil {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+		return nil, err
+	}
+
+	if err := checkTenantUpdate(tenant, req); err != nil {
+		return nil, err
 	}
 
 	// Check subdomain uniqueness if changed
-	if tenant.Subdomain != req.Subdomain {
-		existingTenant, err := s.repo.GetBySubdomain(ctx, req.Subdomain)
-		if err != nil {
-			log.Error().Err(err).Msg("Failed to check subdomain uniqueness")
-			return nil, status.Error(codes.Internal, "Internal server error")
-		}
-		if existingTenant != nil {
-			return nil, status.Error(codes.AlreadyExists, "Subdomain already exists")
+	renamed := tenant.Subdomain != req.Subdomain
+	if renamed {
+		if err := s.checkSubdomainAvailable(ctx, req.Subdomain); err != nil {
+			return nil, err
 		}
 	}
 
+	previous := *tenant
 	tenant.Name = req.Name
 	tenant.Subdomain = req.Subdomain
+	if tenant.Status != req.Status {
+		tenant.StatusReason = ""
+	}
 	tenant.Status = req.Status
-	if err := s.repo.Update(ctx, tenant); err != nil {
-		log.Error().Err(err).Msg("Failed to update tenant")
-		return nil, status.Error(codes.Internal, "Failed to update tenant")
+	save := func(ctx context.Context) error {
+		return s.repo.Update(ctx, tenant)
+	}
+	if renamed && (previous.Status == "active" || previous.Status == "inactive") && s.provisioningService != nil {
+		// The tenant's DNS record moves to the new subdomain
+		err = s.provisioningService.RenameTenant(ctx, &previous, req.Subdomain, save)
+	} else {
+		err = save(ctx)
+	}
+	if err != nil {
+		return nil, toStatusError(ctx, err, "Failed to update tenant")
 	}
 
 	respTenant := &tenantpb.Tenant{
-		Id:        tenant.ID.String(),
-		Name:      tenant.Name,
-		Subdomain: tenant.Subdomain,
-		Status:    tenant.Status,
-		CreatedAt: tenant.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: tenant.UpdatedAt.Format(time.RFC3339),
+		Id:           tenant.ID.String(),
+		Name:         tenant.Name,
+		Subdomain:    tenant.Subdomain,
+		Tier:         tenant.Tier,
+		Region:       tenant.Region,
+		Status:       tenant.Status,
+		StatusReason: tenant.StatusReason,
+		CreatedAt:    tenant.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    tenant.UpdatedAt.Format(time.RFC3339),
 		DeletedAt: func() string {
 			if tenant.DeletedAt != nil {
 				return tenant.DeletedAt.Format(time.RFC3339)
@@ -190,56 +291,89 @@ func (s *TenantService) UpdateTenant(ctx context.Context, req *tenantpb.UpdateTe
 func (s *TenantService) DeleteTenant(ctx context.Context, req *tenantpb.DeleteTenantRequest) (*tenantpb.DeleteTenantResponse, error) {
 	id, err := uuid.Parse(req.Id)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, "Invalid tenant ID")
+		return nil, invalidField("id", "Invalid tenant ID")
+	}
+	if err := s.checkNotMoving(ctx, id); err != nil {
+		return nil, err
 	}
 
 	if err := s.repo.Delete(ctx, id); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, status.Error(codes.NotFound, "Tenant not found")
-		}
-		log.Error().Err(err).Msg("Failed to delete tenant")
-		return nil, status.Error(codes.Internal, "Internal server error")
+		return nil, toStatusError(ctx, err, "Failed to delete tenant")
 	}
 
-	return &tenantpb.DeleteTenantResponse{Success: true}, nil
+	// The tenant is deleted either way; if queueing fails, recovery queues the
+	// deprovisioning job later
+	resp := &tenantpb.DeleteTenantResponse{Success: true}
+	if s.provisioningService != nil {
+		job, err := s.provisioningService.QueueForDeprovisioning(ctx, id)
+		if err != nil {
+			grpcmw.LoggerFromContext(ctx).Error().Err(err).Str("tenant_id", id.String()).Msg("Failed to queue tenant for deprovisioning")
+		} else {
+			resp.DeprovisioningJobId = job.ID.String()
+		}
+	}
+	return resp, nil
 }
 
 // validateCreateTenantRequest validates the create tenant request
 func validateCreateTenantRequest(req *tenantpb.CreateTenantRequest) error {
+	var v violations
 	if req.Name == "" {
-		return errors.New("name is required")
+		v.add("name", "name is required")
 	}
 	if req.Subdomain == "" {
-		return errors.New("subdomain is required")
-	}
-	if !isValidSubdomain(req.Subdomain) {
-		return errors.New("invalid subdomain format")
+		v.add("subdomain", "subdomain is required")
+	} else if !isValidSubdomain(req.Subdomain) {
+		v.add("subdomain", "invalid subdomain format")
 	}
 	if req.ContactEmail == "" {
-		return errors.New("contact email is required")
+		v.add("contact_email", "contact email is required")
+	} else if !isValidEmail(req.ContactEmail) {
+		v.add("contact_email", "invalid email format")
 	}
-	if !isValidEmail(req.ContactEmail) {
-		return errors.New("invalid email format")
+	if req.Tier != "" && !contains(model.Tiers, req.Tier) {
+		v.add("tier", "invalid tier")
 	}
-	return nil
+	if len(req.Region) > maxRegionLength {
+		v.add("region", fmt.Sprintf("region must be at most %d characters", maxRegionLength))
+	}
+	return v.err()
 }
 
 // validateUpdateTenantRequest validates the update tenant request
 func validateUpdateTenantRequest(req *tenantpb.UpdateTenantRequest) error {
+	var v violations
 	if req.Id == "" {
-		return errors.New("id is required")
+		v.add("id", "id is required")
 	}
 	if req.Name == "" {
-		return errors.New("name is required")
+		v.add("name", "name is required")
 	}
 	if req.Subdomain == "" {
-		return errors.New("subdomain is required")
-	}
-	if !isValidSubdomain(req.Subdomain) {
-		return errors.New("invalid subdomain format")
+		v.add("subdomain", "subdomain is required")
+	} else if !isValidSubdomain(req.Subdomain) {
+		v.add("subdomain", "invalid subdomain format")
 	}
 	if req.Status == "" || req.Status != "active" && req.Status != "inactive" && req.Status != "provisioning" && req.Status != "error" {
-		return errors.New("invalid status")
+		v.add("status", "invalid status")
+	}
+	return v.err()
+}
+
+// checkTenantUpdate rejects updates the tenant's state does not allow. Only
+// provisioned tenants change status, and only between active and inactive;
+// provisioning and error are left to the provisioning workers. The subdomain
+// does not change while the tenant is provisioning, since the running job
+// publishes the tenant's DNS record under it.
+func checkTenantUpdate(tenant *model.Tenant, req *tenantpb.UpdateTenantRequest) error {
+	provisioned := tenant.Status == "active" || tenant.Status == "inactive"
+	if tenant.Status != req.Status && (!provisioned || req.Status != "active" && req.Status != "inactive") {
+		return preconditionFailed(ReasonStatusTransition,
+			fmt.Sprintf("Tenant status cannot change from %s to %s", tenant.Status, req.Status),
+			resourceTypeTenant, tenant.ID.String())
+	}
+	if tenant.Subdomain != req.Subdomain && tenant.Status == "provisioning" {
+		return preconditionFailed(ReasonSubdomainFixed, "Tenant subdomain cannot be changed while the tenant is provisioning", resourceTypeTenant, tenant.ID.String())
 	}
 	return nil
 }
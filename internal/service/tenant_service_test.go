@@ -18,8 +18,34 @@ import (
 // mockProvisioningService implements ProvisioningServiceInterface
 type mockProvisioningService struct{}
 
-func (m *mockProvisioningService) QueueForProvisioning(tenant *model.Tenant) {
+func (m *mockProvisioningService) Admit(ctx context.Context) (string, error) {
+	return ProvisioningAccepted, nil
+}
+
+func (m *mockProvisioningService) CheckPlacement(ctx context.Context, tenant *model.Tenant) error {
+	return nil
+}
+
+func (m *mockProvisioningService) QueueForProvisioning(ctx context.Context, tenant *model.Tenant) (*model.ProvisioningJob, error) {
 	// Mock implementation: do nothing
+	return &model.ProvisioningJob{ID: uuid.New(), TenantID: tenant.ID, Kind: model.JobKindProvision}, nil
+}
+
+func (m *mockProvisioningService) RenameTenant(ctx context.Context, tenant *model.Tenant, subdomain string, save func(ctx context.Context) error) error {
+	return save(ctx)
+}
+
+func (m *mockProvisioningService) QueueForDeprovisioning(ctx context.Context, tenantID uuid.UUID) (*model.ProvisioningJob, error) {
+	return &model.ProvisioningJob{ID: uuid.New(), TenantID: tenantID, Kind: model.JobKindDeprovision}, nil
+}
+
+func (m *mockProvisioningService) RotateCredentials(ctx context.Context, tenant *model.Tenant) (*model.TenantDatabaseConfig, error) {
+	return &model.TenantDatabaseConfig{TenantID: tenant.ID, Username: "tenant_role", PasswordSecretID: "tenants/secret"}, nil
+}
+
+func (m *mockProvisioningService) QueueMove(ctx context.Context, tenant *model.Tenant, targetID uuid.UUID) (*model.TenantMove, *model.ProvisioningJob, error) {
+	move := &model.TenantMove{ID: uuid.New(), TenantID: tenant.ID, TargetShardID: targetID, Status: model.MoveStatusPending}
+	return move, &model.ProvisioningJob{ID: uuid.New(), TenantID: tenant.ID, Kind: model.JobKindMove}, nil
 }
 
 func setupTestService(t *testing.T) (*TenantService, *store.TenantRepository, func()) {
@@ -145,3 +171,41 @@ func TestTenantService_GetTenant_NotFound(t *testing.T) {
 	assert.Equal(t, codes.NotFound, st.Code())
 	assert.Equal(t, "Tenant not found", st.Message())
 }
+
+func TestCheckTenantUpdate_Status(t *testing.T) {
+	tests := []struct {
+		from, to string
+		ok       bool
+	}{
+		{"active", "inactive", true},
+		{"inactive", "active", true},
+		{"active", "active", true},
+		{"error", "error", true},
+		{"provisioning", "provisioning", true},
+		{"active", "provisioning", false},
+		{"inactive", "error", false},
+		{"error", "active", false},
+		{"provisioning", "active", false},
+		{"error", "inactive", false},
+	}
+	for _, tt := range tests {
+		tenant := &model.Tenant{ID: uuid.New(), Subdomain: "acme", Status: tt.from}
+		err := checkTenantUpdate(tenant, &tenantpb.UpdateTenantRequest{Subdomain: "acme", Status: tt.to})
+		if tt.ok {
+			assert.NoError(t, err, "%s to %s", tt.from, tt.to)
+		} else {
+			st, _ := status.FromError(toStatusError(context.Background(), err, "update failed"))
+			assert.Equal(t, codes.FailedPrecondition, st.Code(), "%s to %s", tt.from, tt.to)
+		}
+	}
+}
+
+func TestCheckTenantUpdate_Subdomain(t *testing.T) {
+	rename := &tenantpb.UpdateTenantRequest{Subdomain: "globex", Status: "active"}
+	assert.NoError(t, checkTenantUpdate(&model.Tenant{ID: uuid.New(), Subdomain: "acme", Status: "active"}, rename))
+
+	rename.Status = "provisioning"
+	err := checkTenantUpdate(&model.Tenant{ID: uuid.New(), Subdomain: "acme", Status: "provisioning"}, rename)
+	st, _ := status.FromError(toStatusError(context.Background(), err, "update failed"))
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+}
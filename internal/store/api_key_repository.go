@@ -0,0 +1,106 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+const apiKeyColumns = `id, tenant_id, name, prefix, key_hash, salt, scopes, expires_at, last_used_at, revoked_at, created_at, updated_at`
+
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (*model.APIKey, error) {
+	key := &model.APIKey{}
+	err := row.Scan(&key.ID, &key.TenantID, &key.Name, &key.Prefix, &key.KeyHash, &key.Salt, pq.Array(&key.Scopes), &key.ExpiresAt, &key.LastUsedAt, &key.RevokedAt, &key.CreatedAt, &key.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *TenantRepository) CreateAPIKey(ctx context.Context, key *model.APIKey) error {
+	query := `INSERT INTO tenant_api_keys (id, tenant_id, name, prefix, key_hash, salt, scopes, expires_at, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	key.ID = uuid.New()
+	key.CreatedAt = time.Now()
+	key.UpdatedAt = key.CreatedAt
+	_, err := r.db.ExecContext(ctx, query, key.ID, key.TenantID, key.Name, key.Prefix, key.KeyHash, key.Salt, pq.Array(key.Scopes), key.ExpiresAt, key.CreatedAt, key.UpdatedAt)
+	return err
+}
+
+func (r *TenantRepository) GetAPIKey(ctx context.Context, tenantID, id uuid.UUID) (*model.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM tenant_api_keys WHERE tenant_id = $1 AND id = $2`
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, notFound(ResourceAPIKey, id.String())
+	}
+	return key, err
+}
+
+func (r *TenantRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*model.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM tenant_api_keys WHERE prefix = $1`
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, prefix))
+	if err == sql.ErrNoRows {
+		return nil, notFound(ResourceAPIKey, prefix)
+	}
+	return key, err
+}
+
+func (r *TenantRepository) ListAPIKeys(ctx context.Context, tenantID uuid.UUID, includeRevoked bool) ([]*model.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM tenant_api_keys
+              WHERE tenant_id = $1 AND ($2 OR revoked_at IS NULL)
+              ORDER BY created_at`
+	rows, err := r.db.QueryContext(ctx, query, tenantID, includeRevoked)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*model.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey revokes an active key, returning a NotFoundError when the
+// tenant has no such active key
+func (r *TenantRepository) RevokeAPIKey(ctx context.Context, tenantID, id uuid.UUID) error {
+	query := `UPDATE tenant_api_keys SET revoked_at = $3 WHERE tenant_id = $1 AND id = $2 AND revoked_at IS NULL`
+	res, err := r.db.ExecContext(ctx, query, tenantID, id, time.Now())
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return notFound(ResourceAPIKey, id.String())
+	}
+	return nil
+}
+
+// ExpireAPIKey shortens the lifetime of a key so that it stops working at expiresAt
+func (r *TenantRepository) ExpireAPIKey(ctx context.Context, id uuid.UUID, expiresAt time.Time) error {
+	query := `UPDATE tenant_api_keys SET expires_at = $2
+              WHERE id = $1 AND (expires_at IS NULL OR expires_at > $2)`
+	_, err := r.db.ExecContext(ctx, query, id, expiresAt)
+	return err
+}
+
+// TouchAPIKey records that a key was used. Writes are skipped while the last
+// recorded use is under a minute old, so busy keys don't update on every call.
+func (r *TenantRepository) TouchAPIKey(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE tenant_api_keys SET last_used_at = $2
+              WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $2 - interval '1 minute')`
+	_, err := r.db.ExecContext(ctx, query, id, time.Now())
+	return err
+}
@@ -0,0 +1,154 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+const customDomainColumns = `id, tenant_id, domain, status, verification_token, token_expires_at, verified_at, last_checked_at, last_check_error, created_at, updated_at`
+
+func scanCustomDomain(row interface{ Scan(...interface{}) error }) (*model.CustomDomain, error) {
+	d := &model.CustomDomain{}
+	err := row.Scan(&d.ID, &d.TenantID, &d.Domain, &d.Status, &d.VerificationToken, &d.TokenExpiresAt, &d.VerifiedAt, &d.LastCheckedAt, &d.LastCheckError, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// CreateCustomDomain attaches a pending custom domain to a tenant, returning
+// a ConflictError when the tenant already has it
+func (r *TenantRepository) CreateCustomDomain(ctx context.Context, d *model.CustomDomain) error {
+	query := `INSERT INTO tenant_custom_domains (id, tenant_id, domain, status, verification_token, token_expires_at, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	d.ID = uuid.New()
+	d.Status = model.DomainStatusPending
+	d.CreatedAt = time.Now()
+	d.UpdatedAt = d.CreatedAt
+	_, err := r.db.ExecContext(ctx, query, d.ID, d.TenantID, d.Domain, d.Status, d.VerificationToken, d.TokenExpiresAt, d.CreatedAt, d.UpdatedAt)
+	return uniqueViolation(err, customDomainTenantConstraint, ResourceCustomDomain, "domain", d.Domain)
+}
+
+func (r *TenantRepository) GetCustomDomain(ctx context.Context, tenantID uuid.UUID, domain string) (*model.CustomDomain, error) {
+	query := `SELECT ` + customDomainColumns + ` FROM tenant_custom_domains WHERE tenant_id = $1 AND domain = $2`
+	d, err := scanCustomDomain(r.db.QueryRowContext(ctx, query, tenantID, domain))
+	if err == sql.ErrNoRows {
+		return nil, notFound(ResourceCustomDomain, domain)
+	}
+	return d, err
+}
+
+// GetVerifiedCustomDomain returns the verified custom domain of that name,
+// whichever tenant holds it, or a NotFoundError
+func (r *TenantRepository) GetVerifiedCustomDomain(ctx context.Context, domain string) (*model.CustomDomain, error) {
+	query := `SELECT ` + customDomainColumns + ` FROM tenant_custom_domains WHERE domain = $1 AND status = 'verified'`
+	d, err := scanCustomDomain(r.db.QueryRowContext(ctx, query, domain))
+	if err == sql.ErrNoRows {
+		return nil, notFound(ResourceCustomDomain, domain)
+	}
+	return d, err
+}
+
+func (r *TenantRepository) ListCustomDomains(ctx context.Context, tenantID uuid.UUID) ([]*model.CustomDomain, error) {
+	query := `SELECT ` + customDomainColumns + ` FROM tenant_custom_domains WHERE tenant_id = $1 ORDER BY domain`
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var domains []*model.CustomDomain
+	for rows.Next() {
+		d, err := scanCustomDomain(rows)
+		if err != nil {
+			return nil, err
+		}
+		domains = append(domains, d)
+	}
+	return domains, rows.Err()
+}
+
+// RenewCustomDomainToken gives a pending custom domain a new verification
+// token and forgets earlier verification attempts
+func (r *TenantRepository) RenewCustomDomainToken(ctx context.Context, d *model.CustomDomain, token string, expiresAt time.Time) error {
+	query := `UPDATE tenant_custom_domains
+              SET verification_token = $2, token_expires_at = $3, last_checked_at = NULL, last_check_error = NULL
+              WHERE id = $1 AND status = 'pending'`
+	res, err := r.db.ExecContext(ctx, query, d.ID, token, expiresAt)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return notFound(ResourceCustomDomain, d.Domain)
+	}
+	d.VerificationToken, d.TokenExpiresAt = token, expiresAt
+	d.LastCheckedAt, d.LastCheckError = nil, nil
+	return nil
+}
+
+// RecordCustomDomainCheck records a failed verification attempt of a pending
+// custom domain
+func (r *TenantRepository) RecordCustomDomainCheck(ctx context.Context, d *model.CustomDomain, checkErr string) error {
+	now := time.Now()
+	query := `UPDATE tenant_custom_domains SET last_checked_at = $2, last_check_error = $3 WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, d.ID, now, checkErr); err != nil {
+		return err
+	}
+	d.LastCheckedAt, d.LastCheckError = &now, &checkErr
+	return nil
+}
+
+// VerifyCustomDomain marks a custom domain verified. Verified claims of
+// deleted tenants on the domain are dropped first; a live tenant holding it
+// verified makes it fail with a ConflictError.
+func (r *TenantRepository) VerifyCustomDomain(ctx context.Context, d *model.CustomDomain) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	release := `DELETE FROM tenant_custom_domains d USING tenants t
+                WHERE d.domain = $1 AND d.status = 'verified' AND d.tenant_id = t.id AND t.deleted_at IS NOT NULL`
+	if _, err := tx.ExecContext(ctx, release, d.Domain); err != nil {
+		return err
+	}
+	now := time.Now()
+	query := `UPDATE tenant_custom_domains
+              SET status = 'verified', verified_at = $2, last_checked_at = $2, last_check_error = NULL
+              WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, query, d.ID, now); err != nil {
+		return uniqueViolation(err, CustomDomainConstraint, ResourceCustomDomain, "domain", d.Domain)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	d.Status = model.DomainStatusVerified
+	d.VerifiedAt, d.LastCheckedAt, d.LastCheckError = &now, &now, nil
+	return nil
+}
+
+// DeleteCustomDomain detaches a custom domain from a tenant, returning a
+// NotFoundError when the tenant does not have it
+func (r *TenantRepository) DeleteCustomDomain(ctx context.Context, tenantID uuid.UUID, domain string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM tenant_custom_domains WHERE tenant_id = $1 AND domain = $2`, tenantID, domain)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return notFound(ResourceCustomDomain, domain)
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/lib/pq"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+// templateVersionSchema holds the template version of a dedicated database.
+// Tenant roles get no access to it.
+const templateVersionSchema = "tenant_meta"
+
+// DatabaseServer manages the dedicated tenant databases on one Postgres
+// server. It connects as an administrator allowed to create roles and
+// databases, and keeps a connection pool per tenant database it touched.
+type DatabaseServer struct {
+	host     string
+	port     int
+	user     string
+	password string
+	admin    *sql.DB
+
+	mu  sync.Mutex
+	dbs map[string]*sql.DB
+}
+
+// NewDatabaseServer connects to adminDB on a server as user
+func NewDatabaseServer(host string, port int, user, password, adminDB string) (*DatabaseServer, error) {
+	s := &DatabaseServer{host: host, port: port, user: user, password: password, dbs: make(map[string]*sql.DB)}
+	admin, err := sql.Open("postgres", s.dsn(adminDB))
+	if err != nil {
+		return nil, err
+	}
+	s.admin = admin
+	return s, nil
+}
+
+func (s *DatabaseServer) dsn(dbName string) string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
+		s.host, s.port, s.user, s.password, dbName)
+}
+
+// Host returns the host of the server
+func (s *DatabaseServer) Host() string {
+	return s.host
+}
+
+// Port returns the port of the server
+func (s *DatabaseServer) Port() int {
+	return s.port
+}
+
+// database returns the connection pool of a tenant database
+func (s *DatabaseServer) database(dbName string) (*sql.DB, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if db, ok := s.dbs[dbName]; ok {
+		return db, nil
+	}
+	db, err := sql.Open("postgres", s.dsn(dbName))
+	if err != nil {
+		return nil, err
+	}
+	s.dbs[dbName] = db
+	return db, nil
+}
+
+// closeDatabase closes the connection pool of a tenant database, if any
+func (s *DatabaseServer) closeDatabase(dbName string) error {
+	s.mu.Lock()
+	db, ok := s.dbs[dbName]
+	delete(s.dbs, dbName)
+	s.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return db.Close()
+}
+
+// CreateTenantRole creates a login role held to limits, or updates an
+// existing one. It has no password until SetTenantRolePassword gives it one.
+func (s *DatabaseServer) CreateTenantRole(ctx context.Context, role string, limits model.RoleLimits) error {
+	exists, err := s.roleExists(ctx, role)
+	if err != nil {
+		return err
+	}
+	quoted := pq.QuoteIdentifier(role)
+	var statements []string
+	if !exists {
+		statements = append(statements, `CREATE ROLE `+quoted)
+	}
+	statements = append(statements, roleSettings(quoted, "public", limits)...)
+	for _, stmt := range statements {
+		if _, err := s.admin.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetTenantRolePassword replaces the password of a tenant role
+func (s *DatabaseServer) SetTenantRolePassword(ctx context.Context, role, password string) error {
+	_, err := s.admin.ExecContext(ctx, `ALTER ROLE `+pq.QuoteIdentifier(role)+` PASSWORD `+pq.QuoteLiteral(password))
+	return err
+}
+
+// DropTenantRole drops a tenant role. It does nothing when the role does not
+// exist; the role's database must be dropped first.
+func (s *DatabaseServer) DropTenantRole(ctx context.Context, role string) error {
+	_, err := s.admin.ExecContext(ctx, `DROP ROLE IF EXISTS `+pq.QuoteIdentifier(role))
+	return err
+}
+
+// CreateTenantDatabase creates a database only role may connect to, with
+// data access to its public schema. The database is owned by the server's
+// administrator so the tenant cannot change its structure or grants.
+func (s *DatabaseServer) CreateTenantDatabase(ctx context.Context, dbName, role string) error {
+	var exists bool
+	err := s.admin.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = $1)`, dbName).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	quotedDB, quotedRole := pq.QuoteIdentifier(dbName), pq.QuoteIdentifier(role)
+	if !exists {
+		// CREATE DATABASE cannot run inside a transaction
+		if _, err := s.admin.ExecContext(ctx, `CREATE DATABASE `+quotedDB); err != nil {
+			return err
+		}
+	}
+	for _, stmt := range []string{
+		`REVOKE ALL ON DATABASE ` + quotedDB + ` FROM PUBLIC`,
+		`GRANT CONNECT, TEMPORARY ON DATABASE ` + quotedDB + ` TO ` + quotedRole,
+	} {
+		if _, err := s.admin.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	db, err := s.database(dbName)
+	if err != nil {
+		return err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		`REVOKE CREATE ON SCHEMA public FROM PUBLIC`,
+		`CREATE SCHEMA IF NOT EXISTS ` + templateVersionSchema,
+		`CREATE TABLE IF NOT EXISTS ` + templateVersionSchema + `.template_version (version INTEGER NOT NULL)`,
+		`INSERT INTO ` + templateVersionSchema + `.template_version (version)
+         SELECT 0 WHERE NOT EXISTS (SELECT 1 FROM ` + templateVersionSchema + `.template_version)`,
+	}
+	statements = append(statements, schemaGrants("public", quotedRole)...)
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DropTenantDatabase terminates the connections to a tenant database and
+// drops it. It does nothing when the database does not exist.
+func (s *DatabaseServer) DropTenantDatabase(ctx context.Context, dbName string) error {
+	if err := s.closeDatabase(dbName); err != nil {
+		return err
+	}
+	_, err := s.admin.ExecContext(ctx, `SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE datname = $1 AND pid <> pg_backend_pid()`, dbName)
+	if err != nil {
+		return err
+	}
+	_, err = s.admin.ExecContext(ctx, `DROP DATABASE IF EXISTS `+pq.QuoteIdentifier(dbName))
+	return err
+}
+
+// BlockTenantConnections stops a tenant role from opening connections and
+// terminates the ones it has open. It does nothing when the role does not exist.
+func (s *DatabaseServer) BlockTenantConnections(ctx context.Context, role string) error {
+	exists, err := s.roleExists(ctx, role)
+	if err != nil || !exists {
+		return err
+	}
+	if _, err := s.admin.ExecContext(ctx, `ALTER ROLE `+pq.QuoteIdentifier(role)+` CONNECTION LIMIT 0`); err != nil {
+		return err
+	}
+	_, err = s.admin.ExecContext(ctx, `SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE usename = $1`, role)
+	return err
+}
+
+// UnblockTenantConnections lifts the connection block of a tenant role,
+// restoring its connection limit
+func (s *DatabaseServer) UnblockTenantConnections(ctx context.Context, role string, limit int) error {
+	exists, err := s.roleExists(ctx, role)
+	if err != nil || !exists {
+		return err
+	}
+	_, err = s.admin.ExecContext(ctx, `ALTER ROLE `+pq.QuoteIdentifier(role)+` CONNECTION LIMIT `+strconv.Itoa(limit))
+	return err
+}
+
+func (s *DatabaseServer) roleExists(ctx context.Context, role string) (bool, error) {
+	var exists bool
+	err := s.admin.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = $1)`, role).Scan(&exists)
+	return exists, err
+}
+
+// TemplateVersion returns the template version a tenant database is at
+func (s *DatabaseServer) TemplateVersion(ctx context.Context, dbName string) (int, error) {
+	db, err := s.database(dbName)
+	if err != nil {
+		return 0, err
+	}
+	var version int
+	err = db.QueryRowContext(ctx, `SELECT version FROM `+templateVersionSchema+`.template_version`).Scan(&version)
+	if err == sql.ErrNoRows {
+		return 0, errors.New("tenant database " + dbName + " has no template version")
+	}
+	return version, err
+}
+
+// ApplyMigration runs a template migration in a tenant database and records
+// its version in the same transaction. It returns false when the database is
+// already at version or later.
+func (s *DatabaseServer) ApplyMigration(ctx context.Context, dbName string, version int, migration string) (bool, error) {
+	db, err := s.database(dbName)
+	if err != nil {
+		return false, err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var current int
+	err = tx.QueryRowContext(ctx, `SELECT version FROM `+templateVersionSchema+`.template_version FOR UPDATE`).Scan(&current)
+	if err != nil {
+		return false, err
+	}
+	if current >= version {
+		return false, nil
+	}
+	if _, err := tx.ExecContext(ctx, `SET LOCAL search_path TO public`); err != nil {
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, migration); err != nil {
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE `+templateVersionSchema+`.template_version SET version = $1`, version); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// Close closes every connection to the server
+func (s *DatabaseServer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	errs := []error{s.admin.Close()}
+	for name, db := range s.dbs {
+		errs = append(errs, db.Close())
+		delete(s.dbs, name)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,114 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+const dedicatedDatabaseColumns = `tenant_id, host, port, database_name, role_name, template_version, drop_after, created_at, updated_at`
+
+func scanDedicatedDatabase(row interface{ Scan(...interface{}) error }) (*model.DedicatedDatabase, error) {
+	d := &model.DedicatedDatabase{}
+	err := row.Scan(&d.TenantID, &d.Host, &d.Port, &d.DatabaseName, &d.RoleName, &d.TemplateVersion, &d.DropAfter,
+		&d.CreatedAt, &d.UpdatedAt)
+	return d, err
+}
+
+// RecordDedicatedDatabase records where a tenant's dedicated database lives,
+// keeping the template version of an existing record
+func (r *TenantRepository) RecordDedicatedDatabase(ctx context.Context, d *model.DedicatedDatabase) error {
+	query := `INSERT INTO tenant_dedicated_databases (tenant_id, host, port, database_name, role_name)
+              VALUES ($1, $2, $3, $4, $5)
+              ON CONFLICT (tenant_id) DO UPDATE SET host = EXCLUDED.host, port = EXCLUDED.port,
+                  database_name = EXCLUDED.database_name, role_name = EXCLUDED.role_name
+              RETURNING template_version, created_at, updated_at`
+	err := r.db.QueryRowContext(ctx, query, d.TenantID, d.Host, d.Port, d.DatabaseName, d.RoleName).
+		Scan(&d.TemplateVersion, &d.CreatedAt, &d.UpdatedAt)
+	return uniqueViolation(err, "tenant_dedicated_databases_host_port_database_name_key", ResourceDedicatedDatabase, "database_name", d.DatabaseName)
+}
+
+// GetDedicatedDatabase returns a tenant's dedicated database or a NotFoundError
+func (r *TenantRepository) GetDedicatedDatabase(ctx context.Context, tenantID uuid.UUID) (*model.DedicatedDatabase, error) {
+	query := `SELECT ` + dedicatedDatabaseColumns + ` FROM tenant_dedicated_databases WHERE tenant_id = $1`
+	d, err := scanDedicatedDatabase(r.db.QueryRowContext(ctx, query, tenantID))
+	if err == sql.ErrNoRows {
+		return nil, notFound(ResourceDedicatedDatabase, tenantID.String())
+	}
+	return d, err
+}
+
+// ListDedicatedDatabases returns the dedicated databases on a host that are
+// not scheduled to be dropped, ordered by database name
+func (r *TenantRepository) ListDedicatedDatabases(ctx context.Context, host string) ([]model.DedicatedDatabase, error) {
+	query := `SELECT ` + dedicatedDatabaseColumns + ` FROM tenant_dedicated_databases
+              WHERE host = $1 AND drop_after IS NULL ORDER BY database_name`
+	return r.queryDedicatedDatabases(ctx, query, host)
+}
+
+// SetDedicatedTemplateVersion records the template version a tenant's
+// dedicated database is at
+func (r *TenantRepository) SetDedicatedTemplateVersion(ctx context.Context, tenantID uuid.UUID, version int) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE tenant_dedicated_databases SET template_version = $2 WHERE tenant_id = $1`, tenantID, version)
+	return err
+}
+
+// ScheduleDedicatedDatabaseDrop marks a tenant's dedicated database to be
+// dropped at the given time, keeping an earlier schedule. It does nothing
+// when the tenant has no dedicated database.
+func (r *TenantRepository) ScheduleDedicatedDatabaseDrop(ctx context.Context, tenantID uuid.UUID, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE tenant_dedicated_databases SET drop_after = COALESCE(drop_after, $2) WHERE tenant_id = $1`, tenantID, at)
+	return err
+}
+
+// CancelDedicatedDatabaseDrop keeps a dedicated database that was scheduled to be dropped
+func (r *TenantRepository) CancelDedicatedDatabaseDrop(ctx context.Context, tenantID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE tenant_dedicated_databases SET drop_after = NULL WHERE tenant_id = $1`, tenantID)
+	return err
+}
+
+// DueDatabaseDrops returns up to limit tenants whose dedicated database is due to be dropped
+func (r *TenantRepository) DueDatabaseDrops(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT tenant_id FROM tenant_dedicated_databases WHERE drop_after <= now() ORDER BY drop_after LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// DeleteDedicatedDatabase forgets a tenant's dedicated database
+func (r *TenantRepository) DeleteDedicatedDatabase(ctx context.Context, tenantID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM tenant_dedicated_databases WHERE tenant_id = $1`, tenantID)
+	return err
+}
+
+func (r *TenantRepository) queryDedicatedDatabases(ctx context.Context, query string, args ...interface{}) ([]model.DedicatedDatabase, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dbs []model.DedicatedDatabase
+	for rows.Next() {
+		d, err := scanDedicatedDatabase(rows)
+		if err != nil {
+			return nil, err
+		}
+		dbs = append(dbs, *d)
+	}
+	return dbs, rows.Err()
+}
@@ -0,0 +1,212 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// RevokeTenantRole takes away everything a tenant role was granted on the
+// tenant's schema. It does nothing when the role does not exist; with an
+// empty schemaName only the role's existence is checked.
+func (r *TenantRepository) RevokeTenantRole(ctx context.Context, role, schemaName string) error {
+	exists, err := r.roleExists(ctx, role)
+	if err != nil || !exists || schemaName == "" {
+		return err
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range schemaRevokes(schemaName, pq.QuoteIdentifier(role)) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// BlockTenantConnections stops a tenant role from opening connections and
+// terminates the ones it has open. It does nothing when the role does not exist.
+func (r *TenantRepository) BlockTenantConnections(ctx context.Context, role string) error {
+	exists, err := r.roleExists(ctx, role)
+	if err != nil || !exists {
+		return err
+	}
+	if _, err := r.db.ExecContext(ctx, `ALTER ROLE `+pq.QuoteIdentifier(role)+` CONNECTION LIMIT 0`); err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE usename = $1`, role)
+	return err
+}
+
+// UnblockTenantConnections lifts the connection block of a tenant role,
+// restoring its connection limit
+func (r *TenantRepository) UnblockTenantConnections(ctx context.Context, role string, limit int) error {
+	exists, err := r.roleExists(ctx, role)
+	if err != nil || !exists {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `ALTER ROLE `+pq.QuoteIdentifier(role)+` CONNECTION LIMIT `+strconv.Itoa(limit))
+	return err
+}
+
+func (r *TenantRepository) roleExists(ctx context.Context, role string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = $1)`, role).Scan(&exists)
+	return exists, err
+}
+
+// SnapshotTenantSchema copies every table of a tenant's schema into
+// snapshotSchema and records the snapshot. It does nothing when the tenant
+// has no schema or already has a snapshot, and returns the snapshot schema,
+// or "" when there was nothing to copy.
+func (r *TenantRepository) SnapshotTenantSchema(ctx context.Context, tenantID uuid.UUID, snapshotSchema string) (string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var existing string
+	err = tx.QueryRowContext(ctx, `SELECT snapshot_schema FROM tenant_snapshots WHERE tenant_id = $1`, tenantID).Scan(&existing)
+	if err == nil {
+		return existing, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", err
+	}
+	var schemaName string
+	err = tx.QueryRowContext(ctx, `SELECT schema_name FROM tenant_schemas WHERE tenant_id = $1 FOR UPDATE`, tenantID).Scan(&schemaName)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := copySchema(ctx, tx, schemaName, snapshotSchema); err != nil {
+		return "", err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO tenant_snapshots (tenant_id, source_schema, snapshot_schema) VALUES ($1, $2, $3)`,
+		tenantID, schemaName, snapshotSchema)
+	if err != nil {
+		return "", err
+	}
+	return snapshotSchema, tx.Commit()
+}
+
+// schemaRevokes returns the statements taking away what schemaGrants gave a role
+func schemaRevokes(schemaName, quotedRole string) []string {
+	return []string{
+		`ALTER DEFAULT PRIVILEGES IN SCHEMA ` + schemaName + ` REVOKE ALL ON TABLES FROM ` + quotedRole,
+		`ALTER DEFAULT PRIVILEGES IN SCHEMA ` + schemaName + ` REVOKE ALL ON SEQUENCES FROM ` + quotedRole,
+		`REVOKE ALL ON ALL TABLES IN SCHEMA ` + schemaName + ` FROM ` + quotedRole,
+		`REVOKE ALL ON ALL SEQUENCES IN SCHEMA ` + schemaName + ` FROM ` + quotedRole,
+		`REVOKE ALL ON SCHEMA ` + schemaName + ` FROM ` + quotedRole,
+	}
+}
+
+// copySchema creates the schema copyName holding a copy of every table of
+// schemaName, without indexes or constraints
+func copySchema(ctx context.Context, tx *sql.Tx, schemaName, copyName string) error {
+	rows, err := tx.QueryContext(ctx, `SELECT relname FROM pg_class WHERE relnamespace = $1::regnamespace AND relkind IN ('r', 'p') ORDER BY relname`, schemaName)
+	if err != nil {
+		return err
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			return err
+		}
+		tables = append(tables, pq.QuoteIdentifier(table))
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	quoted := pq.QuoteIdentifier(copyName)
+	if _, err := tx.ExecContext(ctx, `CREATE SCHEMA `+quoted); err != nil {
+		return err
+	}
+	for _, table := range tables {
+		stmt := fmt.Sprintf(`CREATE TABLE %s.%s AS TABLE %s.%s`, quoted, table, schemaName, table)
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ScheduleTenantSchemaDrop marks a tenant's schema to be dropped at the given
+// time, keeping an earlier schedule. It does nothing when the tenant has no schema.
+func (r *TenantRepository) ScheduleTenantSchemaDrop(ctx context.Context, tenantID uuid.UUID, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE tenant_schemas SET drop_after = COALESCE(drop_after, $2) WHERE tenant_id = $1`, tenantID, at)
+	return err
+}
+
+// CancelTenantSchemaDrop keeps a tenant's schema that was scheduled to be dropped
+func (r *TenantRepository) CancelTenantSchemaDrop(ctx context.Context, tenantID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE tenant_schemas SET drop_after = NULL WHERE tenant_id = $1`, tenantID)
+	return err
+}
+
+// DueSchemaDrops returns up to limit tenants whose schema is due to be dropped
+func (r *TenantRepository) DueSchemaDrops(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT tenant_id FROM tenant_schemas WHERE drop_after <= now() ORDER BY drop_after LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// FinishDeprovisioning moves a deleted tenant from deprovisioning to deleted
+func (r *TenantRepository) FinishDeprovisioning(ctx context.Context, tenantID uuid.UUID) error {
+	query := `UPDATE tenants SET status = 'deleted', status_reason = NULL, provisioned = false, updated_at = now()
+              WHERE id = $1 AND deleted_at IS NOT NULL`
+	if _, err := r.db.ExecContext(ctx, query, tenantID); err != nil {
+		return err
+	}
+	r.redis.Del(ctx, fmt.Sprintf("tenant:%s", tenantID.String()))
+	return nil
+}
+
+// ActivateTenant marks a provisioned tenant active unless it was deleted in
+// the meantime, in which case a NotFoundError is returned
+func (r *TenantRepository) ActivateTenant(ctx context.Context, tenantID uuid.UUID) error {
+	query := `UPDATE tenants SET status = 'active', status_reason = NULL, provisioned = true, updated_at = now()
+              WHERE id = $1 AND deleted_at IS NULL`
+	res, err := r.db.ExecContext(ctx, query, tenantID)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	r.redis.Del(ctx, fmt.Sprintf("tenant:%s", tenantID.String()))
+	if count == 0 {
+		return notFound(ResourceTenant, tenantID.String())
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrNotFound matches every NotFoundError via errors.Is
+	ErrNotFound = errors.New("not found")
+	// ErrConflict matches every ConflictError via errors.Is
+	ErrConflict = errors.New("conflict")
+)
+
+// uniqueViolationCode is the Postgres SQLSTATE for unique_violation
+const uniqueViolationCode = "23505"
+
+// SubdomainConstraint is the partial unique index keeping subdomains unique
+// among tenants that have not been deleted
+const SubdomainConstraint = "uq_tenants_subdomain_active"
+
+// CustomDomainConstraint is the partial unique index letting one tenant at a
+// time hold a verified custom domain
+const CustomDomainConstraint = "uq_tenant_custom_domains_verified"
+
+// customDomainTenantConstraint keeps the custom domains of a tenant unique
+const customDomainTenantConstraint = "tenant_custom_domains_tenant_id_domain_key"
+
+// Resource types reported by store errors
+const (
+	ResourceTenant               = "tenant"
+	ResourceTenantSchema         = "tenant_schema"
+	ResourceAPIKey               = "api_key"
+	ResourceTenantDatabaseConfig = "tenant_database_config"
+	ResourceProvisioningJob      = "provisioning_job"
+	ResourceDedicatedDatabase    = "dedicated_database"
+	ResourceShard                = "database_shard"
+	ResourceShardSchema          = "shard_schema"
+	ResourceTenantMove           = "tenant_move"
+	ResourceDNSRecord            = "dns_record"
+	ResourceCustomDomain         = "custom_domain"
+)
+
+// NotFoundError reports that a record does not exist (or is no longer visible)
+type NotFoundError struct {
+	Resource string
+	Key      string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s %s not found", e.Resource, e.Key)
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// ConflictError reports that a write clashes with existing data, such as a
+// unique value already in use
+type ConflictError struct {
+	Resource string
+	Field    string
+	Value    string
+	Err      error
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s with %s %q already exists", e.Resource, e.Field, e.Value)
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+func notFound(resource, key string) error {
+	return &NotFoundError{Resource: resource, Key: key}
+}
+
+// uniqueViolation converts a Postgres unique violation of constraint into a
+// ConflictError on field, leaving every other error untouched
+func uniqueViolation(err error, constraint, resource, field, value string) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolationCode && pqErr.Constraint == constraint {
+		return &ConflictError{Resource: resource, Field: field, Value: value, Err: err}
+	}
+	return err
+}
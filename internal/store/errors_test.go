@@ -0,0 +1,25 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUniqueViolation(t *testing.T) {
+	dup := &pq.Error{Code: "23505", Constraint: SubdomainConstraint}
+	err := uniqueViolation(fmt.Errorf("insert: %w", dup), SubdomainConstraint, ResourceTenant, "subdomain", "acme")
+
+	var conflict *ConflictError
+	assert.True(t, errors.As(err, &conflict))
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.Equal(t, "subdomain", conflict.Field)
+	assert.Equal(t, "acme", conflict.Value)
+
+	other := &pq.Error{Code: "23505", Constraint: "tenants_pkey"}
+	assert.Equal(t, error(other), uniqueViolation(other, SubdomainConstraint, ResourceTenant, "subdomain", "acme"))
+	assert.NoError(t, uniqueViolation(nil, SubdomainConstraint, ResourceTenant, "subdomain", "acme"))
+}
@@ -0,0 +1,262 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+var (
+	// ErrNoJobReady is returned by ClaimJob when no job is ready to run
+	ErrNoJobReady = errors.New("no provisioning job ready")
+	// ErrLeaseLost is returned when a worker acts on a job it no longer holds
+	ErrLeaseLost = errors.New("provisioning job lease lost")
+)
+
+const jobColumns = `id, tenant_id, kind, status, db_host, attempts, max_attempts, run_at, locked_by, locked_until, heartbeat_at, last_error, completed_at, created_at, updated_at`
+
+func scanJob(row interface{ Scan(...interface{}) error }) (*model.ProvisioningJob, error) {
+	job := &model.ProvisioningJob{}
+	err := row.Scan(&job.ID, &job.TenantID, &job.Kind, &job.Status, &job.DBHost, &job.Attempts, &job.MaxAttempts, &job.RunAt, &job.LockedBy, &job.LockedUntil, &job.HeartbeatAt, &job.LastError, &job.CompletedAt, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// EnqueueJob adds a job to the queue. If the tenant already has an outstanding
+// job of the same kind, that job is returned instead of creating a second one.
+func (r *TenantRepository) EnqueueJob(ctx context.Context, tenantID uuid.UUID, kind, dbHost string, maxAttempts int) (*model.ProvisioningJob, error) {
+	query := `INSERT INTO provisioning_jobs (id, tenant_id, kind, db_host, max_attempts)
+              VALUES ($1, $2, $3, $4, $5)
+              ON CONFLICT (tenant_id, kind) WHERE status IN ('pending', 'running') DO NOTHING
+              RETURNING ` + jobColumns
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, uuid.New(), tenantID, kind, dbHost, maxAttempts))
+	if err != sql.ErrNoRows {
+		return job, err
+	}
+
+	query = `SELECT ` + jobColumns + ` FROM provisioning_jobs
+             WHERE tenant_id = $1 AND kind = $2 AND status IN ('pending', 'running')`
+	job, err = scanJob(r.db.QueryRowContext(ctx, query, tenantID, kind))
+	if err == sql.ErrNoRows {
+		// The outstanding job finished between the two statements
+		return r.EnqueueJob(ctx, tenantID, kind, dbHost, maxAttempts)
+	}
+	return job, err
+}
+
+// ClaimJob leases the next ready job to workerID, skipping jobs that target
+// one of excludeHosts. A job is ready when it is pending and due, or when it
+// is running under a lease that has lapsed and it has attempts left.
+// Concurrent workers skip each other's rows.
+func (r *TenantRepository) ClaimJob(ctx context.Context, workerID string, lease time.Duration, excludeHosts []string) (*model.ProvisioningJob, error) {
+	query := `WITH next AS (
+                  SELECT id FROM provisioning_jobs
+                  WHERE ((status = 'pending' AND run_at <= now())
+                      OR (status = 'running' AND locked_until < now() AND attempts < max_attempts))
+                    AND NOT (db_host = ANY($3))
+                  ORDER BY run_at
+                  LIMIT 1
+                  FOR UPDATE SKIP LOCKED
+              )
+              UPDATE provisioning_jobs
+              SET status = 'running', attempts = attempts + 1, locked_by = $1,
+                  locked_until = now() + make_interval(secs => $2), heartbeat_at = now()
+              WHERE id = (SELECT id FROM next)
+              RETURNING ` + jobColumns
+	if excludeHosts == nil {
+		excludeHosts = []string{}
+	}
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, workerID, lease.Seconds(), pq.Array(excludeHosts)))
+	if err == sql.ErrNoRows {
+		return nil, ErrNoJobReady
+	}
+	return job, err
+}
+
+// HeartbeatJob extends the lease of a job held by workerID
+func (r *TenantRepository) HeartbeatJob(ctx context.Context, id uuid.UUID, workerID string, lease time.Duration) error {
+	query := `UPDATE provisioning_jobs
+              SET locked_until = now() + make_interval(secs => $3), heartbeat_at = now()
+              WHERE id = $1 AND locked_by = $2 AND status = 'running'`
+	return r.execJobUpdate(ctx, query, id, workerID, lease.Seconds())
+}
+
+// CompleteJob marks a job held by workerID as succeeded
+func (r *TenantRepository) CompleteJob(ctx context.Context, id uuid.UUID, workerID string) error {
+	query := `UPDATE provisioning_jobs
+              SET status = 'succeeded', completed_at = now(), locked_by = NULL, locked_until = NULL
+              WHERE id = $1 AND locked_by = $2 AND status = 'running'`
+	return r.execJobUpdate(ctx, query, id, workerID)
+}
+
+// RetryJob releases a job held by workerID so it runs again at runAt
+func (r *TenantRepository) RetryJob(ctx context.Context, id uuid.UUID, workerID, lastError string, runAt time.Time) error {
+	query := `UPDATE provisioning_jobs
+              SET status = 'pending', run_at = $4, last_error = $3, locked_by = NULL, locked_until = NULL
+              WHERE id = $1 AND locked_by = $2 AND status = 'running'`
+	return r.execJobUpdate(ctx, query, id, workerID, lastError, runAt)
+}
+
+// DeadLetterJob parks a job held by workerID that will not be retried
+func (r *TenantRepository) DeadLetterJob(ctx context.Context, id uuid.UUID, workerID, lastError string) error {
+	query := `UPDATE provisioning_jobs
+              SET status = 'dead_letter', last_error = $3, completed_at = now(), locked_by = NULL, locked_until = NULL
+              WHERE id = $1 AND locked_by = $2 AND status = 'running'`
+	return r.execJobUpdate(ctx, query, id, workerID, lastError)
+}
+
+// DeadLetterExpiredJobs parks running jobs whose lease lapsed after their last
+// attempt, typically because the worker crashed, and returns them
+func (r *TenantRepository) DeadLetterExpiredJobs(ctx context.Context) ([]*model.ProvisioningJob, error) {
+	query := `UPDATE provisioning_jobs
+              SET status = 'dead_letter', completed_at = now(), locked_by = NULL, locked_until = NULL,
+                  last_error = COALESCE(last_error || '; ', '') || 'lease expired on final attempt'
+              WHERE status = 'running' AND locked_until < now() AND attempts >= max_attempts
+              RETURNING ` + jobColumns
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*model.ProvisioningJob
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// GetJob returns a provisioning job or a NotFoundError
+func (r *TenantRepository) GetJob(ctx context.Context, id uuid.UUID) (*model.ProvisioningJob, error) {
+	job, err := scanJob(r.db.QueryRowContext(ctx, `SELECT `+jobColumns+` FROM provisioning_jobs WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, notFound(ResourceProvisioningJob, id.String())
+	}
+	return job, err
+}
+
+// FindStuckTenants returns up to limit tenants that have been provisioning
+// or deprovisioning for longer than stuckAfter without progress: they have no
+// job of the matching kind, their latest such job has finished, or it is
+// running but has logged nothing for stuckAfter. Tenants with a pending job
+// are left to the workers.
+func (r *TenantRepository) FindStuckTenants(ctx context.Context, stuckAfter time.Duration, limit int) ([]*model.StuckTenant, error) {
+	query := `SELECT t.id, t.status, j.id, COALESCE(l.last_log, t.updated_at)
+              FROM tenants t
+              LEFT JOIN LATERAL (
+                  SELECT id, status FROM provisioning_jobs
+                  WHERE tenant_id = t.id
+                    AND kind = CASE t.status WHEN 'provisioning' THEN 'provision' ELSE 'deprovision' END
+                  ORDER BY created_at DESC LIMIT 1
+              ) j ON true
+              LEFT JOIN LATERAL (
+                  SELECT MAX(created_at) AS last_log FROM tenant_provisioning_logs WHERE tenant_id = t.id
+              ) l ON true
+              WHERE ((t.status = 'provisioning' AND t.deleted_at IS NULL)
+                     OR (t.status = 'deprovisioning' AND t.deleted_at IS NOT NULL))
+                AND t.updated_at < now() - make_interval(secs => $1)
+                AND (j.id IS NULL
+                     OR j.status IN ('succeeded', 'dead_letter')
+                     OR (j.status = 'running' AND COALESCE(l.last_log, t.updated_at) < now() - make_interval(secs => $1)))
+              ORDER BY t.created_at
+              LIMIT $2`
+	rows, err := r.db.QueryContext(ctx, query, stuckAfter.Seconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+	var stuck []*model.StuckTenant
+	var jobIDs []*uuid.UUID
+	for rows.Next() {
+		t := &model.StuckTenant{}
+		var jobID *uuid.UUID
+		if err := rows.Scan(&t.TenantID, &t.Status, &jobID, &t.LastProgressAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		stuck = append(stuck, t)
+		jobIDs = append(jobIDs, jobID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, jobID := range jobIDs {
+		if jobID == nil {
+			continue
+		}
+		if stuck[i].Job, err = r.GetJob(ctx, *jobID); err != nil {
+			return nil, err
+		}
+	}
+	return stuck, nil
+}
+
+// ReleaseStalledJob takes a running job away from its worker, whatever its
+// lease says. The job becomes pending again if it has attempts left and is
+// dead-lettered otherwise; the updated job is returned. A worker still
+// holding it loses the lease at its next heartbeat.
+func (r *TenantRepository) ReleaseStalledJob(ctx context.Context, id uuid.UUID, lastError string) (*model.ProvisioningJob, error) {
+	query := `UPDATE provisioning_jobs
+              SET status = CASE WHEN attempts < max_attempts THEN 'pending' ELSE 'dead_letter' END,
+                  completed_at = CASE WHEN attempts < max_attempts THEN NULL ELSE now() END,
+                  run_at = now(), last_error = $2, locked_by = NULL, locked_until = NULL
+              WHERE id = $1 AND status = 'running'
+              RETURNING ` + jobColumns
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, id, lastError))
+	if err == sql.ErrNoRows {
+		return nil, ErrLeaseLost
+	}
+	return job, err
+}
+
+// CountPendingJobs returns the number of jobs waiting to be claimed
+func (r *TenantRepository) CountPendingJobs(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM provisioning_jobs WHERE status = 'pending'`).Scan(&count)
+	return count, err
+}
+
+// JobQueueStats returns queue depth by status and the age of the oldest due job
+func (r *TenantRepository) JobQueueStats(ctx context.Context) (*model.QueueStats, error) {
+	query := `SELECT COUNT(*) FILTER (WHERE status = 'pending'),
+                     COUNT(*) FILTER (WHERE status = 'running'),
+                     COUNT(*) FILTER (WHERE status = 'dead_letter'),
+                     COALESCE(EXTRACT(EPOCH FROM now() - MIN(run_at) FILTER (WHERE status = 'pending' AND run_at <= now())), 0)
+              FROM provisioning_jobs
+              WHERE status IN ('pending', 'running', 'dead_letter')`
+	stats := &model.QueueStats{}
+	var oldestSeconds float64
+	err := r.db.QueryRowContext(ctx, query).Scan(&stats.Pending, &stats.Running, &stats.DeadLetter, &oldestSeconds)
+	if err != nil {
+		return nil, err
+	}
+	stats.OldestPendingAge = time.Duration(oldestSeconds * float64(time.Second))
+	return stats, nil
+}
+
+func (r *TenantRepository) execJobUpdate(ctx context.Context, query string, args ...interface{}) error {
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
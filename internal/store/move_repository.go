@@ -0,0 +1,195 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+// tenantMovePendingConstraint is the partial unique index allowing one
+// pending move per tenant
+const tenantMovePendingConstraint = "idx_tenant_moves_pending"
+
+const tenantMoveColumns = `id, tenant_id, source_shard_id, target_shard_id, schema_name, status, last_error,
+                           drop_after, source_dropped_at, created_at, updated_at, completed_at`
+
+func scanTenantMove(row interface{ Scan(...interface{}) error }) (*model.TenantMove, error) {
+	m := &model.TenantMove{}
+	err := row.Scan(&m.ID, &m.TenantID, &m.SourceShardID, &m.TargetShardID, &m.SchemaName, &m.Status, &m.LastError,
+		&m.DropAfter, &m.SourceDroppedAt, &m.CreatedAt, &m.UpdatedAt, &m.CompletedAt)
+	return m, err
+}
+
+// CreateTenantMove records a pending move of a tenant's schema, which holds a
+// slot on the target shard until it ends. It returns ErrShardFull when the
+// target cannot take the tenant and a ConflictError while the tenant has
+// another pending move.
+func (r *TenantRepository) CreateTenantMove(ctx context.Context, m *model.TenantMove) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := reserveShardSlot(ctx, tx, m.TargetShardID); err != nil {
+		return err
+	}
+	query := `INSERT INTO tenant_moves (id, tenant_id, source_shard_id, target_shard_id, schema_name)
+              VALUES ($1, $2, $3, $4, $5)
+              RETURNING ` + tenantMoveColumns
+	created, err := scanTenantMove(tx.QueryRowContext(ctx, query, m.ID, m.TenantID, m.SourceShardID, m.TargetShardID, m.SchemaName))
+	if err != nil {
+		return uniqueViolation(err, tenantMovePendingConstraint, ResourceTenantMove, "tenant_id", m.TenantID.String())
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	*m = *created
+	return nil
+}
+
+// GetPendingTenantMove returns the move a tenant is in the middle of, or a
+// NotFoundError when it is not moving
+func (r *TenantRepository) GetPendingTenantMove(ctx context.Context, tenantID uuid.UUID) (*model.TenantMove, error) {
+	query := `SELECT ` + tenantMoveColumns + ` FROM tenant_moves WHERE tenant_id = $1 AND status = 'pending'`
+	m, err := scanTenantMove(r.db.QueryRowContext(ctx, query, tenantID))
+	if err == sql.ErrNoRows {
+		return nil, notFound(ResourceTenantMove, tenantID.String())
+	}
+	return m, err
+}
+
+// LatestTenantMove returns the last move of a tenant, or a NotFoundError when
+// it never moved
+func (r *TenantRepository) LatestTenantMove(ctx context.Context, tenantID uuid.UUID) (*model.TenantMove, error) {
+	query := `SELECT ` + tenantMoveColumns + ` FROM tenant_moves WHERE tenant_id = $1 ORDER BY created_at DESC LIMIT 1`
+	m, err := scanTenantMove(r.db.QueryRowContext(ctx, query, tenantID))
+	if err == sql.ErrNoRows {
+		return nil, notFound(ResourceTenantMove, tenantID.String())
+	}
+	return m, err
+}
+
+// CompleteTenantMove marks a pending move completed
+func (r *TenantRepository) CompleteTenantMove(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE tenant_moves SET status = 'completed', last_error = NULL, completed_at = now()
+                                     WHERE id = $1 AND status = 'pending'`, id)
+	return err
+}
+
+// FailTenantMove marks a pending move failed, recording why
+func (r *TenantRepository) FailTenantMove(ctx context.Context, id uuid.UUID, reason string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE tenant_moves SET status = 'failed', last_error = $2, completed_at = now()
+                                     WHERE id = $1 AND status = 'pending'`, id, reason)
+	return err
+}
+
+// SwitchTenantShard points a tenant's placement and database configs at
+// shard in one transaction, so connections are only ever handed out for the
+// shard the tenant's data is on
+func (r *TenantRepository) SwitchTenantShard(ctx context.Context, tenantID uuid.UUID, shard *model.Shard) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE tenant_shard_schemas SET shard_id = $2 WHERE tenant_id = $1`, tenantID, shard.ID)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return notFound(ResourceShardSchema, tenantID.String())
+	}
+	for _, query := range []string{
+		`UPDATE tenant_database_configs SET host = $2, port = $3, database_name = $4 WHERE tenant_id = $1`,
+		`UPDATE tenant_specific_configs SET db_host = $2, db_port = $3, db_name = $4 WHERE tenant_id = $1`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, tenantID, shard.Host, shard.Port, shard.DatabaseName); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	r.redis.Del(ctx, fmt.Sprintf("tenant:%s", tenantID.String()))
+	return nil
+}
+
+// SetTenantMaintenance moves an active tenant into maintenance, or back out
+// of it. A tenant in any other status is left alone, so a deleted or failed
+// tenant is never made active.
+func (r *TenantRepository) SetTenantMaintenance(ctx context.Context, tenantID uuid.UUID, on bool) error {
+	from, to := "active", "maintenance"
+	if !on {
+		from, to = to, from
+	}
+	query := `UPDATE tenants SET status = $3, status_reason = NULL, updated_at = now()
+              WHERE id = $1 AND status = $2 AND deleted_at IS NULL`
+	if _, err := r.db.ExecContext(ctx, query, tenantID, from, to); err != nil {
+		return err
+	}
+	r.redis.Del(ctx, fmt.Sprintf("tenant:%s", tenantID.String()))
+	return nil
+}
+
+// ScheduleMovedSchemaDrop marks the source schema of a move to be dropped at
+// the given time, keeping an earlier schedule
+func (r *TenantRepository) ScheduleMovedSchemaDrop(ctx context.Context, moveID uuid.UUID, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE tenant_moves SET drop_after = COALESCE(drop_after, $2) WHERE id = $1`, moveID, at)
+	return err
+}
+
+// DueMovedSchemaDrops returns up to limit tenants that moved off a shard
+// and whose schema left behind there is due to be dropped
+func (r *TenantRepository) DueMovedSchemaDrops(ctx context.Context, shardID uuid.UUID, limit int) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT tenant_id FROM tenant_moves
+                                         WHERE source_shard_id = $1 AND drop_after <= now() AND source_dropped_at IS NULL
+                                         LIMIT $2`, shardID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// MarkMovedSchemaDropped records that the schemas a tenant left behind on a
+// shard are gone
+func (r *TenantRepository) MarkMovedSchemaDropped(ctx context.Context, tenantID, shardID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE tenant_moves SET source_dropped_at = now()
+                                     WHERE tenant_id = $1 AND source_shard_id = $2 AND source_dropped_at IS NULL`, tenantID, shardID)
+	return err
+}
+
+// LatestProvisioningStep returns the step and status of the last provisioning
+// log written for a tenant since the given time, or empty strings when none was
+func (r *TenantRepository) LatestProvisioningStep(ctx context.Context, tenantID uuid.UUID, since time.Time) (string, string, error) {
+	var step, status string
+	err := r.db.QueryRowContext(ctx, `SELECT step, status FROM tenant_provisioning_logs
+                                      WHERE tenant_id = $1 AND created_at >= $2
+                                      ORDER BY created_at DESC LIMIT 1`, tenantID, since).Scan(&step, &status)
+	if err == sql.ErrNoRows {
+		return "", "", nil
+	}
+	return step, status, err
+}
@@ -0,0 +1,310 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+// DropTenantSchema drops a tenant's schema with everything in it and forgets
+// the assignment. It does nothing when the tenant has no schema.
+func (r *TenantRepository) DropTenantSchema(ctx context.Context, tenantID uuid.UUID) error {
+	schemaName, err := r.GetTenantSchema(ctx, tenantID)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := r.db.ExecContext(ctx, `DROP SCHEMA IF EXISTS `+schemaName+` CASCADE`); err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `DELETE FROM tenant_schemas WHERE tenant_id = $1`, tenantID)
+	return err
+}
+
+// TenantTemplateVersion returns the template version a tenant's schema is
+// at, or a NotFoundError when the tenant has no schema
+func (r *TenantRepository) TenantTemplateVersion(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	var version int
+	err := r.db.QueryRowContext(ctx, `SELECT template_version FROM tenant_schemas WHERE tenant_id = $1`, tenantID).Scan(&version)
+	if err == sql.ErrNoRows {
+		return 0, notFound(ResourceTenantSchema, tenantID.String())
+	}
+	return version, err
+}
+
+// ListTenantSchemas returns every tenant schema that is not scheduled to be
+// dropped, ordered by schema name
+func (r *TenantRepository) ListTenantSchemas(ctx context.Context) ([]model.TenantSchema, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT tenant_id, schema_name, template_version FROM tenant_schemas
+                                         WHERE drop_after IS NULL ORDER BY schema_name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schemas []model.TenantSchema
+	for rows.Next() {
+		var s model.TenantSchema
+		if err := rows.Scan(&s.TenantID, &s.SchemaName, &s.TemplateVersion); err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, s)
+	}
+	return schemas, rows.Err()
+}
+
+// ApplyTenantMigration runs a template migration with the tenant's schema
+// first on the search path and records its version in the same transaction.
+// The tenant_schemas row is locked meanwhile, so concurrent runners apply each
+// version once; it returns false when the schema is already at version or later.
+func (r *TenantRepository) ApplyTenantMigration(ctx context.Context, tenantID uuid.UUID, version int, migration string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var schemaName string
+	var current int
+	err = tx.QueryRowContext(ctx, `SELECT schema_name, template_version FROM tenant_schemas WHERE tenant_id = $1 FOR UPDATE`, tenantID).
+		Scan(&schemaName, &current)
+	if err == sql.ErrNoRows {
+		return false, notFound(ResourceTenantSchema, tenantID.String())
+	}
+	if err != nil {
+		return false, err
+	}
+	if current >= version {
+		return false, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `SET LOCAL search_path TO `+schemaName+`, public`); err != nil {
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, migration); err != nil {
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE tenant_schemas SET template_version = $2 WHERE tenant_id = $1`, tenantID, version); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// CreateTenantRole creates a login role owning no objects, or updates an
+// existing one. The role gets data access to the tenant's schema, including
+// tables created later, finds its tables without qualifying them and is held
+// to limits. It has no password until SetTenantRolePassword gives it one.
+func (r *TenantRepository) CreateTenantRole(ctx context.Context, role, schemaName string, limits model.RoleLimits) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = $1)`, role).Scan(&exists); err != nil {
+		return err
+	}
+	quoted := pq.QuoteIdentifier(role)
+	var statements []string
+	if !exists {
+		statements = append(statements, `CREATE ROLE `+quoted)
+	}
+	statements = append(statements, roleSettings(quoted, schemaName, limits)...)
+	statements = append(statements, schemaGrants(schemaName, quoted)...)
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SetTenantRolePassword replaces the password of a tenant role
+func (r *TenantRepository) SetTenantRolePassword(ctx context.Context, role, password string) error {
+	_, err := r.db.ExecContext(ctx, `ALTER ROLE `+pq.QuoteIdentifier(role)+` PASSWORD `+pq.QuoteLiteral(password))
+	return err
+}
+
+// roleSettings returns the statements letting a role log in within limits,
+// with searchPath as its search path
+func roleSettings(quotedRole, searchPath string, limits model.RoleLimits) []string {
+	return []string{
+		`ALTER ROLE ` + quotedRole + ` LOGIN CONNECTION LIMIT ` + strconv.Itoa(limits.ConnectionLimit),
+		`ALTER ROLE ` + quotedRole + ` SET search_path TO ` + searchPath,
+		`ALTER ROLE ` + quotedRole + ` SET statement_timeout = ` + strconv.FormatInt(limits.StatementTimeout.Milliseconds(), 10),
+	}
+}
+
+// schemaGrants returns the statements giving a role data access to a schema,
+// including tables and sequences created later
+func schemaGrants(schemaName, quotedRole string) []string {
+	return []string{
+		`GRANT USAGE ON SCHEMA ` + schemaName + ` TO ` + quotedRole,
+		`GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA ` + schemaName + ` TO ` + quotedRole,
+		`GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA ` + schemaName + ` TO ` + quotedRole,
+		`ALTER DEFAULT PRIVILEGES IN SCHEMA ` + schemaName + ` GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO ` + quotedRole,
+		`ALTER DEFAULT PRIVILEGES IN SCHEMA ` + schemaName + ` GRANT USAGE, SELECT ON SEQUENCES TO ` + quotedRole,
+	}
+}
+
+// DropTenantRole revokes everything granted to a tenant role and drops it.
+// It does nothing when the role does not exist.
+func (r *TenantRepository) DropTenantRole(ctx context.Context, role string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = $1)`, role).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+	quoted := pq.QuoteIdentifier(role)
+	for _, stmt := range []string{`DROP OWNED BY ` + quoted, `DROP ROLE ` + quoted} {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// UpsertTenantDatabaseConfig records where and how a tenant's data is reached,
+// in both tenant_database_configs and tenant_specific_configs
+func (r *TenantRepository) UpsertTenantDatabaseConfig(ctx context.Context, cfg *model.TenantDatabaseConfig) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if cfg.ID == uuid.Nil {
+		cfg.ID = uuid.New()
+	}
+	if cfg.IsolationMode == "" {
+		cfg.IsolationMode = model.IsolationSchema
+	}
+	query := `INSERT INTO tenant_database_configs (id, tenant_id, host, port, database_name, schema_name, username, password_secret_id,
+                  max_connections, idle_connections, connection_lifetime_minutes, isolation_mode)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+              ON CONFLICT (tenant_id) DO UPDATE SET host = EXCLUDED.host, port = EXCLUDED.port,
+                  database_name = EXCLUDED.database_name, schema_name = EXCLUDED.schema_name,
+                  username = EXCLUDED.username, password_secret_id = EXCLUDED.password_secret_id,
+                  max_connections = EXCLUDED.max_connections, idle_connections = EXCLUDED.idle_connections,
+                  connection_lifetime_minutes = EXCLUDED.connection_lifetime_minutes, isolation_mode = EXCLUDED.isolation_mode
+              RETURNING id, created_at, updated_at`
+	err = tx.QueryRowContext(ctx, query, cfg.ID, cfg.TenantID, cfg.Host, cfg.Port, cfg.DatabaseName, cfg.SchemaName, cfg.Username,
+		cfg.PasswordSecretID, cfg.MaxConnections, cfg.IdleConnections, cfg.ConnectionLifetimeMinutes, cfg.IsolationMode).
+		Scan(&cfg.ID, &cfg.CreatedAt, &cfg.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	query = `INSERT INTO tenant_specific_configs (tenant_id, db_host, db_port, db_name, db_schema)
+             VALUES ($1, $2, $3, $4, $5)
+             ON CONFLICT (tenant_id) DO UPDATE SET db_host = EXCLUDED.db_host, db_port = EXCLUDED.db_port,
+                 db_name = EXCLUDED.db_name, db_schema = EXCLUDED.db_schema`
+	if _, err := tx.ExecContext(ctx, query, cfg.TenantID, cfg.Host, cfg.Port, cfg.DatabaseName, cfg.SchemaName); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetTenantDatabaseConfig returns the database settings of a tenant or a NotFoundError
+func (r *TenantRepository) GetTenantDatabaseConfig(ctx context.Context, tenantID uuid.UUID) (*model.TenantDatabaseConfig, error) {
+	query := `SELECT id, tenant_id, host, port, database_name, schema_name, username, password_secret_id,
+                     max_connections, idle_connections, connection_lifetime_minutes, isolation_mode, created_at, updated_at
+              FROM tenant_database_configs WHERE tenant_id = $1`
+	cfg := &model.TenantDatabaseConfig{}
+	err := r.db.QueryRowContext(ctx, query, tenantID).Scan(&cfg.ID, &cfg.TenantID, &cfg.Host, &cfg.Port, &cfg.DatabaseName,
+		&cfg.SchemaName, &cfg.Username, &cfg.PasswordSecretID, &cfg.MaxConnections, &cfg.IdleConnections,
+		&cfg.ConnectionLifetimeMinutes, &cfg.IsolationMode, &cfg.CreatedAt, &cfg.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, notFound(ResourceTenantDatabaseConfig, tenantID.String())
+	}
+	return cfg, err
+}
+
+// DeleteTenantDatabaseConfig removes a tenant's database settings
+func (r *TenantRepository) DeleteTenantDatabaseConfig(ctx context.Context, tenantID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tenant_database_configs WHERE tenant_id = $1`, tenantID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tenant_specific_configs WHERE tenant_id = $1`, tenantID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetTenantDNSRecord records the DNS record published for a tenant's
+// hostname, or clears it when record is nil. It needs the tenant's database
+// config to have been written.
+func (r *TenantRepository) SetTenantDNSRecord(ctx context.Context, tenantID uuid.UUID, record *model.DNSRecord) error {
+	var value sql.NullString
+	if record != nil {
+		value = sql.NullString{String: record.String(), Valid: true}
+	}
+	res, err := r.db.ExecContext(ctx, `UPDATE tenant_specific_configs SET dns_record = $2 WHERE tenant_id = $1`, tenantID, value)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 && record != nil {
+		return notFound(ResourceTenantDatabaseConfig, tenantID.String())
+	}
+	return nil
+}
+
+// GetTenantDNSRecord returns the DNS record published for a tenant's
+// hostname, or a NotFoundError when none was
+func (r *TenantRepository) GetTenantDNSRecord(ctx context.Context, tenantID uuid.UUID) (*model.DNSRecord, error) {
+	var value sql.NullString
+	err := r.db.QueryRowContext(ctx, `SELECT dns_record FROM tenant_specific_configs WHERE tenant_id = $1`, tenantID).Scan(&value)
+	if err == sql.ErrNoRows || err == nil && !value.Valid {
+		return nil, notFound(ResourceDNSRecord, tenantID.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+	record, err := model.ParseDNSRecord(value.String)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// SeedTenantFeatures enables features for a tenant, keeping any existing flags
+func (r *TenantRepository) SeedTenantFeatures(ctx context.Context, tenantID uuid.UUID, features []string) error {
+	query := `INSERT INTO tenant_features (tenant_id, feature_name, enabled, created_at, updated_at)
+              SELECT $1, unnest($2::text[]), true, $3, $3
+              ON CONFLICT (tenant_id, feature_name) DO NOTHING`
+	_, err := r.db.ExecContext(ctx, query, tenantID, pq.Array(features), time.Now())
+	return err
+}
+
+// DeleteTenantFeatures removes all feature flags of a tenant
+func (r *TenantRepository) DeleteTenantFeatures(ctx context.Context, tenantID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM tenant_features WHERE tenant_id = $1`, tenantID)
+	return err
+}
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// TierQuotaLimits returns the limits configured for a tier, keyed by resource
+func (r *TenantRepository) TierQuotaLimits(ctx context.Context, tier string) (map[string]int64, error) {
+	query := `SELECT resource, quota_limit FROM tier_quotas WHERE tier = $1`
+	return r.queryQuotaLimits(ctx, query, tier)
+}
+
+// TenantQuotaOverrides returns the per-tenant limits replacing tier limits
+func (r *TenantRepository) TenantQuotaOverrides(ctx context.Context, tenantID uuid.UUID) (map[string]int64, error) {
+	query := `SELECT resource, quota_limit FROM tenant_quota_overrides WHERE tenant_id = $1`
+	return r.queryQuotaLimits(ctx, query, tenantID)
+}
+
+func (r *TenantRepository) queryQuotaLimits(ctx context.Context, query string, arg interface{}) (map[string]int64, error) {
+	rows, err := r.db.QueryContext(ctx, query, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	limits := make(map[string]int64)
+	for rows.Next() {
+		var resource string
+		var limit int64
+		if err := rows.Scan(&resource, &limit); err != nil {
+			return nil, err
+		}
+		limits[resource] = limit
+	}
+	return limits, rows.Err()
+}
+
+// SetTenantQuotaOverride replaces the tier limit of a resource for one tenant
+func (r *TenantRepository) SetTenantQuotaOverride(ctx context.Context, tenantID uuid.UUID, resource string, limit int64) error {
+	query := `INSERT INTO tenant_quota_overrides (tenant_id, resource, quota_limit)
+              VALUES ($1, $2, $3)
+              ON CONFLICT (tenant_id, resource) DO UPDATE SET quota_limit = EXCLUDED.quota_limit`
+	_, err := r.db.ExecContext(ctx, query, tenantID, resource, limit)
+	return err
+}
+
+// DeleteTenantQuotaOverride restores the tier limit of a resource for one tenant
+func (r *TenantRepository) DeleteTenantQuotaOverride(ctx context.Context, tenantID uuid.UUID, resource string) error {
+	query := `DELETE FROM tenant_quota_overrides WHERE tenant_id = $1 AND resource = $2`
+	_, err := r.db.ExecContext(ctx, query, tenantID, resource)
+	return err
+}
+
+// CountTenantsInTier counts the tenants of a tier that have not been deleted
+func (r *TenantRepository) CountTenantsInTier(ctx context.Context, tier string) (int64, error) {
+	query := `SELECT COUNT(*) FROM tenants WHERE tier = $1 AND deleted_at IS NULL`
+	var count int64
+	err := r.db.QueryRowContext(ctx, query, tier).Scan(&count)
+	return count, err
+}
+
+// CountTenantResource counts a tenant's current use of a countable resource
+func (r *TenantRepository) CountTenantResource(ctx context.Context, tenantID uuid.UUID, resource string) (int64, error) {
+	var query string
+	switch resource {
+	case "contacts":
+		query = `SELECT COUNT(*) FROM tenant_contacts WHERE tenant_id = $1`
+	case "api_keys":
+		query = `SELECT COUNT(*) FROM tenant_api_keys
+                 WHERE tenant_id = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > now())`
+	case "config_keys":
+		query = `SELECT COUNT(*) FROM tenant_configs WHERE tenant_id = $1`
+	default:
+		return 0, fmt.Errorf("resource %q cannot be counted", resource)
+	}
+	var count int64
+	err := r.db.QueryRowContext(ctx, query, tenantID).Scan(&count)
+	return count, err
+}
+
+// TenantSchemaSize returns the on-disk size in bytes of the tables in a
+// tenant's schema. Only schemas in the registry database can be measured;
+// ok is false for every other tenant.
+func (r *TenantRepository) TenantSchemaSize(ctx context.Context, tenantID uuid.UUID) (int64, bool, error) {
+	query := `SELECT COALESCE((SELECT SUM(pg_total_relation_size(c.oid))
+                                FROM pg_namespace n
+                                JOIN pg_class c ON c.relnamespace = n.oid AND c.relkind IN ('r', 'm')
+                                WHERE n.nspname = ts.schema_name), 0)
+              FROM tenant_schemas ts
+              WHERE ts.tenant_id = $1 AND ts.drop_after IS NULL`
+	var size int64
+	err := r.db.QueryRowContext(ctx, query, tenantID).Scan(&size)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return size, true, nil
+}
@@ -0,0 +1,231 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"io"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/lib/pq"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+// copySettings make the text form of values independent of the server and
+// session defaults, so copies and checksums taken on two shards agree
+var copySettings = []string{
+	`SET LOCAL TimeZone TO 'UTC'`,
+	`SET LOCAL DateStyle TO 'ISO, YMD'`,
+	`SET LOCAL IntervalStyle TO 'postgres'`,
+	`SET LOCAL extra_float_digits TO 3`,
+	`SET LOCAL bytea_output TO 'hex'`,
+}
+
+// qualifiedTable quotes a table of a tenant schema
+func qualifiedTable(schemaName, table string) string {
+	return pq.QuoteIdentifier(schemaName) + "." + pq.QuoteIdentifier(table)
+}
+
+// SetSchemaReadOnly takes the write privileges on a tenant schema away from
+// its role, terminating the role's open sessions so no write in flight
+// survives, or gives them back. Reads keep working throughout.
+func (s *ShardServer) SetSchemaReadOnly(ctx context.Context, role, schemaName string, readOnly bool) error {
+	exists, err := s.server.roleExists(ctx, role)
+	if err != nil || !exists {
+		return err
+	}
+	quotedRole, quotedSchema := pq.QuoteIdentifier(role), pq.QuoteIdentifier(schemaName)
+	if !readOnly {
+		return s.inDatabase(ctx, schemaGrants(quotedSchema, quotedRole))
+	}
+	err = s.inDatabase(ctx, []string{
+		`REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON ALL TABLES IN SCHEMA ` + quotedSchema + ` FROM ` + quotedRole,
+		`REVOKE USAGE, UPDATE ON ALL SEQUENCES IN SCHEMA ` + quotedSchema + ` FROM ` + quotedRole,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = s.server.admin.ExecContext(ctx, `SELECT pg_terminate_backend(pid) FROM pg_stat_activity
+                                              WHERE usename = $1 AND datname = $2`, role, s.dbName)
+	return err
+}
+
+// TableChecksums returns the row count and a checksum of the rows of every
+// table in a tenant schema, taken in one snapshot. The checksum does not
+// depend on row order, so two copies of a table have the same one.
+func (s *ShardServer) TableChecksums(ctx context.Context, schemaName string) (map[string]model.TableChecksum, error) {
+	db, err := s.database()
+	if err != nil {
+		return nil, err
+	}
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range copySettings {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return nil, err
+		}
+	}
+	tables, err := schemaTables(ctx, tx, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	checksums := make(map[string]model.TableChecksum, len(tables))
+	for _, table := range tables {
+		var sum model.TableChecksum
+		query := `SELECT count(*), coalesce(md5(string_agg(md5(t::text), '' ORDER BY md5(t::text))), '')
+                  FROM ` + qualifiedTable(schemaName, table) + ` AS t`
+		if err := tx.QueryRowContext(ctx, query).Scan(&sum.Rows, &sum.Checksum); err != nil {
+			return nil, err
+		}
+		checksums[table] = sum
+	}
+	return checksums, tx.Commit()
+}
+
+// schemaTables returns the names of the plain tables of a schema
+func schemaTables(ctx context.Context, tx *sql.Tx, schemaName string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT c.relname FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+                                       WHERE n.nspname = $1 AND c.relkind = 'r' ORDER BY c.relname`, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+// SequenceValues returns the last value of every sequence in a tenant schema
+// that was used
+func (s *ShardServer) SequenceValues(ctx context.Context, schemaName string) (map[string]int64, error) {
+	db, err := s.database()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx, `SELECT sequencename, last_value FROM pg_sequences
+                                       WHERE schemaname = $1 AND last_value IS NOT NULL`, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make(map[string]int64)
+	for rows.Next() {
+		var name string
+		var value int64
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, err
+		}
+		values[name] = value
+	}
+	return values, rows.Err()
+}
+
+// SetSequenceValues sets the last value of sequences in a tenant schema, so
+// the next value they hand out follows it
+func (s *ShardServer) SetSequenceValues(ctx context.Context, schemaName string, values map[string]int64) error {
+	db, err := s.database()
+	if err != nil {
+		return err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for name, value := range values {
+		if _, err := tx.ExecContext(ctx, `SELECT setval($1::regclass, $2)`, qualifiedTable(schemaName, name), value); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// connect opens a connection to the shard's database for COPY, which
+// database/sql cannot stream
+func (s *ShardServer) connect(ctx context.Context) (*pgx.Conn, error) {
+	return pgx.Connect(ctx, s.server.dsn(s.dbName))
+}
+
+// ExportTable writes the rows of a table of a tenant schema to w in COPY
+// text format, from one snapshot
+func (s *ShardServer) ExportTable(ctx context.Context, schemaName, table string, w io.Writer) error {
+	conn, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(context.WithoutCancel(ctx))
+
+	for _, stmt := range copySettings {
+		if _, err := tx.Exec(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	_, err = conn.PgConn().CopyTo(ctx, w, `COPY `+qualifiedTable(schemaName, table)+` TO STDOUT`)
+	return err
+}
+
+// ImportTables replaces the rows of tables of a tenant schema with what
+// export writes for each of them, in one transaction. Triggers and foreign
+// keys are not fired while loading, as the rows are copies of rows that
+// passed them; this needs a user allowed to set session_replication_role.
+func (s *ShardServer) ImportTables(ctx context.Context, schemaName string, tables []string, export func(ctx context.Context, table string, w io.Writer) error) error {
+	conn, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(context.WithoutCancel(ctx))
+
+	for _, stmt := range append([]string{`SET LOCAL session_replication_role TO replica`}, copySettings...) {
+		if _, err := tx.Exec(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	for _, table := range tables {
+		name := qualifiedTable(schemaName, table)
+		if _, err := tx.Exec(ctx, `DELETE FROM `+name); err != nil {
+			return err
+		}
+		r, w := io.Pipe()
+		exported := make(chan error, 1)
+		go func() {
+			err := export(ctx, table, w)
+			w.CloseWithError(err)
+			exported <- err
+		}()
+		_, err := conn.PgConn().CopyFrom(ctx, r, `COPY `+name+` FROM STDIN`)
+		// Unblock the export if the copy stopped reading early
+		r.Close()
+		exportErr := <-exported
+		if err != nil {
+			return err
+		}
+		if exportErr != nil {
+			return exportErr
+		}
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,285 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+// ErrShardInUse is returned by DeleteShard while tenant schemas or snapshots
+// are kept on the shard, or tenants are moving to or from it
+var ErrShardInUse = errors.New("database shard still holds tenant schemas or snapshots")
+
+// ErrShardFull is returned when a tenant is placed or moved onto a shard that
+// is draining or has no capacity left
+var ErrShardFull = errors.New("database shard is draining or full")
+
+// Unique constraints of database_shards
+const (
+	shardNameConstraint     = "database_shards_name_key"
+	shardDatabaseConstraint = "database_shards_host_port_database_name_key"
+)
+
+const shardColumns = `d.id, d.name, d.host, d.port, d.database_name, d.region, d.capacity, d.weight, d.draining,
+                      (SELECT count(*) FROM tenant_shard_schemas s WHERE s.shard_id = d.id), d.created_at, d.updated_at`
+
+func scanShard(row interface{ Scan(...interface{}) error }) (*model.Shard, error) {
+	s := &model.Shard{}
+	err := row.Scan(&s.ID, &s.Name, &s.Host, &s.Port, &s.DatabaseName, &s.Region, &s.Capacity, &s.Weight, &s.Draining,
+		&s.Tenants, &s.CreatedAt, &s.UpdatedAt)
+	return s, err
+}
+
+const shardSchemaColumns = `tenant_id, shard_id, schema_name, template_version, drop_after, created_at, updated_at`
+
+func scanShardSchema(row interface{ Scan(...interface{}) error }) (*model.ShardSchema, error) {
+	s := &model.ShardSchema{}
+	err := row.Scan(&s.TenantID, &s.ShardID, &s.SchemaName, &s.TemplateVersion, &s.DropAfter, &s.CreatedAt, &s.UpdatedAt)
+	return s, err
+}
+
+// CreateShard registers a database shard
+func (r *TenantRepository) CreateShard(ctx context.Context, shard *model.Shard) error {
+	if shard.ID == uuid.Nil {
+		shard.ID = uuid.New()
+	}
+	query := `INSERT INTO database_shards (id, name, host, port, database_name, region, capacity, weight, draining)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+              RETURNING created_at, updated_at`
+	err := r.db.QueryRowContext(ctx, query, shard.ID, shard.Name, shard.Host, shard.Port, shard.DatabaseName, shard.Region,
+		shard.Capacity, shard.Weight, shard.Draining).Scan(&shard.CreatedAt, &shard.UpdatedAt)
+	err = uniqueViolation(err, shardNameConstraint, ResourceShard, "name", shard.Name)
+	return uniqueViolation(err, shardDatabaseConstraint, ResourceShard, "database_name", shard.DatabaseName)
+}
+
+// GetShard returns a database shard with its tenant count, or a NotFoundError
+func (r *TenantRepository) GetShard(ctx context.Context, id uuid.UUID) (*model.Shard, error) {
+	shard, err := scanShard(r.db.QueryRowContext(ctx, `SELECT `+shardColumns+` FROM database_shards d WHERE d.id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, notFound(ResourceShard, id.String())
+	}
+	return shard, err
+}
+
+// ListShards returns every database shard with its tenant count, ordered by name
+func (r *TenantRepository) ListShards(ctx context.Context) ([]model.Shard, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+shardColumns+` FROM database_shards d ORDER BY d.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shards []model.Shard
+	for rows.Next() {
+		shard, err := scanShard(rows)
+		if err != nil {
+			return nil, err
+		}
+		shards = append(shards, *shard)
+	}
+	return shards, rows.Err()
+}
+
+// UpdateShard saves the region, capacity, weight and draining flag of a
+// shard. Where a shard lives cannot be changed once tenants may be on it.
+func (r *TenantRepository) UpdateShard(ctx context.Context, shard *model.Shard) error {
+	query := `UPDATE database_shards SET region = $2, capacity = $3, weight = $4, draining = $5
+              WHERE id = $1 RETURNING updated_at`
+	err := r.db.QueryRowContext(ctx, query, shard.ID, shard.Region, shard.Capacity, shard.Weight, shard.Draining).Scan(&shard.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return notFound(ResourceShard, shard.ID.String())
+	}
+	return err
+}
+
+// DeleteShard forgets a database shard. It returns ErrShardInUse while any
+// tenant schema or snapshot is kept on it, including the schemas tenants that
+// moved away left behind, or a tenant is moving onto it.
+func (r *TenantRepository) DeleteShard(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var inUse bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM tenant_shard_schemas WHERE shard_id = $1)
+                                       OR EXISTS (SELECT 1 FROM tenant_snapshots WHERE shard_id = $1)
+                                       OR EXISTS (SELECT 1 FROM tenant_moves WHERE target_shard_id = $1 AND status = 'pending')
+                                       OR EXISTS (SELECT 1 FROM tenant_moves WHERE source_shard_id = $1 AND source_dropped_at IS NULL
+                                                  AND (status = 'pending' OR drop_after IS NOT NULL))`, id).Scan(&inUse)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrShardInUse
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM database_shards WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return notFound(ResourceShard, id.String())
+	}
+	return tx.Commit()
+}
+
+// reserveShardSlot locks a shard for the rest of tx and returns ErrShardFull
+// unless it can take one more tenant. Tenants moving onto the shard hold a
+// slot already, so concurrent placements and moves never overfill it.
+func reserveShardSlot(ctx context.Context, tx *sql.Tx, shardID uuid.UUID) error {
+	var capacity int
+	var draining bool
+	err := tx.QueryRowContext(ctx, `SELECT capacity, draining FROM database_shards WHERE id = $1 FOR UPDATE`, shardID).
+		Scan(&capacity, &draining)
+	if err == sql.ErrNoRows {
+		return notFound(ResourceShard, shardID.String())
+	}
+	if err != nil {
+		return err
+	}
+	var tenants int
+	err = tx.QueryRowContext(ctx, `SELECT (SELECT count(*) FROM tenant_shard_schemas WHERE shard_id = $1)
+                                        + (SELECT count(*) FROM tenant_moves WHERE target_shard_id = $1 AND status = 'pending')`, shardID).
+		Scan(&tenants)
+	if err != nil {
+		return err
+	}
+	if draining || tenants >= capacity {
+		return ErrShardFull
+	}
+	return nil
+}
+
+// PlaceTenantSchema records that a tenant's schema lives on a shard. A tenant
+// is placed once: when it already has a placement, s is filled in with it.
+// Otherwise it returns ErrShardFull when the shard cannot take the tenant.
+func (r *TenantRepository) PlaceTenantSchema(ctx context.Context, s *model.ShardSchema) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var placed bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM tenant_shard_schemas WHERE tenant_id = $1)`, s.TenantID).Scan(&placed)
+	if err != nil {
+		return err
+	}
+	if !placed {
+		if err := reserveShardSlot(ctx, tx, s.ShardID); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO tenant_shard_schemas (tenant_id, shard_id, schema_name)
+                                      VALUES ($1, $2, $3) ON CONFLICT (tenant_id) DO NOTHING`, s.TenantID, s.ShardID, s.SchemaName)
+		if err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	placement, err := r.GetShardSchema(ctx, s.TenantID)
+	if err != nil {
+		return err
+	}
+	*s = *placement
+	return nil
+}
+
+// GetShardSchema returns where a tenant's schema is placed, or a
+// NotFoundError when the tenant is not on a shard
+func (r *TenantRepository) GetShardSchema(ctx context.Context, tenantID uuid.UUID) (*model.ShardSchema, error) {
+	query := `SELECT ` + shardSchemaColumns + ` FROM tenant_shard_schemas WHERE tenant_id = $1`
+	s, err := scanShardSchema(r.db.QueryRowContext(ctx, query, tenantID))
+	if err == sql.ErrNoRows {
+		return nil, notFound(ResourceShardSchema, tenantID.String())
+	}
+	return s, err
+}
+
+// ListShardSchemas returns the tenant schemas on a shard that are not
+// scheduled to be dropped, ordered by schema name
+func (r *TenantRepository) ListShardSchemas(ctx context.Context, shardID uuid.UUID) ([]model.ShardSchema, error) {
+	query := `SELECT ` + shardSchemaColumns + ` FROM tenant_shard_schemas
+              WHERE shard_id = $1 AND drop_after IS NULL ORDER BY schema_name`
+	rows, err := r.db.QueryContext(ctx, query, shardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schemas []model.ShardSchema
+	for rows.Next() {
+		s, err := scanShardSchema(rows)
+		if err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, *s)
+	}
+	return schemas, rows.Err()
+}
+
+// SetShardTemplateVersion records the template version a tenant's schema on
+// a shard is at
+func (r *TenantRepository) SetShardTemplateVersion(ctx context.Context, tenantID uuid.UUID, version int) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE tenant_shard_schemas SET template_version = $2 WHERE tenant_id = $1`, tenantID, version)
+	return err
+}
+
+// ScheduleShardSchemaDrop marks a tenant's schema on a shard to be dropped at
+// the given time, keeping an earlier schedule
+func (r *TenantRepository) ScheduleShardSchemaDrop(ctx context.Context, tenantID uuid.UUID, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE tenant_shard_schemas SET drop_after = COALESCE(drop_after, $2) WHERE tenant_id = $1`, tenantID, at)
+	return err
+}
+
+// CancelShardSchemaDrop keeps a tenant's schema on a shard that was scheduled to be dropped
+func (r *TenantRepository) CancelShardSchemaDrop(ctx context.Context, tenantID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE tenant_shard_schemas SET drop_after = NULL WHERE tenant_id = $1`, tenantID)
+	return err
+}
+
+// DueShardSchemaDrops returns up to limit tenants on a shard whose schema is due to be dropped
+func (r *TenantRepository) DueShardSchemaDrops(ctx context.Context, shardID uuid.UUID, limit int) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT tenant_id FROM tenant_shard_schemas WHERE shard_id = $1 AND drop_after <= now()
+                                         ORDER BY drop_after LIMIT $2`, shardID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// DeleteShardSchema forgets where a tenant's schema was placed, freeing its
+// place on the shard
+func (r *TenantRepository) DeleteShardSchema(ctx context.Context, tenantID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM tenant_shard_schemas WHERE tenant_id = $1`, tenantID)
+	return err
+}
+
+// RecordShardSnapshot records a snapshot of a tenant schema kept on a shard.
+// Recording the same snapshot again does nothing.
+func (r *TenantRepository) RecordShardSnapshot(ctx context.Context, tenantID, shardID uuid.UUID, sourceSchema, snapshotSchema string) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO tenant_snapshots (tenant_id, shard_id, source_schema, snapshot_schema)
+                                     VALUES ($1, $2, $3, $4) ON CONFLICT (snapshot_schema) DO NOTHING`,
+		tenantID, shardID, sourceSchema, snapshotSchema)
+	return err
+}
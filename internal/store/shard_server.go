@@ -0,0 +1,291 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+// ShardServer manages the tenant schemas in one database of a shard. Each
+// schema's template version is kept next to it, in tenant_meta.schema_versions,
+// so a migration and its version commit together.
+type ShardServer struct {
+	server *DatabaseServer
+	dbName string
+}
+
+// NewShardServer connects to a shard as user, managing roles through adminDB
+// and tenant schemas in dbName
+func NewShardServer(host string, port int, user, password, adminDB, dbName string) (*ShardServer, error) {
+	server, err := NewDatabaseServer(host, port, user, password, adminDB)
+	if err != nil {
+		return nil, err
+	}
+	return &ShardServer{server: server, dbName: dbName}, nil
+}
+
+// Host returns the host of the shard
+func (s *ShardServer) Host() string {
+	return s.server.Host()
+}
+
+// Port returns the port of the shard
+func (s *ShardServer) Port() int {
+	return s.server.Port()
+}
+
+// DatabaseName returns the database tenant schemas live in
+func (s *ShardServer) DatabaseName() string {
+	return s.dbName
+}
+
+func (s *ShardServer) database() (*sql.DB, error) {
+	return s.server.database(s.dbName)
+}
+
+// CreateSchema creates a tenant schema at template version 0, unless it exists
+func (s *ShardServer) CreateSchema(ctx context.Context, schemaName string) error {
+	db, err := s.database()
+	if err != nil {
+		return err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range []string{
+		`REVOKE CREATE ON SCHEMA public FROM PUBLIC`,
+		`CREATE SCHEMA IF NOT EXISTS ` + templateVersionSchema,
+		`CREATE TABLE IF NOT EXISTS ` + templateVersionSchema + `.schema_versions (
+             schema_name VARCHAR(63) PRIMARY KEY,
+             version INTEGER NOT NULL DEFAULT 0
+         )`,
+		`CREATE SCHEMA IF NOT EXISTS ` + pq.QuoteIdentifier(schemaName),
+	} {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO `+templateVersionSchema+`.schema_versions (schema_name) VALUES ($1)
+                                  ON CONFLICT DO NOTHING`, schemaName)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DropSchema drops a tenant schema with everything in it. It does nothing
+// when the schema does not exist.
+func (s *ShardServer) DropSchema(ctx context.Context, schemaName string) error {
+	db, err := s.database()
+	if err != nil {
+		return err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DROP SCHEMA IF EXISTS `+pq.QuoteIdentifier(schemaName)+` CASCADE`); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM `+templateVersionSchema+`.schema_versions WHERE schema_name = $1`, schemaName)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == undefinedTableCode {
+		// No schema was ever created on the shard
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// undefinedTableCode is the Postgres SQLSTATE for undefined_table
+const undefinedTableCode = "42P01"
+
+// CreateTenantRole creates a login role or updates an existing one, like
+// TenantRepository.CreateTenantRole does for schemas in the registry database
+func (s *ShardServer) CreateTenantRole(ctx context.Context, role, schemaName string, limits model.RoleLimits) error {
+	exists, err := s.server.roleExists(ctx, role)
+	if err != nil {
+		return err
+	}
+	quotedRole, quotedSchema := pq.QuoteIdentifier(role), pq.QuoteIdentifier(schemaName)
+	var statements []string
+	if !exists {
+		statements = append(statements, `CREATE ROLE `+quotedRole)
+	}
+	statements = append(statements, roleSettings(quotedRole, quotedSchema, limits)...)
+	statements = append(statements, `GRANT CONNECT, TEMPORARY ON DATABASE `+pq.QuoteIdentifier(s.dbName)+` TO `+quotedRole)
+	for _, stmt := range statements {
+		if _, err := s.server.admin.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return s.inDatabase(ctx, schemaGrants(quotedSchema, quotedRole))
+}
+
+// SetTenantRolePassword replaces the password of a tenant role
+func (s *ShardServer) SetTenantRolePassword(ctx context.Context, role, password string) error {
+	return s.server.SetTenantRolePassword(ctx, role, password)
+}
+
+// DropTenantRole revokes everything granted to a tenant role in the shard's
+// database and drops it. It does nothing when the role does not exist.
+func (s *ShardServer) DropTenantRole(ctx context.Context, role string) error {
+	exists, err := s.server.roleExists(ctx, role)
+	if err != nil || !exists {
+		return err
+	}
+	if err := s.ForgetTenantRole(ctx, role); err != nil {
+		return err
+	}
+	_, err = s.server.admin.ExecContext(ctx, `DROP ROLE `+pq.QuoteIdentifier(role))
+	return err
+}
+
+// ForgetTenantRole revokes everything granted to a tenant role in the shard's
+// database but keeps the role, which another database on the same server may
+// still grant access to. It does nothing when the role does not exist.
+func (s *ShardServer) ForgetTenantRole(ctx context.Context, role string) error {
+	exists, err := s.server.roleExists(ctx, role)
+	if err != nil || !exists {
+		return err
+	}
+	quoted := pq.QuoteIdentifier(role)
+	if err := s.inDatabase(ctx, []string{`DROP OWNED BY ` + quoted}); err != nil {
+		return err
+	}
+	_, err = s.server.admin.ExecContext(ctx, `REVOKE ALL ON DATABASE `+pq.QuoteIdentifier(s.dbName)+` FROM `+quoted)
+	return err
+}
+
+// RevokeTenantRole takes away everything a tenant role was granted on its
+// schema. It does nothing when the role does not exist.
+func (s *ShardServer) RevokeTenantRole(ctx context.Context, role, schemaName string) error {
+	exists, err := s.server.roleExists(ctx, role)
+	if err != nil || !exists || schemaName == "" {
+		return err
+	}
+	return s.inDatabase(ctx, schemaRevokes(pq.QuoteIdentifier(schemaName), pq.QuoteIdentifier(role)))
+}
+
+// BlockTenantConnections stops a tenant role from opening connections and
+// terminates the ones it has open
+func (s *ShardServer) BlockTenantConnections(ctx context.Context, role string) error {
+	return s.server.BlockTenantConnections(ctx, role)
+}
+
+// UnblockTenantConnections lifts the connection block of a tenant role
+func (s *ShardServer) UnblockTenantConnections(ctx context.Context, role string, limit int) error {
+	return s.server.UnblockTenantConnections(ctx, role, limit)
+}
+
+// SnapshotSchema copies every table of a tenant schema into snapshotSchema on
+// the shard. An existing snapshot is kept; it reports false, copying nothing,
+// when neither the snapshot nor the tenant schema exists.
+func (s *ShardServer) SnapshotSchema(ctx context.Context, schemaName, snapshotSchema string) (bool, error) {
+	db, err := s.database()
+	if err != nil {
+		return false, err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var snapshotExists, schemaExists bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = $1),
+                                          EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = $2)`, snapshotSchema, schemaName).
+		Scan(&snapshotExists, &schemaExists)
+	if err != nil || snapshotExists || !schemaExists {
+		return snapshotExists, err
+	}
+	if err := copySchema(ctx, tx, pq.QuoteIdentifier(schemaName), snapshotSchema); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// SchemaTemplateVersion returns the template version a tenant schema is at
+func (s *ShardServer) SchemaTemplateVersion(ctx context.Context, schemaName string) (int, error) {
+	db, err := s.database()
+	if err != nil {
+		return 0, err
+	}
+	var version int
+	err = db.QueryRowContext(ctx, `SELECT version FROM `+templateVersionSchema+`.schema_versions WHERE schema_name = $1`, schemaName).Scan(&version)
+	if err == sql.ErrNoRows {
+		return 0, errors.New("tenant schema " + schemaName + " has no template version")
+	}
+	return version, err
+}
+
+// ApplySchemaMigration runs a template migration with a tenant schema first
+// on the search path and records its version in the same transaction. It
+// returns false when the schema is already at version or later.
+func (s *ShardServer) ApplySchemaMigration(ctx context.Context, schemaName string, version int, migration string) (bool, error) {
+	db, err := s.database()
+	if err != nil {
+		return false, err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var current int
+	err = tx.QueryRowContext(ctx, `SELECT version FROM `+templateVersionSchema+`.schema_versions WHERE schema_name = $1 FOR UPDATE`, schemaName).
+		Scan(&current)
+	if err != nil {
+		return false, err
+	}
+	if current >= version {
+		return false, nil
+	}
+	if _, err := tx.ExecContext(ctx, `SET LOCAL search_path TO `+pq.QuoteIdentifier(schemaName)+`, public`); err != nil {
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, migration); err != nil {
+		return false, err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE `+templateVersionSchema+`.schema_versions SET version = $2 WHERE schema_name = $1`, schemaName, version)
+	if err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// inDatabase runs statements in one transaction in the shard's database
+func (s *ShardServer) inDatabase(ctx context.Context, statements []string) error {
+	db, err := s.database()
+	if err != nil {
+		return err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Close closes every connection to the shard
+func (s *ShardServer) Close() error {
+	return s.server.Close()
+}
@@ -0,0 +1,235 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+// SharedSchema holds the tables shared tenants keep their rows in
+const SharedSchema = "tenant_shared"
+
+// sharedAccessRole is granted to the role of every shared tenant
+const sharedAccessRole = "tenant_shared_access"
+
+// SharedTemplateVersion returns the template version of the shared tables
+func (r *TenantRepository) SharedTemplateVersion(ctx context.Context) (int, error) {
+	var version int
+	err := r.db.QueryRowContext(ctx, `SELECT version FROM tenant_shared_template`).Scan(&version)
+	return version, err
+}
+
+// ApplySharedMigration runs a shared template migration with the shared
+// schema first on the search path and records its version in the same
+// transaction, like ApplyTenantMigration does for a tenant schema
+func (r *TenantRepository) ApplySharedMigration(ctx context.Context, version int, migration string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var current int
+	if err := tx.QueryRowContext(ctx, `SELECT version FROM tenant_shared_template FOR UPDATE`).Scan(&current); err != nil {
+		return false, err
+	}
+	if current >= version {
+		return false, nil
+	}
+	if _, err := tx.ExecContext(ctx, `SET LOCAL search_path TO `+SharedSchema+`, public`); err != nil {
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, migration); err != nil {
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE tenant_shared_template SET version = $1`, version); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// AddSharedMember records that a tenant keeps its rows in the shared tables
+func (r *TenantRepository) AddSharedMember(ctx context.Context, tenantID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO tenant_shared_members (tenant_id) VALUES ($1) ON CONFLICT DO NOTHING`, tenantID)
+	return err
+}
+
+// RemoveSharedMember deletes a tenant's rows from every shared table and
+// forgets the tenant, in one transaction
+func (r *TenantRepository) RemoveSharedMember(ctx context.Context, tenantID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tables, err := sharedTables(ctx, tx, tenantID)
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+SharedSchema+`.`+table+` WHERE tenant_id = $1`, tenantID); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tenant_shared_members WHERE tenant_id = $1`, tenantID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// sharedTables returns the quoted names of the shared tables holding tenant
+// rows. It also points the transaction's app.tenant_id at the tenant, so
+// that row-level security lets it reach the tenant's rows.
+func sharedTables(ctx context.Context, tx *sql.Tx, tenantID uuid.UUID) ([]string, error) {
+	if _, err := tx.ExecContext(ctx, `SELECT set_config('app.tenant_id', $1, true)`, tenantID.String()); err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT c.relname FROM pg_class c
+                                       JOIN pg_attribute a ON a.attrelid = c.oid AND a.attname = 'tenant_id' AND NOT a.attisdropped
+                                       WHERE c.relnamespace = $1::regnamespace AND c.relkind IN ('r', 'p')
+                                       ORDER BY c.relname`, SharedSchema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, pq.QuoteIdentifier(table))
+	}
+	return tables, rows.Err()
+}
+
+// CreateSharedTenantRole creates a login role for a shared tenant, or
+// updates an existing one. The role reaches the shared tables through
+// tenant_shared_access, with app.tenant_id set to its tenant, and is held to
+// limits. It has no password until SetTenantRolePassword gives it one.
+func (r *TenantRepository) CreateSharedTenantRole(ctx context.Context, role string, tenantID uuid.UUID, limits model.RoleLimits) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = $1)`, role).Scan(&exists); err != nil {
+		return err
+	}
+	quoted := pq.QuoteIdentifier(role)
+	var statements []string
+	if !exists {
+		statements = append(statements, `CREATE ROLE `+quoted)
+	}
+	statements = append(statements, roleSettings(quoted, SharedSchema, limits)...)
+	statements = append(statements,
+		`ALTER ROLE `+quoted+` SET app.tenant_id = `+pq.QuoteLiteral(tenantID.String()),
+		`GRANT `+sharedAccessRole+` TO `+quoted,
+	)
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// RevokeSharedTenantRole takes away a shared tenant role's access to the
+// shared tables. It does nothing when the role does not exist.
+func (r *TenantRepository) RevokeSharedTenantRole(ctx context.Context, role string) error {
+	exists, err := r.roleExists(ctx, role)
+	if err != nil || !exists {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `REVOKE `+sharedAccessRole+` FROM `+pq.QuoteIdentifier(role))
+	return err
+}
+
+// SnapshotSharedTenant copies a shared tenant's rows of every shared table
+// into snapshotSchema and records the snapshot, like SnapshotTenantSchema.
+// It returns "" when the tenant is not a shared tenant.
+func (r *TenantRepository) SnapshotSharedTenant(ctx context.Context, tenantID uuid.UUID, snapshotSchema string) (string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var existing string
+	err = tx.QueryRowContext(ctx, `SELECT snapshot_schema FROM tenant_snapshots WHERE tenant_id = $1`, tenantID).Scan(&existing)
+	if err == nil {
+		return existing, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", err
+	}
+	var member bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM tenant_shared_members WHERE tenant_id = $1)`, tenantID).Scan(&member)
+	if err != nil || !member {
+		return "", err
+	}
+
+	tables, err := sharedTables(ctx, tx, tenantID)
+	if err != nil {
+		return "", err
+	}
+	quoted := pq.QuoteIdentifier(snapshotSchema)
+	if _, err := tx.ExecContext(ctx, `CREATE SCHEMA `+quoted); err != nil {
+		return "", err
+	}
+	for _, table := range tables {
+		// CREATE TABLE AS takes no parameters
+		stmt := fmt.Sprintf(`CREATE TABLE %s.%s AS SELECT * FROM %s.%s WHERE tenant_id = %s`,
+			quoted, table, SharedSchema, table, pq.QuoteLiteral(tenantID.String()))
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return "", err
+		}
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO tenant_snapshots (tenant_id, source_schema, snapshot_schema) VALUES ($1, $2, $3)`,
+		tenantID, SharedSchema, snapshotSchema)
+	if err != nil {
+		return "", err
+	}
+	return snapshotSchema, tx.Commit()
+}
+
+// ScheduleSharedMemberDrop marks a shared tenant's rows to be deleted at the
+// given time, keeping an earlier schedule. It does nothing for other tenants.
+func (r *TenantRepository) ScheduleSharedMemberDrop(ctx context.Context, tenantID uuid.UUID, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE tenant_shared_members SET drop_after = COALESCE(drop_after, $2) WHERE tenant_id = $1`, tenantID, at)
+	return err
+}
+
+// CancelSharedMemberDrop keeps a shared tenant's rows that were scheduled to be deleted
+func (r *TenantRepository) CancelSharedMemberDrop(ctx context.Context, tenantID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE tenant_shared_members SET drop_after = NULL WHERE tenant_id = $1`, tenantID)
+	return err
+}
+
+// DueSharedDrops returns up to limit shared tenants whose rows are due to be deleted
+func (r *TenantRepository) DueSharedDrops(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT tenant_id FROM tenant_shared_members WHERE drop_after <= now() ORDER BY drop_after LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
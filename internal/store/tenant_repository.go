@@ -19,21 +19,42 @@ type TenantRepository struct {
 	redis *redis.Client
 }
 
-func NewTenantRepository(dsn string) (*TenantRepository, error) {
+// RepositoryOption configures a TenantRepository
+type RepositoryOption func(*redis.Options)
+
+// WithRedis caches tenants in the Redis server at addr, logging in with
+// password
+func WithRedis(addr, password string) RepositoryOption {
+	return func(o *redis.Options) {
+		o.Addr = addr
+		o.Password = password
+	}
+}
+
+func NewTenantRepository(dsn string, opts ...RepositoryOption) (*TenantRepository, error) {
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
 		return nil, err
 	}
 
-	rdb := redis.NewClient(&redis.Options{
+	redisOpts := &redis.Options{
 		Addr:     "localhost:6379",
 		Password: "", // No password by default
 		DB:       0,  // Use default DB
-	})
+	}
+	for _, opt := range opts {
+		opt(redisOpts)
+	}
+	rdb := redis.NewClient(redisOpts)
 
 	return &TenantRepository{db: db, redis: rdb}, nil
 }
 
+// Redis returns the Redis client shared with the tenant cache
+func (r *TenantRepository) Redis() *redis.Client {
+	return r.redis
+}
+
 func (r *TenantRepository) Close() error {
 	if err := r.db.Close(); err != nil {
 		return err
@@ -42,19 +63,37 @@ func (r *TenantRepository) Close() error {
 }
 
 func (r *TenantRepository) Create(ctx context.Context, tenant *model.Tenant) error {
-	query := `INSERT INTO tenants (id, name, subdomain, encrypted_email, email_iv, status, provisioned, created_at, updated_at)
-              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	query := `INSERT INTO tenants (id, name, subdomain, tier, region, encrypted_email, email_iv, status, provisioned, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
 	tenant.ID = uuid.New()
 	tenant.CreatedAt = time.Now()
 	tenant.UpdatedAt = tenant.CreatedAt
-	_, err := r.db.ExecContext(ctx, query, tenant.ID, tenant.Name, tenant.Subdomain, tenant.EncryptedEmail, tenant.EmailIV, tenant.Status, tenant.Provisioned, tenant.CreatedAt, tenant.UpdatedAt)
+	if tenant.Tier == "" {
+		tenant.Tier = model.TierBasic
+	}
+	_, err := r.db.ExecContext(ctx, query, tenant.ID, tenant.Name, tenant.Subdomain, tenant.Tier, tenant.Region, tenant.EncryptedEmail, tenant.EmailIV, tenant.Status, tenant.Provisioned, tenant.CreatedAt, tenant.UpdatedAt)
 	if err == nil {
 		// Invalidate cache for this tenant (if it exists)
 		r.redis.Del(ctx, fmt.Sprintf("tenant:%s", tenant.ID.String()))
 	}
-	return err
+	return uniqueViolation(err, SubdomainConstraint, ResourceTenant, "subdomain", tenant.Subdomain)
 }
 
+// PurgeUnprovisionedTenant removes a tenant that was created but never
+// queued for provisioning, freeing its subdomain. Tenants that got further
+// are left alone.
+func (r *TenantRepository) PurgeUnprovisionedTenant(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM tenants t WHERE t.id = $1 AND t.status = 'provisioning' AND NOT t.provisioned
+              AND NOT EXISTS (SELECT 1 FROM provisioning_jobs j WHERE j.tenant_id = t.id)
+              AND NOT EXISTS (SELECT 1 FROM tenant_shard_schemas s WHERE s.tenant_id = t.id)`
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return err
+	}
+	r.redis.Del(ctx, fmt.Sprintf("tenant:%s", id.String()))
+	return nil
+}
+
+// GetByID returns a tenant, including soft-deleted ones, or a NotFoundError
 func (r *TenantRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Tenant, error) {
 	// Check cache first
 	key := fmt.Sprintf("tenant:%s", id.String())
@@ -67,12 +106,12 @@ func (r *TenantRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Te
 	}
 
 	// Cache miss, query database
-	query := `SELECT id, name, subdomain, encrypted_email, email_iv, status, provisioned, created_at, updated_at, deleted_at
+	query := `SELECT id, name, subdomain, tier, region, encrypted_email, email_iv, status, COALESCE(status_reason, ''), provisioned, created_at, updated_at, deleted_at
               FROM tenants WHERE id = $1`
 	tenant := &model.Tenant{}
-	err = r.db.QueryRowContext(ctx, query, id).Scan(&tenant.ID, &tenant.Name, &tenant.Subdomain, &tenant.EncryptedEmail, &tenant.EmailIV, &tenant.Status, &tenant.Provisioned, &tenant.CreatedAt, &tenant.UpdatedAt, &tenant.DeletedAt)
+	err = r.db.QueryRowContext(ctx, query, id).Scan(&tenant.ID, &tenant.Name, &tenant.Subdomain, &tenant.Tier, &tenant.Region, &tenant.EncryptedEmail, &tenant.EmailIV, &tenant.Status, &tenant.StatusReason, &tenant.Provisioned, &tenant.CreatedAt, &tenant.UpdatedAt, &tenant.DeletedAt)
 	if err == sql.ErrNoRows {
-		return nil, nil
+		return nil, notFound(ResourceTenant, id.String())
 	}
 	if err != nil {
 		return nil, err
@@ -97,24 +136,31 @@ func (r *TenantRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Te
 }
 
 func (r *TenantRepository) Update(ctx context.Context, tenant *model.Tenant) error {
-	query := `UPDATE tenants SET name = $2, subdomain = $3, encrypted_email = $4, email_iv = $5, status = $6, provisioned = $7, updated_at = $8
+	query := `UPDATE tenants SET name = $2, subdomain = $3, tier = $4, encrypted_email = $5, email_iv = $6, status = $7, status_reason = NULLIF($10, ''), provisioned = $8, updated_at = $9
               WHERE id = $1`
 	tenant.UpdatedAt = time.Now()
-	_, err := r.db.ExecContext(ctx, query, tenant.ID, tenant.Name, tenant.Subdomain, tenant.EncryptedEmail, tenant.EmailIV, tenant.Status, tenant.Provisioned, tenant.UpdatedAt)
+	if tenant.Tier == "" {
+		tenant.Tier = model.TierBasic
+	}
+	_, err := r.db.ExecContext(ctx, query, tenant.ID, tenant.Name, tenant.Subdomain, tenant.Tier, tenant.EncryptedEmail, tenant.EmailIV, tenant.Status, tenant.Provisioned, tenant.UpdatedAt, tenant.StatusReason)
 	if err == nil {
 		// Invalidate cache
 		r.redis.Del(ctx, fmt.Sprintf("tenant:%s", tenant.ID.String()))
 	}
-	return err
+	return uniqueViolation(err, SubdomainConstraint, ResourceTenant, "subdomain", tenant.Subdomain)
 }
 
+// GetBySubdomain returns the tenant owning a subdomain or a NotFoundError.
+// When no live tenant owns it, the most recently deleted previous owner is
+// returned so callers can apply the subdomain reclaim policy.
 func (r *TenantRepository) GetBySubdomain(ctx context.Context, subdomain string) (*model.Tenant, error) {
-	query := `SELECT id, name, subdomain, encrypted_email, email_iv, status, provisioned, created_at, updated_at, deleted_at
-              FROM tenants WHERE subdomain = $1`
+	query := `SELECT id, name, subdomain, tier, region, encrypted_email, email_iv, status, COALESCE(status_reason, ''), provisioned, created_at, updated_at, deleted_at
+              FROM tenants WHERE subdomain = $1
+              ORDER BY deleted_at DESC NULLS FIRST LIMIT 1`
 	tenant := &model.Tenant{}
-	err := r.db.QueryRowContext(ctx, query, subdomain).Scan(&tenant.ID, &tenant.Name, &tenant.Subdomain, &tenant.EncryptedEmail, &tenant.EmailIV, &tenant.Status, &tenant.Provisioned, &tenant.CreatedAt, &tenant.UpdatedAt, &tenant.DeletedAt)
+	err := r.db.QueryRowContext(ctx, query, subdomain).Scan(&tenant.ID, &tenant.Name, &tenant.Subdomain, &tenant.Tier, &tenant.Region, &tenant.EncryptedEmail, &tenant.EmailIV, &tenant.Status, &tenant.StatusReason, &tenant.Provisioned, &tenant.CreatedAt, &tenant.UpdatedAt, &tenant.DeletedAt)
 	if err == sql.ErrNoRows {
-		return nil, nil
+		return nil, notFound(ResourceTenant, subdomain)
 	}
 	if err != nil {
 		return nil, err
@@ -132,20 +178,20 @@ func (r *TenantRepository) GetBySubdomain(ctx context.Context, subdomain string)
 	return tenant, nil
 }
 
+// Delete soft deletes a tenant and moves it to deprovisioning, returning a
+// NotFoundError when the tenant does not exist or has already been deleted
 func (r *TenantRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `UPDATE tenants SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`
-	_, err := r.db.ExecContext(ctx, query, id, time.Now())
+	query := `UPDATE tenants SET deleted_at = $2, status = 'deprovisioning', status_reason = NULL WHERE id = $1 AND deleted_at IS NULL`
+	res, err := r.db.ExecContext(ctx, query, id, time.Now())
 	if err != nil {
 		return err
 	}
-	var count int
-	countQuery := `SELECT COUNT(*) FROM tenants WHERE id = $1 AND deleted_at IS NOT NULL`
-	err = r.db.QueryRowContext(ctx, countQuery, id).Scan(&count)
+	count, err := res.RowsAffected()
 	if err != nil {
 		return err
 	}
 	if count == 0 {
-		return fmt.Errorf("tenant not found or already deleted")
+		return notFound(ResourceTenant, id.String())
 	}
 	// Invalidate cache
 	r.redis.Del(ctx, fmt.Sprintf("tenant:%s", id.String()))
@@ -167,7 +213,7 @@ func (r *TenantRepository) GetTenantSchema(ctx context.Context, tenantID uuid.UU
 	var schemaName string
 	err := r.db.QueryRowContext(ctx, query, tenantID).Scan(&schemaName)
 	if err == sql.ErrNoRows {
-		return "", fmt.Errorf("schema not found for tenant %s", tenantID)
+		return "", notFound(ResourceTenantSchema, tenantID.String())
 	}
 	return schemaName, err
 }
@@ -98,7 +98,7 @@ func TestTenantRepository_GetBySubdomain(t *testing.T) {
 
 	// Test non-existent subdomain
 	fetchedTenant, err = repo.GetBySubdomain(ctx, "nonexistent")
-	assert.NoError(t, err)
+	assert.ErrorIs(t, err, ErrNotFound)
 	assert.Nil(t, fetchedTenant)
 }
 
@@ -165,4 +165,5 @@ func TestTenantRepository_Delete(t *testing.T) {
 	fetchedTenant, err := repo.GetByID(ctx, tenant.ID)
 	assert.NoError(t, err)
 	assert.NotNil(t, fetchedTenant.DeletedAt)
+	assert.Equal(t, "deprovisioning", fetchedTenant.Status)
 }
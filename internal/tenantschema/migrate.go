@@ -0,0 +1,36 @@
+package tenantschema
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Store applies template migrations to tenant schemas and tracks the version
+// each schema is at
+type Store interface {
+	TenantTemplateVersion(ctx context.Context, tenantID uuid.UUID) (int, error)
+	// ApplyTenantMigration runs sql in the tenant's schema and records version,
+	// atomically. It reports false without running anything if the schema is
+	// already at version or later.
+	ApplyTenantMigration(ctx context.Context, tenantID uuid.UUID, version int, sql string) (bool, error)
+}
+
+// Migrate brings a tenant schema up to the template's version and returns
+// the versions it started and ended at. Each migration commits on its own, so
+// a failure leaves the schema at the last migration that succeeded.
+func Migrate(ctx context.Context, s Store, tenantID uuid.UUID, t *Template) (from, to int, err error) {
+	from, err = s.TenantTemplateVersion(ctx, tenantID)
+	if err != nil {
+		return 0, 0, err
+	}
+	to = from
+	for _, m := range t.Pending(from) {
+		if _, err := s.ApplyTenantMigration(ctx, tenantID, m.Version, m.Up); err != nil {
+			return from, to, fmt.Errorf("template migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		to = m.Version
+	}
+	return from, to, nil
+}
@@ -0,0 +1,178 @@
+package tenantschema
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+// Registry lists the tenant schemas a rollout covers
+type Registry interface {
+	Store
+	ListTenantSchemas(ctx context.Context) ([]model.TenantSchema, error)
+}
+
+// RolloutOptions controls how a template is rolled across tenant schemas
+type RolloutOptions struct {
+	// Concurrency is how many schemas are migrated at once
+	Concurrency int
+	// Tenants restricts the rollout to these tenants when not empty
+	Tenants []uuid.UUID
+	// Canary limits the rollout to the first Canary schemas, by name, that
+	// are behind the template; 0 covers all of them. Schemas are always taken
+	// in the same order, so a larger canary extends a smaller one.
+	Canary int
+	// MaxFailures stops the rollout once this many schemas failed; schemas
+	// not started by then are skipped. 0 never stops.
+	MaxFailures int
+	// DryRun reports the pending migrations without applying them
+	DryRun bool
+}
+
+// Schema outcomes of a rollout
+const (
+	OutcomeMigrated = "migrated"
+	OutcomeUpToDate = "up_to_date"
+	OutcomePending  = "pending"
+	OutcomeFailed   = "failed"
+	OutcomeSkipped  = "skipped"
+)
+
+// SchemaResult is what a rollout did to one tenant schema
+type SchemaResult struct {
+	TenantID   uuid.UUID
+	SchemaName string
+	Outcome    string
+	// From and To are the template versions before and after the rollout;
+	// in a dry run To is the version the schema would reach
+	From, To int
+	Duration time.Duration
+	Err      error
+}
+
+// Report summarizes a rollout
+type Report struct {
+	TemplateVersion int
+	DryRun          bool
+	Stopped         bool
+	// Results holds one entry per schema, in schema name order
+	Results []SchemaResult
+}
+
+// Count returns how many schemas ended with outcome
+func (r *Report) Count(outcome string) int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Outcome == outcome {
+			n++
+		}
+	}
+	return n
+}
+
+// Rollout brings every tenant schema selected by opts up to the template's
+// version. A failed schema does not stop the others until opts.MaxFailures is
+// reached; failures are reported per schema rather than returned. The error
+// is only set when the schemas cannot be listed.
+func Rollout(ctx context.Context, r Registry, t *Template, opts RolloutOptions) (*Report, error) {
+	schemas, err := r.ListTenantSchemas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{TemplateVersion: t.Version(), DryRun: opts.DryRun}
+	report.Results = selectSchemas(schemas, t, opts)
+
+	var due []int
+	for i := range report.Results {
+		res := &report.Results[i]
+		switch {
+		case res.From >= t.Version():
+			res.Outcome = OutcomeUpToDate
+		case opts.DryRun:
+			res.Outcome = OutcomePending
+		default:
+			due = append(due, i)
+		}
+	}
+	if len(due) == 0 {
+		return report, nil
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var (
+		mu       sync.Mutex
+		failures int
+		wg       sync.WaitGroup
+	)
+	stopped := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return opts.MaxFailures > 0 && failures >= opts.MaxFailures
+	}
+	// Wait for a free slot before deciding whether to go on, so the decision
+	// sees the failures of every schema finished so far
+	slots := make(chan struct{}, concurrency)
+	for n, i := range due {
+		slots <- struct{}{}
+		if stopped() || ctx.Err() != nil {
+			report.Stopped = true
+			for _, j := range due[n:] {
+				report.Results[j].Outcome = OutcomeSkipped
+			}
+			break
+		}
+		wg.Add(1)
+		go func(res *SchemaResult) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			start := time.Now()
+			res.From, res.To, res.Err = Migrate(ctx, r, res.TenantID, t)
+			res.Duration = time.Since(start)
+			if res.Err != nil {
+				res.Outcome = OutcomeFailed
+				mu.Lock()
+				failures++
+				mu.Unlock()
+				return
+			}
+			res.Outcome = OutcomeMigrated
+		}(&report.Results[i])
+	}
+	wg.Wait()
+	return report, nil
+}
+
+// selectSchemas applies the tenant filter and the canary limit. The version
+// in the listing seeds From; Migrate reads it again before applying anything.
+func selectSchemas(schemas []model.TenantSchema, t *Template, opts RolloutOptions) []SchemaResult {
+	wanted := make(map[uuid.UUID]bool, len(opts.Tenants))
+	for _, id := range opts.Tenants {
+		wanted[id] = true
+	}
+	var results []SchemaResult
+	behind := 0
+	for _, s := range schemas {
+		if len(wanted) > 0 && !wanted[s.TenantID] {
+			continue
+		}
+		if s.TemplateVersion < t.Version() {
+			if opts.Canary > 0 && behind >= opts.Canary {
+				continue
+			}
+			behind++
+		}
+		results = append(results, SchemaResult{
+			TenantID:   s.TenantID,
+			SchemaName: s.SchemaName,
+			From:       s.TemplateVersion,
+			To:         max(s.TemplateVersion, t.Version()),
+		})
+	}
+	return results
+}
@@ -0,0 +1,109 @@
+package tenantschema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/teresa-solution/tenant-management-service/internal/model"
+)
+
+type fakeRegistry struct {
+	mu       sync.Mutex
+	schemas  []model.TenantSchema
+	versions map[uuid.UUID]int
+	failing  map[uuid.UUID]bool
+}
+
+func newFakeRegistry(versions ...int) *fakeRegistry {
+	r := &fakeRegistry{versions: make(map[uuid.UUID]int), failing: make(map[uuid.UUID]bool)}
+	for i, v := range versions {
+		id := uuid.New()
+		r.schemas = append(r.schemas, model.TenantSchema{TenantID: id, SchemaName: fmt.Sprintf("tenant_%02d", i), TemplateVersion: v})
+		r.versions[id] = v
+	}
+	return r
+}
+
+func (r *fakeRegistry) ListTenantSchemas(ctx context.Context) ([]model.TenantSchema, error) {
+	return r.schemas, nil
+}
+
+func (r *fakeRegistry) TenantTemplateVersion(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.versions[tenantID], nil
+}
+
+func (r *fakeRegistry) ApplyTenantMigration(ctx context.Context, tenantID uuid.UUID, version int, sql string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failing[tenantID] {
+		return false, errors.New("relation already exists")
+	}
+	r.versions[tenantID] = version
+	return true, nil
+}
+
+func testTemplate(t *testing.T) *Template {
+	tmpl, err := NewTemplate(Migration{Version: 1, Name: "a"}, Migration{Version: 2, Name: "b"})
+	assert.NoError(t, err)
+	return tmpl
+}
+
+func TestRollout(t *testing.T) {
+	r := newFakeRegistry(0, 2, 1)
+	report, err := Rollout(context.Background(), r, testTemplate(t), RolloutOptions{Concurrency: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Count(OutcomeMigrated))
+	assert.Equal(t, 1, report.Count(OutcomeUpToDate))
+	assert.Equal(t, 0, report.Results[0].From)
+	assert.Equal(t, 2, report.Results[0].To)
+	for _, s := range r.schemas {
+		assert.Equal(t, 2, r.versions[s.TenantID])
+	}
+}
+
+func TestRollout_DryRun(t *testing.T) {
+	r := newFakeRegistry(0, 2)
+	report, err := Rollout(context.Background(), r, testTemplate(t), RolloutOptions{DryRun: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Count(OutcomePending))
+	assert.Equal(t, 2, report.Results[0].To)
+	assert.Equal(t, 0, r.versions[r.schemas[0].TenantID])
+}
+
+func TestRollout_CanaryAndTenants(t *testing.T) {
+	r := newFakeRegistry(2, 0, 0, 0)
+	report, err := Rollout(context.Background(), r, testTemplate(t), RolloutOptions{Canary: 2})
+	assert.NoError(t, err)
+	assert.Len(t, report.Results, 3)
+	assert.Equal(t, 2, report.Count(OutcomeMigrated))
+	assert.Equal(t, 0, r.versions[r.schemas[3].TenantID])
+
+	report, err = Rollout(context.Background(), r, testTemplate(t), RolloutOptions{Tenants: []uuid.UUID{r.schemas[3].TenantID}})
+	assert.NoError(t, err)
+	assert.Len(t, report.Results, 1)
+	assert.Equal(t, 2, r.versions[r.schemas[3].TenantID])
+}
+
+func TestRollout_StopsAtMaxFailures(t *testing.T) {
+	r := newFakeRegistry(0, 0, 0)
+	r.failing[r.schemas[0].TenantID] = true
+	report, err := Rollout(context.Background(), r, testTemplate(t), RolloutOptions{Concurrency: 1, MaxFailures: 1})
+	assert.NoError(t, err)
+	assert.True(t, report.Stopped)
+	assert.Equal(t, 1, report.Count(OutcomeFailed))
+	assert.Equal(t, 2, report.Count(OutcomeSkipped))
+	assert.Error(t, report.Results[0].Err)
+
+	report, err = Rollout(context.Background(), r, testTemplate(t), RolloutOptions{Concurrency: 1})
+	assert.NoError(t, err)
+	assert.False(t, report.Stopped)
+	assert.Equal(t, 1, report.Count(OutcomeFailed))
+	assert.Equal(t, 2, report.Count(OutcomeMigrated))
+}
@@ -0,0 +1,93 @@
+package tenantschema
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// migrationFile matches template migrations such as 000001_tenant_settings.up.sql
+var migrationFile = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.up\.sql$`)
+
+// Migration is one versioned step of the tenant schema template. Its SQL
+// runs with the tenant's schema first on the search path, so unqualified
+// names refer to tenant tables.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+}
+
+// Template is the ordered set of migrations every tenant schema is built from
+type Template struct {
+	migrations []Migration
+}
+
+// NewTemplate creates a template from migrations, which must have distinct
+// positive versions
+func NewTemplate(migrations ...Migration) (*Template, error) {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	for i, m := range sorted {
+		if m.Version < 1 {
+			return nil, fmt.Errorf("template migration %s: version must be positive", m.Name)
+		}
+		if i > 0 && sorted[i-1].Version == m.Version {
+			return nil, fmt.Errorf("template migrations %s and %s share version %d", sorted[i-1].Name, m.Name, m.Version)
+		}
+	}
+	return &Template{migrations: sorted}, nil
+}
+
+// Load reads a template from the *.up.sql files at the root of fsys. Other
+// files, including down migrations, are ignored: tenant schemas only move forward.
+func Load(fsys fs.FS) (*Template, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	var migrations []Migration
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("template migration %s: %w", entry.Name(), err)
+		}
+		up, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{Version: version, Name: match[2], Up: string(up)})
+	}
+	return NewTemplate(migrations...)
+}
+
+// LoadDir reads a template from a directory
+func LoadDir(dir string) (*Template, error) {
+	return Load(os.DirFS(dir))
+}
+
+// Version returns the version a schema has once every migration is applied
+func (t *Template) Version() int {
+	if len(t.migrations) == 0 {
+		return 0
+	}
+	return t.migrations[len(t.migrations)-1].Version
+}
+
+// Migrations returns every migration in version order
+func (t *Template) Migrations() []Migration {
+	return t.migrations
+}
+
+// Pending returns the migrations a schema at version current still needs
+func (t *Template) Pending(current int) []Migration {
+	i := sort.Search(len(t.migrations), func(i int) bool { return t.migrations[i].Version > current })
+	return t.migrations[i:]
+}
@@ -0,0 +1,80 @@
+package tenantschema
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000002_audit_events.up.sql":      {Data: []byte("CREATE TABLE audit_events ()")},
+		"000001_tenant_settings.up.sql":   {Data: []byte("CREATE TABLE tenant_settings ()")},
+		"000001_tenant_settings.down.sql": {Data: []byte("DROP TABLE tenant_settings")},
+		"README.md":                       {Data: []byte("notes")},
+	}
+	tmpl, err := Load(fsys)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, tmpl.Version())
+	if assert.Len(t, tmpl.Migrations(), 2) {
+		assert.Equal(t, Migration{Version: 1, Name: "tenant_settings", Up: "CREATE TABLE tenant_settings ()"}, tmpl.Migrations()[0])
+		assert.Equal(t, "audit_events", tmpl.Migrations()[1].Name)
+	}
+	assert.Len(t, tmpl.Pending(0), 2)
+	assert.Len(t, tmpl.Pending(1), 1)
+	assert.Empty(t, tmpl.Pending(2))
+}
+
+func TestLoad_DuplicateVersion(t *testing.T) {
+	_, err := Load(fstest.MapFS{
+		"000001_a.up.sql": {Data: []byte("SELECT 1")},
+		"1_b.up.sql":      {Data: []byte("SELECT 1")},
+	})
+	assert.Error(t, err)
+}
+
+func TestLoadDir_RepositoryTemplate(t *testing.T) {
+	tmpl, err := LoadDir("../../scripts/tenant-migrations")
+	assert.NoError(t, err)
+	assert.Greater(t, tmpl.Version(), 0)
+}
+
+type fakeStore struct {
+	version int
+	applied []int
+	failAt  int
+}
+
+func (f *fakeStore) TenantTemplateVersion(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	return f.version, nil
+}
+
+func (f *fakeStore) ApplyTenantMigration(ctx context.Context, tenantID uuid.UUID, version int, sql string) (bool, error) {
+	if version == f.failAt {
+		return false, errors.New("syntax error")
+	}
+	f.applied = append(f.applied, version)
+	f.version = version
+	return true, nil
+}
+
+func TestMigrate(t *testing.T) {
+	tmpl, err := NewTemplate(Migration{Version: 1, Name: "a"}, Migration{Version: 2, Name: "b"}, Migration{Version: 3, Name: "c"})
+	assert.NoError(t, err)
+
+	s := &fakeStore{version: 1}
+	from, to, err := Migrate(context.Background(), s, uuid.New(), tmpl)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, from)
+	assert.Equal(t, 3, to)
+	assert.Equal(t, []int{2, 3}, s.applied)
+
+	s = &fakeStore{failAt: 2}
+	_, to, err = Migrate(context.Background(), s, uuid.New(), tmpl)
+	assert.ErrorContains(t, err, "2_b")
+	assert.Equal(t, 1, to)
+}
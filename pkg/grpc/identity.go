@@ -0,0 +1,108 @@
+package grpc
+
+import (
+	"context"
+	"crypto/x509"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// Identity sources recorded on an Identity
+const (
+	IdentitySourceClientCert = "client-cert"
+)
+
+// Identity describes the authenticated caller of an RPC
+type Identity struct {
+	// Subject is the stable name of the caller and is used as the audit actor
+	Subject string
+	// Source records how the identity was established
+	Source string
+}
+
+type identityContextKey struct{}
+
+// WithIdentity returns a copy of ctx carrying the caller identity
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityContextKey{}, identity)
+}
+
+// IdentityFromContext returns the caller identity stored in ctx, if any
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityContextKey{}).(Identity)
+	return identity, ok
+}
+
+// Actor returns the name to record as the actor of an audited action
+func Actor(ctx context.Context) string {
+	if identity, ok := IdentityFromContext(ctx); ok && identity.Subject != "" {
+		return identity.Subject
+	}
+	return "anonymous"
+}
+
+// UnaryClientCertIdentityInterceptor stores the identity of a verified client
+// certificate in the context of unary calls
+func UnaryClientCertIdentityInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(withClientCertIdentity(ctx), req)
+	}
+}
+
+// StreamClientCertIdentityInterceptor is the streaming counterpart of
+// UnaryClientCertIdentityInterceptor
+func StreamClientCertIdentityInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, WrapServerStream(ss, withClientCertIdentity(ss.Context())))
+	}
+}
+
+func withClientCertIdentity(ctx context.Context) context.Context {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return ctx
+	}
+	subject := CertificateSubject(tlsInfo.State.VerifiedChains[0][0])
+	if subject == "" {
+		return ctx
+	}
+	logger := LoggerFromContext(ctx).With().Str("actor", subject).Logger()
+	ctx = logger.WithContext(ctx)
+	return WithIdentity(ctx, Identity{Subject: subject, Source: IdentitySourceClientCert})
+}
+
+// peerAddress returns the host of the remote peer without its port
+func peerAddress(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// CertificateSubject derives a caller name from a client certificate,
+// preferring a URI SAN (such as a SPIFFE ID), then the common name, then the
+// first DNS SAN
+func CertificateSubject(cert *x509.Certificate) string {
+	if len(cert.URIs) > 0 {
+		return cert.URIs[0].String()
+	}
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	if len(cert.DNSNames) > 0 {
+		return cert.DNSNames[0]
+	}
+	return ""
+}
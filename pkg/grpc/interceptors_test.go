@@ -0,0 +1,107 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/teresa-solution/tenant-management-service/pkg/resilience"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var testInfo = &grpc.UnaryServerInfo{FullMethod: "/tenant.v1.TenantService/GetTenant"}
+
+func TestUnaryRequestIDInterceptor_PropagatesIncomingID(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDKey, "req-123"))
+
+	var seen string
+	_, err := UnaryRequestIDInterceptor()(ctx, nil, testInfo, func(ctx context.Context, req interface{}) (interface{}, error) {
+		seen = RequestIDFromContext(ctx)
+		return nil, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "req-123", seen)
+}
+
+func TestUnaryRequestIDInterceptor_GeneratesID(t *testing.T) {
+	var seen string
+	var logger interface{}
+	_, err := UnaryRequestIDInterceptor()(context.Background(), nil, testInfo, func(ctx context.Context, req interface{}) (interface{}, error) {
+		seen = RequestIDFromContext(ctx)
+		logger = LoggerFromContext(ctx)
+		return nil, nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, seen, 36)
+	assert.NotNil(t, logger)
+}
+
+func TestUnaryRecoveryInterceptor_ConvertsPanic(t *testing.T) {
+	resp, err := UnaryRecoveryInterceptor()(context.Background(), nil, testInfo, func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	})
+	assert.Nil(t, resp)
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.Internal, st.Code())
+}
+
+func TestUnaryLoggingInterceptor_PassesThrough(t *testing.T) {
+	wantErr := status.Error(codes.NotFound, "Tenant not found")
+	resp, err := UnaryLoggingInterceptor()(context.Background(), "req", testInfo, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "resp", wantErr
+	})
+	assert.Equal(t, "resp", resp)
+	assert.Equal(t, wantErr, err)
+}
+
+type fakeLimiter struct {
+	allowed bool
+	keys    []string
+}
+
+func (f *fakeLimiter) Allow(ctx context.Context, key string, limit resilience.Limit) (resilience.Reservation, error) {
+	f.keys = append(f.keys, key)
+	return resilience.Reservation{Allowed: f.allowed, RetryAfter: 1500 * time.Millisecond}, nil
+}
+
+func TestConnectionRateLimitPolicy(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 51234}})
+	limiter := &fakeLimiter{allowed: false}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+
+	_, err := UnaryRateLimitInterceptor(limiter, ConnectionRateLimitPolicy(resilience.Limit{Rate: 1, Burst: 1}))(ctx, nil, testInfo, handler)
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+	assert.Equal(t, []string{"conn:peer:10.0.0.7:" + testInfo.FullMethod}, limiter.keys)
+
+	_, _, ok := ConnectionRateLimitPolicy(resilience.Limit{})(ctx, testInfo.FullMethod)
+	assert.False(t, ok)
+}
+
+func TestUnaryRateLimitInterceptor(t *testing.T) {
+	policy := func(ctx context.Context, method string) (string, resilience.Limit, bool) {
+		return CallerKey(ctx), resilience.Limit{Rate: 1, Burst: 1}, true
+	}
+	ctx := WithIdentity(context.Background(), Identity{Subject: "svc-a"})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+
+	limiter := &fakeLimiter{allowed: true}
+	resp, err := UnaryRateLimitInterceptor(limiter, policy)(ctx, nil, testInfo, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+	assert.Equal(t, []string{"svc-a:" + testInfo.FullMethod}, limiter.keys)
+
+	limiter.allowed = false
+	resp, err = UnaryRateLimitInterceptor(limiter, policy)(ctx, nil, testInfo, handler)
+	assert.Nil(t, resp)
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+	assert.Contains(t, st.Message(), "retry after 2 seconds")
+}
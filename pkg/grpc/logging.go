@@ -0,0 +1,51 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryLoggingInterceptor writes one structured access log line per unary call
+func UnaryLoggingInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamLoggingInterceptor writes one structured access log line per stream
+func StreamLoggingInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), info.FullMethod, start, err)
+		return err
+	}
+}
+
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+	logger := LoggerFromContext(ctx)
+
+	var event *zerolog.Event
+	switch code {
+	case codes.OK:
+		event = logger.Info()
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		event = logger.Error().Err(err)
+	default:
+		event = logger.Warn().Err(err)
+	}
+	event.
+		Str("grpc_method", method).
+		Str("grpc_code", code.String()).
+		Dur("duration", time.Since(start)).
+		Msg("gRPC call finished")
+}
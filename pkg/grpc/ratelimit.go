@@ -0,0 +1,91 @@
+package grpc
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"github.com/teresa-solution/tenant-management-service/pkg/resilience"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RetryAfterKey is the response metadata key telling a throttled caller how
+// many seconds to wait before retrying
+const RetryAfterKey = "retry-after"
+
+// RateLimitPolicy returns the bucket key and limit that apply to a call.
+// Returning ok=false exempts the call from rate limiting.
+type RateLimitPolicy func(ctx context.Context, method string) (key string, limit resilience.Limit, ok bool)
+
+// UnaryRateLimitInterceptor rejects calls with codes.ResourceExhausted once
+// the caller's bucket for the method is empty. Limiter errors fail open so an
+// unavailable Redis does not take the API down.
+func UnaryRateLimitInterceptor(limiter resilience.Limiter, policy RateLimitPolicy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := checkRateLimit(ctx, limiter, policy, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamRateLimitInterceptor applies the rate limit when a stream is opened
+func StreamRateLimitInterceptor(limiter resilience.Limiter, policy RateLimitPolicy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := checkRateLimit(ss.Context(), limiter, policy, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func checkRateLimit(ctx context.Context, limiter resilience.Limiter, policy RateLimitPolicy, method string) error {
+	key, limit, ok := policy(ctx, method)
+	if !ok || limit.Unlimited() {
+		return nil
+	}
+	reservation, err := limiter.Allow(ctx, key+":"+method, limit)
+	if err != nil {
+		LoggerFromContext(ctx).Warn().Err(err).Msg("Rate limiter unavailable, allowing call")
+		return nil
+	}
+	if reservation.Allowed {
+		return nil
+	}
+
+	retryAfter := int64(math.Ceil(reservation.RetryAfter.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RetryAfterKey, strconv.FormatInt(retryAfter, 10)))
+	LoggerFromContext(ctx).Warn().
+		Str("rate_limit_key", key).
+		Int64("retry_after_seconds", retryAfter).
+		Msg("Rate limit exceeded")
+	return status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry after %d seconds", retryAfter)
+}
+
+// ConnectionRateLimitPolicy applies one limit to every call, keyed by the
+// client certificate identity or, without one, the peer address. It runs
+// before authentication so that callers presenting bad credentials are
+// throttled too.
+func ConnectionRateLimitPolicy(limit resilience.Limit) RateLimitPolicy {
+	return func(ctx context.Context, method string) (string, resilience.Limit, bool) {
+		return "conn:" + CallerKey(ctx), limit, !limit.Unlimited()
+	}
+}
+
+// CallerKey identifies the caller for rate limiting: the authenticated
+// identity when there is one, otherwise the peer address
+func CallerKey(ctx context.Context) string {
+	if identity, ok := IdentityFromContext(ctx); ok && identity.Subject != "" {
+		return identity.Subject
+	}
+	if addr := peerAddress(ctx); addr != "" {
+		return "peer:" + addr
+	}
+	return "anonymous"
+}
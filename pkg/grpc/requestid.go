@@ -0,0 +1,56 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryRequestIDInterceptor assigns a request ID to every unary call, reusing
+// the caller's x-request-id when present, and injects a logger tagged with it
+func UnaryRequestIDInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(withRequestScope(ctx, info.FullMethod), req)
+	}
+}
+
+// StreamRequestIDInterceptor is the streaming counterpart of UnaryRequestIDInterceptor
+func StreamRequestIDInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, WrapServerStream(ss, withRequestScope(ss.Context(), info.FullMethod)))
+	}
+}
+
+// withRequestScope resolves the request ID for ctx, echoes it back to the
+// caller in the response header and stores a request-scoped logger
+func withRequestScope(ctx context.Context, method string) context.Context {
+	requestID := incomingRequestID(ctx)
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+	// Failing to set the header only happens if headers were already sent
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, requestID))
+
+	logger := log.Logger.With().
+		Str("request_id", requestID).
+		Str("method", method).
+		Logger()
+	ctx = WithRequestID(ctx, requestID)
+	return logger.WithContext(ctx)
+}
+
+// incomingRequestID extracts a caller-supplied request ID from the metadata
+func incomingRequestID(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get(RequestIDKey)
+	if len(values) == 0 || len(values[0]) > 128 {
+		return ""
+	}
+	return values[0]
+}
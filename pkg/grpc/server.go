@@ -0,0 +1,27 @@
+package grpc
+
+import (
+	"google.golang.org/grpc"
+)
+
+// ServerOptions returns the interceptor chain shared by every gRPC server:
+// request IDs first so that access logs and panic reports carry them, then
+// access logging, then panic recovery closest to the handler so recovered
+// panics are logged with codes.Internal. Additional interceptors run after
+// the built-in ones, in the order given.
+func ServerOptions(unary []grpc.UnaryServerInterceptor, stream []grpc.StreamServerInterceptor) []grpc.ServerOption {
+	unaryChain := append([]grpc.UnaryServerInterceptor{
+		UnaryRequestIDInterceptor(),
+		UnaryLoggingInterceptor(),
+		UnaryRecoveryInterceptor(),
+	}, unary...)
+	streamChain := append([]grpc.StreamServerInterceptor{
+		StreamRequestIDInterceptor(),
+		StreamLoggingInterceptor(),
+		StreamRecoveryInterceptor(),
+	}, stream...)
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unaryChain...),
+		grpc.ChainStreamInterceptor(streamChain...),
+	}
+}
@@ -0,0 +1,109 @@
+package resilience
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Limit describes a token bucket refilled at Rate tokens per second that
+// holds at most Burst tokens
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Unlimited reports whether the limit disables rate limiting
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0
+}
+
+// ParseLimit parses a limit written as rate:burst, for example "5:10"
+func ParseLimit(value string) (Limit, error) {
+	var l Limit
+	if _, err := fmt.Sscanf(value, "%g:%d", &l.Rate, &l.Burst); err != nil {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, expected rate:burst", value)
+	}
+	if l.Rate < 0 || l.Burst < 1 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, rate must be positive and burst at least 1", value)
+	}
+	return l, nil
+}
+
+// Reservation is the outcome of taking a token from a bucket
+type Reservation struct {
+	Allowed    bool
+	Remaining  float64
+	RetryAfter time.Duration
+}
+
+// Limiter takes tokens from named buckets
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Reservation, error)
+}
+
+// tokenBucketScript refills and drains a bucket atomically. Time is taken from
+// the Redis server so that replicas with skewed clocks share one view.
+var tokenBucketScript = redis.NewScript(`
+redis.replicate_commands()
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) * 1000 / rate)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return {allowed, tostring(tokens), retry}
+`)
+
+// RedisLimiter is a Limiter whose buckets live in Redis so that every replica
+// of the service enforces the same limits
+type RedisLimiter struct {
+	client    redis.Scripter
+	keyPrefix string
+}
+
+// NewRedisLimiter creates a new RedisLimiter
+func NewRedisLimiter(client redis.Scripter) *RedisLimiter {
+	return &RedisLimiter{client: client, keyPrefix: "ratelimit:"}
+}
+
+// Allow takes one token from the bucket named key
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit Limit) (Reservation, error) {
+	if limit.Unlimited() {
+		return Reservation{Allowed: true}, nil
+	}
+	res, err := tokenBucketScript.Run(ctx, l.client, []string{l.keyPrefix + key}, limit.Rate, limit.Burst).Slice()
+	if err != nil {
+		return Reservation{}, err
+	}
+	if len(res) != 3 {
+		return Reservation{}, fmt.Errorf("unexpected token bucket reply %v", res)
+	}
+	allowed, _ := res[0].(int64)
+	remainingStr, _ := res[1].(string)
+	remaining, _ := strconv.ParseFloat(remainingStr, 64)
+	retryMillis, _ := res[2].(int64)
+	return Reservation{
+		Allowed:    allowed == 1,
+		Remaining:  remaining,
+		RetryAfter: time.Duration(retryMillis) * time.Millisecond,
+	}, nil
+}
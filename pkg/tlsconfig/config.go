@@ -0,0 +1,80 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+// Client authentication modes accepted by Config.ClientAuth
+const (
+	ClientAuthNone          = "none"
+	ClientAuthRequest       = "request"
+	ClientAuthVerifyIfGiven = "verify-if-given"
+	ClientAuthRequire       = "require"
+)
+
+// Config describes the TLS settings of a listener
+type Config struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+	ClientAuth   string
+}
+
+// Enabled reports whether TLS has been configured at all
+func (c Config) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// Validate checks that the configuration is internally consistent
+func (c Config) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	if c.CertFile == "" || c.KeyFile == "" {
+		return fmt.Errorf("both a certificate and a key file are required for TLS")
+	}
+	mode, err := clientAuthType(c.ClientAuth)
+	if err != nil {
+		return err
+	}
+	if mode >= tls.VerifyClientCertIfGiven && c.ClientCAFile == "" {
+		return fmt.Errorf("client auth mode %q requires a client CA bundle", c.ClientAuth)
+	}
+	return nil
+}
+
+// NewServerConfig builds a server tls.Config whose certificate and client CA
+// bundle are served by reloader, so that file changes apply to new handshakes
+func NewServerConfig(c Config, reloader *CertReloader) (*tls.Config, error) {
+	mode, err := clientAuthType(c.ClientAuth)
+	if err != nil {
+		return nil, err
+	}
+	base := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		ClientAuth:     mode,
+		GetCertificate: reloader.GetCertificate,
+	}
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		cfg := base.Clone()
+		cfg.ClientCAs = reloader.ClientCAs()
+		return cfg, nil
+	}
+	return base, nil
+}
+
+func clientAuthType(mode string) (tls.ClientAuthType, error) {
+	switch mode {
+	case "", ClientAuthNone:
+		return tls.NoClientCert, nil
+	case ClientAuthRequest:
+		return tls.RequestClientCert, nil
+	case ClientAuthVerifyIfGiven:
+		return tls.VerifyClientCertIfGiven, nil
+	case ClientAuthRequire:
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("unknown client auth mode %q", mode)
+	}
+}
@@ -22,14 +22,19 @@ const (
 )
 
 type Tenant struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Subdomain     string                 `protobuf:"bytes,3,opt,name=subdomain,proto3" json:"subdomain,omitempty"`
-	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
-	CreatedAt     string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt     string                 `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
-	DeletedAt     string                 `protobuf:"bytes,7,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name      string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Subdomain string                 `protobuf:"bytes,3,opt,name=subdomain,proto3" json:"subdomain,omitempty"`
+	Status    string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	CreatedAt string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt string                 `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	DeletedAt string                 `protobuf:"bytes,7,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	Tier      string                 `protobuf:"bytes,8,opt,name=tier,proto3" json:"tier,omitempty"`
+	// Why the tenant is in its status, e.g. the root cause of a provisioning failure
+	StatusReason string `protobuf:"bytes,9,opt,name=status_reason,json=statusReason,proto3" json:"status_reason,omitempty"`
+	// Region the tenant's data should stay in
+	Region        string `protobuf:"bytes,10,opt,name=region,proto3" json:"region,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -113,12 +118,35 @@ func (x *Tenant) GetDeletedAt() string {
 	return ""
 }
 
+func (x *Tenant) GetTier() string {
+	if x != nil {
+		return x.Tier
+	}
+	return ""
+}
+
+func (x *Tenant) GetStatusReason() string {
+	if x != nil {
+		return x.StatusReason
+	}
+	return ""
+}
+
+func (x *Tenant) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
 type CreateTenantRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Subdomain     string                 `protobuf:"bytes,2,opt,name=subdomain,proto3" json:"subdomain,omitempty"`
-	ContactEmail  string                 `protobuf:"bytes,3,opt,name=contact_email,json=contactEmail,proto3" json:"contact_email,omitempty"`
-	Tier          string                 `protobuf:"bytes,4,opt,name=tier,proto3" json:"tier,omitempty"`
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Name         string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Subdomain    string                 `protobuf:"bytes,2,opt,name=subdomain,proto3" json:"subdomain,omitempty"`
+	ContactEmail string                 `protobuf:"bytes,3,opt,name=contact_email,json=contactEmail,proto3" json:"contact_email,omitempty"`
+	Tier         string                 `protobuf:"bytes,4,opt,name=tier,proto3" json:"tier,omitempty"`
+	// Keeps the tenant on shards of this region under region-affine placement
+	Region        string `protobuf:"bytes,5,opt,name=region,proto3" json:"region,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -181,11 +209,23 @@ func (x *CreateTenantRequest) GetTier() string {
 	return ""
 }
 
+func (x *CreateTenantRequest) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
 type CreateTenantResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Tenant        *Tenant                `protobuf:"bytes,1,opt,name=tenant,proto3" json:"tenant,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Tenant *Tenant                `protobuf:"bytes,1,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	// Durable job that provisions the tenant
+	ProvisioningJobId string `protobuf:"bytes,2,opt,name=provisioning_job_id,json=provisioningJobId,proto3" json:"provisioning_job_id,omitempty"`
+	// "accepted" when workers have capacity, "queued" when the provisioning
+	// backlog is saturated and the tenant will wait longer than usual
+	ProvisioningState string `protobuf:"bytes,3,opt,name=provisioning_state,json=provisioningState,proto3" json:"provisioning_state,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *CreateTenantResponse) Reset() {
@@ -225,6 +265,20 @@ func (x *CreateTenantResponse) GetTenant() *Tenant {
 	return nil
 }
 
+func (x *CreateTenantResponse) GetProvisioningJobId() string {
+	if x != nil {
+		return x.ProvisioningJobId
+	}
+	return ""
+}
+
+func (x *CreateTenantResponse) GetProvisioningState() string {
+	if x != nil {
+		return x.ProvisioningState
+	}
+	return ""
+}
+
 type GetTenantRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -470,10 +524,12 @@ func (x *DeleteTenantRequest) GetId() string {
 }
 
 type DeleteTenantResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// Durable job that deprovisions the tenant
+	DeprovisioningJobId string `protobuf:"bytes,2,opt,name=deprovisioning_job_id,json=deprovisioningJobId,proto3" json:"deprovisioning_job_id,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
 }
 
 func (x *DeleteTenantResponse) Reset() {
@@ -513,49 +569,3030 @@ func (x *DeleteTenantResponse) GetSuccess() bool {
 	return false
 }
 
-var File_proto_tenant_proto protoreflect.FileDescriptor
+func (x *DeleteTenantResponse) GetDeprovisioningJobId() string {
+	if x != nil {
+		return x.DeprovisioningJobId
+	}
+	return ""
+}
 
-const file_proto_tenant_proto_rawDesc = "" +
-	"\n" +
-	"\x12proto/tenant.proto\x12\ttenant.v1\"\xbf\x01\n" +
-	"\x06Tenant\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1c\n" +
-	"\tsubdomain\x18\x03 \x01(\tR\tsubdomain\x12\x16\n" +
-	"\x06status\x18\x04 \x01(\tR\x06status\x12\x1d\n" +
-	"\n" +
-	"created_at\x18\x05 \x01(\tR\tcreatedAt\x12\x1d\n" +
-	"\n" +
-	"updated_at\x18\x06 \x01(\tR\tupdatedAt\x12\x1d\n" +
-	"\n" +
-	"deleted_at\x18\a \x01(\tR\tdeletedAt\"\x80\x01\n" +
-	"\x13CreateTenantRequest\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1c\n" +
-	"\tsubdomain\x18\x02 \x01(\tR\tsubdomain\x12#\n" +
-	"\rcontact_email\x18\x03 \x01(\tR\fcontactEmail\x12\x12\n" +
-	"\x04tier\x18\x04 \x01(\tR\x04tier\"A\n" +
-	"\x14CreateTenantResponse\x12)\n" +
-	"\x06tenant\x18\x01 \x01(\v2\x11.tenant.v1.TenantR\x06tenant\"\"\n" +
-	"\x10GetTenantRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\">\n" +
-	"\x11GetTenantResponse\x12)\n" +
-	"\x06tenant\x18\x01 \x01(\v2\x11.tenant.v1.TenantR\x06tenant\"o\n" +
-	"\x13UpdateTenantRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1c\n" +
-	"\tsubdomain\x18\x03 \x01(\tR\tsubdomain\x12\x16\n" +
-	"\x06status\x18\x04 \x01(\tR\x06status\"A\n" +
-	"\x14UpdateTenantResponse\x12)\n" +
-	"\x06tenant\x18\x01 \x01(\v2\x11.tenant.v1.TenantR\x06tenant\"%\n" +
-	"\x13DeleteTenantRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"0\n" +
-	"\x14DeleteTenantResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess2\xd2\x02\n" +
-	"\rTenantService\x12Q\n" +
-	"\fCreateTenant\x12\x1e.tenant.v1.CreateTenantRequest\x1a\x1f.tenant.v1.CreateTenantResponse\"\x00\x12H\n" +
-	"\tGetTenant\x12\x1b.tenant.v1.GetTenantRequest\x1a\x1c.tenant.v1.GetTenantResponse\"\x00\x12Q\n" +
-	"\fUpdateTenant\x12\x1e.tenant.v1.UpdateTenantRequest\x1a\x1f.tenant.v1.UpdateTenantResponse\"\x00\x12Q\n" +
-	"\fDeleteTenant\x12\x1e.tenant.v1.DeleteTenantRequest\x1a\x1f.tenant.v1.DeleteTenantResponse\"\x00BIZGgithub.com/teresa-solution/tenant-management-service/proto/gen;tenantpbb\x06proto3"
+type APIKey struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TenantId      string                 `protobuf:"bytes,2,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Prefix        string                 `protobuf:"bytes,4,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Scopes        []string               `protobuf:"bytes,5,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	ExpiresAt     string                 `protobuf:"bytes,6,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	LastUsedAt    string                 `protobuf:"bytes,7,opt,name=last_used_at,json=lastUsedAt,proto3" json:"last_used_at,omitempty"`
+	RevokedAt     string                 `protobuf:"bytes,8,opt,name=revoked_at,json=revokedAt,proto3" json:"revoked_at,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *APIKey) Reset() {
+	*x = APIKey{}
+	mi := &file_proto_tenant_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *APIKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*APIKey) ProtoMessage() {}
+
+func (x *APIKey) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use APIKey.ProtoReflect.Descriptor instead.
+func (*APIKey) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *APIKey) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *APIKey) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *APIKey) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *APIKey) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *APIKey) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+func (x *APIKey) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+func (x *APIKey) GetLastUsedAt() string {
+	if x != nil {
+		return x.LastUsedAt
+	}
+	return ""
+}
+
+func (x *APIKey) GetRevokedAt() string {
+	if x != nil {
+		return x.RevokedAt
+	}
+	return ""
+}
+
+func (x *APIKey) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type CreateAPIKeyRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	TenantId string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Name     string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Scopes   []string               `protobuf:"bytes,3,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	// Lifetime of the key; zero means the key does not expire
+	TtlSeconds    int64 `protobuf:"varint,4,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAPIKeyRequest) Reset() {
+	*x = CreateAPIKeyRequest{}
+	mi := &file_proto_tenant_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAPIKeyRequest) ProtoMessage() {}
+
+func (x *CreateAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*CreateAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CreateAPIKeyRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *CreateAPIKeyRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateAPIKeyRequest) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+func (x *CreateAPIKeyRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+type CreateAPIKeyResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	ApiKey *APIKey                `protobuf:"bytes,1,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
+	// The plaintext key, returned only once
+	Secret        string `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAPIKeyResponse) Reset() {
+	*x = CreateAPIKeyResponse{}
+	mi := &file_proto_tenant_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAPIKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAPIKeyResponse) ProtoMessage() {}
+
+func (x *CreateAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*CreateAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CreateAPIKeyResponse) GetApiKey() *APIKey {
+	if x != nil {
+		return x.ApiKey
+	}
+	return nil
+}
+
+func (x *CreateAPIKeyResponse) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+type ListAPIKeysRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TenantId       string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	IncludeRevoked bool                   `protobuf:"varint,2,opt,name=include_revoked,json=includeRevoked,proto3" json:"include_revoked,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListAPIKeysRequest) Reset() {
+	*x = ListAPIKeysRequest{}
+	mi := &file_proto_tenant_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAPIKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAPIKeysRequest) ProtoMessage() {}
+
+func (x *ListAPIKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAPIKeysRequest.ProtoReflect.Descriptor instead.
+func (*ListAPIKeysRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListAPIKeysRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *ListAPIKeysRequest) GetIncludeRevoked() bool {
+	if x != nil {
+		return x.IncludeRevoked
+	}
+	return false
+}
+
+type ListAPIKeysResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ApiKeys       []*APIKey              `protobuf:"bytes,1,rep,name=api_keys,json=apiKeys,proto3" json:"api_keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAPIKeysResponse) Reset() {
+	*x = ListAPIKeysResponse{}
+	mi := &file_proto_tenant_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAPIKeysResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAPIKeysResponse) ProtoMessage() {}
+
+func (x *ListAPIKeysResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAPIKeysResponse.ProtoReflect.Descriptor instead.
+func (*ListAPIKeysResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListAPIKeysResponse) GetApiKeys() []*APIKey {
+	if x != nil {
+		return x.ApiKeys
+	}
+	return nil
+}
+
+type RotateAPIKeyRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	TenantId string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Id       string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	// How long the old key keeps working; zero revokes it immediately
+	GracePeriodSeconds int64 `protobuf:"varint,3,opt,name=grace_period_seconds,json=gracePeriodSeconds,proto3" json:"grace_period_seconds,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *RotateAPIKeyRequest) Reset() {
+	*x = RotateAPIKeyRequest{}
+	mi := &file_proto_tenant_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RotateAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RotateAPIKeyRequest) ProtoMessage() {}
+
+func (x *RotateAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RotateAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*RotateAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RotateAPIKeyRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *RotateAPIKeyRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RotateAPIKeyRequest) GetGracePeriodSeconds() int64 {
+	if x != nil {
+		return x.GracePeriodSeconds
+	}
+	return 0
+}
+
+type RotateAPIKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ApiKey        *APIKey                `protobuf:"bytes,1,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
+	Secret        string                 `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RotateAPIKeyResponse) Reset() {
+	*x = RotateAPIKeyResponse{}
+	mi := &file_proto_tenant_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RotateAPIKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RotateAPIKeyResponse) ProtoMessage() {}
+
+func (x *RotateAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RotateAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*RotateAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RotateAPIKeyResponse) GetApiKey() *APIKey {
+	if x != nil {
+		return x.ApiKey
+	}
+	return nil
+}
+
+func (x *RotateAPIKeyResponse) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+type RevokeAPIKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TenantId      string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeAPIKeyRequest) Reset() {
+	*x = RevokeAPIKeyRequest{}
+	mi := &file_proto_tenant_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAPIKeyRequest) ProtoMessage() {}
+
+func (x *RevokeAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*RevokeAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RevokeAPIKeyRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *RevokeAPIKeyRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RevokeAPIKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeAPIKeyResponse) Reset() {
+	*x = RevokeAPIKeyResponse{}
+	mi := &file_proto_tenant_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAPIKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAPIKeyResponse) ProtoMessage() {}
+
+func (x *RevokeAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*RevokeAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *RevokeAPIKeyResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type VerifyAPIKeyRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Optional scope the key must hold
+	RequiredScope string `protobuf:"bytes,2,opt,name=required_scope,json=requiredScope,proto3" json:"required_scope,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyAPIKeyRequest) Reset() {
+	*x = VerifyAPIKeyRequest{}
+	mi := &file_proto_tenant_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyAPIKeyRequest) ProtoMessage() {}
+
+func (x *VerifyAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*VerifyAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *VerifyAPIKeyRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *VerifyAPIKeyRequest) GetRequiredScope() string {
+	if x != nil {
+		return x.RequiredScope
+	}
+	return ""
+}
+
+type VerifyAPIKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Valid         bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	TenantId      string                 `protobuf:"bytes,2,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	KeyId         string                 `protobuf:"bytes,3,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	Scopes        []string               `protobuf:"bytes,4,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyAPIKeyResponse) Reset() {
+	*x = VerifyAPIKeyResponse{}
+	mi := &file_proto_tenant_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyAPIKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyAPIKeyResponse) ProtoMessage() {}
+
+func (x *VerifyAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*VerifyAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *VerifyAPIKeyResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *VerifyAPIKeyResponse) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *VerifyAPIKeyResponse) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
+func (x *VerifyAPIKeyResponse) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+type QuotaUsage struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Resource string                 `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	// -1 means unlimited
+	Limit int64 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Used  int64 `protobuf:"varint,3,opt,name=used,proto3" json:"used,omitempty"`
+	// True when the limit comes from a per-tenant override instead of the tier
+	Overridden    bool `protobuf:"varint,4,opt,name=overridden,proto3" json:"overridden,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuotaUsage) Reset() {
+	*x = QuotaUsage{}
+	mi := &file_proto_tenant_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuotaUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuotaUsage) ProtoMessage() {}
+
+func (x *QuotaUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuotaUsage.ProtoReflect.Descriptor instead.
+func (*QuotaUsage) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *QuotaUsage) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *QuotaUsage) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *QuotaUsage) GetUsed() int64 {
+	if x != nil {
+		return x.Used
+	}
+	return 0
+}
+
+func (x *QuotaUsage) GetOverridden() bool {
+	if x != nil {
+		return x.Overridden
+	}
+	return false
+}
+
+type GetQuotaUsageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TenantId      string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQuotaUsageRequest) Reset() {
+	*x = GetQuotaUsageRequest{}
+	mi := &file_proto_tenant_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQuotaUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuotaUsageRequest) ProtoMessage() {}
+
+func (x *GetQuotaUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuotaUsageRequest.ProtoReflect.Descriptor instead.
+func (*GetQuotaUsageRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GetQuotaUsageRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+type GetQuotaUsageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tier          string                 `protobuf:"bytes,1,opt,name=tier,proto3" json:"tier,omitempty"`
+	Usage         []*QuotaUsage          `protobuf:"bytes,2,rep,name=usage,proto3" json:"usage,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQuotaUsageResponse) Reset() {
+	*x = GetQuotaUsageResponse{}
+	mi := &file_proto_tenant_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQuotaUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuotaUsageResponse) ProtoMessage() {}
+
+func (x *GetQuotaUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuotaUsageResponse.ProtoReflect.Descriptor instead.
+func (*GetQuotaUsageResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetQuotaUsageResponse) GetTier() string {
+	if x != nil {
+		return x.Tier
+	}
+	return ""
+}
+
+func (x *GetQuotaUsageResponse) GetUsage() []*QuotaUsage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+type SetQuotaOverrideRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	TenantId string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Resource string                 `protobuf:"bytes,2,opt,name=resource,proto3" json:"resource,omitempty"`
+	// -1 means unlimited
+	Limit int64 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Removes the override so the tier limit applies again
+	Clear         bool `protobuf:"varint,4,opt,name=clear,proto3" json:"clear,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetQuotaOverrideRequest) Reset() {
+	*x = SetQuotaOverrideRequest{}
+	mi := &file_proto_tenant_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetQuotaOverrideRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetQuotaOverrideRequest) ProtoMessage() {}
+
+func (x *SetQuotaOverrideRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetQuotaOverrideRequest.ProtoReflect.Descriptor instead.
+func (*SetQuotaOverrideRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SetQuotaOverrideRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *SetQuotaOverrideRequest) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *SetQuotaOverrideRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SetQuotaOverrideRequest) GetClear() bool {
+	if x != nil {
+		return x.Clear
+	}
+	return false
+}
+
+type SetQuotaOverrideResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Usage         *QuotaUsage            `protobuf:"bytes,1,opt,name=usage,proto3" json:"usage,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetQuotaOverrideResponse) Reset() {
+	*x = SetQuotaOverrideResponse{}
+	mi := &file_proto_tenant_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetQuotaOverrideResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetQuotaOverrideResponse) ProtoMessage() {}
+
+func (x *SetQuotaOverrideResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetQuotaOverrideResponse.ProtoReflect.Descriptor instead.
+func (*SetQuotaOverrideResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *SetQuotaOverrideResponse) GetUsage() *QuotaUsage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+// Where and how a tenant's data is reached
+type DatabaseConfig struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// schema, database or shared
+	IsolationMode string `protobuf:"bytes,1,opt,name=isolation_mode,json=isolationMode,proto3" json:"isolation_mode,omitempty"`
+	Host          string `protobuf:"bytes,2,opt,name=host,proto3" json:"host,omitempty"`
+	Port          int32  `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`
+	DatabaseName  string `protobuf:"bytes,4,opt,name=database_name,json=databaseName,proto3" json:"database_name,omitempty"`
+	SchemaName    string `protobuf:"bytes,5,opt,name=schema_name,json=schemaName,proto3" json:"schema_name,omitempty"`
+	Username      string `protobuf:"bytes,6,opt,name=username,proto3" json:"username,omitempty"`
+	// Secret holding the password of username
+	PasswordSecretId          string `protobuf:"bytes,7,opt,name=password_secret_id,json=passwordSecretId,proto3" json:"password_secret_id,omitempty"`
+	MaxConnections            int32  `protobuf:"varint,8,opt,name=max_connections,json=maxConnections,proto3" json:"max_connections,omitempty"`
+	IdleConnections           int32  `protobuf:"varint,9,opt,name=idle_connections,json=idleConnections,proto3" json:"idle_connections,omitempty"`
+	ConnectionLifetimeMinutes int32  `protobuf:"varint,10,opt,name=connection_lifetime_minutes,json=connectionLifetimeMinutes,proto3" json:"connection_lifetime_minutes,omitempty"`
+	unknownFields             protoimpl.UnknownFields
+	sizeCache                 protoimpl.SizeCache
+}
+
+func (x *DatabaseConfig) Reset() {
+	*x = DatabaseConfig{}
+	mi := &file_proto_tenant_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DatabaseConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DatabaseConfig) ProtoMessage() {}
+
+func (x *DatabaseConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DatabaseConfig.ProtoReflect.Descriptor instead.
+func (*DatabaseConfig) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *DatabaseConfig) GetIsolationMode() string {
+	if x != nil {
+		return x.IsolationMode
+	}
+	return ""
+}
+
+func (x *DatabaseConfig) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *DatabaseConfig) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *DatabaseConfig) GetDatabaseName() string {
+	if x != nil {
+		return x.DatabaseName
+	}
+	return ""
+}
+
+func (x *DatabaseConfig) GetSchemaName() string {
+	if x != nil {
+		return x.SchemaName
+	}
+	return ""
+}
+
+func (x *DatabaseConfig) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *DatabaseConfig) GetPasswordSecretId() string {
+	if x != nil {
+		return x.PasswordSecretId
+	}
+	return ""
+}
+
+func (x *DatabaseConfig) GetMaxConnections() int32 {
+	if x != nil {
+		return x.MaxConnections
+	}
+	return 0
+}
+
+func (x *DatabaseConfig) GetIdleConnections() int32 {
+	if x != nil {
+		return x.IdleConnections
+	}
+	return 0
+}
+
+func (x *DatabaseConfig) GetConnectionLifetimeMinutes() int32 {
+	if x != nil {
+		return x.ConnectionLifetimeMinutes
+	}
+	return 0
+}
+
+type ResolveTenantRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Hostname a request was addressed to, e.g. acme.tenants.example.com or a
+	// verified custom domain of the tenant
+	Host          string `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveTenantRequest) Reset() {
+	*x = ResolveTenantRequest{}
+	mi := &file_proto_tenant_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveTenantRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveTenantRequest) ProtoMessage() {}
+
+func (x *ResolveTenantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveTenantRequest.ProtoReflect.Descriptor instead.
+func (*ResolveTenantRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ResolveTenantRequest) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+type ResolveTenantResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Tenant *Tenant                `protobuf:"bytes,1,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	// Unset until the tenant is provisioned
+	Database      *DatabaseConfig `protobuf:"bytes,2,opt,name=database,proto3" json:"database,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveTenantResponse) Reset() {
+	*x = ResolveTenantResponse{}
+	mi := &file_proto_tenant_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveTenantResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveTenantResponse) ProtoMessage() {}
+
+func (x *ResolveTenantResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveTenantResponse.ProtoReflect.Descriptor instead.
+func (*ResolveTenantResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ResolveTenantResponse) GetTenant() *Tenant {
+	if x != nil {
+		return x.Tenant
+	}
+	return nil
+}
+
+func (x *ResolveTenantResponse) GetDatabase() *DatabaseConfig {
+	if x != nil {
+		return x.Database
+	}
+	return nil
+}
+
+type GetDatabaseConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TenantId      string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDatabaseConfigRequest) Reset() {
+	*x = GetDatabaseConfigRequest{}
+	mi := &file_proto_tenant_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDatabaseConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDatabaseConfigRequest) ProtoMessage() {}
+
+func (x *GetDatabaseConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDatabaseConfigRequest.ProtoReflect.Descriptor instead.
+func (*GetDatabaseConfigRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GetDatabaseConfigRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+type GetDatabaseConfigResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Config        *DatabaseConfig        `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDatabaseConfigResponse) Reset() {
+	*x = GetDatabaseConfigResponse{}
+	mi := &file_proto_tenant_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDatabaseConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDatabaseConfigResponse) ProtoMessage() {}
+
+func (x *GetDatabaseConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDatabaseConfigResponse.ProtoReflect.Descriptor instead.
+func (*GetDatabaseConfigResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *GetDatabaseConfigResponse) GetConfig() *DatabaseConfig {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+type RotateDatabaseCredentialsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TenantId      string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RotateDatabaseCredentialsRequest) Reset() {
+	*x = RotateDatabaseCredentialsRequest{}
+	mi := &file_proto_tenant_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RotateDatabaseCredentialsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RotateDatabaseCredentialsRequest) ProtoMessage() {}
+
+func (x *RotateDatabaseCredentialsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RotateDatabaseCredentialsRequest.ProtoReflect.Descriptor instead.
+func (*RotateDatabaseCredentialsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *RotateDatabaseCredentialsRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+// The new password is only written to the secret store
+type RotateDatabaseCredentialsResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Username         string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	PasswordSecretId string                 `protobuf:"bytes,2,opt,name=password_secret_id,json=passwordSecretId,proto3" json:"password_secret_id,omitempty"`
+	RotatedAt        string                 `protobuf:"bytes,3,opt,name=rotated_at,json=rotatedAt,proto3" json:"rotated_at,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *RotateDatabaseCredentialsResponse) Reset() {
+	*x = RotateDatabaseCredentialsResponse{}
+	mi := &file_proto_tenant_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RotateDatabaseCredentialsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RotateDatabaseCredentialsResponse) ProtoMessage() {}
+
+func (x *RotateDatabaseCredentialsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RotateDatabaseCredentialsResponse.ProtoReflect.Descriptor instead.
+func (*RotateDatabaseCredentialsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *RotateDatabaseCredentialsResponse) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *RotateDatabaseCredentialsResponse) GetPasswordSecretId() string {
+	if x != nil {
+		return x.PasswordSecretId
+	}
+	return ""
+}
+
+func (x *RotateDatabaseCredentialsResponse) GetRotatedAt() string {
+	if x != nil {
+		return x.RotatedAt
+	}
+	return ""
+}
+
+// A domain a tenant is reachable under once its owner proved control of it
+// by publishing verification_value in a TXT record named verification_name
+type CustomDomain struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	TenantId string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Domain   string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	// pending, verified or expired; expired domains need a new token
+	Status            string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	VerificationName  string `protobuf:"bytes,4,opt,name=verification_name,json=verificationName,proto3" json:"verification_name,omitempty"`
+	VerificationValue string `protobuf:"bytes,5,opt,name=verification_value,json=verificationValue,proto3" json:"verification_value,omitempty"`
+	// When the verification token stops being accepted
+	TokenExpiresAt string `protobuf:"bytes,6,opt,name=token_expires_at,json=tokenExpiresAt,proto3" json:"token_expires_at,omitempty"`
+	VerifiedAt     string `protobuf:"bytes,7,opt,name=verified_at,json=verifiedAt,proto3" json:"verified_at,omitempty"`
+	LastCheckedAt  string `protobuf:"bytes,8,opt,name=last_checked_at,json=lastCheckedAt,proto3" json:"last_checked_at,omitempty"`
+	// Why the last verification attempt failed
+	LastCheckError string `protobuf:"bytes,9,opt,name=last_check_error,json=lastCheckError,proto3" json:"last_check_error,omitempty"`
+	CreatedAt      string `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CustomDomain) Reset() {
+	*x = CustomDomain{}
+	mi := &file_proto_tenant_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CustomDomain) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CustomDomain) ProtoMessage() {}
+
+func (x *CustomDomain) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CustomDomain.ProtoReflect.Descriptor instead.
+func (*CustomDomain) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *CustomDomain) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *CustomDomain) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *CustomDomain) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *CustomDomain) GetVerificationName() string {
+	if x != nil {
+		return x.VerificationName
+	}
+	return ""
+}
+
+func (x *CustomDomain) GetVerificationValue() string {
+	if x != nil {
+		return x.VerificationValue
+	}
+	return ""
+}
+
+func (x *CustomDomain) GetTokenExpiresAt() string {
+	if x != nil {
+		return x.TokenExpiresAt
+	}
+	return ""
+}
+
+func (x *CustomDomain) GetVerifiedAt() string {
+	if x != nil {
+		return x.VerifiedAt
+	}
+	return ""
+}
+
+func (x *CustomDomain) GetLastCheckedAt() string {
+	if x != nil {
+		return x.LastCheckedAt
+	}
+	return ""
+}
+
+func (x *CustomDomain) GetLastCheckError() string {
+	if x != nil {
+		return x.LastCheckError
+	}
+	return ""
+}
+
+func (x *CustomDomain) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+// Attaching a domain the tenant already has returns it, with a new token if
+// the previous one expired
+type AttachCustomDomainRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TenantId      string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachCustomDomainRequest) Reset() {
+	*x = AttachCustomDomainRequest{}
+	mi := &file_proto_tenant_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachCustomDomainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachCustomDomainRequest) ProtoMessage() {}
+
+func (x *AttachCustomDomainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachCustomDomainRequest.ProtoReflect.Descriptor instead.
+func (*AttachCustomDomainRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *AttachCustomDomainRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *AttachCustomDomainRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+type AttachCustomDomainResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CustomDomain  *CustomDomain          `protobuf:"bytes,1,opt,name=custom_domain,json=customDomain,proto3" json:"custom_domain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachCustomDomainResponse) Reset() {
+	*x = AttachCustomDomainResponse{}
+	mi := &file_proto_tenant_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachCustomDomainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachCustomDomainResponse) ProtoMessage() {}
+
+func (x *AttachCustomDomainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachCustomDomainResponse.ProtoReflect.Descriptor instead.
+func (*AttachCustomDomainResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *AttachCustomDomainResponse) GetCustomDomain() *CustomDomain {
+	if x != nil {
+		return x.CustomDomain
+	}
+	return nil
+}
+
+type VerifyCustomDomainRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TenantId      string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyCustomDomainRequest) Reset() {
+	*x = VerifyCustomDomainRequest{}
+	mi := &file_proto_tenant_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyCustomDomainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyCustomDomainRequest) ProtoMessage() {}
+
+func (x *VerifyCustomDomainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyCustomDomainRequest.ProtoReflect.Descriptor instead.
+func (*VerifyCustomDomainRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *VerifyCustomDomainRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *VerifyCustomDomainRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+type VerifyCustomDomainResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CustomDomain  *CustomDomain          `protobuf:"bytes,1,opt,name=custom_domain,json=customDomain,proto3" json:"custom_domain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyCustomDomainResponse) Reset() {
+	*x = VerifyCustomDomainResponse{}
+	mi := &file_proto_tenant_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyCustomDomainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyCustomDomainResponse) ProtoMessage() {}
+
+func (x *VerifyCustomDomainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyCustomDomainResponse.ProtoReflect.Descriptor instead.
+func (*VerifyCustomDomainResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *VerifyCustomDomainResponse) GetCustomDomain() *CustomDomain {
+	if x != nil {
+		return x.CustomDomain
+	}
+	return nil
+}
+
+type ListCustomDomainsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TenantId      string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCustomDomainsRequest) Reset() {
+	*x = ListCustomDomainsRequest{}
+	mi := &file_proto_tenant_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCustomDomainsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCustomDomainsRequest) ProtoMessage() {}
+
+func (x *ListCustomDomainsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCustomDomainsRequest.ProtoReflect.Descriptor instead.
+func (*ListCustomDomainsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *ListCustomDomainsRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+type ListCustomDomainsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CustomDomains []*CustomDomain        `protobuf:"bytes,1,rep,name=custom_domains,json=customDomains,proto3" json:"custom_domains,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCustomDomainsResponse) Reset() {
+	*x = ListCustomDomainsResponse{}
+	mi := &file_proto_tenant_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCustomDomainsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCustomDomainsResponse) ProtoMessage() {}
+
+func (x *ListCustomDomainsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCustomDomainsResponse.ProtoReflect.Descriptor instead.
+func (*ListCustomDomainsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ListCustomDomainsResponse) GetCustomDomains() []*CustomDomain {
+	if x != nil {
+		return x.CustomDomains
+	}
+	return nil
+}
+
+type DetachCustomDomainRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TenantId      string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DetachCustomDomainRequest) Reset() {
+	*x = DetachCustomDomainRequest{}
+	mi := &file_proto_tenant_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DetachCustomDomainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetachCustomDomainRequest) ProtoMessage() {}
+
+func (x *DetachCustomDomainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetachCustomDomainRequest.ProtoReflect.Descriptor instead.
+func (*DetachCustomDomainRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *DetachCustomDomainRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *DetachCustomDomainRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+type DetachCustomDomainResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DetachCustomDomainResponse) Reset() {
+	*x = DetachCustomDomainResponse{}
+	mi := &file_proto_tenant_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DetachCustomDomainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetachCustomDomainResponse) ProtoMessage() {}
+
+func (x *DetachCustomDomainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetachCustomDomainResponse.ProtoReflect.Descriptor instead.
+func (*DetachCustomDomainResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *DetachCustomDomainResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// A database that tenant schemas are placed on
+type Shard struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	ShardId      string                 `protobuf:"bytes,1,opt,name=shard_id,json=shardId,proto3" json:"shard_id,omitempty"`
+	Name         string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Host         string                 `protobuf:"bytes,3,opt,name=host,proto3" json:"host,omitempty"`
+	Port         int32                  `protobuf:"varint,4,opt,name=port,proto3" json:"port,omitempty"`
+	DatabaseName string                 `protobuf:"bytes,5,opt,name=database_name,json=databaseName,proto3" json:"database_name,omitempty"`
+	Region       string                 `protobuf:"bytes,6,opt,name=region,proto3" json:"region,omitempty"`
+	// How many tenant schemas the shard holds at most
+	Capacity int32 `protobuf:"varint,7,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	// Share of new tenants under weighted placement
+	Weight int32 `protobuf:"varint,8,opt,name=weight,proto3" json:"weight,omitempty"`
+	// Draining shards keep their tenants but take no new ones
+	Draining bool `protobuf:"varint,9,opt,name=draining,proto3" json:"draining,omitempty"`
+	// How many tenant schemas are placed on the shard
+	Tenants       int32  `protobuf:"varint,10,opt,name=tenants,proto3" json:"tenants,omitempty"`
+	CreatedAt     string `protobuf:"bytes,11,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     string `protobuf:"bytes,12,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Shard) Reset() {
+	*x = Shard{}
+	mi := &file_proto_tenant_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Shard) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Shard) ProtoMessage() {}
+
+func (x *Shard) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Shard.ProtoReflect.Descriptor instead.
+func (*Shard) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *Shard) GetShardId() string {
+	if x != nil {
+		return x.ShardId
+	}
+	return ""
+}
+
+func (x *Shard) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Shard) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *Shard) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *Shard) GetDatabaseName() string {
+	if x != nil {
+		return x.DatabaseName
+	}
+	return ""
+}
+
+func (x *Shard) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *Shard) GetCapacity() int32 {
+	if x != nil {
+		return x.Capacity
+	}
+	return 0
+}
+
+func (x *Shard) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *Shard) GetDraining() bool {
+	if x != nil {
+		return x.Draining
+	}
+	return false
+}
+
+func (x *Shard) GetTenants() int32 {
+	if x != nil {
+		return x.Tenants
+	}
+	return 0
+}
+
+func (x *Shard) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Shard) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+type CreateShardRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Host  string                 `protobuf:"bytes,2,opt,name=host,proto3" json:"host,omitempty"`
+	// Defaults to 5432
+	Port         int32  `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`
+	DatabaseName string `protobuf:"bytes,4,opt,name=database_name,json=databaseName,proto3" json:"database_name,omitempty"`
+	Region       string `protobuf:"bytes,5,opt,name=region,proto3" json:"region,omitempty"`
+	Capacity     int32  `protobuf:"varint,6,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	// Defaults to 1
+	Weight        int32 `protobuf:"varint,7,opt,name=weight,proto3" json:"weight,omitempty"`
+	Draining      bool  `protobuf:"varint,8,opt,name=draining,proto3" json:"draining,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateShardRequest) Reset() {
+	*x = CreateShardRequest{}
+	mi := &file_proto_tenant_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateShardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateShardRequest) ProtoMessage() {}
+
+func (x *CreateShardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateShardRequest.ProtoReflect.Descriptor instead.
+func (*CreateShardRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *CreateShardRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateShardRequest) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *CreateShardRequest) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *CreateShardRequest) GetDatabaseName() string {
+	if x != nil {
+		return x.DatabaseName
+	}
+	return ""
+}
+
+func (x *CreateShardRequest) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *CreateShardRequest) GetCapacity() int32 {
+	if x != nil {
+		return x.Capacity
+	}
+	return 0
+}
+
+func (x *CreateShardRequest) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *CreateShardRequest) GetDraining() bool {
+	if x != nil {
+		return x.Draining
+	}
+	return false
+}
+
+type CreateShardResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Shard         *Shard                 `protobuf:"bytes,1,opt,name=shard,proto3" json:"shard,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateShardResponse) Reset() {
+	*x = CreateShardResponse{}
+	mi := &file_proto_tenant_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateShardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateShardResponse) ProtoMessage() {}
+
+func (x *CreateShardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateShardResponse.ProtoReflect.Descriptor instead.
+func (*CreateShardResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *CreateShardResponse) GetShard() *Shard {
+	if x != nil {
+		return x.Shard
+	}
+	return nil
+}
+
+type ListShardsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListShardsRequest) Reset() {
+	*x = ListShardsRequest{}
+	mi := &file_proto_tenant_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListShardsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListShardsRequest) ProtoMessage() {}
+
+func (x *ListShardsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListShardsRequest.ProtoReflect.Descriptor instead.
+func (*ListShardsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{44}
+}
+
+type ListShardsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Shards        []*Shard               `protobuf:"bytes,1,rep,name=shards,proto3" json:"shards,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListShardsResponse) Reset() {
+	*x = ListShardsResponse{}
+	mi := &file_proto_tenant_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListShardsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListShardsResponse) ProtoMessage() {}
+
+func (x *ListShardsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListShardsResponse.ProtoReflect.Descriptor instead.
+func (*ListShardsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *ListShardsResponse) GetShards() []*Shard {
+	if x != nil {
+		return x.Shards
+	}
+	return nil
+}
+
+// Unset fields are left unchanged. Where a shard lives cannot be changed.
+type UpdateShardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShardId       string                 `protobuf:"bytes,1,opt,name=shard_id,json=shardId,proto3" json:"shard_id,omitempty"`
+	Region        *string                `protobuf:"bytes,2,opt,name=region,proto3,oneof" json:"region,omitempty"`
+	Capacity      *int32                 `protobuf:"varint,3,opt,name=capacity,proto3,oneof" json:"capacity,omitempty"`
+	Weight        *int32                 `protobuf:"varint,4,opt,name=weight,proto3,oneof" json:"weight,omitempty"`
+	Draining      *bool                  `protobuf:"varint,5,opt,name=draining,proto3,oneof" json:"draining,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateShardRequest) Reset() {
+	*x = UpdateShardRequest{}
+	mi := &file_proto_tenant_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateShardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateShardRequest) ProtoMessage() {}
+
+func (x *UpdateShardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateShardRequest.ProtoReflect.Descriptor instead.
+func (*UpdateShardRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *UpdateShardRequest) GetShardId() string {
+	if x != nil {
+		return x.ShardId
+	}
+	return ""
+}
+
+func (x *UpdateShardRequest) GetRegion() string {
+	if x != nil && x.Region != nil {
+		return *x.Region
+	}
+	return ""
+}
+
+func (x *UpdateShardRequest) GetCapacity() int32 {
+	if x != nil && x.Capacity != nil {
+		return *x.Capacity
+	}
+	return 0
+}
+
+func (x *UpdateShardRequest) GetWeight() int32 {
+	if x != nil && x.Weight != nil {
+		return *x.Weight
+	}
+	return 0
+}
+
+func (x *UpdateShardRequest) GetDraining() bool {
+	if x != nil && x.Draining != nil {
+		return *x.Draining
+	}
+	return false
+}
+
+type UpdateShardResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Shard         *Shard                 `protobuf:"bytes,1,opt,name=shard,proto3" json:"shard,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateShardResponse) Reset() {
+	*x = UpdateShardResponse{}
+	mi := &file_proto_tenant_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateShardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateShardResponse) ProtoMessage() {}
+
+func (x *UpdateShardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateShardResponse.ProtoReflect.Descriptor instead.
+func (*UpdateShardResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *UpdateShardResponse) GetShard() *Shard {
+	if x != nil {
+		return x.Shard
+	}
+	return nil
+}
+
+// Fails while tenant schemas or snapshots are kept on the shard
+type DeleteShardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShardId       string                 `protobuf:"bytes,1,opt,name=shard_id,json=shardId,proto3" json:"shard_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteShardRequest) Reset() {
+	*x = DeleteShardRequest{}
+	mi := &file_proto_tenant_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteShardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteShardRequest) ProtoMessage() {}
+
+func (x *DeleteShardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteShardRequest.ProtoReflect.Descriptor instead.
+func (*DeleteShardRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *DeleteShardRequest) GetShardId() string {
+	if x != nil {
+		return x.ShardId
+	}
+	return ""
+}
+
+type DeleteShardResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteShardResponse) Reset() {
+	*x = DeleteShardResponse{}
+	mi := &file_proto_tenant_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteShardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteShardResponse) ProtoMessage() {}
+
+func (x *DeleteShardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteShardResponse.ProtoReflect.Descriptor instead.
+func (*DeleteShardResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{49}
+}
+
+type TenantMove struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MoveId        string                 `protobuf:"bytes,1,opt,name=move_id,json=moveId,proto3" json:"move_id,omitempty"`
+	TenantId      string                 `protobuf:"bytes,2,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	SourceShardId string                 `protobuf:"bytes,3,opt,name=source_shard_id,json=sourceShardId,proto3" json:"source_shard_id,omitempty"`
+	TargetShardId string                 `protobuf:"bytes,4,opt,name=target_shard_id,json=targetShardId,proto3" json:"target_shard_id,omitempty"`
+	// "pending", "completed" or "failed"
+	Status string `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	// Why the move failed
+	LastError string `protobuf:"bytes,6,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	// Last step of the move written to the provisioning logs, and its status
+	CurrentStep       string `protobuf:"bytes,7,opt,name=current_step,json=currentStep,proto3" json:"current_step,omitempty"`
+	CurrentStepStatus string `protobuf:"bytes,8,opt,name=current_step_status,json=currentStepStatus,proto3" json:"current_step_status,omitempty"`
+	// When the schema left on the source shard is dropped
+	SourceDropAfter string `protobuf:"bytes,9,opt,name=source_drop_after,json=sourceDropAfter,proto3" json:"source_drop_after,omitempty"`
+	CreatedAt       string `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt       string `protobuf:"bytes,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	CompletedAt     string `protobuf:"bytes,12,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *TenantMove) Reset() {
+	*x = TenantMove{}
+	mi := &file_proto_tenant_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TenantMove) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TenantMove) ProtoMessage() {}
+
+func (x *TenantMove) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TenantMove.ProtoReflect.Descriptor instead.
+func (*TenantMove) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *TenantMove) GetMoveId() string {
+	if x != nil {
+		return x.MoveId
+	}
+	return ""
+}
+
+func (x *TenantMove) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *TenantMove) GetSourceShardId() string {
+	if x != nil {
+		return x.SourceShardId
+	}
+	return ""
+}
+
+func (x *TenantMove) GetTargetShardId() string {
+	if x != nil {
+		return x.TargetShardId
+	}
+	return ""
+}
+
+func (x *TenantMove) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *TenantMove) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *TenantMove) GetCurrentStep() string {
+	if x != nil {
+		return x.CurrentStep
+	}
+	return ""
+}
+
+func (x *TenantMove) GetCurrentStepStatus() string {
+	if x != nil {
+		return x.CurrentStepStatus
+	}
+	return ""
+}
+
+func (x *TenantMove) GetSourceDropAfter() string {
+	if x != nil {
+		return x.SourceDropAfter
+	}
+	return ""
+}
+
+func (x *TenantMove) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *TenantMove) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+func (x *TenantMove) GetCompletedAt() string {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return ""
+}
+
+type MoveTenantRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TenantId      string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	TargetShardId string                 `protobuf:"bytes,2,opt,name=target_shard_id,json=targetShardId,proto3" json:"target_shard_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MoveTenantRequest) Reset() {
+	*x = MoveTenantRequest{}
+	mi := &file_proto_tenant_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoveTenantRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoveTenantRequest) ProtoMessage() {}
+
+func (x *MoveTenantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoveTenantRequest.ProtoReflect.Descriptor instead.
+func (*MoveTenantRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *MoveTenantRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *MoveTenantRequest) GetTargetShardId() string {
+	if x != nil {
+		return x.TargetShardId
+	}
+	return ""
+}
+
+type MoveTenantResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Move  *TenantMove            `protobuf:"bytes,1,opt,name=move,proto3" json:"move,omitempty"`
+	// Durable job that moves the tenant
+	JobId         string `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MoveTenantResponse) Reset() {
+	*x = MoveTenantResponse{}
+	mi := &file_proto_tenant_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoveTenantResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoveTenantResponse) ProtoMessage() {}
+
+func (x *MoveTenantResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoveTenantResponse.ProtoReflect.Descriptor instead.
+func (*MoveTenantResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *MoveTenantResponse) GetMove() *TenantMove {
+	if x != nil {
+		return x.Move
+	}
+	return nil
+}
+
+func (x *MoveTenantResponse) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type GetTenantMoveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TenantId      string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTenantMoveRequest) Reset() {
+	*x = GetTenantMoveRequest{}
+	mi := &file_proto_tenant_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTenantMoveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTenantMoveRequest) ProtoMessage() {}
+
+func (x *GetTenantMoveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTenantMoveRequest.ProtoReflect.Descriptor instead.
+func (*GetTenantMoveRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *GetTenantMoveRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+type GetTenantMoveResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Latest move of the tenant
+	Move          *TenantMove `protobuf:"bytes,1,opt,name=move,proto3" json:"move,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTenantMoveResponse) Reset() {
+	*x = GetTenantMoveResponse{}
+	mi := &file_proto_tenant_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTenantMoveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTenantMoveResponse) ProtoMessage() {}
+
+func (x *GetTenantMoveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tenant_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTenantMoveResponse.ProtoReflect.Descriptor instead.
+func (*GetTenantMoveResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tenant_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *GetTenantMoveResponse) GetMove() *TenantMove {
+	if x != nil {
+		return x.Move
+	}
+	return nil
+}
+
+var File_proto_tenant_proto protoreflect.FileDescriptor
+
+const file_proto_tenant_proto_rawDesc = "" +
+	"\n" +
+	"\x12proto/tenant.proto\x12\ttenant.v1\"\x90\x02\n" +
+	"\x06Tenant\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1c\n" +
+	"\tsubdomain\x18\x03 \x01(\tR\tsubdomain\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x06 \x01(\tR\tupdatedAt\x12\x1d\n" +
+	"\n" +
+	"deleted_at\x18\a \x01(\tR\tdeletedAt\x12\x12\n" +
+	"\x04tier\x18\b \x01(\tR\x04tier\x12#\n" +
+	"\rstatus_reason\x18\t \x01(\tR\fstatusReason\x12\x16\n" +
+	"\x06region\x18\n" +
+	" \x01(\tR\x06region\"\x98\x01\n" +
+	"\x13CreateTenantRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1c\n" +
+	"\tsubdomain\x18\x02 \x01(\tR\tsubdomain\x12#\n" +
+	"\rcontact_email\x18\x03 \x01(\tR\fcontactEmail\x12\x12\n" +
+	"\x04tier\x18\x04 \x01(\tR\x04tier\x12\x16\n" +
+	"\x06region\x18\x05 \x01(\tR\x06region\"\xa0\x01\n" +
+	"\x14CreateTenantResponse\x12)\n" +
+	"\x06tenant\x18\x01 \x01(\v2\x11.tenant.v1.TenantR\x06tenant\x12.\n" +
+	"\x13provisioning_job_id\x18\x02 \x01(\tR\x11provisioningJobId\x12-\n" +
+	"\x12provisioning_state\x18\x03 \x01(\tR\x11provisioningState\"\"\n" +
+	"\x10GetTenantRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\">\n" +
+	"\x11GetTenantResponse\x12)\n" +
+	"\x06tenant\x18\x01 \x01(\v2\x11.tenant.v1.TenantR\x06tenant\"o\n" +
+	"\x13UpdateTenantRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1c\n" +
+	"\tsubdomain\x18\x03 \x01(\tR\tsubdomain\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\"A\n" +
+	"\x14UpdateTenantResponse\x12)\n" +
+	"\x06tenant\x18\x01 \x01(\v2\x11.tenant.v1.TenantR\x06tenant\"%\n" +
+	"\x13DeleteTenantRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"d\n" +
+	"\x14DeleteTenantResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x122\n" +
+	"\x15deprovisioning_job_id\x18\x02 \x01(\tR\x13deprovisioningJobId\"\xf8\x01\n" +
+	"\x06APIKey\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\ttenant_id\x18\x02 \x01(\tR\btenantId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x16\n" +
+	"\x06prefix\x18\x04 \x01(\tR\x06prefix\x12\x16\n" +
+	"\x06scopes\x18\x05 \x03(\tR\x06scopes\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x06 \x01(\tR\texpiresAt\x12 \n" +
+	"\flast_used_at\x18\a \x01(\tR\n" +
+	"lastUsedAt\x12\x1d\n" +
+	"\n" +
+	"revoked_at\x18\b \x01(\tR\trevokedAt\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\t \x01(\tR\tcreatedAt\"\x7f\n" +
+	"\x13CreateAPIKeyRequest\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06scopes\x18\x03 \x03(\tR\x06scopes\x12\x1f\n" +
+	"\vttl_seconds\x18\x04 \x01(\x03R\n" +
+	"ttlSeconds\"Z\n" +
+	"\x14CreateAPIKeyResponse\x12*\n" +
+	"\aapi_key\x18\x01 \x01(\v2\x11.tenant.v1.APIKeyR\x06apiKey\x12\x16\n" +
+	"\x06secret\x18\x02 \x01(\tR\x06secret\"Z\n" +
+	"\x12ListAPIKeysRequest\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\x12'\n" +
+	"\x0finclude_revoked\x18\x02 \x01(\bR\x0eincludeRevoked\"C\n" +
+	"\x13ListAPIKeysResponse\x12,\n" +
+	"\bapi_keys\x18\x01 \x03(\v2\x11.tenant.v1.APIKeyR\aapiKeys\"t\n" +
+	"\x13RotateAPIKeyRequest\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x120\n" +
+	"\x14grace_period_seconds\x18\x03 \x01(\x03R\x12gracePeriodSeconds\"Z\n" +
+	"\x14RotateAPIKeyResponse\x12*\n" +
+	"\aapi_key\x18\x01 \x01(\v2\x11.tenant.v1.APIKeyR\x06apiKey\x12\x16\n" +
+	"\x06secret\x18\x02 \x01(\tR\x06secret\"B\n" +
+	"\x13RevokeAPIKeyRequest\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"0\n" +
+	"\x14RevokeAPIKeyResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"N\n" +
+	"\x13VerifyAPIKeyRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12%\n" +
+	"\x0erequired_scope\x18\x02 \x01(\tR\rrequiredScope\"x\n" +
+	"\x14VerifyAPIKeyResponse\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x12\x1b\n" +
+	"\ttenant_id\x18\x02 \x01(\tR\btenantId\x12\x15\n" +
+	"\x06key_id\x18\x03 \x01(\tR\x05keyId\x12\x16\n" +
+	"\x06scopes\x18\x04 \x03(\tR\x06scopes\"r\n" +
+	"\n" +
+	"QuotaUsage\x12\x1a\n" +
+	"\bresource\x18\x01 \x01(\tR\bresource\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x03R\x05limit\x12\x12\n" +
+	"\x04used\x18\x03 \x01(\x03R\x04used\x12\x1e\n" +
+	"\n" +
+	"overridden\x18\x04 \x01(\bR\n" +
+	"overridden\"3\n" +
+	"\x14GetQuotaUsageRequest\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\"X\n" +
+	"\x15GetQuotaUsageResponse\x12\x12\n" +
+	"\x04tier\x18\x01 \x01(\tR\x04tier\x12+\n" +
+	"\x05usage\x18\x02 \x03(\v2\x15.tenant.v1.QuotaUsageR\x05usage\"~\n" +
+	"\x17SetQuotaOverrideRequest\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\x12\x1a\n" +
+	"\bresource\x18\x02 \x01(\tR\bresource\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x03R\x05limit\x12\x14\n" +
+	"\x05clear\x18\x04 \x01(\bR\x05clear\"G\n" +
+	"\x18SetQuotaOverrideResponse\x12+\n" +
+	"\x05usage\x18\x01 \x01(\v2\x15.tenant.v1.QuotaUsageR\x05usage\"\x83\x03\n" +
+	"\x0eDatabaseConfig\x12%\n" +
+	"\x0eisolation_mode\x18\x01 \x01(\tR\risolationMode\x12\x12\n" +
+	"\x04host\x18\x02 \x01(\tR\x04host\x12\x12\n" +
+	"\x04port\x18\x03 \x01(\x05R\x04port\x12#\n" +
+	"\rdatabase_name\x18\x04 \x01(\tR\fdatabaseName\x12\x1f\n" +
+	"\vschema_name\x18\x05 \x01(\tR\n" +
+	"schemaName\x12\x1a\n" +
+	"\busername\x18\x06 \x01(\tR\busername\x12,\n" +
+	"\x12password_secret_id\x18\a \x01(\tR\x10passwordSecretId\x12'\n" +
+	"\x0fmax_connections\x18\b \x01(\x05R\x0emaxConnections\x12)\n" +
+	"\x10idle_connections\x18\t \x01(\x05R\x0fidleConnections\x12>\n" +
+	"\x1bconnection_lifetime_minutes\x18\n" +
+	" \x01(\x05R\x19connectionLifetimeMinutes\"*\n" +
+	"\x14ResolveTenantRequest\x12\x12\n" +
+	"\x04host\x18\x01 \x01(\tR\x04host\"y\n" +
+	"\x15ResolveTenantResponse\x12)\n" +
+	"\x06tenant\x18\x01 \x01(\v2\x11.tenant.v1.TenantR\x06tenant\x125\n" +
+	"\bdatabase\x18\x02 \x01(\v2\x19.tenant.v1.DatabaseConfigR\bdatabase\"7\n" +
+	"\x18GetDatabaseConfigRequest\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\"N\n" +
+	"\x19GetDatabaseConfigResponse\x121\n" +
+	"\x06config\x18\x01 \x01(\v2\x19.tenant.v1.DatabaseConfigR\x06config\"?\n" +
+	" RotateDatabaseCredentialsRequest\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\"\x8c\x01\n" +
+	"!RotateDatabaseCredentialsResponse\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x12,\n" +
+	"\x12password_secret_id\x18\x02 \x01(\tR\x10passwordSecretId\x12\x1d\n" +
+	"\n" +
+	"rotated_at\x18\x03 \x01(\tR\trotatedAt\"\xf3\x02\n" +
+	"\fCustomDomain\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\x12\x16\n" +
+	"\x06domain\x18\x02 \x01(\tR\x06domain\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12+\n" +
+	"\x11verification_name\x18\x04 \x01(\tR\x10verificationName\x12-\n" +
+	"\x12verification_value\x18\x05 \x01(\tR\x11verificationValue\x12(\n" +
+	"\x10token_expires_at\x18\x06 \x01(\tR\x0etokenExpiresAt\x12\x1f\n" +
+	"\vverified_at\x18\a \x01(\tR\n" +
+	"verifiedAt\x12&\n" +
+	"\x0flast_checked_at\x18\b \x01(\tR\rlastCheckedAt\x12(\n" +
+	"\x10last_check_error\x18\t \x01(\tR\x0elastCheckError\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\tR\tcreatedAt\"P\n" +
+	"\x19AttachCustomDomainRequest\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\x12\x16\n" +
+	"\x06domain\x18\x02 \x01(\tR\x06domain\"Z\n" +
+	"\x1aAttachCustomDomainResponse\x12<\n" +
+	"\rcustom_domain\x18\x01 \x01(\v2\x17.tenant.v1.CustomDomainR\fcustomDomain\"P\n" +
+	"\x19VerifyCustomDomainRequest\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\x12\x16\n" +
+	"\x06domain\x18\x02 \x01(\tR\x06domain\"Z\n" +
+	"\x1aVerifyCustomDomainResponse\x12<\n" +
+	"\rcustom_domain\x18\x01 \x01(\v2\x17.tenant.v1.CustomDomainR\fcustomDomain\"7\n" +
+	"\x18ListCustomDomainsRequest\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\"[\n" +
+	"\x19ListCustomDomainsResponse\x12>\n" +
+	"\x0ecustom_domains\x18\x01 \x03(\v2\x17.tenant.v1.CustomDomainR\rcustomDomains\"P\n" +
+	"\x19DetachCustomDomainRequest\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\x12\x16\n" +
+	"\x06domain\x18\x02 \x01(\tR\x06domain\"6\n" +
+	"\x1aDetachCustomDomainResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\xc3\x02\n" +
+	"\x05Shard\x12\x19\n" +
+	"\bshard_id\x18\x01 \x01(\tR\ashardId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04host\x18\x03 \x01(\tR\x04host\x12\x12\n" +
+	"\x04port\x18\x04 \x01(\x05R\x04port\x12#\n" +
+	"\rdatabase_name\x18\x05 \x01(\tR\fdatabaseName\x12\x16\n" +
+	"\x06region\x18\x06 \x01(\tR\x06region\x12\x1a\n" +
+	"\bcapacity\x18\a \x01(\x05R\bcapacity\x12\x16\n" +
+	"\x06weight\x18\b \x01(\x05R\x06weight\x12\x1a\n" +
+	"\bdraining\x18\t \x01(\bR\bdraining\x12\x18\n" +
+	"\atenants\x18\n" +
+	" \x01(\x05R\atenants\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\v \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\f \x01(\tR\tupdatedAt\"\xdd\x01\n" +
+	"\x12CreateShardRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04host\x18\x02 \x01(\tR\x04host\x12\x12\n" +
+	"\x04port\x18\x03 \x01(\x05R\x04port\x12#\n" +
+	"\rdatabase_name\x18\x04 \x01(\tR\fdatabaseName\x12\x16\n" +
+	"\x06region\x18\x05 \x01(\tR\x06region\x12\x1a\n" +
+	"\bcapacity\x18\x06 \x01(\x05R\bcapacity\x12\x16\n" +
+	"\x06weight\x18\a \x01(\x05R\x06weight\x12\x1a\n" +
+	"\bdraining\x18\b \x01(\bR\bdraining\"=\n" +
+	"\x13CreateShardResponse\x12&\n" +
+	"\x05shard\x18\x01 \x01(\v2\x10.tenant.v1.ShardR\x05shard\"\x13\n" +
+	"\x11ListShardsRequest\">\n" +
+	"\x12ListShardsResponse\x12(\n" +
+	"\x06shards\x18\x01 \x03(\v2\x10.tenant.v1.ShardR\x06shards\"\xdb\x01\n" +
+	"\x12UpdateShardRequest\x12\x19\n" +
+	"\bshard_id\x18\x01 \x01(\tR\ashardId\x12\x1b\n" +
+	"\x06region\x18\x02 \x01(\tH\x00R\x06region\x88\x01\x01\x12\x1f\n" +
+	"\bcapacity\x18\x03 \x01(\x05H\x01R\bcapacity\x88\x01\x01\x12\x1b\n" +
+	"\x06weight\x18\x04 \x01(\x05H\x02R\x06weight\x88\x01\x01\x12\x1f\n" +
+	"\bdraining\x18\x05 \x01(\bH\x03R\bdraining\x88\x01\x01B\t\n" +
+	"\a_regionB\v\n" +
+	"\t_capacityB\t\n" +
+	"\a_weightB\v\n" +
+	"\t_draining\"=\n" +
+	"\x13UpdateShardResponse\x12&\n" +
+	"\x05shard\x18\x01 \x01(\v2\x10.tenant.v1.ShardR\x05shard\"/\n" +
+	"\x12DeleteShardRequest\x12\x19\n" +
+	"\bshard_id\x18\x01 \x01(\tR\ashardId\"\x15\n" +
+	"\x13DeleteShardResponse\"\xa9\x03\n" +
+	"\n" +
+	"TenantMove\x12\x17\n" +
+	"\amove_id\x18\x01 \x01(\tR\x06moveId\x12\x1b\n" +
+	"\ttenant_id\x18\x02 \x01(\tR\btenantId\x12&\n" +
+	"\x0fsource_shard_id\x18\x03 \x01(\tR\rsourceShardId\x12&\n" +
+	"\x0ftarget_shard_id\x18\x04 \x01(\tR\rtargetShardId\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x06 \x01(\tR\tlastError\x12!\n" +
+	"\fcurrent_step\x18\a \x01(\tR\vcurrentStep\x12.\n" +
+	"\x13current_step_status\x18\b \x01(\tR\x11currentStepStatus\x12*\n" +
+	"\x11source_drop_after\x18\t \x01(\tR\x0fsourceDropAfter\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\v \x01(\tR\tupdatedAt\x12!\n" +
+	"\fcompleted_at\x18\f \x01(\tR\vcompletedAt\"X\n" +
+	"\x11MoveTenantRequest\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\x12&\n" +
+	"\x0ftarget_shard_id\x18\x02 \x01(\tR\rtargetShardId\"V\n" +
+	"\x12MoveTenantResponse\x12)\n" +
+	"\x04move\x18\x01 \x01(\v2\x15.tenant.v1.TenantMoveR\x04move\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\"3\n" +
+	"\x14GetTenantMoveRequest\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\"B\n" +
+	"\x15GetTenantMoveResponse\x12)\n" +
+	"\x04move\x18\x01 \x01(\v2\x15.tenant.v1.TenantMoveR\x04move2\xc6\x10\n" +
+	"\rTenantService\x12Q\n" +
+	"\fCreateTenant\x12\x1e.tenant.v1.CreateTenantRequest\x1a\x1f.tenant.v1.CreateTenantResponse\"\x00\x12H\n" +
+	"\tGetTenant\x12\x1b.tenant.v1.GetTenantRequest\x1a\x1c.tenant.v1.GetTenantResponse\"\x00\x12Q\n" +
+	"\fUpdateTenant\x12\x1e.tenant.v1.UpdateTenantRequest\x1a\x1f.tenant.v1.UpdateTenantResponse\"\x00\x12Q\n" +
+	"\fDeleteTenant\x12\x1e.tenant.v1.DeleteTenantRequest\x1a\x1f.tenant.v1.DeleteTenantResponse\"\x00\x12Q\n" +
+	"\fCreateAPIKey\x12\x1e.tenant.v1.CreateAPIKeyRequest\x1a\x1f.tenant.v1.CreateAPIKeyResponse\"\x00\x12N\n" +
+	"\vListAPIKeys\x12\x1d.tenant.v1.ListAPIKeysRequest\x1a\x1e.tenant.v1.ListAPIKeysResponse\"\x00\x12Q\n" +
+	"\fRotateAPIKey\x12\x1e.tenant.v1.RotateAPIKeyRequest\x1a\x1f.tenant.v1.RotateAPIKeyResponse\"\x00\x12Q\n" +
+	"\fRevokeAPIKey\x12\x1e.tenant.v1.RevokeAPIKeyRequest\x1a\x1f.tenant.v1.RevokeAPIKeyResponse\"\x00\x12Q\n" +
+	"\fVerifyAPIKey\x12\x1e.tenant.v1.VerifyAPIKeyRequest\x1a\x1f.tenant.v1.VerifyAPIKeyResponse\"\x00\x12T\n" +
+	"\rGetQuotaUsage\x12\x1f.tenant.v1.GetQuotaUsageRequest\x1a .tenant.v1.GetQuotaUsageResponse\"\x00\x12]\n" +
+	"\x10SetQuotaOverride\x12\".tenant.v1.SetQuotaOverrideRequest\x1a#.tenant.v1.SetQuotaOverrideResponse\"\x00\x12T\n" +
+	"\rResolveTenant\x12\x1f.tenant.v1.ResolveTenantRequest\x1a .tenant.v1.ResolveTenantResponse\"\x00\x12`\n" +
+	"\x11GetDatabaseConfig\x12#.tenant.v1.GetDatabaseConfigRequest\x1a$.tenant.v1.GetDatabaseConfigResponse\"\x00\x12x\n" +
+	"\x19RotateDatabaseCredentials\x12+.tenant.v1.RotateDatabaseCredentialsRequest\x1a,.tenant.v1.RotateDatabaseCredentialsResponse\"\x00\x12c\n" +
+	"\x12AttachCustomDomain\x12$.tenant.v1.AttachCustomDomainRequest\x1a%.tenant.v1.AttachCustomDomainResponse\"\x00\x12c\n" +
+	"\x12VerifyCustomDomain\x12$.tenant.v1.VerifyCustomDomainRequest\x1a%.tenant.v1.VerifyCustomDomainResponse\"\x00\x12`\n" +
+	"\x11ListCustomDomains\x12#.tenant.v1.ListCustomDomainsRequest\x1a$.tenant.v1.ListCustomDomainsResponse\"\x00\x12c\n" +
+	"\x12DetachCustomDomain\x12$.tenant.v1.DetachCustomDomainRequest\x1a%.tenant.v1.DetachCustomDomainResponse\"\x00\x12N\n" +
+	"\vCreateShard\x12\x1d.tenant.v1.CreateShardRequest\x1a\x1e.tenant.v1.CreateShardResponse\"\x00\x12K\n" +
+	"\n" +
+	"ListShards\x12\x1c.tenant.v1.ListShardsRequest\x1a\x1d.tenant.v1.ListShardsResponse\"\x00\x12N\n" +
+	"\vUpdateShard\x12\x1d.tenant.v1.UpdateShardRequest\x1a\x1e.tenant.v1.UpdateShardResponse\"\x00\x12N\n" +
+	"\vDeleteShard\x12\x1d.tenant.v1.DeleteShardRequest\x1a\x1e.tenant.v1.DeleteShardResponse\"\x00\x12K\n" +
+	"\n" +
+	"MoveTenant\x12\x1c.tenant.v1.MoveTenantRequest\x1a\x1d.tenant.v1.MoveTenantResponse\"\x00\x12T\n" +
+	"\rGetTenantMove\x12\x1f.tenant.v1.GetTenantMoveRequest\x1a .tenant.v1.GetTenantMoveResponse\"\x00BIZGgithub.com/teresa-solution/tenant-management-service/proto/gen;tenantpbb\x06proto3"
 
 var (
 	file_proto_tenant_proto_rawDescOnce sync.Once
@@ -569,35 +3606,137 @@ func file_proto_tenant_proto_rawDescGZIP() []byte {
 	return file_proto_tenant_proto_rawDescData
 }
 
-var file_proto_tenant_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_proto_tenant_proto_msgTypes = make([]protoimpl.MessageInfo, 55)
 var file_proto_tenant_proto_goTypes = []any{
-	(*Tenant)(nil),               // 0: tenant.v1.Tenant
-	(*CreateTenantRequest)(nil),  // 1: tenant.v1.CreateTenantRequest
-	(*CreateTenantResponse)(nil), // 2: tenant.v1.CreateTenantResponse
-	(*GetTenantRequest)(nil),     // 3: tenant.v1.GetTenantRequest
-	(*GetTenantResponse)(nil),    // 4: tenant.v1.GetTenantResponse
-	(*UpdateTenantRequest)(nil),  // 5: tenant.v1.UpdateTenantRequest
-	(*UpdateTenantResponse)(nil), // 6: tenant.v1.UpdateTenantResponse
-	(*DeleteTenantRequest)(nil),  // 7: tenant.v1.DeleteTenantRequest
-	(*DeleteTenantResponse)(nil), // 8: tenant.v1.DeleteTenantResponse
+	(*Tenant)(nil),                            // 0: tenant.v1.Tenant
+	(*CreateTenantRequest)(nil),               // 1: tenant.v1.CreateTenantRequest
+	(*CreateTenantResponse)(nil),              // 2: tenant.v1.CreateTenantResponse
+	(*GetTenantRequest)(nil),                  // 3: tenant.v1.GetTenantRequest
+	(*GetTenantResponse)(nil),                 // 4: tenant.v1.GetTenantResponse
+	(*UpdateTenantRequest)(nil),               // 5: tenant.v1.UpdateTenantRequest
+	(*UpdateTenantResponse)(nil),              // 6: tenant.v1.UpdateTenantResponse
+	(*DeleteTenantRequest)(nil),               // 7: tenant.v1.DeleteTenantRequest
+	(*DeleteTenantResponse)(nil),              // 8: tenant.v1.DeleteTenantResponse
+	(*APIKey)(nil),                            // 9: tenant.v1.APIKey
+	(*CreateAPIKeyRequest)(nil),               // 10: tenant.v1.CreateAPIKeyRequest
+	(*CreateAPIKeyResponse)(nil),              // 11: tenant.v1.CreateAPIKeyResponse
+	(*ListAPIKeysRequest)(nil),                // 12: tenant.v1.ListAPIKeysRequest
+	(*ListAPIKeysResponse)(nil),               // 13: tenant.v1.ListAPIKeysResponse
+	(*RotateAPIKeyRequest)(nil),               // 14: tenant.v1.RotateAPIKeyRequest
+	(*RotateAPIKeyResponse)(nil),              // 15: tenant.v1.RotateAPIKeyResponse
+	(*RevokeAPIKeyRequest)(nil),               // 16: tenant.v1.RevokeAPIKeyRequest
+	(*RevokeAPIKeyResponse)(nil),              // 17: tenant.v1.RevokeAPIKeyResponse
+	(*VerifyAPIKeyRequest)(nil),               // 18: tenant.v1.VerifyAPIKeyRequest
+	(*VerifyAPIKeyResponse)(nil),              // 19: tenant.v1.VerifyAPIKeyResponse
+	(*QuotaUsage)(nil),                        // 20: tenant.v1.QuotaUsage
+	(*GetQuotaUsageRequest)(nil),              // 21: tenant.v1.GetQuotaUsageRequest
+	(*GetQuotaUsageResponse)(nil),             // 22: tenant.v1.GetQuotaUsageResponse
+	(*SetQuotaOverrideRequest)(nil),           // 23: tenant.v1.SetQuotaOverrideRequest
+	(*SetQuotaOverrideResponse)(nil),          // 24: tenant.v1.SetQuotaOverrideResponse
+	(*DatabaseConfig)(nil),                    // 25: tenant.v1.DatabaseConfig
+	(*ResolveTenantRequest)(nil),              // 26: tenant.v1.ResolveTenantRequest
+	(*ResolveTenantResponse)(nil),             // 27: tenant.v1.ResolveTenantResponse
+	(*GetDatabaseConfigRequest)(nil),          // 28: tenant.v1.GetDatabaseConfigRequest
+	(*GetDatabaseConfigResponse)(nil),         // 29: tenant.v1.GetDatabaseConfigResponse
+	(*RotateDatabaseCredentialsRequest)(nil),  // 30: tenant.v1.RotateDatabaseCredentialsRequest
+	(*RotateDatabaseCredentialsResponse)(nil), // 31: tenant.v1.RotateDatabaseCredentialsResponse
+	(*CustomDomain)(nil),                      // 32: tenant.v1.CustomDomain
+	(*AttachCustomDomainRequest)(nil),         // 33: tenant.v1.AttachCustomDomainRequest
+	(*AttachCustomDomainResponse)(nil),        // 34: tenant.v1.AttachCustomDomainResponse
+	(*VerifyCustomDomainRequest)(nil),         // 35: tenant.v1.VerifyCustomDomainRequest
+	(*VerifyCustomDomainResponse)(nil),        // 36: tenant.v1.VerifyCustomDomainResponse
+	(*ListCustomDomainsRequest)(nil),          // 37: tenant.v1.ListCustomDomainsRequest
+	(*ListCustomDomainsResponse)(nil),         // 38: tenant.v1.ListCustomDomainsResponse
+	(*DetachCustomDomainRequest)(nil),         // 39: tenant.v1.DetachCustomDomainRequest
+	(*DetachCustomDomainResponse)(nil),        // 40: tenant.v1.DetachCustomDomainResponse
+	(*Shard)(nil),                             // 41: tenant.v1.Shard
+	(*CreateShardRequest)(nil),                // 42: tenant.v1.CreateShardRequest
+	(*CreateShardResponse)(nil),               // 43: tenant.v1.CreateShardResponse
+	(*ListShardsRequest)(nil),                 // 44: tenant.v1.ListShardsRequest
+	(*ListShardsResponse)(nil),                // 45: tenant.v1.ListShardsResponse
+	(*UpdateShardRequest)(nil),                // 46: tenant.v1.UpdateShardRequest
+	(*UpdateShardResponse)(nil),               // 47: tenant.v1.UpdateShardResponse
+	(*DeleteShardRequest)(nil),                // 48: tenant.v1.DeleteShardRequest
+	(*DeleteShardResponse)(nil),               // 49: tenant.v1.DeleteShardResponse
+	(*TenantMove)(nil),                        // 50: tenant.v1.TenantMove
+	(*MoveTenantRequest)(nil),                 // 51: tenant.v1.MoveTenantRequest
+	(*MoveTenantResponse)(nil),                // 52: tenant.v1.MoveTenantResponse
+	(*GetTenantMoveRequest)(nil),              // 53: tenant.v1.GetTenantMoveRequest
+	(*GetTenantMoveResponse)(nil),             // 54: tenant.v1.GetTenantMoveResponse
 }
 var file_proto_tenant_proto_depIdxs = []int32{
-	0, // 0: tenant.v1.CreateTenantResponse.tenant:type_name -> tenant.v1.Tenant
-	0, // 1: tenant.v1.GetTenantResponse.tenant:type_name -> tenant.v1.Tenant
-	0, // 2: tenant.v1.UpdateTenantResponse.tenant:type_name -> tenant.v1.Tenant
-	1, // 3: tenant.v1.TenantService.CreateTenant:input_type -> tenant.v1.CreateTenantRequest
-	3, // 4: tenant.v1.TenantService.GetTenant:input_type -> tenant.v1.GetTenantRequest
-	5, // 5: tenant.v1.TenantService.UpdateTenant:input_type -> tenant.v1.UpdateTenantRequest
-	7, // 6: tenant.v1.TenantService.DeleteTenant:input_type -> tenant.v1.DeleteTenantRequest
-	2, // 7: tenant.v1.TenantService.CreateTenant:output_type -> tenant.v1.CreateTenantResponse
-	4, // 8: tenant.v1.TenantService.GetTenant:output_type -> tenant.v1.GetTenantResponse
-	6, // 9: tenant.v1.TenantService.UpdateTenant:output_type -> tenant.v1.UpdateTenantResponse
-	8, // 10: tenant.v1.TenantService.DeleteTenant:output_type -> tenant.v1.DeleteTenantResponse
-	7, // [7:11] is the sub-list for method output_type
-	3, // [3:7] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	0,  // 0: tenant.v1.CreateTenantResponse.tenant:type_name -> tenant.v1.Tenant
+	0,  // 1: tenant.v1.GetTenantResponse.tenant:type_name -> tenant.v1.Tenant
+	0,  // 2: tenant.v1.UpdateTenantResponse.tenant:type_name -> tenant.v1.Tenant
+	9,  // 3: tenant.v1.CreateAPIKeyResponse.api_key:type_name -> tenant.v1.APIKey
+	9,  // 4: tenant.v1.ListAPIKeysResponse.api_keys:type_name -> tenant.v1.APIKey
+	9,  // 5: tenant.v1.RotateAPIKeyResponse.api_key:type_name -> tenant.v1.APIKey
+	20, // 6: tenant.v1.GetQuotaUsageResponse.usage:type_name -> tenant.v1.QuotaUsage
+	20, // 7: tenant.v1.SetQuotaOverrideResponse.usage:type_name -> tenant.v1.QuotaUsage
+	0,  // 8: tenant.v1.ResolveTenantResponse.tenant:type_name -> tenant.v1.Tenant
+	25, // 9: tenant.v1.ResolveTenantResponse.database:type_name -> tenant.v1.DatabaseConfig
+	25, // 10: tenant.v1.GetDatabaseConfigResponse.config:type_name -> tenant.v1.DatabaseConfig
+	32, // 11: tenant.v1.AttachCustomDomainResponse.custom_domain:type_name -> tenant.v1.CustomDomain
+	32, // 12: tenant.v1.VerifyCustomDomainResponse.custom_domain:type_name -> tenant.v1.CustomDomain
+	32, // 13: tenant.v1.ListCustomDomainsResponse.custom_domains:type_name -> tenant.v1.CustomDomain
+	41, // 14: tenant.v1.CreateShardResponse.shard:type_name -> tenant.v1.Shard
+	41, // 15: tenant.v1.ListShardsResponse.shards:type_name -> tenant.v1.Shard
+	41, // 16: tenant.v1.UpdateShardResponse.shard:type_name -> tenant.v1.Shard
+	50, // 17: tenant.v1.MoveTenantResponse.move:type_name -> tenant.v1.TenantMove
+	50, // 18: tenant.v1.GetTenantMoveResponse.move:type_name -> tenant.v1.TenantMove
+	1,  // 19: tenant.v1.TenantService.CreateTenant:input_type -> tenant.v1.CreateTenantRequest
+	3,  // 20: tenant.v1.TenantService.GetTenant:input_type -> tenant.v1.GetTenantRequest
+	5,  // 21: tenant.v1.TenantService.UpdateTenant:input_type -> tenant.v1.UpdateTenantRequest
+	7,  // 22: tenant.v1.TenantService.DeleteTenant:input_type -> tenant.v1.DeleteTenantRequest
+	10, // 23: tenant.v1.TenantService.CreateAPIKey:input_type -> tenant.v1.CreateAPIKeyRequest
+	12, // 24: tenant.v1.TenantService.ListAPIKeys:input_type -> tenant.v1.ListAPIKeysRequest
+	14, // 25: tenant.v1.TenantService.RotateAPIKey:input_type -> tenant.v1.RotateAPIKeyRequest
+	16, // 26: tenant.v1.TenantService.RevokeAPIKey:input_type -> tenant.v1.RevokeAPIKeyRequest
+	18, // 27: tenant.v1.TenantService.VerifyAPIKey:input_type -> tenant.v1.VerifyAPIKeyRequest
+	21, // 28: tenant.v1.TenantService.GetQuotaUsage:input_type -> tenant.v1.GetQuotaUsageRequest
+	23, // 29: tenant.v1.TenantService.SetQuotaOverride:input_type -> tenant.v1.SetQuotaOverrideRequest
+	26, // 30: tenant.v1.TenantService.ResolveTenant:input_type -> tenant.v1.ResolveTenantRequest
+	28, // 31: tenant.v1.TenantService.GetDatabaseConfig:input_type -> tenant.v1.GetDatabaseConfigRequest
+	30, // 32: tenant.v1.TenantService.RotateDatabaseCredentials:input_type -> tenant.v1.RotateDatabaseCredentialsRequest
+	33, // 33: tenant.v1.TenantService.AttachCustomDomain:input_type -> tenant.v1.AttachCustomDomainRequest
+	35, // 34: tenant.v1.TenantService.VerifyCustomDomain:input_type -> tenant.v1.VerifyCustomDomainRequest
+	37, // 35: tenant.v1.TenantService.ListCustomDomains:input_type -> tenant.v1.ListCustomDomainsRequest
+	39, // 36: tenant.v1.TenantService.DetachCustomDomain:input_type -> tenant.v1.DetachCustomDomainRequest
+	42, // 37: tenant.v1.TenantService.CreateShard:input_type -> tenant.v1.CreateShardRequest
+	44, // 38: tenant.v1.TenantService.ListShards:input_type -> tenant.v1.ListShardsRequest
+	46, // 39: tenant.v1.TenantService.UpdateShard:input_type -> tenant.v1.UpdateShardRequest
+	48, // 40: tenant.v1.TenantService.DeleteShard:input_type -> tenant.v1.DeleteShardRequest
+	51, // 41: tenant.v1.TenantService.MoveTenant:input_type -> tenant.v1.MoveTenantRequest
+	53, // 42: tenant.v1.TenantService.GetTenantMove:input_type -> tenant.v1.GetTenantMoveRequest
+	2,  // 43: tenant.v1.TenantService.CreateTenant:output_type -> tenant.v1.CreateTenantResponse
+	4,  // 44: tenant.v1.TenantService.GetTenant:output_type -> tenant.v1.GetTenantResponse
+	6,  // 45: tenant.v1.TenantService.UpdateTenant:output_type -> tenant.v1.UpdateTenantResponse
+	8,  // 46: tenant.v1.TenantService.DeleteTenant:output_type -> tenant.v1.DeleteTenantResponse
+	11, // 47: tenant.v1.TenantService.CreateAPIKey:output_type -> tenant.v1.CreateAPIKeyResponse
+	13, // 48: tenant.v1.TenantService.ListAPIKeys:output_type -> tenant.v1.ListAPIKeysResponse
+	15, // 49: tenant.v1.TenantService.RotateAPIKey:output_type -> tenant.v1.RotateAPIKeyResponse
+	17, // 50: tenant.v1.TenantService.RevokeAPIKey:output_type -> tenant.v1.RevokeAPIKeyResponse
+	19, // 51: tenant.v1.TenantService.VerifyAPIKey:output_type -> tenant.v1.VerifyAPIKeyResponse
+	22, // 52: tenant.v1.TenantService.GetQuotaUsage:output_type -> tenant.v1.GetQuotaUsageResponse
+	24, // 53: tenant.v1.TenantService.SetQuotaOverride:output_type -> tenant.v1.SetQuotaOverrideResponse
+	27, // 54: tenant.v1.TenantService.ResolveTenant:output_type -> tenant.v1.ResolveTenantResponse
+	29, // 55: tenant.v1.TenantService.GetDatabaseConfig:output_type -> tenant.v1.GetDatabaseConfigResponse
+	31, // 56: tenant.v1.TenantService.RotateDatabaseCredentials:output_type -> tenant.v1.RotateDatabaseCredentialsResponse
+	34, // 57: tenant.v1.TenantService.AttachCustomDomain:output_type -> tenant.v1.AttachCustomDomainResponse
+	36, // 58: tenant.v1.TenantService.VerifyCustomDomain:output_type -> tenant.v1.VerifyCustomDomainResponse
+	38, // 59: tenant.v1.TenantService.ListCustomDomains:output_type -> tenant.v1.ListCustomDomainsResponse
+	40, // 60: tenant.v1.TenantService.DetachCustomDomain:output_type -> tenant.v1.DetachCustomDomainResponse
+	43, // 61: tenant.v1.TenantService.CreateShard:output_type -> tenant.v1.CreateShardResponse
+	45, // 62: tenant.v1.TenantService.ListShards:output_type -> tenant.v1.ListShardsResponse
+	47, // 63: tenant.v1.TenantService.UpdateShard:output_type -> tenant.v1.UpdateShardResponse
+	49, // 64: tenant.v1.TenantService.DeleteShard:output_type -> tenant.v1.DeleteShardResponse
+	52, // 65: tenant.v1.TenantService.MoveTenant:output_type -> tenant.v1.MoveTenantResponse
+	54, // 66: tenant.v1.TenantService.GetTenantMove:output_type -> tenant.v1.GetTenantMoveResponse
+	43, // [43:67] is the sub-list for method output_type
+	19, // [19:43] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
 }
 
 func init() { file_proto_tenant_proto_init() }
@@ -605,13 +3744,14 @@ func file_proto_tenant_proto_init() {
 	if File_proto_tenant_proto != nil {
 		return
 	}
+	file_proto_tenant_proto_msgTypes[46].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_tenant_proto_rawDesc), len(file_proto_tenant_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   9,
+			NumMessages:   55,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
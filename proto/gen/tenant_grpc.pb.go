@@ -19,10 +19,30 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	TenantService_CreateTenant_FullMethodName = "/tenant.v1.TenantService/CreateTenant"
-	TenantService_GetTenant_FullMethodName    = "/tenant.v1.TenantService/GetTenant"
-	TenantService_UpdateTenant_FullMethodName = "/tenant.v1.TenantService/UpdateTenant"
-	TenantService_DeleteTenant_FullMethodName = "/tenant.v1.TenantService/DeleteTenant"
+	TenantService_CreateTenant_FullMethodName              = "/tenant.v1.TenantService/CreateTenant"
+	TenantService_GetTenant_FullMethodName                 = "/tenant.v1.TenantService/GetTenant"
+	TenantService_UpdateTenant_FullMethodName              = "/tenant.v1.TenantService/UpdateTenant"
+	TenantService_DeleteTenant_FullMethodName              = "/tenant.v1.TenantService/DeleteTenant"
+	TenantService_CreateAPIKey_FullMethodName              = "/tenant.v1.TenantService/CreateAPIKey"
+	TenantService_ListAPIKeys_FullMethodName               = "/tenant.v1.TenantService/ListAPIKeys"
+	TenantService_RotateAPIKey_FullMethodName              = "/tenant.v1.TenantService/RotateAPIKey"
+	TenantService_RevokeAPIKey_FullMethodName              = "/tenant.v1.TenantService/RevokeAPIKey"
+	TenantService_VerifyAPIKey_FullMethodName              = "/tenant.v1.TenantService/VerifyAPIKey"
+	TenantService_GetQuotaUsage_FullMethodName             = "/tenant.v1.TenantService/GetQuotaUsage"
+	TenantService_SetQuotaOverride_FullMethodName          = "/tenant.v1.TenantService/SetQuotaOverride"
+	TenantService_ResolveTenant_FullMethodName             = "/tenant.v1.TenantService/ResolveTenant"
+	TenantService_GetDatabaseConfig_FullMethodName         = "/tenant.v1.TenantService/GetDatabaseConfig"
+	TenantService_RotateDatabaseCredentials_FullMethodName = "/tenant.v1.TenantService/RotateDatabaseCredentials"
+	TenantService_AttachCustomDomain_FullMethodName        = "/tenant.v1.TenantService/AttachCustomDomain"
+	TenantService_VerifyCustomDomain_FullMethodName        = "/tenant.v1.TenantService/VerifyCustomDomain"
+	TenantService_ListCustomDomains_FullMethodName         = "/tenant.v1.TenantService/ListCustomDomains"
+	TenantService_DetachCustomDomain_FullMethodName        = "/tenant.v1.TenantService/DetachCustomDomain"
+	TenantService_CreateShard_FullMethodName               = "/tenant.v1.TenantService/CreateShard"
+	TenantService_ListShards_FullMethodName                = "/tenant.v1.TenantService/ListShards"
+	TenantService_UpdateShard_FullMethodName               = "/tenant.v1.TenantService/UpdateShard"
+	TenantService_DeleteShard_FullMethodName               = "/tenant.v1.TenantService/DeleteShard"
+	TenantService_MoveTenant_FullMethodName                = "/tenant.v1.TenantService/MoveTenant"
+	TenantService_GetTenantMove_FullMethodName             = "/tenant.v1.TenantService/GetTenantMove"
 )
 
 // TenantServiceClient is the client API for TenantService service.
@@ -33,6 +53,26 @@ type TenantServiceClient interface {
 	GetTenant(ctx context.Context, in *GetTenantRequest, opts ...grpc.CallOption) (*GetTenantResponse, error)
 	UpdateTenant(ctx context.Context, in *UpdateTenantRequest, opts ...grpc.CallOption) (*UpdateTenantResponse, error)
 	DeleteTenant(ctx context.Context, in *DeleteTenantRequest, opts ...grpc.CallOption) (*DeleteTenantResponse, error)
+	CreateAPIKey(ctx context.Context, in *CreateAPIKeyRequest, opts ...grpc.CallOption) (*CreateAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context, in *ListAPIKeysRequest, opts ...grpc.CallOption) (*ListAPIKeysResponse, error)
+	RotateAPIKey(ctx context.Context, in *RotateAPIKeyRequest, opts ...grpc.CallOption) (*RotateAPIKeyResponse, error)
+	RevokeAPIKey(ctx context.Context, in *RevokeAPIKeyRequest, opts ...grpc.CallOption) (*RevokeAPIKeyResponse, error)
+	VerifyAPIKey(ctx context.Context, in *VerifyAPIKeyRequest, opts ...grpc.CallOption) (*VerifyAPIKeyResponse, error)
+	GetQuotaUsage(ctx context.Context, in *GetQuotaUsageRequest, opts ...grpc.CallOption) (*GetQuotaUsageResponse, error)
+	SetQuotaOverride(ctx context.Context, in *SetQuotaOverrideRequest, opts ...grpc.CallOption) (*SetQuotaOverrideResponse, error)
+	ResolveTenant(ctx context.Context, in *ResolveTenantRequest, opts ...grpc.CallOption) (*ResolveTenantResponse, error)
+	GetDatabaseConfig(ctx context.Context, in *GetDatabaseConfigRequest, opts ...grpc.CallOption) (*GetDatabaseConfigResponse, error)
+	RotateDatabaseCredentials(ctx context.Context, in *RotateDatabaseCredentialsRequest, opts ...grpc.CallOption) (*RotateDatabaseCredentialsResponse, error)
+	AttachCustomDomain(ctx context.Context, in *AttachCustomDomainRequest, opts ...grpc.CallOption) (*AttachCustomDomainResponse, error)
+	VerifyCustomDomain(ctx context.Context, in *VerifyCustomDomainRequest, opts ...grpc.CallOption) (*VerifyCustomDomainResponse, error)
+	ListCustomDomains(ctx context.Context, in *ListCustomDomainsRequest, opts ...grpc.CallOption) (*ListCustomDomainsResponse, error)
+	DetachCustomDomain(ctx context.Context, in *DetachCustomDomainRequest, opts ...grpc.CallOption) (*DetachCustomDomainResponse, error)
+	CreateShard(ctx context.Context, in *CreateShardRequest, opts ...grpc.CallOption) (*CreateShardResponse, error)
+	ListShards(ctx context.Context, in *ListShardsRequest, opts ...grpc.CallOption) (*ListShardsResponse, error)
+	UpdateShard(ctx context.Context, in *UpdateShardRequest, opts ...grpc.CallOption) (*UpdateShardResponse, error)
+	DeleteShard(ctx context.Context, in *DeleteShardRequest, opts ...grpc.CallOption) (*DeleteShardResponse, error)
+	MoveTenant(ctx context.Context, in *MoveTenantRequest, opts ...grpc.CallOption) (*MoveTenantResponse, error)
+	GetTenantMove(ctx context.Context, in *GetTenantMoveRequest, opts ...grpc.CallOption) (*GetTenantMoveResponse, error)
 }
 
 type tenantServiceClient struct {
@@ -83,6 +123,206 @@ func (c *tenantServiceClient) DeleteTenant(ctx context.Context, in *DeleteTenant
 	return out, nil
 }
 
+func (c *tenantServiceClient) CreateAPIKey(ctx context.Context, in *CreateAPIKeyRequest, opts ...grpc.CallOption) (*CreateAPIKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateAPIKeyResponse)
+	err := c.cc.Invoke(ctx, TenantService_CreateAPIKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tenantServiceClient) ListAPIKeys(ctx context.Context, in *ListAPIKeysRequest, opts ...grpc.CallOption) (*ListAPIKeysResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAPIKeysResponse)
+	err := c.cc.Invoke(ctx, TenantService_ListAPIKeys_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tenantServiceClient) RotateAPIKey(ctx context.Context, in *RotateAPIKeyRequest, opts ...grpc.CallOption) (*RotateAPIKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RotateAPIKeyResponse)
+	err := c.cc.Invoke(ctx, TenantService_RotateAPIKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tenantServiceClient) RevokeAPIKey(ctx context.Context, in *RevokeAPIKeyRequest, opts ...grpc.CallOption) (*RevokeAPIKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokeAPIKeyResponse)
+	err := c.cc.Invoke(ctx, TenantService_RevokeAPIKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tenantServiceClient) VerifyAPIKey(ctx context.Context, in *VerifyAPIKeyRequest, opts ...grpc.CallOption) (*VerifyAPIKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerifyAPIKeyResponse)
+	err := c.cc.Invoke(ctx, TenantService_VerifyAPIKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tenantServiceClient) GetQuotaUsage(ctx context.Context, in *GetQuotaUsageRequest, opts ...grpc.CallOption) (*GetQuotaUsageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetQuotaUsageResponse)
+	err := c.cc.Invoke(ctx, TenantService_GetQuotaUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tenantServiceClient) SetQuotaOverride(ctx context.Context, in *SetQuotaOverrideRequest, opts ...grpc.CallOption) (*SetQuotaOverrideResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetQuotaOverrideResponse)
+	err := c.cc.Invoke(ctx, TenantService_SetQuotaOverride_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tenantServiceClient) ResolveTenant(ctx context.Context, in *ResolveTenantRequest, opts ...grpc.CallOption) (*ResolveTenantResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResolveTenantResponse)
+	err := c.cc.Invoke(ctx, TenantService_ResolveTenant_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tenantServiceClient) GetDatabaseConfig(ctx context.Context, in *GetDatabaseConfigRequest, opts ...grpc.CallOption) (*GetDatabaseConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDatabaseConfigResponse)
+	err := c.cc.Invoke(ctx, TenantService_GetDatabaseConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tenantServiceClient) RotateDatabaseCredentials(ctx context.Context, in *RotateDatabaseCredentialsRequest, opts ...grpc.CallOption) (*RotateDatabaseCredentialsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RotateDatabaseCredentialsResponse)
+	err := c.cc.Invoke(ctx, TenantService_RotateDatabaseCredentials_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tenantServiceClient) AttachCustomDomain(ctx context.Context, in *AttachCustomDomainRequest, opts ...grpc.CallOption) (*AttachCustomDomainResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AttachCustomDomainResponse)
+	err := c.cc.Invoke(ctx, TenantService_AttachCustomDomain_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tenantServiceClient) VerifyCustomDomain(ctx context.Context, in *VerifyCustomDomainRequest, opts ...grpc.CallOption) (*VerifyCustomDomainResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerifyCustomDomainResponse)
+	err := c.cc.Invoke(ctx, TenantService_VerifyCustomDomain_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tenantServiceClient) ListCustomDomains(ctx context.Context, in *ListCustomDomainsRequest, opts ...grpc.CallOption) (*ListCustomDomainsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCustomDomainsResponse)
+	err := c.cc.Invoke(ctx, TenantService_ListCustomDomains_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tenantServiceClient) DetachCustomDomain(ctx context.Context, in *DetachCustomDomainRequest, opts ...grpc.CallOption) (*DetachCustomDomainResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DetachCustomDomainResponse)
+	err := c.cc.Invoke(ctx, TenantService_DetachCustomDomain_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tenantServiceClient) CreateShard(ctx context.Context, in *CreateShardRequest, opts ...grpc.CallOption) (*CreateShardResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateShardResponse)
+	err := c.cc.Invoke(ctx, TenantService_CreateShard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tenantServiceClient) ListShards(ctx context.Context, in *ListShardsRequest, opts ...grpc.CallOption) (*ListShardsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListShardsResponse)
+	err := c.cc.Invoke(ctx, TenantService_ListShards_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tenantServiceClient) UpdateShard(ctx context.Context, in *UpdateShardRequest, opts ...grpc.CallOption) (*UpdateShardResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateShardResponse)
+	err := c.cc.Invoke(ctx, TenantService_UpdateShard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tenantServiceClient) DeleteShard(ctx context.Context, in *DeleteShardRequest, opts ...grpc.CallOption) (*DeleteShardResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteShardResponse)
+	err := c.cc.Invoke(ctx, TenantService_DeleteShard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tenantServiceClient) MoveTenant(ctx context.Context, in *MoveTenantRequest, opts ...grpc.CallOption) (*MoveTenantResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MoveTenantResponse)
+	err := c.cc.Invoke(ctx, TenantService_MoveTenant_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tenantServiceClient) GetTenantMove(ctx context.Context, in *GetTenantMoveRequest, opts ...grpc.CallOption) (*GetTenantMoveResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTenantMoveResponse)
+	err := c.cc.Invoke(ctx, TenantService_GetTenantMove_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TenantServiceServer is the server API for TenantService service.
 // All implementations must embed UnimplementedTenantServiceServer
 // for forward compatibility.
@@ -91,6 +331,26 @@ type TenantServiceServer interface {
 	GetTenant(context.Context, *GetTenantRequest) (*GetTenantResponse, error)
 	UpdateTenant(context.Context, *UpdateTenantRequest) (*UpdateTenantResponse, error)
 	DeleteTenant(context.Context, *DeleteTenantRequest) (*DeleteTenantResponse, error)
+	CreateAPIKey(context.Context, *CreateAPIKeyRequest) (*CreateAPIKeyResponse, error)
+	ListAPIKeys(context.Context, *ListAPIKeysRequest) (*ListAPIKeysResponse, error)
+	RotateAPIKey(context.Context, *RotateAPIKeyRequest) (*RotateAPIKeyResponse, error)
+	RevokeAPIKey(context.Context, *RevokeAPIKeyRequest) (*RevokeAPIKeyResponse, error)
+	VerifyAPIKey(context.Context, *VerifyAPIKeyRequest) (*VerifyAPIKeyResponse, error)
+	GetQuotaUsage(context.Context, *GetQuotaUsageRequest) (*GetQuotaUsageResponse, error)
+	SetQuotaOverride(context.Context, *SetQuotaOverrideRequest) (*SetQuotaOverrideResponse, error)
+	ResolveTenant(context.Context, *ResolveTenantRequest) (*ResolveTenantResponse, error)
+	GetDatabaseConfig(context.Context, *GetDatabaseConfigRequest) (*GetDatabaseConfigResponse, error)
+	RotateDatabaseCredentials(context.Context, *RotateDatabaseCredentialsRequest) (*RotateDatabaseCredentialsResponse, error)
+	AttachCustomDomain(context.Context, *AttachCustomDomainRequest) (*AttachCustomDomainResponse, error)
+	VerifyCustomDomain(context.Context, *VerifyCustomDomainRequest) (*VerifyCustomDomainResponse, error)
+	ListCustomDomains(context.Context, *ListCustomDomainsRequest) (*ListCustomDomainsResponse, error)
+	DetachCustomDomain(context.Context, *DetachCustomDomainRequest) (*DetachCustomDomainResponse, error)
+	CreateShard(context.Context, *CreateShardRequest) (*CreateShardResponse, error)
+	ListShards(context.Context, *ListShardsRequest) (*ListShardsResponse, error)
+	UpdateShard(context.Context, *UpdateShardRequest) (*UpdateShardResponse, error)
+	DeleteShard(context.Context, *DeleteShardRequest) (*DeleteShardResponse, error)
+	MoveTenant(context.Context, *MoveTenantRequest) (*MoveTenantResponse, error)
+	GetTenantMove(context.Context, *GetTenantMoveRequest) (*GetTenantMoveResponse, error)
 	mustEmbedUnimplementedTenantServiceServer()
 }
 
@@ -113,6 +373,66 @@ func (UnimplementedTenantServiceServer) UpdateTenant(context.Context, *UpdateTen
 func (UnimplementedTenantServiceServer) DeleteTenant(context.Context, *DeleteTenantRequest) (*DeleteTenantResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteTenant not implemented")
 }
+func (UnimplementedTenantServiceServer) CreateAPIKey(context.Context, *CreateAPIKeyRequest) (*CreateAPIKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAPIKey not implemented")
+}
+func (UnimplementedTenantServiceServer) ListAPIKeys(context.Context, *ListAPIKeysRequest) (*ListAPIKeysResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAPIKeys not implemented")
+}
+func (UnimplementedTenantServiceServer) RotateAPIKey(context.Context, *RotateAPIKeyRequest) (*RotateAPIKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RotateAPIKey not implemented")
+}
+func (UnimplementedTenantServiceServer) RevokeAPIKey(context.Context, *RevokeAPIKeyRequest) (*RevokeAPIKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeAPIKey not implemented")
+}
+func (UnimplementedTenantServiceServer) VerifyAPIKey(context.Context, *VerifyAPIKeyRequest) (*VerifyAPIKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyAPIKey not implemented")
+}
+func (UnimplementedTenantServiceServer) GetQuotaUsage(context.Context, *GetQuotaUsageRequest) (*GetQuotaUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQuotaUsage not implemented")
+}
+func (UnimplementedTenantServiceServer) SetQuotaOverride(context.Context, *SetQuotaOverrideRequest) (*SetQuotaOverrideResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetQuotaOverride not implemented")
+}
+func (UnimplementedTenantServiceServer) ResolveTenant(context.Context, *ResolveTenantRequest) (*ResolveTenantResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResolveTenant not implemented")
+}
+func (UnimplementedTenantServiceServer) GetDatabaseConfig(context.Context, *GetDatabaseConfigRequest) (*GetDatabaseConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDatabaseConfig not implemented")
+}
+func (UnimplementedTenantServiceServer) RotateDatabaseCredentials(context.Context, *RotateDatabaseCredentialsRequest) (*RotateDatabaseCredentialsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RotateDatabaseCredentials not implemented")
+}
+func (UnimplementedTenantServiceServer) AttachCustomDomain(context.Context, *AttachCustomDomainRequest) (*AttachCustomDomainResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AttachCustomDomain not implemented")
+}
+func (UnimplementedTenantServiceServer) VerifyCustomDomain(context.Context, *VerifyCustomDomainRequest) (*VerifyCustomDomainResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyCustomDomain not implemented")
+}
+func (UnimplementedTenantServiceServer) ListCustomDomains(context.Context, *ListCustomDomainsRequest) (*ListCustomDomainsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCustomDomains not implemented")
+}
+func (UnimplementedTenantServiceServer) DetachCustomDomain(context.Context, *DetachCustomDomainRequest) (*DetachCustomDomainResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DetachCustomDomain not implemented")
+}
+func (UnimplementedTenantServiceServer) CreateShard(context.Context, *CreateShardRequest) (*CreateShardResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateShard not implemented")
+}
+func (UnimplementedTenantServiceServer) ListShards(context.Context, *ListShardsRequest) (*ListShardsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListShards not implemented")
+}
+func (UnimplementedTenantServiceServer) UpdateShard(context.Context, *UpdateShardRequest) (*UpdateShardResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateShard not implemented")
+}
+func (UnimplementedTenantServiceServer) DeleteShard(context.Context, *DeleteShardRequest) (*DeleteShardResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteShard not implemented")
+}
+func (UnimplementedTenantServiceServer) MoveTenant(context.Context, *MoveTenantRequest) (*MoveTenantResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MoveTenant not implemented")
+}
+func (UnimplementedTenantServiceServer) GetTenantMove(context.Context, *GetTenantMoveRequest) (*GetTenantMoveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTenantMove not implemented")
+}
 func (UnimplementedTenantServiceServer) mustEmbedUnimplementedTenantServiceServer() {}
 func (UnimplementedTenantServiceServer) testEmbeddedByValue()                       {}
 
@@ -206,6 +526,366 @@ func _TenantService_DeleteTenant_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TenantService_CreateAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAPIKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TenantServiceServer).CreateAPIKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TenantService_CreateAPIKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TenantServiceServer).CreateAPIKey(ctx, req.(*CreateAPIKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TenantService_ListAPIKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAPIKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TenantServiceServer).ListAPIKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TenantService_ListAPIKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TenantServiceServer).ListAPIKeys(ctx, req.(*ListAPIKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TenantService_RotateAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RotateAPIKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TenantServiceServer).RotateAPIKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TenantService_RotateAPIKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TenantServiceServer).RotateAPIKey(ctx, req.(*RotateAPIKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TenantService_RevokeAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeAPIKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TenantServiceServer).RevokeAPIKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TenantService_RevokeAPIKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TenantServiceServer).RevokeAPIKey(ctx, req.(*RevokeAPIKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TenantService_VerifyAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyAPIKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TenantServiceServer).VerifyAPIKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TenantService_VerifyAPIKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TenantServiceServer).VerifyAPIKey(ctx, req.(*VerifyAPIKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TenantService_GetQuotaUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQuotaUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TenantServiceServer).GetQuotaUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TenantService_GetQuotaUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TenantServiceServer).GetQuotaUsage(ctx, req.(*GetQuotaUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TenantService_SetQuotaOverride_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetQuotaOverrideRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TenantServiceServer).SetQuotaOverride(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TenantService_SetQuotaOverride_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TenantServiceServer).SetQuotaOverride(ctx, req.(*SetQuotaOverrideRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TenantService_ResolveTenant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveTenantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TenantServiceServer).ResolveTenant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TenantService_ResolveTenant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TenantServiceServer).ResolveTenant(ctx, req.(*ResolveTenantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TenantService_GetDatabaseConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDatabaseConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TenantServiceServer).GetDatabaseConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TenantService_GetDatabaseConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TenantServiceServer).GetDatabaseConfig(ctx, req.(*GetDatabaseConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TenantService_RotateDatabaseCredentials_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RotateDatabaseCredentialsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TenantServiceServer).RotateDatabaseCredentials(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TenantService_RotateDatabaseCredentials_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TenantServiceServer).RotateDatabaseCredentials(ctx, req.(*RotateDatabaseCredentialsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TenantService_AttachCustomDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttachCustomDomainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TenantServiceServer).AttachCustomDomain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TenantService_AttachCustomDomain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TenantServiceServer).AttachCustomDomain(ctx, req.(*AttachCustomDomainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TenantService_VerifyCustomDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyCustomDomainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TenantServiceServer).VerifyCustomDomain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TenantService_VerifyCustomDomain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TenantServiceServer).VerifyCustomDomain(ctx, req.(*VerifyCustomDomainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TenantService_ListCustomDomains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCustomDomainsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TenantServiceServer).ListCustomDomains(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TenantService_ListCustomDomains_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TenantServiceServer).ListCustomDomains(ctx, req.(*ListCustomDomainsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TenantService_DetachCustomDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DetachCustomDomainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TenantServiceServer).DetachCustomDomain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TenantService_DetachCustomDomain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TenantServiceServer).DetachCustomDomain(ctx, req.(*DetachCustomDomainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TenantService_CreateShard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateShardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TenantServiceServer).CreateShard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TenantService_CreateShard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TenantServiceServer).CreateShard(ctx, req.(*CreateShardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TenantService_ListShards_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListShardsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TenantServiceServer).ListShards(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TenantService_ListShards_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TenantServiceServer).ListShards(ctx, req.(*ListShardsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TenantService_UpdateShard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateShardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TenantServiceServer).UpdateShard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TenantService_UpdateShard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TenantServiceServer).UpdateShard(ctx, req.(*UpdateShardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TenantService_DeleteShard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteShardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TenantServiceServer).DeleteShard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TenantService_DeleteShard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TenantServiceServer).DeleteShard(ctx, req.(*DeleteShardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TenantService_MoveTenant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveTenantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TenantServiceServer).MoveTenant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TenantService_MoveTenant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TenantServiceServer).MoveTenant(ctx, req.(*MoveTenantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TenantService_GetTenantMove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTenantMoveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TenantServiceServer).GetTenantMove(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TenantService_GetTenantMove_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TenantServiceServer).GetTenantMove(ctx, req.(*GetTenantMoveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // TenantService_ServiceDesc is the grpc.ServiceDesc for TenantService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -229,6 +909,86 @@ var TenantService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteTenant",
 			Handler:    _TenantService_DeleteTenant_Handler,
 		},
+		{
+			MethodName: "CreateAPIKey",
+			Handler:    _TenantService_CreateAPIKey_Handler,
+		},
+		{
+			MethodName: "ListAPIKeys",
+			Handler:    _TenantService_ListAPIKeys_Handler,
+		},
+		{
+			MethodName: "RotateAPIKey",
+			Handler:    _TenantService_RotateAPIKey_Handler,
+		},
+		{
+			MethodName: "RevokeAPIKey",
+			Handler:    _TenantService_RevokeAPIKey_Handler,
+		},
+		{
+			MethodName: "VerifyAPIKey",
+			Handler:    _TenantService_VerifyAPIKey_Handler,
+		},
+		{
+			MethodName: "GetQuotaUsage",
+			Handler:    _TenantService_GetQuotaUsage_Handler,
+		},
+		{
+			MethodName: "SetQuotaOverride",
+			Handler:    _TenantService_SetQuotaOverride_Handler,
+		},
+		{
+			MethodName: "ResolveTenant",
+			Handler:    _TenantService_ResolveTenant_Handler,
+		},
+		{
+			MethodName: "GetDatabaseConfig",
+			Handler:    _TenantService_GetDatabaseConfig_Handler,
+		},
+		{
+			MethodName: "RotateDatabaseCredentials",
+			Handler:    _TenantService_RotateDatabaseCredentials_Handler,
+		},
+		{
+			MethodName: "AttachCustomDomain",
+			Handler:    _TenantService_AttachCustomDomain_Handler,
+		},
+		{
+			MethodName: "VerifyCustomDomain",
+			Handler:    _TenantService_VerifyCustomDomain_Handler,
+		},
+		{
+			MethodName: "ListCustomDomains",
+			Handler:    _TenantService_ListCustomDomains_Handler,
+		},
+		{
+			MethodName: "DetachCustomDomain",
+			Handler:    _TenantService_DetachCustomDomain_Handler,
+		},
+		{
+			MethodName: "CreateShard",
+			Handler:    _TenantService_CreateShard_Handler,
+		},
+		{
+			MethodName: "ListShards",
+			Handler:    _TenantService_ListShards_Handler,
+		},
+		{
+			MethodName: "UpdateShard",
+			Handler:    _TenantService_UpdateShard_Handler,
+		},
+		{
+			MethodName: "DeleteShard",
+			Handler:    _TenantService_DeleteShard_Handler,
+		},
+		{
+			MethodName: "MoveTenant",
+			Handler:    _TenantService_MoveTenant_Handler,
+		},
+		{
+			MethodName: "GetTenantMove",
+			Handler:    _TenantService_GetTenantMove_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/tenant.proto",
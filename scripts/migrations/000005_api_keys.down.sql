@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS trigger_tenant_api_keys_updated_at ON tenant_api_keys;
+DROP TABLE IF EXISTS tenant_api_keys;
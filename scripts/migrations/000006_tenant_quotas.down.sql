@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS trigger_tenant_quota_overrides_updated_at ON tenant_quota_overrides;
+DROP TRIGGER IF EXISTS trigger_tier_quotas_updated_at ON tier_quotas;
+DROP TABLE IF EXISTS tenant_quota_overrides;
+DROP TABLE IF EXISTS tier_quotas;
+DROP INDEX IF EXISTS idx_tenants_tier;
+ALTER TABLE tenants DROP COLUMN tier;
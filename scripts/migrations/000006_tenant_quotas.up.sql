@@ -0,0 +1,57 @@
+-- Record the subscription tier of each tenant
+ALTER TABLE tenants ADD COLUMN tier VARCHAR(20) NOT NULL DEFAULT 'basic'
+    CHECK (tier IN ('free', 'basic', 'premium', 'enterprise'));
+
+CREATE INDEX IF NOT EXISTS idx_tenants_tier ON tenants(tier) WHERE deleted_at IS NULL;
+
+-- Create tier_quotas table holding the limits of each tier. A limit of -1
+-- means unlimited.
+CREATE TABLE IF NOT EXISTS tier_quotas (
+    tier VARCHAR(20) NOT NULL,
+    resource VARCHAR(50) NOT NULL,
+    quota_limit BIGINT NOT NULL CHECK (quota_limit >= -1),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (tier, resource)
+);
+
+CREATE TRIGGER trigger_tier_quotas_updated_at
+BEFORE UPDATE ON tier_quotas
+FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+-- Create tenant_quota_overrides table replacing tier limits for one tenant
+CREATE TABLE IF NOT EXISTS tenant_quota_overrides (
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    resource VARCHAR(50) NOT NULL,
+    quota_limit BIGINT NOT NULL CHECK (quota_limit >= -1),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (tenant_id, resource)
+);
+
+CREATE TRIGGER trigger_tenant_quota_overrides_updated_at
+BEFORE UPDATE ON tenant_quota_overrides
+FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+INSERT INTO tier_quotas (tier, resource, quota_limit) VALUES
+    ('free', 'tenants', 10000),
+    ('free', 'contacts', 2),
+    ('free', 'api_keys', 2),
+    ('free', 'config_keys', 20),
+    ('free', 'schema_storage_bytes', 104857600),
+    ('basic', 'tenants', -1),
+    ('basic', 'contacts', 5),
+    ('basic', 'api_keys', 5),
+    ('basic', 'config_keys', 100),
+    ('basic', 'schema_storage_bytes', 1073741824),
+    ('premium', 'tenants', -1),
+    ('premium', 'contacts', 10),
+    ('premium', 'api_keys', 25),
+    ('premium', 'config_keys', 500),
+    ('premium', 'schema_storage_bytes', 10737418240),
+    ('enterprise', 'tenants', -1),
+    ('enterprise', 'contacts', -1),
+    ('enterprise', 'api_keys', -1),
+    ('enterprise', 'config_keys', -1),
+    ('enterprise', 'schema_storage_bytes', -1)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,14 @@
+CREATE OR REPLACE FUNCTION create_tenant_schema(p_tenant_id UUID, p_subdomain VARCHAR)
+RETURNS VOID AS $$
+BEGIN
+    EXECUTE format('CREATE SCHEMA tenant_%I', p_subdomain);
+    INSERT INTO tenant_schemas (tenant_id, schema_name)
+    VALUES (p_tenant_id, format('tenant_%I', p_subdomain));
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_tenants_subdomain_deleted;
+DROP INDEX IF EXISTS uq_tenants_subdomain_active;
+CREATE INDEX IF NOT EXISTS idx_tenants_subdomain ON tenants(subdomain) WHERE deleted_at IS NULL;
+-- Fails if a subdomain has been reclaimed in the meantime
+ALTER TABLE tenants ADD CONSTRAINT tenants_subdomain_key UNIQUE (subdomain);
@@ -0,0 +1,22 @@
+-- Subdomains only need to be unique among tenants that have not been deleted;
+-- whether a deleted tenant's subdomain may be reused is decided by the service.
+ALTER TABLE tenants DROP CONSTRAINT IF EXISTS tenants_subdomain_key;
+DROP INDEX IF EXISTS idx_tenants_subdomain;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_tenants_subdomain_active ON tenants(subdomain) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_tenants_subdomain_deleted ON tenants(subdomain, deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- A reclaimed subdomain may still have the previous owner's schema, in which
+-- case the new tenant's schema name is suffixed with its ID
+CREATE OR REPLACE FUNCTION create_tenant_schema(p_tenant_id UUID, p_subdomain VARCHAR)
+RETURNS VOID AS $$
+DECLARE
+    v_schema VARCHAR(63) := format('tenant_%I', p_subdomain);
+BEGIN
+    IF EXISTS (SELECT 1 FROM tenant_schemas WHERE schema_name = v_schema) THEN
+        v_schema := format('tenant_%I', left(p_subdomain, 46) || '_' || left(replace(p_tenant_id::text, '-', ''), 8));
+    END IF;
+    EXECUTE format('CREATE SCHEMA %s', v_schema);
+    INSERT INTO tenant_schemas (tenant_id, schema_name)
+    VALUES (p_tenant_id, v_schema);
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS trigger_provisioning_jobs_updated_at ON provisioning_jobs;
+DROP TABLE IF EXISTS provisioning_jobs;
@@ -0,0 +1,30 @@
+-- Durable provisioning job queue. Workers claim ready jobs with
+-- SELECT ... FOR UPDATE SKIP LOCKED and hold them under a lease that they
+-- extend with heartbeats; a job whose lease lapses becomes visible again.
+CREATE TABLE IF NOT EXISTS provisioning_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    kind VARCHAR(30) NOT NULL CHECK (kind IN ('provision')),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'succeeded', 'dead_letter')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5 CHECK (max_attempts > 0),
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    locked_by VARCHAR(255),
+    locked_until TIMESTAMP WITH TIME ZONE,
+    heartbeat_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_provisioning_jobs_ready ON provisioning_jobs(run_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_provisioning_jobs_leases ON provisioning_jobs(locked_until) WHERE status = 'running';
+CREATE INDEX IF NOT EXISTS idx_provisioning_jobs_dead_letter ON provisioning_jobs(updated_at) WHERE status = 'dead_letter';
+CREATE INDEX IF NOT EXISTS idx_provisioning_jobs_tenant_id ON provisioning_jobs(tenant_id);
+-- A tenant has at most one outstanding job of each kind
+CREATE UNIQUE INDEX IF NOT EXISTS uq_provisioning_jobs_outstanding ON provisioning_jobs(tenant_id, kind) WHERE status IN ('pending', 'running');
+
+CREATE TRIGGER trigger_provisioning_jobs_updated_at
+BEFORE UPDATE ON provisioning_jobs
+FOR EACH ROW EXECUTE FUNCTION update_updated_at();
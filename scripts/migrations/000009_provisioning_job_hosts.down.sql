@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_provisioning_jobs_db_host;
+ALTER TABLE provisioning_jobs DROP COLUMN db_host;
@@ -0,0 +1,5 @@
+-- Record the database host each provisioning job targets so workers can
+-- limit how many jobs run against one host at a time
+ALTER TABLE provisioning_jobs ADD COLUMN db_host VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_provisioning_jobs_db_host ON provisioning_jobs(db_host) WHERE status = 'running';
@@ -0,0 +1,6 @@
+DELETE FROM tenant_provisioning_logs WHERE status IN ('compensating', 'compensated', 'compensation_failed');
+ALTER TABLE tenant_provisioning_logs DROP CONSTRAINT IF EXISTS tenant_provisioning_logs_status_check;
+ALTER TABLE tenant_provisioning_logs ADD CONSTRAINT tenant_provisioning_logs_status_check
+    CHECK (status IN ('pending', 'in_progress', 'success', 'failed'));
+
+ALTER TABLE tenants DROP COLUMN IF EXISTS status_reason;
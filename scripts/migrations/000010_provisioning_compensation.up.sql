@@ -0,0 +1,7 @@
+-- Explain why a tenant is in its current status, e.g. the root cause of a provisioning failure
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS status_reason TEXT;
+
+-- Allow compensation rows in the provisioning log
+ALTER TABLE tenant_provisioning_logs DROP CONSTRAINT IF EXISTS tenant_provisioning_logs_status_check;
+ALTER TABLE tenant_provisioning_logs ADD CONSTRAINT tenant_provisioning_logs_status_check
+    CHECK (status IN ('pending', 'in_progress', 'success', 'failed', 'compensating', 'compensated', 'compensation_failed'));
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS tenant_snapshots;
+
+DROP INDEX IF EXISTS idx_tenant_schemas_drop_after;
+ALTER TABLE tenant_schemas DROP COLUMN IF EXISTS drop_after;
+
+DELETE FROM provisioning_jobs WHERE kind = 'deprovision';
+ALTER TABLE provisioning_jobs DROP CONSTRAINT IF EXISTS provisioning_jobs_kind_check;
+ALTER TABLE provisioning_jobs ADD CONSTRAINT provisioning_jobs_kind_check CHECK (kind IN ('provision'));
+
+UPDATE tenants SET status = 'inactive' WHERE status IN ('deprovisioning', 'deleted');
+ALTER TABLE tenants DROP CONSTRAINT IF EXISTS tenants_status_check;
+ALTER TABLE tenants ADD CONSTRAINT tenants_status_check
+    CHECK (status IN ('active', 'inactive', 'provisioning', 'error'));
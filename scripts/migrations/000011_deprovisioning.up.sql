@@ -0,0 +1,23 @@
+-- Deleted tenants are deprovisioned by a job of their own and end up deleted
+ALTER TABLE tenants DROP CONSTRAINT IF EXISTS tenants_status_check;
+ALTER TABLE tenants ADD CONSTRAINT tenants_status_check
+    CHECK (status IN ('active', 'inactive', 'provisioning', 'error', 'deprovisioning', 'deleted'));
+
+ALTER TABLE provisioning_jobs DROP CONSTRAINT IF EXISTS provisioning_jobs_kind_check;
+ALTER TABLE provisioning_jobs ADD CONSTRAINT provisioning_jobs_kind_check
+    CHECK (kind IN ('provision', 'deprovision'));
+
+-- When a deprovisioned tenant's schema is dropped for good
+ALTER TABLE tenant_schemas ADD COLUMN IF NOT EXISTS drop_after TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_tenant_schemas_drop_after ON tenant_schemas(drop_after) WHERE drop_after IS NOT NULL;
+
+-- Copies of tenant data taken before deprovisioning; they outlive the tenant schema
+CREATE TABLE IF NOT EXISTS tenant_snapshots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    source_schema VARCHAR(63) NOT NULL,
+    snapshot_schema VARCHAR(63) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenant_snapshots_tenant_id ON tenant_snapshots(tenant_id);
@@ -0,0 +1 @@
+ALTER TABLE tenant_schemas DROP COLUMN IF EXISTS template_version;
@@ -0,0 +1,2 @@
+-- Version of the tenant schema template (scripts/tenant-migrations) applied to each tenant schema
+ALTER TABLE tenant_schemas ADD COLUMN IF NOT EXISTS template_version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS tenant_dedicated_databases;
+
+ALTER TABLE tenant_database_configs DROP CONSTRAINT IF EXISTS tenant_database_configs_isolation_mode_check;
+ALTER TABLE tenant_database_configs DROP COLUMN IF EXISTS isolation_mode;
@@ -0,0 +1,24 @@
+-- How a tenant's data is isolated: a schema in the shared database or a database of its own
+ALTER TABLE tenant_database_configs ADD COLUMN IF NOT EXISTS isolation_mode VARCHAR(20) NOT NULL DEFAULT 'schema';
+ALTER TABLE tenant_database_configs ADD CONSTRAINT tenant_database_configs_isolation_mode_check
+    CHECK (isolation_mode IN ('schema', 'database'));
+
+-- Dedicated tenant databases, which live outside the registry database
+CREATE TABLE IF NOT EXISTS tenant_dedicated_databases (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id),
+    host VARCHAR(255) NOT NULL,
+    port INTEGER NOT NULL DEFAULT 5432,
+    database_name VARCHAR(63) NOT NULL,
+    role_name VARCHAR(63) NOT NULL,
+    template_version INTEGER NOT NULL DEFAULT 0,
+    drop_after TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    UNIQUE (host, port, database_name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenant_dedicated_databases_drop_after ON tenant_dedicated_databases(drop_after) WHERE drop_after IS NOT NULL;
+
+CREATE TRIGGER trigger_tenant_dedicated_databases_updated_at
+BEFORE UPDATE ON tenant_dedicated_databases
+FOR EACH ROW EXECUTE FUNCTION update_updated_at();
@@ -0,0 +1,16 @@
+DO $$
+DECLARE
+    r RECORD;
+BEGIN
+    FOR r IN
+        SELECT c.username
+        FROM tenant_database_configs c
+        WHERE c.isolation_mode = 'schema'
+          AND EXISTS (SELECT 1 FROM pg_roles WHERE rolname = c.username)
+    LOOP
+        EXECUTE format('ALTER ROLE %I NOLOGIN PASSWORD NULL CONNECTION LIMIT -1', r.username);
+        EXECUTE format('ALTER ROLE %I RESET ALL', r.username);
+    END LOOP;
+END $$;
+
+GRANT CREATE ON SCHEMA public TO PUBLIC;
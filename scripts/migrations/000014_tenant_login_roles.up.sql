@@ -0,0 +1,22 @@
+-- Tenant roles log in; nobody but the service may create objects in the registry's public schema
+REVOKE CREATE ON SCHEMA public FROM PUBLIC;
+
+-- Roles of existing schema tenants become login roles with the default limits.
+-- They get a password from RotateDatabaseCredentials.
+DO $$
+DECLARE
+    r RECORD;
+BEGIN
+    FOR r IN
+        SELECT c.username, s.schema_name, c.max_connections
+        FROM tenant_database_configs c
+        JOIN tenant_schemas s ON s.tenant_id = c.tenant_id
+        JOIN tenants t ON t.id = c.tenant_id
+        WHERE c.isolation_mode = 'schema' AND s.drop_after IS NULL AND t.deleted_at IS NULL
+          AND EXISTS (SELECT 1 FROM pg_roles WHERE rolname = c.username)
+    LOOP
+        EXECUTE format('ALTER ROLE %I LOGIN CONNECTION LIMIT %s', r.username, r.max_connections);
+        EXECUTE format('ALTER ROLE %I SET search_path TO %s', r.username, r.schema_name);
+        EXECUTE format('ALTER ROLE %I SET statement_timeout = 30000', r.username);
+    END LOOP;
+END $$;
@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS tenant_shared_members;
+DROP TABLE IF EXISTS tenant_shared_template;
+DROP SCHEMA IF EXISTS tenant_shared CASCADE;
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'tenant_shared_access') THEN
+        DROP OWNED BY tenant_shared_access;
+        DROP ROLE tenant_shared_access;
+    END IF;
+END $$;
+
+ALTER TABLE tenant_database_configs DROP CONSTRAINT IF EXISTS tenant_database_configs_isolation_mode_check;
+ALTER TABLE tenant_database_configs ADD CONSTRAINT tenant_database_configs_isolation_mode_check
+    CHECK (isolation_mode IN ('schema', 'database'));
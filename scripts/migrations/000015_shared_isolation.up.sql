@@ -0,0 +1,44 @@
+-- Tenants in the shared isolation mode keep their rows in the tables of the tenant_shared
+-- schema, kept apart by row-level security policies on the app.tenant_id session variable
+ALTER TABLE tenant_database_configs DROP CONSTRAINT IF EXISTS tenant_database_configs_isolation_mode_check;
+ALTER TABLE tenant_database_configs ADD CONSTRAINT tenant_database_configs_isolation_mode_check
+    CHECK (isolation_mode IN ('schema', 'database', 'shared'));
+
+CREATE SCHEMA IF NOT EXISTS tenant_shared;
+
+-- Granted to the role of every shared tenant; gives data access to the shared tables
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'tenant_shared_access') THEN
+        CREATE ROLE tenant_shared_access NOLOGIN;
+    END IF;
+END $$;
+GRANT USAGE ON SCHEMA tenant_shared TO tenant_shared_access;
+ALTER DEFAULT PRIVILEGES IN SCHEMA tenant_shared GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO tenant_shared_access;
+ALTER DEFAULT PRIVILEGES IN SCHEMA tenant_shared GRANT USAGE, SELECT ON SEQUENCES TO tenant_shared_access;
+
+-- The tenant whose rows a session may see: the app.tenant_id session variable. Tenant roles
+-- can set the variable themselves, so for them it only counts when it names their own tenant.
+CREATE OR REPLACE FUNCTION tenant_shared.current_tenant_id() RETURNS UUID
+LANGUAGE sql STABLE AS $$
+    SELECT id FROM (SELECT NULLIF(current_setting('app.tenant_id', true), '')::uuid AS id) s
+    WHERE NOT pg_has_role(current_user, 'tenant_shared_access', 'MEMBER')
+       OR current_user = 'tenant_' || replace(id::text, '-', '')
+$$;
+
+-- Version of the shared tenant template (scripts/tenant-shared-migrations) applied to tenant_shared
+CREATE TABLE IF NOT EXISTS tenant_shared_template (
+    singleton BOOLEAN PRIMARY KEY DEFAULT true CHECK (singleton),
+    version INTEGER NOT NULL DEFAULT 0
+);
+INSERT INTO tenant_shared_template (singleton) VALUES (true) ON CONFLICT DO NOTHING;
+
+-- Tenants whose rows live in the shared tables
+CREATE TABLE IF NOT EXISTS tenant_shared_members (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id),
+    -- When a deprovisioned tenant's rows are deleted for good
+    drop_after TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenant_shared_members_drop_after ON tenant_shared_members(drop_after) WHERE drop_after IS NOT NULL;
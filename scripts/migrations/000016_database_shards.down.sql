@@ -0,0 +1,4 @@
+ALTER TABLE tenant_snapshots DROP COLUMN IF EXISTS shard_id;
+DROP TABLE IF EXISTS tenant_shard_schemas;
+DROP TABLE IF EXISTS database_shards;
+ALTER TABLE tenants DROP COLUMN IF EXISTS region;
@@ -0,0 +1,45 @@
+-- Region a tenant's data should stay in; empty when it may live anywhere
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS region VARCHAR(64) NOT NULL DEFAULT '';
+
+-- Database servers tenant schemas are placed on, besides the registry database
+CREATE TABLE IF NOT EXISTS database_shards (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(63) NOT NULL UNIQUE,
+    host VARCHAR(255) NOT NULL,
+    port INTEGER NOT NULL DEFAULT 5432,
+    database_name VARCHAR(63) NOT NULL,
+    region VARCHAR(64) NOT NULL DEFAULT '',
+    capacity INTEGER NOT NULL CHECK (capacity > 0),
+    weight INTEGER NOT NULL DEFAULT 1 CHECK (weight >= 0),
+    draining BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    UNIQUE (host, port, database_name)
+);
+
+CREATE TRIGGER trigger_database_shards_updated_at
+BEFORE UPDATE ON database_shards
+FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+-- Tenant schemas placed on a shard. The row is written when the tenant is
+-- placed, before its schema exists, and counts against the shard's capacity
+-- until the schema is dropped.
+CREATE TABLE IF NOT EXISTS tenant_shard_schemas (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id),
+    shard_id UUID NOT NULL REFERENCES database_shards(id),
+    schema_name VARCHAR(63) NOT NULL,
+    template_version INTEGER NOT NULL DEFAULT 0,
+    drop_after TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    UNIQUE (shard_id, schema_name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenant_shard_schemas_drop_after ON tenant_shard_schemas(drop_after) WHERE drop_after IS NOT NULL;
+
+CREATE TRIGGER trigger_tenant_shard_schemas_updated_at
+BEFORE UPDATE ON tenant_shard_schemas
+FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+-- Snapshots of tenants on a shard are kept on that shard
+ALTER TABLE tenant_snapshots ADD COLUMN IF NOT EXISTS shard_id UUID REFERENCES database_shards(id);
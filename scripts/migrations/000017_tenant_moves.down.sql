@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS tenant_moves;
+
+DELETE FROM provisioning_jobs WHERE kind = 'move';
+ALTER TABLE provisioning_jobs DROP CONSTRAINT IF EXISTS provisioning_jobs_kind_check;
+ALTER TABLE provisioning_jobs ADD CONSTRAINT provisioning_jobs_kind_check
+    CHECK (kind IN ('provision', 'deprovision'));
+
+UPDATE tenants SET status = 'active' WHERE status = 'maintenance';
+ALTER TABLE tenants DROP CONSTRAINT IF EXISTS tenants_status_check;
+ALTER TABLE tenants ADD CONSTRAINT tenants_status_check
+    CHECK (status IN ('active', 'inactive', 'provisioning', 'error', 'deprovisioning', 'deleted'));
@@ -0,0 +1,37 @@
+-- Tenants being cut over to another shard are read-only for a short while
+ALTER TABLE tenants DROP CONSTRAINT IF EXISTS tenants_status_check;
+ALTER TABLE tenants ADD CONSTRAINT tenants_status_check
+    CHECK (status IN ('active', 'inactive', 'provisioning', 'error', 'deprovisioning', 'deleted', 'maintenance'));
+
+ALTER TABLE provisioning_jobs DROP CONSTRAINT IF EXISTS provisioning_jobs_kind_check;
+ALTER TABLE provisioning_jobs ADD CONSTRAINT provisioning_jobs_kind_check
+    CHECK (kind IN ('provision', 'deprovision', 'move'));
+
+-- Moves of tenant schemas from one shard to another. The source schema is
+-- kept after the cutover until drop_after, so a move can be checked before
+-- the old copy goes.
+CREATE TABLE IF NOT EXISTS tenant_moves (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    source_shard_id UUID NOT NULL REFERENCES database_shards(id) ON DELETE CASCADE,
+    target_shard_id UUID NOT NULL REFERENCES database_shards(id) ON DELETE CASCADE,
+    schema_name VARCHAR(63) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'failed')),
+    last_error TEXT,
+    drop_after TIMESTAMP WITH TIME ZONE,
+    source_dropped_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    completed_at TIMESTAMP WITH TIME ZONE,
+    CHECK (source_shard_id <> target_shard_id)
+);
+
+-- A tenant moves to one shard at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenant_moves_pending ON tenant_moves(tenant_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_tenant_moves_tenant_id ON tenant_moves(tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_tenant_moves_drop_after ON tenant_moves(drop_after)
+    WHERE drop_after IS NOT NULL AND source_dropped_at IS NULL;
+
+CREATE TRIGGER trigger_tenant_moves_updated_at
+BEFORE UPDATE ON tenant_moves
+FOR EACH ROW EXECUTE FUNCTION update_updated_at();
@@ -0,0 +1,3 @@
+-- Records that do not fit are cleared rather than cut into invalid ones
+ALTER TABLE tenant_specific_configs ALTER COLUMN dns_record TYPE VARCHAR(255)
+    USING CASE WHEN length(dns_record) <= 255 THEN dns_record END;
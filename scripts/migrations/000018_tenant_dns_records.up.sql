@@ -0,0 +1,3 @@
+-- Records are kept in zone file form, which two hostnames of up to 253
+-- characters do not fit in 255
+ALTER TABLE tenant_specific_configs ALTER COLUMN dns_record TYPE TEXT;
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS trigger_tenant_custom_domains_updated_at ON tenant_custom_domains;
+DROP TABLE IF EXISTS tenant_custom_domains;
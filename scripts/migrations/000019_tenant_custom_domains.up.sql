@@ -0,0 +1,24 @@
+-- Custom domains tenants are reachable under besides their subdomain. A
+-- domain is attached pending, with a token its owner publishes in a TXT
+-- record before token_expires_at, and resolves to the tenant once verified.
+CREATE TABLE IF NOT EXISTS tenant_custom_domains (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    domain VARCHAR(253) NOT NULL CHECK (domain = lower(domain)),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'verified')),
+    verification_token VARCHAR(64) NOT NULL,
+    token_expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    verified_at TIMESTAMP WITH TIME ZONE,
+    last_checked_at TIMESTAMP WITH TIME ZONE,
+    last_check_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    UNIQUE (tenant_id, domain)
+);
+
+-- Several tenants may claim a domain, but only one can prove it owns it
+CREATE UNIQUE INDEX IF NOT EXISTS uq_tenant_custom_domains_verified ON tenant_custom_domains(domain) WHERE status = 'verified';
+
+CREATE TRIGGER trigger_tenant_custom_domains_updated_at
+BEFORE UPDATE ON tenant_custom_domains
+FOR EACH ROW EXECUTE FUNCTION update_updated_at();
@@ -0,0 +1,6 @@
+INSERT INTO tier_quotas (tier, resource, quota_limit) VALUES
+    ('free', 'schema_storage_bytes', 104857600),
+    ('basic', 'schema_storage_bytes', 1073741824),
+    ('premium', 'schema_storage_bytes', 10737418240),
+    ('enterprise', 'schema_storage_bytes', -1)
+ON CONFLICT DO NOTHING;
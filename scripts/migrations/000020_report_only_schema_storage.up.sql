@@ -0,0 +1,4 @@
+-- Schema storage is reported but not limited, since it can only be measured
+-- for schemas in the registry database
+DELETE FROM tenant_quota_overrides WHERE resource = 'schema_storage_bytes';
+DELETE FROM tier_quotas WHERE resource = 'schema_storage_bytes';
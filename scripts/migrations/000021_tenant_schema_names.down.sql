@@ -0,0 +1,13 @@
+CREATE OR REPLACE FUNCTION create_tenant_schema(p_tenant_id UUID, p_subdomain VARCHAR)
+RETURNS VOID AS $$
+DECLARE
+    v_schema VARCHAR(63) := format('tenant_%I', p_subdomain);
+BEGIN
+    IF EXISTS (SELECT 1 FROM tenant_schemas WHERE schema_name = v_schema) THEN
+        v_schema := format('tenant_%I', left(p_subdomain, 46) || '_' || left(replace(p_tenant_id::text, '-', ''), 8));
+    END IF;
+    EXECUTE format('CREATE SCHEMA %s', v_schema);
+    INSERT INTO tenant_schemas (tenant_id, schema_name)
+    VALUES (p_tenant_id, v_schema);
+END;
+$$ LANGUAGE plpgsql;
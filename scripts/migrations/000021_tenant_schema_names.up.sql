@@ -0,0 +1,16 @@
+-- Schema names are cut so they fit in 63 characters and have hyphens turned
+-- into underscores, and the whole name is quoted when the schema is created.
+-- Existing schemas keep their names.
+CREATE OR REPLACE FUNCTION create_tenant_schema(p_tenant_id UUID, p_subdomain VARCHAR)
+RETURNS VOID AS $$
+DECLARE
+    v_schema VARCHAR(63) := 'tenant_' || replace(left(p_subdomain, 56), '-', '_');
+BEGIN
+    IF EXISTS (SELECT 1 FROM tenant_schemas WHERE schema_name = v_schema) THEN
+        v_schema := 'tenant_' || replace(left(p_subdomain, 46), '-', '_') || '_' || left(replace(p_tenant_id::text, '-', ''), 8);
+    END IF;
+    EXECUTE format('CREATE SCHEMA %I', v_schema);
+    INSERT INTO tenant_schemas (tenant_id, schema_name)
+    VALUES (p_tenant_id, v_schema);
+END;
+$$ LANGUAGE plpgsql;
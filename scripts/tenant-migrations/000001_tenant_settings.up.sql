@@ -0,0 +1,6 @@
+-- Key/value settings owned by the tenant's applications
+CREATE TABLE IF NOT EXISTS tenant_settings (
+    key VARCHAR(100) PRIMARY KEY,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
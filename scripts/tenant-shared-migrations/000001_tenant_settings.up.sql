@@ -0,0 +1,15 @@
+-- Key/value settings owned by the tenants' applications
+CREATE TABLE IF NOT EXISTS tenant_settings (
+    tenant_id UUID NOT NULL DEFAULT tenant_shared.current_tenant_id(),
+    key VARCHAR(100) NOT NULL,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (tenant_id, key)
+);
+
+ALTER TABLE tenant_settings ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tenant_settings FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON tenant_settings;
+CREATE POLICY tenant_isolation ON tenant_settings
+    USING (tenant_id = tenant_shared.current_tenant_id())
+    WITH CHECK (tenant_id = tenant_shared.current_tenant_id());